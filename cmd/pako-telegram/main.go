@@ -3,52 +3,124 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/rashpile/pako-telegram/internal/audit"
 	"github.com/rashpile/pako-telegram/internal/auth"
+	"github.com/rashpile/pako-telegram/internal/backup"
 	"github.com/rashpile/pako-telegram/internal/bot"
 	"github.com/rashpile/pako-telegram/internal/command"
 	"github.com/rashpile/pako-telegram/internal/command/builtin"
 	"github.com/rashpile/pako-telegram/internal/config"
 	"github.com/rashpile/pako-telegram/internal/executor"
+	"github.com/rashpile/pako-telegram/internal/fileref"
+	"github.com/rashpile/pako-telegram/internal/history"
+	"github.com/rashpile/pako-telegram/internal/inbox"
+	"github.com/rashpile/pako-telegram/internal/logbuf"
 	"github.com/rashpile/pako-telegram/internal/msgstore"
 	"github.com/rashpile/pako-telegram/internal/scheduler"
+	"github.com/rashpile/pako-telegram/internal/settings"
 	"github.com/rashpile/pako-telegram/internal/status"
+	"github.com/rashpile/pako-telegram/internal/transcribe"
+	"github.com/rashpile/pako-telegram/internal/watcher"
+	"github.com/rashpile/pako-telegram/internal/webhook"
 	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
 func main() {
 	configPath := flag.String("config", "config.yaml", "path to configuration file")
+	validate := flag.Bool("validate", false, "validate config and commands, then exit")
+	validateDir := flag.String("validate-commands", "", "validate commands in comma-separated `dirs` without loading config, then exit")
+	containers := flag.Bool("containers", false, "print Docker container status and exit (status 1 if any need attention)")
 	flag.Parse()
 
-	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
-	slog.SetDefault(logger)
+	if *containers {
+		os.Exit(printContainers(os.Stdout))
+	}
+
+	if *validate || *validateDir != "" {
+		var problems int
+		if *validateDir != "" {
+			problems = validateCommands(strings.Split(*validateDir, ","), "", &config.Config{}, os.Stdout)
+		} else {
+			problems = validateConfig(*configPath, os.Stdout)
+		}
+		if problems > 0 {
+			os.Exit(1)
+		}
+		return
+	}
 
-	if err := run(*configPath); err != nil {
+	// Recent records are also kept for /logs, and warnings and errors can
+	// be mirrored to a chat once the bot is up
+	logs := logbuf.New(logbuf.DefaultSize)
+	mirror := logbuf.NewMirror()
+	handler := logbuf.NewHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}), logs)
+	handler.SetMirror(mirror)
+	slog.SetDefault(slog.New(handler))
+
+	if err := run(*configPath, logs, mirror); err != nil {
+		if errors.Is(err, errRestart) {
+			slog.Info("exiting for restart", "code", restartExitCode)
+			os.Exit(restartExitCode)
+		}
 		slog.Error("fatal error", "error", err)
 		os.Exit(1)
 	}
 }
 
-func run(configPath string) error {
+// restartExitCode is the exit status after /restart. It is non-zero so
+// that systemd's Restart=on-failure and Docker's on-failure restart policy
+// start the bot again.
+const restartExitCode = 75
+
+// schedulerStateFile holds paused schedules and interval timing across
+// restarts, next to the database.
+const schedulerStateFile = "scheduler-state.json"
+
+// errRestart is returned by run when the bot stopped for /restart.
+var errRestart = errors.New("restart requested")
+
+// restartSignal asks run to restart the bot.
+type restartSignal chan struct{}
+
+// Restart requests a restart; repeated requests are ignored.
+func (r restartSignal) Restart() {
+	select {
+	case r <- struct{}{}:
+	default:
+	}
+}
+
+func run(configPath string, logs *logbuf.Buffer, mirror *logbuf.Mirror) error {
 	cfg, err := config.Load(configPath)
 	if err != nil {
 		return err
 	}
+	logs.Resize(cfg.Logs.Buffer)
 
 	// Resolve paths relative to config file
-	commandsDir := cfg.ExpandPath(configPath, cfg.CommandsDir)
+	commandDirs := cfg.CommandDirs(configPath)
 	dbPath := cfg.ExpandPath(configPath, cfg.Database.Path)
+	statePath := filepath.Join(filepath.Dir(dbPath), schedulerStateFile)
 
 	slog.Info("configuration loaded",
-		"commands_dir", commandsDir,
+		"commands_dirs", commandDirs,
 		"database", dbPath,
 	)
 
@@ -59,22 +131,61 @@ func run(configPath string) error {
 	}
 	defer auditLogger.Close()
 
+	// Per-chat preferences chosen with /settings
+	chatSettings, err := settings.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer chatSettings.Close()
+
+	// Metric samples for the trends in /status
+	metricsHistory, err := history.Open(dbPath)
+	if err != nil {
+		return err
+	}
+	defer metricsHistory.Close()
+
 	// Set up authorization
-	authorizer := auth.NewAllowlist(cfg.Telegram.AllowedChatIDs)
+	allowlist := auth.NewAllowlist(cfg.Telegram.AllowedChatIDs)
+	allowlist.ReloadUsers(cfg.Telegram.AllowedUserIDs, cfg.Telegram.AllowedUsernames)
+	roles, err := auth.NewRoleMap(cfg.Roles.Default, cfg.Roles.Chats, cfg.Roles.Users)
+	if err != nil {
+		return err
+	}
+	otp, err := auth.NewOTPVerifier(cfg.OTP.Secrets)
+	if err != nil {
+		return err
+	}
+	sudo, err := auth.NewSudo(cfg.Sudo.PINs, cfg.Sudo.Duration)
+	if err != nil {
+		return err
+	}
 
 	// Set up executor
 	exec := executor.NewShellExecutor()
 
 	// Set up command registry
 	registry := command.NewRegistry()
+	registry.SetCategories(cfg.Categories)
+
+	// Per-command policies are checked centrally for commands, menus and callbacks
+	authorizer := auth.NewPolicyAuthorizer(allowlist, registry.Get,
+		auth.RolePolicy(roles),
+		auth.CommandAllowlistPolicy(),
+		auth.TimeWindowPolicy(nil),
+		auth.ElevationPolicy(sudo),
+	)
 
-	// Set up YAML loader
-	loader := command.NewLoader(commandsDir, cfg.Defaults, exec)
+	// Set up YAML and plugin loader
+	loader := command.NewLoader(commandDirs, cfg.Defaults, exec)
+	loader.SetCategories(cfg.Categories)
+	loader.SetPluginsDir(cfg.PluginsPath(configPath))
+	defer loader.Close()
 
-	// Load YAML commands
+	// Load YAML and plugin commands
 	yamlCommands, err := loader.Load()
 	if err != nil {
-		slog.Warn("failed to load yaml commands", "error", err)
+		slog.Warn("failed to load commands", "error", err)
 	} else {
 		for _, cmd := range yamlCommands {
 			registry.Register(cmd)
@@ -83,27 +194,37 @@ func run(configPath string) error {
 	}
 
 	// Register built-in commands
-	registry.Register(builtin.NewHelpCommand(registry))
-	registry.Register(builtin.NewStatusCommand(status.NewGopsutilCollector()))
+	helpCmd := builtin.NewHelpCommand(registry)
+	helpCmd.SetUsage(auditLogger)
+	registry.RegisterBuiltin(helpCmd)
+	collector := status.NewGopsutilCollector()
+	collector.SetMounts(cfg.Status.MountPoints(), cfg.Status.ExcludeFSTypes)
+	statusCmd := builtin.NewStatusCommand(collector)
+	statusCmd.SetHistory(metricsHistory)
+	registry.RegisterBuiltin(statusCmd)
+	registry.RegisterBuiltin(builtin.NewTopCommand())
+	registerContainers(registry, cfg.Status.DockerSocket)
+	units := status.NewUnitMonitor(cfg.Status.Units)
+	registry.RegisterBuiltin(builtin.NewServicesCommand(units))
+	alerts := status.NewAlertMonitor(collector, cfg.Alerts.Rules)
 	reloadCmd := builtin.NewReloadCommand(loader, registry)
-	registry.Register(reloadCmd)
-	registry.Register(builtin.NewVersionCommand())
+	registry.RegisterBuiltin(reloadCmd)
+	registry.RegisterBuiltin(builtin.NewVersionCommand())
+	registry.RegisterBuiltin(builtin.NewGrantCommand(allowlist))
+	registry.RegisterBuiltin(builtin.NewSecurityCommand(auditLogger))
+	registry.RegisterBuiltin(builtin.NewStatsCommand(auditLogger, registry))
 	scheduledCmd := builtin.NewScheduledCommand()
-	registry.Register(scheduledCmd)
+	registry.RegisterBuiltin(scheduledCmd)
+	restart := make(restartSignal, 1)
+	registry.RegisterBuiltin(builtin.NewRestartCommand(restart))
 
 	// Register podcast command if configured
-	if cfg.Podcast.PodcastgenPath != "" {
-		podcastCfg := builtin.PodcastConfig{
-			PodcastgenPath: cfg.ExpandPath(configPath, cfg.Podcast.PodcastgenPath),
-			ConfigPath:     cfg.ExpandPath(configPath, cfg.Podcast.ConfigPath),
-			TempDir:        cfg.Podcast.TempDir,
-		}
-		registry.Register(builtin.NewPodcastCommand(podcastCfg))
-		slog.Info("podcast command enabled", "path", podcastCfg.PodcastgenPath)
-	}
+	registerPodcast(registry, cfg, configPath)
+	registerGet(registry, cfg.Get.AllowedPaths)
 
 	// Set up message store for cleanup functionality
 	var msgStore *msgstore.Store
+	stateFiles := []string{statePath}
 	if cfg.MessageStorePath != "" {
 		storePath := cfg.ExpandPath(configPath, cfg.MessageStorePath)
 		msgStore, err = msgstore.New(storePath)
@@ -111,29 +232,119 @@ func run(configPath string) error {
 			return err
 		}
 		slog.Info("message store enabled", "path", storePath)
+		stateFiles = append(stateFiles, storePath)
+	}
+
+	// Archives of config, commands and state; changes need a restart
+	backupCmd := builtin.NewBackupCommand(backup.Sources{
+		Config:      configPath,
+		CommandDirs: commandDirs,
+		Files:       stateFiles,
+		Database:    auditLogger,
+	}, newBackupS3(cfg.Backup.S3))
+	registry.RegisterBuiltin(backupCmd)
+
+	// Downloads for [url:...] references (disabled without allowed hosts)
+	downloader := fileref.NewDownloader(cfg.URLFiles.AllowedHosts, int64(cfg.URLFiles.MaxSizeMB)<<20, cfg.URLFiles.Timeout)
+
+	// File inbox for commands that take uploaded files
+	var fileInbox *inbox.Inbox
+	if cfg.Inbox.Dir != "" {
+		fileInbox, err = inbox.New(cfg.ExpandPath(configPath, cfg.Inbox.Dir), cfg.Inbox.Retention)
+		if err != nil {
+			return err
+		}
+		slog.Info("file inbox enabled", "path", fileInbox.Dir())
 	}
 
 	// Create bot with dependencies
 	b, err := bot.New(bot.Config{
-		Token:          cfg.Telegram.Token,
-		Authorizer:     authorizer,
-		Registry:       registry,
-		Defaults:       cfg.Defaults,
-		AllowedChatIDs: cfg.Telegram.AllowedChatIDs,
-		MessageStore:   msgStore,
+		Token:             cfg.Telegram.Token,
+		Authorizer:        authorizer,
+		Registry:          registry,
+		Defaults:          cfg.Defaults,
+		AllowedChatIDs:    cfg.Telegram.AllowedChatIDs,
+		MessageStore:      msgStore,
+		AuditLogger:       auditLogger,
+		AdminChatID:       cfg.Telegram.AdminChatID,
+		Roles:             roles,
+		ApprovalsChatID:   cfg.Telegram.ApprovalsChatID,
+		OTP:               otp,
+		Sudo:              sudo,
+		Downloader:        downloader,
+		Inbox:             fileInbox,
+		Transcriber:       newTranscriber(cfg.Transcription),
+		Transcription:     cfg.Transcription,
+		Menus:             cfg.Menus,
+		RateLimits:        cfg.RateLimit,
+		TrackUserCommands: cfg.TrackUserCommands,
+		Language:          cfg.Language,
+		ChatLanguages:     cfg.ChatLanguages,
+		Settings:          chatSettings,
+		Logs:              logs,
+		Shell:             cfg.Shell,
 	})
 	if err != nil {
 		return err
 	}
-	_ = auditLogger // TODO: wire into bot for command logging
+
+	configureMirror(mirror, cfg.Logs.Mirror, b)
+	registry.RegisterBuiltin(builtin.NewMaintenanceCommand(b))
+	registerPut(registry, cfg, configPath, fileInbox, b)
 
 	// Create scheduler (always, even if no scheduled commands yet)
 	sched := createScheduler(yamlCommands, cfg.Telegram.AllowedChatIDs, b)
 
 	// Wire scheduler with bot and reload command
 	b.SetScheduler(sched)
-	reloadCmd.SetScheduler(&schedulerAdapter{sched: sched})
+	if len(cfg.AutoCleanup.Schedule) > 0 {
+		if msgStore == nil {
+			slog.Warn("auto_cleanup needs message_store_path; skipping")
+		} else {
+			job, err := autoCleanupJob(cfg.AutoCleanup, b)
+			if err != nil {
+				return err
+			}
+			sched.AddJob(job)
+		}
+	}
+	if len(cfg.Backup.Schedule) > 0 {
+		job, err := backupJob(cfg.Backup, backupCmd, b)
+		if err != nil {
+			return err
+		}
+		sched.AddJob(job)
+	}
+
+	// Paused schedules and interval timing survive restarts
+	if err := sched.LoadState(statePath); err != nil {
+		slog.Warn("failed to restore scheduler state", "error", err)
+	}
+	registry.OnChange(schedulerHook(registry, &schedulerAdapter{sched: sched}))
 	scheduledCmd.SetScheduleLister(sched)
+	statusCmd.SetSelfReporter(b)
+
+	// Config hot reload (SIGHUP or /reload config)
+	cfgReloader := &configReloader{
+		path:       configPath,
+		current:    cfg,
+		authorizer: authorizer,
+		roles:      roles,
+		otp:        otp,
+		sudo:       sudo,
+		downloader: downloader,
+		inbox:      fileInbox,
+		bot:        b,
+		collector:  collector,
+		units:      units,
+		alerts:     alerts,
+		loader:     loader,
+		registry:   registry,
+		sched:      sched,
+		logs:       logs,
+		mirror:     mirror,
+	}
+	reloadCmd.SetConfigReloader(cfgReloader)
 
 	// Set up graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -148,6 +359,25 @@ func run(configPath string) error {
 		cancel()
 	}()
 
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+
+	go func() {
+		for range hupCh {
+			slog.Info("received SIGHUP, reloading configuration")
+			if err := cfgReloader.ReloadConfig(); err != nil {
+				slog.Error("config reload failed", "error", err)
+				b.NotifyAdmin(fmt.Sprintf("Config reload failed: %v", err))
+				continue
+			}
+			// Re-apply defaults and category settings to commands
+			if _, err := reloadCmd.Reload(); err != nil {
+				slog.Error("command reload failed", "error", err)
+				b.NotifyAdmin(fmt.Sprintf("Command reload failed: %v", err))
+			}
+		}
+	}()
+
 	// Start scheduler in background
 	go func() {
 		if err := sched.Run(ctx); err != nil && err != context.Canceled {
@@ -155,25 +385,358 @@ func run(configPath string) error {
 		}
 	}()
 
+	// Expire temporary access grants
+	go allowlist.RunExpiry(ctx, time.Minute, func(g auth.Grant) {
+		slog.Info("temporary access expired", "target", g.Target())
+		b.NotifyAdmin(fmt.Sprintf("Temporary access for %s expired", g.Target()))
+	})
+
+	// Forget tracked messages too old to matter so the store stays small
+	if msgStore != nil {
+		go msgStore.RunExpiry(ctx, cfg.MessageStoreTTL, time.Hour, func(removed int, err error) {
+			if err != nil {
+				slog.Warn("message store purge failed", "error", err)
+				return
+			}
+			slog.Info("message store purged", "removed", removed)
+		})
+	}
+
+	// Alert the admin chat when a monitored systemd unit fails or recovers.
+	// Units changed by a config reload are picked up; the interval is not.
+	go units.Run(ctx, cfg.Status.UnitCheckInterval, func(u status.Unit, failed bool) {
+		if failed {
+			slog.Warn("systemd unit failed", "unit", u.Name, "result", u.Result)
+			b.NotifyAdmin(fmt.Sprintf("❌ Unit %s failed (%s)", u.Name, u.Result))
+			return
+		}
+		slog.Info("systemd unit recovered", "unit", u.Name, "state", u.ActiveState)
+		b.NotifyAdmin(fmt.Sprintf("✅ Unit %s recovered (%s)", u.Name, u.ActiveState))
+	})
+
+	// Record metrics for /status trends
+	go metricsHistory.Run(ctx, collector, cfg.Status.HistoryInterval, cfg.Status.HistoryRetention)
+
+	// Alert when a metric crosses a configured threshold, and again when it
+	// recovers. Rules changed by a config reload are picked up; the interval
+	// and chats are not.
+	go alerts.Run(ctx, cfg.Alerts.Interval, func(a status.Alert) {
+		rule := a.Rule
+		if a.Firing {
+			slog.Warn("alert firing", "rule", rule.Name, "subject", a.Subject(), "value", a.Value)
+			text := fmt.Sprintf("🔥 %s: %s at %.1f (above %g", rule.Name, a.Subject(), a.Value, rule.Above)
+			if rule.For > 0 {
+				text += " for " + rule.For.String()
+			}
+			b.NotifyChats(cfg.Alerts.ChatIDs, text+")")
+			return
+		}
+		slog.Info("alert recovered", "rule", rule.Name, "subject", a.Subject(), "value", a.Value)
+		b.NotifyChats(cfg.Alerts.ChatIDs, fmt.Sprintf("✅ %s recovered: %s at %.1f", rule.Name, a.Subject(), a.Value))
+	})
+
+	// Post a liveness message to the ops chat
+	go b.RunHeartbeat(ctx, cfg.Heartbeat)
+
+	// Let other systems trigger commands over HTTP. Changes need a restart.
+	if cfg.Webhook.Listen != "" {
+		if len(cfg.Webhook.Commands) == 0 {
+			slog.Warn("webhook.commands is empty; no command can be triggered")
+		}
+		hooks := webhook.New(cfg.Webhook.Token, cfg.Webhook.ChatID, cfg.Webhook.Commands, b)
+		go func() {
+			if err := hooks.Run(ctx, cfg.Webhook.Listen); err != nil {
+				slog.Error("webhook server error", "error", err)
+				b.NotifyAdmin(fmt.Sprintf("Webhook server failed: %v", err))
+			}
+		}()
+	}
+
+	// Reload commands automatically when YAML files change
+	if cfg.WatchCommands {
+		w := watcher.New(commandDirs, watcher.DefaultDebounce, func() {
+			count, err := reloadCmd.Reload()
+			if err != nil {
+				slog.Error("automatic reload failed", "error", err)
+				b.NotifyAdmin(fmt.Sprintf("Command reload failed: %v", err))
+				return
+			}
+			slog.Info("commands reloaded after file change", "count", count)
+		})
+		go func() {
+			if err := w.Run(ctx); err != nil && err != context.Canceled {
+				slog.Error("commands watcher error", "error", err)
+			}
+		}()
+	}
+
+	// On /restart, stop taking commands and let running ones finish
+	var restarting atomic.Bool
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-restart:
+		}
+		slog.Info("restart requested")
+		b.NotifyRestart()
+		if running := b.Drain(cfg.Restart.DrainTimeout); running > 0 {
+			slog.Warn("restarting with commands still running", "running", running)
+		}
+		restarting.Store(true)
+		cancel()
+	}()
+
 	// Notify users that bot has restarted
 	b.NotifyStartup()
 
 	slog.Info("starting bot")
-	return b.Run(ctx)
+	err = b.Run(ctx)
+	if saveErr := sched.SaveState(statePath); saveErr != nil {
+		slog.Warn("failed to save scheduler state", "error", saveErr)
+	}
+	if err == nil && restarting.Load() {
+		return errRestart
+	}
+	return err
+}
+
+// configureMirror forwards warnings and errors to the configured chat, or
+// stops forwarding when none is set.
+func configureMirror(mirror *logbuf.Mirror, cfg config.LogMirrorConfig, b *bot.Bot) {
+	var send func(text string)
+	if cfg.ChatID != 0 {
+		chatIDs := []int64{cfg.ChatID}
+		send = func(text string) { b.NotifyChats(chatIDs, text) }
+	}
+	mirror.Configure(send, cfg.SlogLevel(), cfg.Window, cfg.Limit)
+}
+
+// registerPodcast registers the podcast command if configured, or removes it otherwise.
+func registerPodcast(registry *command.Registry, cfg *config.Config, configPath string) {
+	if cfg.Podcast.PodcastgenPath == "" {
+		registry.Unregister("podcast")
+		return
+	}
+
+	podcastCfg := builtin.PodcastConfig{
+		PodcastgenPath: cfg.ExpandPath(configPath, cfg.Podcast.PodcastgenPath),
+		ConfigPath:     cfg.ExpandPath(configPath, cfg.Podcast.ConfigPath),
+		TempDir:        cfg.Podcast.TempDir,
+		Voices:         cfg.Podcast.Voices,
+		Speeds:         cfg.Podcast.Speeds,
+		ChunkSize:      cfg.Podcast.ChunkSize,
+		Parallel:       cfg.Podcast.Parallel,
+		Timeout:        cfg.Podcast.Timeout,
+		VoiceNote:      cfg.Podcast.VoiceNote,
+		MaxJobs:        cfg.Podcast.MaxJobs,
+	}
+	// Keep the job queue across reloads
+	if existing, ok := registry.Get("podcast").(*builtin.PodcastCommand); ok {
+		registry.RegisterBuiltin(existing.Reconfigure(podcastCfg))
+	} else {
+		registry.RegisterBuiltin(builtin.NewPodcastCommand(podcastCfg))
+	}
+	slog.Info("podcast command enabled", "path", podcastCfg.PodcastgenPath)
+}
+
+// registerGet registers /get if any paths are allowed, or removes it otherwise.
+func registerGet(registry *command.Registry, allowed []string) {
+	if len(allowed) == 0 {
+		if _, ok := registry.Get("get").(*builtin.GetCommand); ok {
+			registry.Unregister("get")
+		}
+		return
+	}
+	registry.RegisterBuiltin(builtin.NewGetCommand(allowed))
+	slog.Info("get command enabled", "allowed_paths", allowed)
+}
+
+// registerPut registers /put if put.dir is set, or removes it otherwise.
+// Uploads reach it through the inbox, so it stays off without one.
+func registerPut(registry *command.Registry, cfg *config.Config, configPath string, fileInbox *inbox.Inbox, recorder builtin.PutRecorder) {
+	if cfg.Put.Dir == "" || fileInbox == nil {
+		if cfg.Put.Dir != "" {
+			slog.Warn("put.dir set but the inbox is not enabled; /put disabled until restart")
+		}
+		if _, ok := registry.Get("put").(*builtin.PutCommand); ok {
+			registry.Unregister("put")
+		}
+		return
+	}
+	putDir := cfg.ExpandPath(configPath, cfg.Put.Dir)
+	registry.RegisterBuiltin(builtin.NewPutCommand(fileInbox, recorder, builtin.PutConfig{
+		Dir:       putDir,
+		MaxSize:   int64(cfg.Put.MaxSizeMB) << 20,
+		Overwrite: cfg.Put.Overwrite,
+	}))
+	slog.Info("put command enabled", "dir", putDir)
+}
+
+// printContainers writes the /containers report for use in scripts and
+// scheduled commands. Returns the process exit status.
+func printContainers(output io.Writer) int {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	containers, err := status.NewDockerClient("").Containers(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if builtin.WriteContainers(output, containers, false) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// registerContainers registers /containers if the Docker socket exists.
+func registerContainers(registry *command.Registry, socket string) {
+	socket = status.DockerSocket(socket)
+	if _, err := os.Stat(socket); err != nil {
+		slog.Info("docker socket not found; /containers disabled", "socket", socket)
+		return
+	}
+	registry.RegisterBuiltin(builtin.NewContainersCommand(status.NewDockerClient(socket)))
+	slog.Info("containers command enabled", "socket", socket)
+}
+
+// newTranscriber returns the configured speech-to-text backend, or nil.
+func newTranscriber(cfg config.TranscriptionConfig) transcribe.Transcriber {
+	switch {
+	case cfg.Command != "":
+		slog.Info("voice transcription enabled", "backend", "command", "target", cfg.Target)
+		return transcribe.NewCommand(cfg.Command)
+	case cfg.APIURL != "":
+		slog.Info("voice transcription enabled", "backend", "api", "target", cfg.Target)
+		return transcribe.NewAPI(cfg.APIURL, cfg.APIKey, cfg.Model, cfg.Language)
+	}
+	return nil
+}
+
+// configReloader re-reads config.yaml and applies settings that can change
+// at runtime. Implements builtin.ConfigReloader.
+type configReloader struct {
+	mu         sync.Mutex
+	path       string
+	current    *config.Config
+	authorizer auth.Authorizer
+	roles      *auth.RoleMap
+	otp        *auth.OTPVerifier
+	sudo       *auth.Sudo
+	downloader *fileref.Downloader
+	inbox      *inbox.Inbox // Nil without inbox.dir, which needs a restart
+	bot        *bot.Bot
+	collector  *status.GopsutilCollector
+	units      *status.UnitMonitor
+	alerts     *status.AlertMonitor
+	loader     *command.Loader
+	registry   *command.Registry
+	sched      *scheduler.Scheduler
+	logs       *logbuf.Buffer
+	mirror     *logbuf.Mirror
+}
+
+// ReloadConfig reloads the allowlist, defaults, podcast and scheduler settings.
+// The bot token, commands directory and database path require a restart.
+func (r *configReloader) ReloadConfig() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := config.Load(r.path)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Telegram.Token != r.current.Telegram.Token ||
+		!slices.Equal(cfg.CommandsDir, r.current.CommandsDir) ||
+		cfg.Database.Path != r.current.Database.Path {
+		slog.Warn("token, commands_dir and database changes require a restart")
+	}
+
+	if err := r.roles.Reload(cfg.Roles.Default, cfg.Roles.Chats, cfg.Roles.Users); err != nil {
+		return err
+	}
+	if err := r.otp.Reload(cfg.OTP.Secrets); err != nil {
+		return err
+	}
+	if err := r.sudo.Reload(cfg.Sudo.PINs, cfg.Sudo.Duration); err != nil {
+		return err
+	}
+	r.downloader.Reload(cfg.URLFiles.AllowedHosts, int64(cfg.URLFiles.MaxSizeMB)<<20, cfg.URLFiles.Timeout)
+	r.authorizer.Reload(cfg.Telegram.AllowedChatIDs)
+	r.authorizer.ReloadUsers(cfg.Telegram.AllowedUserIDs, cfg.Telegram.AllowedUsernames)
+	r.bot.UpdateSettings(cfg.Defaults, cfg.Telegram.AllowedChatIDs, cfg.Telegram.AdminChatID)
+	r.bot.SetRateLimits(cfg.RateLimit)
+	r.bot.SetChatMenus(cfg.Menus)
+	r.bot.SetLanguages(cfg.Language, cfg.ChatLanguages)
+	r.collector.SetMounts(cfg.Status.MountPoints(), cfg.Status.ExcludeFSTypes)
+	r.units.SetUnits(cfg.Status.Units)
+	r.alerts.SetRules(cfg.Alerts.Rules)
+	r.loader.SetDefaults(cfg.Defaults)
+	r.loader.SetCategories(cfg.Categories)
+	r.loader.SetPluginsDir(cfg.PluginsPath(r.path))
+	r.registry.SetCategories(cfg.Categories)
+	r.logs.Resize(cfg.Logs.Buffer)
+	configureMirror(r.mirror, cfg.Logs.Mirror, r.bot)
+	r.sched.SetChatIDs(cfg.Telegram.AllowedChatIDs)
+	registerPodcast(r.registry, cfg, r.path)
+	registerGet(r.registry, cfg.Get.AllowedPaths)
+	registerPut(r.registry, cfg, r.path, r.inbox, r.bot)
+
+	r.current = cfg
+	slog.Info("configuration reloaded", "allowed_chats", len(cfg.Telegram.AllowedChatIDs))
+	return nil
 }
 
-// schedulerAdapter wraps a scheduler to implement builtin.SchedulerUpdater.
+// ReloadAllowlist re-reads config.yaml and applies only the chat and user
+// allowlists and admin chat, leaving other settings untouched.
+func (r *configReloader) ReloadAllowlist() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := config.Load(r.path)
+	if err != nil {
+		return 0, err
+	}
+
+	chatIDs := cfg.Telegram.AllowedChatIDs
+	r.authorizer.Reload(chatIDs)
+	r.authorizer.ReloadUsers(cfg.Telegram.AllowedUserIDs, cfg.Telegram.AllowedUsernames)
+	r.bot.UpdateSettings(r.current.Defaults, chatIDs, cfg.Telegram.AdminChatID)
+	r.sched.SetChatIDs(chatIDs)
+
+	r.current.Telegram.AllowedChatIDs = chatIDs
+	r.current.Telegram.AdminChatID = cfg.Telegram.AdminChatID
+	slog.Info("allowlist reloaded", "allowed_chats", len(chatIDs))
+	return len(chatIDs), nil
+}
+
+// schedulerAdapter updates a scheduler from registered commands.
 type schedulerAdapter struct {
 	sched *scheduler.Scheduler
 }
 
-// UpdateScheduledCommands implements builtin.SchedulerUpdater.
+// UpdateScheduledCommands schedules the commands with schedules or intervals.
 func (a *schedulerAdapter) UpdateScheduledCommands(cmds []pkgcmd.Command) {
 	scheduled := extractScheduledCommands(cmds)
 	a.sched.UpdateCommands(scheduled)
 	slog.Info("scheduler updated", "scheduled_commands", len(scheduled))
 }
 
+// schedulerHook returns a registry hook updating the scheduler when YAML
+// commands, the only schedulable ones, change.
+func schedulerHook(registry *command.Registry, updater *schedulerAdapter) command.ChangeHook {
+	return func(changes []command.Change) {
+		for _, c := range changes {
+			if _, ok := c.Command.(*command.YAMLCommand); ok {
+				updater.UpdateScheduledCommands(registry.All())
+				return
+			}
+		}
+	}
+}
+
 // createScheduler creates a scheduler and loads any scheduled commands.
 // Always returns a scheduler (even if no commands are scheduled yet).
 func createScheduler(cmds []pkgcmd.Command, chatIDs []int64, exec scheduler.CommandExecutor) *scheduler.Scheduler {
@@ -200,6 +763,11 @@ func extractScheduledCommands(cmds []pkgcmd.Command) []scheduler.ScheduledComman
 			continue
 		}
 
+		// Disabled commands are never run automatically
+		if yamlCmd.Metadata().Disabled {
+			continue
+		}
+
 		schedTimes := yamlCmd.Schedule()
 		interval := yamlCmd.Interval()
 
@@ -231,3 +799,74 @@ func extractScheduledCommands(cmds []pkgcmd.Command) []scheduler.ScheduledComman
 
 	return scheduled
 }
+
+// autoCleanupJob builds the scheduler job that deletes old tracked messages.
+func autoCleanupJob(cfg config.AutoCleanupConfig, b *bot.Bot) (scheduler.ScheduledCommand, error) {
+	times, types, err := parseAutoCleanup(cfg)
+	if err != nil {
+		return scheduler.ScheduledCommand{}, err
+	}
+
+	return scheduler.ScheduledCommand{
+		Name:  "auto-cleanup",
+		Times: times,
+		Job: func(ctx context.Context, chatID int64) error {
+			deleted, failed, err := b.CleanupOlderThan(chatID, time.Now().Add(-cfg.OlderThan), types)
+			if deleted > 0 || failed > 0 {
+				slog.Info("auto cleanup finished", "chat_id", chatID, "deleted", deleted, "failed", failed)
+			}
+			return err
+		},
+	}, nil
+}
+
+// backupJob creates the scheduled backup job, which runs /backup once per
+// scheduled time in the backup chat.
+func backupJob(cfg config.BackupConfig, cmd pkgcmd.Command, b *bot.Bot) (scheduler.ScheduledCommand, error) {
+	times, err := scheduler.ParseTimes(cfg.Schedule)
+	if err != nil {
+		return scheduler.ScheduledCommand{}, fmt.Errorf("backup.schedule: %w", err)
+	}
+	return scheduler.ScheduledCommand{
+		Name:  "auto-backup",
+		Times: times,
+		Once:  true,
+		Job: func(ctx context.Context, _ int64) error {
+			return b.ExecuteScheduled(ctx, cfg.ChatID, cmd)
+		},
+	}, nil
+}
+
+// newBackupS3 returns the bucket for backups, or nil to send them to the
+// chat.
+func newBackupS3(cfg config.S3Config) *backup.S3 {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &backup.S3{
+		Endpoint:  cfg.Endpoint,
+		Region:    cfg.Region,
+		Bucket:    cfg.Bucket,
+		Prefix:    cfg.Prefix,
+		AccessKey: cfg.AccessKey,
+		SecretKey: cfg.SecretKey,
+	}
+}
+
+// parseAutoCleanup validates the auto_cleanup schedule and message types.
+func parseAutoCleanup(cfg config.AutoCleanupConfig) ([]scheduler.TimeOfDay, []msgstore.MessageType, error) {
+	times, err := scheduler.ParseTimes(cfg.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("auto_cleanup.schedule: %w", err)
+	}
+
+	types := make([]msgstore.MessageType, 0, len(cfg.Types))
+	for _, name := range cfg.Types {
+		t, err := msgstore.ParseMessageType(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("auto_cleanup.types: %w", err)
+		}
+		types = append(types, t)
+	}
+	return times, types, nil
+}
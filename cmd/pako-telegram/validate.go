@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/config"
+	"github.com/rashpile/pako-telegram/internal/executor"
+	"github.com/rashpile/pako-telegram/internal/scheduler"
+)
+
+// validateConfig checks config.yaml and every command it references.
+// Returns the number of problems found.
+func validateConfig(configPath string, out io.Writer) int {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintf(out, "%s: %v\n", configPath, err)
+		return 1
+	}
+
+	if _, err := auth.NewRoleMap(cfg.Roles.Default, cfg.Roles.Chats, cfg.Roles.Users); err != nil {
+		fmt.Fprintf(out, "%s: %v\n", configPath, err)
+		return 1
+	}
+	if _, err := auth.NewOTPVerifier(cfg.OTP.Secrets); err != nil {
+		fmt.Fprintf(out, "%s: %v\n", configPath, err)
+		return 1
+	}
+	if _, err := auth.NewSudo(cfg.Sudo.PINs, cfg.Sudo.Duration); err != nil {
+		fmt.Fprintf(out, "%s: %v\n", configPath, err)
+		return 1
+	}
+	if _, _, err := parseAutoCleanup(cfg.AutoCleanup); err != nil {
+		fmt.Fprintf(out, "%s: %v\n", configPath, err)
+		return 1
+	}
+	if _, err := scheduler.ParseTimes(cfg.Backup.Schedule); err != nil {
+		fmt.Fprintf(out, "%s: backup.schedule: %v\n", configPath, err)
+		return 1
+	}
+
+	return validateCommands(cfg.CommandDirs(configPath), cfg.PluginsPath(configPath), cfg, out)
+}
+
+// validateCommands checks every YAML command in dirs and every plugin in
+// pluginsDir (if set) without starting the bot. Returns the number of
+// problems found.
+func validateCommands(dirs []string, pluginsDir string, cfg *config.Config, out io.Writer) int {
+	loader := command.NewLoader(dirs, cfg.Defaults, executor.NewShellExecutor())
+	loader.SetCategories(cfg.Categories)
+	loader.SetPluginsDir(pluginsDir)
+	defer loader.Close()
+	commands, problems := loader.Validate()
+
+	for _, p := range problems {
+		fmt.Fprintln(out, p)
+	}
+
+	locations := dirs
+	if pluginsDir != "" {
+		locations = append(slices.Clip(dirs), pluginsDir)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(out, "%d problem(s) found in %s\n", len(problems), strings.Join(locations, ", "))
+	} else {
+		fmt.Fprintf(out, "OK: %d commands in %s\n", len(commands), strings.Join(locations, ", "))
+	}
+	return len(problems)
+}
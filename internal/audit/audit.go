@@ -5,6 +5,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -12,6 +14,7 @@ import (
 
 // Entry represents a single audit log record.
 type Entry struct {
+	ID         int64 // Set on entries read back from the log
 	Timestamp  time.Time
 	ChatID     int64
 	Username   string
@@ -19,6 +22,71 @@ type Entry struct {
 	Args       string
 	ExitCode   int
 	DurationMs int64
+	Approvers  string // Comma-separated users who approved the execution, if any
+	Status     string // Empty for executions; otherwise why the command did not run (e.g. "throttled")
+}
+
+// Entry statuses for commands that did not run.
+const (
+	StatusThrottled    = "throttled"    // Rejected by a rate limit
+	StatusCooldown     = "cooldown"     // Rejected while the command cools down from its last run
+	StatusDryRun       = "dry_run"      // Rendered but not run in maintenance mode, or a dry_run_command preview
+	StatusUnauthorized = "unauthorized" // Chat or user not in the allowlist
+)
+
+// Entry statuses recording /shell sessions. Args of an input is the line
+// sent to the shell; ExitCode of a close is the shell's.
+const (
+	StatusShellOpen  = "shell_open"  // Session opened
+	StatusShellInput = "shell_input" // Line sent to the shell
+	StatusShellClose = "shell_close" // Session ended
+)
+
+// StatusPut records a file saved to the host by /put. Args is the uploaded
+// file and its destination.
+const StatusPut = "put"
+
+// Entry statuses recording confirmations and approvals. Username is who
+// acted: the requester, or the user who approved or cancelled.
+const (
+	StatusConfirmRequested = "confirm_requested" // Confirmation or approvals requested
+	StatusApproved         = "approved"          // Confirmed, or one approval of several
+	StatusCancelled        = "cancelled"         // Cancelled, denied or wrong phrase typed
+	StatusExpired          = "expired"           // Not confirmed in time
+)
+
+// AttemptSummary groups unauthorized attempts by chat and command.
+type AttemptSummary struct {
+	ChatID    int64
+	Command   string
+	Usernames []string // Distinct usernames seen, if any
+	Count     int
+	Last      time.Time
+}
+
+// CommandStats summarizes a command's executions.
+type CommandStats struct {
+	Command       string
+	Runs          int
+	Failures      int // Runs with a non-zero exit code
+	TotalDuration time.Duration
+	Last          time.Time
+}
+
+// FailureRate returns the fraction of runs that failed.
+func (s CommandStats) FailureRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Runs)
+}
+
+// AvgDuration returns the mean run duration.
+func (s CommandStats) AvgDuration() time.Duration {
+	if s.Runs == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Runs)
 }
 
 // Logger persists command execution records.
@@ -65,7 +133,9 @@ func createSchema(db *sql.DB) error {
 			command TEXT NOT NULL,
 			args TEXT,
 			exit_code INTEGER,
-			duration_ms INTEGER
+			duration_ms INTEGER,
+			approvers TEXT,
+			status TEXT
 		);
 		CREATE INDEX IF NOT EXISTS idx_audit_timestamp ON audit_log(timestamp);
 		CREATE INDEX IF NOT EXISTS idx_audit_chat_id ON audit_log(chat_id);
@@ -76,14 +146,45 @@ func createSchema(db *sql.DB) error {
 		return fmt.Errorf("create schema: %w", err)
 	}
 
+	if err := addColumn(db, "approvers", "TEXT"); err != nil {
+		return err
+	}
+	return addColumn(db, "status", "TEXT")
+}
+
+// addColumn adds a column to audit_log if a database from an older
+// version lacks it.
+func addColumn(db *sql.DB, name, typ string) error {
+	rows, err := db.Query("SELECT name FROM pragma_table_info('audit_log')")
+	if err != nil {
+		return fmt.Errorf("inspect schema: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return fmt.Errorf("inspect schema: %w", err)
+		}
+		if col == name {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("inspect schema: %w", err)
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE audit_log ADD COLUMN %s %s", name, typ)); err != nil {
+		return fmt.Errorf("add column %s: %w", name, err)
+	}
 	return nil
 }
 
 // Log records a command execution.
 func (l *SQLiteLogger) Log(ctx context.Context, entry Entry) error {
 	query := `
-		INSERT INTO audit_log (timestamp, chat_id, username, command, args, exit_code, duration_ms)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO audit_log (timestamp, chat_id, username, command, args, exit_code, duration_ms, approvers, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := l.db.ExecContext(ctx, query,
@@ -94,6 +195,8 @@ func (l *SQLiteLogger) Log(ctx context.Context, entry Entry) error {
 		entry.Args,
 		entry.ExitCode,
 		entry.DurationMs,
+		entry.Approvers,
+		entry.Status,
 	)
 
 	if err != nil {
@@ -103,6 +206,196 @@ func (l *SQLiteLogger) Log(ctx context.Context, entry Entry) error {
 	return nil
 }
 
+// UnauthorizedAttempts summarizes unauthorized attempts since the given
+// time, most frequent first.
+func (l *SQLiteLogger) UnauthorizedAttempts(ctx context.Context, since time.Time) ([]AttemptSummary, error) {
+	// Timestamps are stored as text by the driver, so filter and group
+	// after parsing rather than comparing strings in SQL.
+	query := `
+		SELECT timestamp, chat_id, command, COALESCE(username, '')
+		FROM audit_log
+		WHERE status = ?
+	`
+
+	rows, err := l.db.QueryContext(ctx, query, StatusUnauthorized)
+	if err != nil {
+		return nil, fmt.Errorf("query attempts: %w", err)
+	}
+	defer rows.Close()
+
+	type key struct {
+		chatID  int64
+		command string
+	}
+	groups := make(map[key]*AttemptSummary)
+	for rows.Next() {
+		var ts time.Time
+		var k key
+		var username string
+		if err := rows.Scan(&ts, &k.chatID, &k.command, &username); err != nil {
+			return nil, fmt.Errorf("scan attempt: %w", err)
+		}
+		if ts.Before(since) {
+			continue
+		}
+
+		a, ok := groups[k]
+		if !ok {
+			a = &AttemptSummary{ChatID: k.chatID, Command: k.command}
+			groups[k] = a
+		}
+		a.Count++
+		if ts.After(a.Last) {
+			a.Last = ts
+		}
+		if username != "" && !slices.Contains(a.Usernames, username) {
+			a.Usernames = append(a.Usernames, username)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query attempts: %w", err)
+	}
+
+	attempts := make([]AttemptSummary, 0, len(groups))
+	for _, a := range groups {
+		attempts = append(attempts, *a)
+	}
+	sort.Slice(attempts, func(i, j int) bool {
+		if attempts[i].Count != attempts[j].Count {
+			return attempts[i].Count > attempts[j].Count
+		}
+		if attempts[i].ChatID != attempts[j].ChatID {
+			return attempts[i].ChatID < attempts[j].ChatID
+		}
+		return attempts[i].Command < attempts[j].Command
+	})
+	return attempts, nil
+}
+
+// entryColumns are the columns scanned by scanEntry.
+const entryColumns = `id, timestamp, chat_id, COALESCE(username, ''), command, COALESCE(args, ''),
+	COALESCE(exit_code, 0), COALESCE(duration_ms, 0), COALESCE(approvers, ''), COALESCE(status, '')`
+
+// CommandStats returns execution statistics per command since the given
+// time, most-run first.
+func (l *SQLiteLogger) CommandStats(ctx context.Context, since time.Time) ([]CommandStats, error) {
+	query := `
+		SELECT timestamp, command, COALESCE(exit_code, 0), COALESCE(duration_ms, 0)
+		FROM audit_log
+		WHERE COALESCE(status, '') = ''
+	`
+
+	rows, err := l.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query stats: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make(map[string]*CommandStats)
+	for rows.Next() {
+		var ts time.Time
+		var command string
+		var exitCode int
+		var durationMs int64
+		if err := rows.Scan(&ts, &command, &exitCode, &durationMs); err != nil {
+			return nil, fmt.Errorf("scan stats: %w", err)
+		}
+		if ts.Before(since) {
+			continue
+		}
+
+		s, ok := groups[command]
+		if !ok {
+			s = &CommandStats{Command: command}
+			groups[command] = s
+		}
+		s.Runs++
+		if exitCode != 0 {
+			s.Failures++
+		}
+		s.TotalDuration += time.Duration(durationMs) * time.Millisecond
+		if ts.After(s.Last) {
+			s.Last = ts
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query stats: %w", err)
+	}
+
+	stats := make([]CommandStats, 0, len(groups))
+	for _, s := range groups {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Runs != stats[j].Runs {
+			return stats[i].Runs > stats[j].Runs
+		}
+		return stats[i].Command < stats[j].Command
+	})
+	return stats, nil
+}
+
+// scanEntry reads an entry selected with entryColumns.
+func scanEntry(row interface{ Scan(...any) error }) (Entry, error) {
+	var e Entry
+	err := row.Scan(&e.ID, &e.Timestamp, &e.ChatID, &e.Username, &e.Command, &e.Args,
+		&e.ExitCode, &e.DurationMs, &e.Approvers, &e.Status)
+	return e, err
+}
+
+// Recent returns the chat's last executions, newest first. Entries for
+// commands that did not run (throttled, confirmations) are left out.
+func (l *SQLiteLogger) Recent(ctx context.Context, chatID int64, limit int) ([]Entry, error) {
+	query := `SELECT ` + entryColumns + `
+		FROM audit_log
+		WHERE chat_id = ? AND COALESCE(status, '') = ''
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	rows, err := l.db.QueryContext(ctx, query, chatID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan history: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query history: %w", err)
+	}
+	return entries, nil
+}
+
+// Execution returns the chat's execution with the given ID. The error
+// wraps sql.ErrNoRows if there is none.
+func (l *SQLiteLogger) Execution(ctx context.Context, chatID, id int64) (Entry, error) {
+	query := `SELECT ` + entryColumns + `
+		FROM audit_log
+		WHERE id = ? AND chat_id = ? AND COALESCE(status, '') = ''
+	`
+	e, err := scanEntry(l.db.QueryRowContext(ctx, query, id, chatID))
+	if err != nil {
+		return Entry{}, fmt.Errorf("query execution: %w", err)
+	}
+	return e, nil
+}
+
+// Snapshot writes a consistent copy of the whole database, including
+// tables kept by other packages, to path, which must not exist yet.
+func (l *SQLiteLogger) Snapshot(ctx context.Context, path string) error {
+	if _, err := l.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("snapshot database: %w", err)
+	}
+	return nil
+}
+
 // Close releases database resources.
 func (l *SQLiteLogger) Close() error {
 	return l.db.Close()
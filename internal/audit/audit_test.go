@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUnauthorizedAttempts(t *testing.T) {
+	l, err := NewSQLiteLogger(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	entries := []Entry{
+		{Timestamp: now.Add(-time.Minute), ChatID: 5, Username: "mallory", Command: "deploy", Status: StatusUnauthorized},
+		{Timestamp: now.Add(-2 * time.Minute), ChatID: 5, Username: "eve", Command: "deploy", Status: StatusUnauthorized},
+		{Timestamp: now.Add(-3 * time.Minute), ChatID: 6, Command: "menu", Status: StatusUnauthorized},
+		{Timestamp: now.Add(-48 * time.Hour), ChatID: 7, Command: "deploy", Status: StatusUnauthorized},
+		{Timestamp: now, ChatID: 1, Command: "deploy"},
+	}
+	for _, e := range entries {
+		if err := l.Log(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	attempts, err := l.UnauthorizedAttempts(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("got %d summaries, want 2: %+v", len(attempts), attempts)
+	}
+
+	first := attempts[0]
+	if first.ChatID != 5 || first.Command != "deploy" || first.Count != 2 || len(first.Usernames) != 2 {
+		t.Errorf("first summary = %+v, want chat 5 /deploy x2 by 2 users", first)
+	}
+	if first.Last.Sub(now.Add(-time.Minute)).Abs() > time.Second {
+		t.Errorf("last = %v, want %v", first.Last, now.Add(-time.Minute))
+	}
+}
+
+func TestRecent(t *testing.T) {
+	l, err := NewSQLiteLogger(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	entries := []Entry{
+		{Timestamp: now.Add(-3 * time.Minute), ChatID: 1, Username: "alice", Command: "uptime", DurationMs: 40},
+		{Timestamp: now.Add(-2 * time.Minute), ChatID: 1, Username: "bob", Command: "deploy", Args: "env=prod", ExitCode: 2},
+		{Timestamp: now.Add(-time.Minute), ChatID: 1, Command: "deploy", Status: StatusThrottled},
+		{Timestamp: now, ChatID: 2, Command: "uptime"},
+	}
+	for _, e := range entries {
+		if err := l.Log(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recent, err := l.Recent(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(recent), recent)
+	}
+	if recent[0].Command != "deploy" || recent[0].Args != "env=prod" || recent[0].ExitCode != 2 || recent[0].Username != "bob" {
+		t.Errorf("newest = %+v", recent[0])
+	}
+	if recent[1].Command != "uptime" || recent[1].DurationMs != 40 || !recent[1].Timestamp.Equal(entries[0].Timestamp) {
+		t.Errorf("oldest = %+v", recent[1])
+	}
+
+	if limited, err := l.Recent(ctx, 1, 1); err != nil || len(limited) != 1 {
+		t.Errorf("Recent(limit 1) = %d entries, %v", len(limited), err)
+	}
+
+	e, err := l.Execution(ctx, 1, recent[0].ID)
+	if err != nil || e.Command != "deploy" {
+		t.Errorf("Execution() = %+v, %v", e, err)
+	}
+	if _, err := l.Execution(ctx, 2, recent[0].ID); err == nil {
+		t.Error("Execution() returned another chat's entry")
+	}
+}
+
+func TestCommandStats(t *testing.T) {
+	l, err := NewSQLiteLogger(filepath.Join(t.TempDir(), "audit.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	entries := []Entry{
+		{Timestamp: now.Add(-3 * time.Minute), ChatID: 1, Command: "deploy", DurationMs: 1000},
+		{Timestamp: now.Add(-2 * time.Minute), ChatID: 2, Command: "deploy", DurationMs: 3000, ExitCode: 1},
+		{Timestamp: now.Add(-time.Minute), ChatID: 1, Command: "deploy", Status: StatusThrottled},
+		{Timestamp: now, ChatID: 1, Command: "uptime", DurationMs: 20},
+		{Timestamp: now.Add(-48 * time.Hour), ChatID: 1, Command: "backup"},
+	}
+	for _, e := range entries {
+		if err := l.Log(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := l.CommandStats(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %d commands, want 2: %+v", len(stats), stats)
+	}
+
+	deploy := stats[0]
+	if deploy.Command != "deploy" || deploy.Runs != 2 || deploy.Failures != 1 {
+		t.Errorf("first = %+v, want deploy x2 with 1 failure", deploy)
+	}
+	if deploy.FailureRate() != 0.5 {
+		t.Errorf("failure rate = %v, want 0.5", deploy.FailureRate())
+	}
+	if deploy.AvgDuration() != 2*time.Second {
+		t.Errorf("avg duration = %v, want 2s", deploy.AvgDuration())
+	}
+	if deploy.Last.Sub(now.Add(-2*time.Minute)).Abs() > time.Second {
+		t.Errorf("last = %v, want %v", deploy.Last, now.Add(-2*time.Minute))
+	}
+	if stats[1].Command != "uptime" || stats[1].Runs != 1 {
+		t.Errorf("second = %+v, want uptime x1", stats[1])
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewSQLiteLogger(filepath.Join(dir, "audit.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	ctx := context.Background()
+	if err := l.Log(ctx, Entry{Timestamp: time.Now(), ChatID: 1, Command: "uptime"}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "copy.db")
+	if err := l.Snapshot(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := NewSQLiteLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshot.Close()
+	recent, err := snapshot.Recent(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0].Command != "uptime" {
+		t.Errorf("snapshot holds %+v, want the uptime entry", recent)
+	}
+}
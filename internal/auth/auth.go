@@ -1,4 +1,5 @@
-// Package auth provides chat ID-based authorization for Telegram commands.
+// Package auth provides chat ID-based authorization and role assignments
+// for Telegram commands.
 package auth
 
 import "sync"
@@ -10,12 +11,24 @@ type Authorizer interface {
 
 	// Reload replaces the allowlist with a new set of chat IDs.
 	Reload(allowedIDs []int64)
+
+	// IsAllowedUser returns true if the user may run commands in an allowed
+	// chat. Always true when no user restrictions are configured.
+	IsAllowedUser(userID int64, username string) bool
+
+	// ReloadUsers replaces the user restrictions. Empty lists allow anyone.
+	ReloadUsers(userIDs []int64, usernames []string)
 }
 
-// Allowlist implements Authorizer using a set of permitted chat IDs.
+// Allowlist implements Authorizer using a set of permitted chat IDs and,
+// optionally, permitted users.
 type Allowlist struct {
-	mu      sync.RWMutex
-	allowed map[int64]struct{}
+	mu        sync.RWMutex
+	allowed   map[int64]struct{}
+	userIDs   map[int64]struct{}
+	usernames map[string]struct{}
+	grants    map[string]Grant   // Temporary grants by target, see grants.go
+	approved  map[int64]struct{} // IDs approved at runtime via access requests
 }
 
 // NewAllowlist creates an Authorizer that permits only the specified chat IDs.
@@ -33,8 +46,13 @@ func NewAllowlist(allowedIDs []int64) *Allowlist {
 func (a *Allowlist) IsAllowed(chatID int64) bool {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
-	_, ok := a.allowed[chatID]
-	return ok
+	if _, ok := a.allowed[chatID]; ok {
+		return true
+	}
+	if _, ok := a.approved[chatID]; ok {
+		return true
+	}
+	return a.grantedLocked(chatID, "")
 }
 
 // Reload replaces the allowlist with a new set of chat IDs.
@@ -48,3 +66,55 @@ func (a *Allowlist) Reload(allowedIDs []int64) {
 	a.allowed = newAllowed
 	a.mu.Unlock()
 }
+
+// IsAllowedUser returns true if no user restrictions are set, or the user
+// matches an allowed ID or username (case-insensitive, "@" optional).
+func (a *Allowlist) IsAllowedUser(userID int64, username string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if len(a.userIDs) == 0 && len(a.usernames) == 0 {
+		return true
+	}
+	if _, ok := a.userIDs[userID]; ok && userID != 0 {
+		return true
+	}
+	if _, ok := a.approved[userID]; ok && userID != 0 {
+		return true
+	}
+	if username != "" {
+		if _, ok := a.usernames[normalizeUsername(username)]; ok {
+			return true
+		}
+	}
+	return a.grantedLocked(userID, username)
+}
+
+// ReloadUsers replaces the user restrictions. Empty lists allow anyone.
+func (a *Allowlist) ReloadUsers(userIDs []int64, usernames []string) {
+	newIDs := make(map[int64]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		newIDs[id] = struct{}{}
+	}
+	newNames := make(map[string]struct{}, len(usernames))
+	for _, name := range usernames {
+		newNames[normalizeUsername(name)] = struct{}{}
+	}
+
+	a.mu.Lock()
+	a.userIDs = newIDs
+	a.usernames = newNames
+	a.mu.Unlock()
+}
+
+// Approve allows a chat/user ID until the process restarts. Like ID grants,
+// it covers both the chat and the user with that ID. Approvals survive
+// Reload; add the ID to the config to keep it across restarts.
+func (a *Allowlist) Approve(id int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.approved == nil {
+		a.approved = make(map[int64]struct{})
+	}
+	a.approved[id] = struct{}{}
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Grant is temporary access for a chat/user ID or a username.
+// An ID grant allows both the chat and the user with that ID, so granting
+// a person's ID also allows their private chat with the bot.
+type Grant struct {
+	ID       int64  // Chat or user ID; 0 for username grants
+	Username string // Normalized username; empty for ID grants
+	Expires  time.Time
+}
+
+// Target returns the grant target as accepted by ParseGrantTarget.
+func (g Grant) Target() string {
+	if g.Username != "" {
+		return "@" + g.Username
+	}
+	return strconv.FormatInt(g.ID, 10)
+}
+
+// ParseGrantTarget parses a chat/user ID or an @username.
+func ParseGrantTarget(s string) (id int64, username string, err error) {
+	if strings.HasPrefix(s, "@") {
+		name := normalizeUsername(s)
+		if name == "" {
+			return 0, "", fmt.Errorf("empty username")
+		}
+		return 0, name, nil
+	}
+	id, err = strconv.ParseInt(s, 10, 64)
+	if err != nil || id == 0 {
+		return 0, "", fmt.Errorf("target must be a chat/user ID or @username, got %q", s)
+	}
+	return id, "", nil
+}
+
+// Grant allows the target until now+d, replacing any existing grant for it.
+func (a *Allowlist) Grant(id int64, username string, d time.Duration) Grant {
+	g := Grant{ID: id, Username: normalizeUsername(username), Expires: time.Now().Add(d)}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.grants == nil {
+		a.grants = make(map[string]Grant)
+	}
+	a.grants[g.Target()] = g
+	return g
+}
+
+// Revoke removes a grant. Returns false if there was none.
+func (a *Allowlist) Revoke(id int64, username string) bool {
+	key := Grant{ID: id, Username: normalizeUsername(username)}.Target()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.grants[key]; !ok {
+		return false
+	}
+	delete(a.grants, key)
+	return true
+}
+
+// Grants returns active grants ordered by expiry.
+func (a *Allowlist) Grants() []Grant {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	now := time.Now()
+	grants := make([]Grant, 0, len(a.grants))
+	for _, g := range a.grants {
+		if now.Before(g.Expires) {
+			grants = append(grants, g)
+		}
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].Expires.Before(grants[j].Expires)
+	})
+	return grants
+}
+
+// RunExpiry removes expired grants every interval and calls onExpire for
+// each one. Blocks until the context is cancelled.
+func (a *Allowlist) RunExpiry(ctx context.Context, interval time.Duration, onExpire func(Grant)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, g := range a.expire(time.Now()) {
+				onExpire(g)
+			}
+		}
+	}
+}
+
+// expire removes and returns grants that expired at or before now.
+func (a *Allowlist) expire(now time.Time) []Grant {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var expired []Grant
+	for key, g := range a.grants {
+		if !now.Before(g.Expires) {
+			expired = append(expired, g)
+			delete(a.grants, key)
+		}
+	}
+	return expired
+}
+
+// grantedLocked returns true if an unexpired grant covers the ID or username.
+// Caller must hold a.mu.
+func (a *Allowlist) grantedLocked(id int64, username string) bool {
+	now := time.Now()
+	if id != 0 {
+		if g, ok := a.grants[strconv.FormatInt(id, 10)]; ok && now.Before(g.Expires) {
+			return true
+		}
+	}
+	if username != "" {
+		if g, ok := a.grants["@"+normalizeUsername(username)]; ok && now.Before(g.Expires) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowlistGrants(t *testing.T) {
+	a := NewAllowlist([]int64{1})
+	a.ReloadUsers([]int64{100}, nil)
+
+	if a.IsAllowed(2) || a.IsAllowedUser(200, "bob") {
+		t.Fatal("expected chat 2 and user 200 to be denied before grant")
+	}
+
+	a.Grant(2, "", time.Hour)
+	a.Grant(0, "@Bob", time.Hour)
+	if !a.IsAllowed(2) {
+		t.Error("expected granted chat to be allowed")
+	}
+	if !a.IsAllowedUser(200, "bob") {
+		t.Error("expected granted username to be allowed")
+	}
+
+	if expired := a.expire(time.Now().Add(2 * time.Hour)); len(expired) != 2 {
+		t.Errorf("expire() returned %d grants, want 2", len(expired))
+	}
+	if a.IsAllowed(2) {
+		t.Error("expected chat 2 to be denied after expiry")
+	}
+
+	a.Grant(3, "", time.Hour)
+	if !a.Revoke(3, "") || a.IsAllowed(3) {
+		t.Error("expected revoked grant to be removed")
+	}
+}
+
+func TestAllowlistApprove(t *testing.T) {
+	a := NewAllowlist([]int64{1})
+	a.ReloadUsers([]int64{100}, nil)
+
+	a.Approve(5)
+	if !a.IsAllowed(5) || !a.IsAllowedUser(5, "") {
+		t.Error("expected approved ID to be allowed as chat and user")
+	}
+
+	a.Reload([]int64{1})
+	a.ReloadUsers([]int64{100}, nil)
+	if !a.IsAllowed(5) {
+		t.Error("expected approval to survive reload")
+	}
+}
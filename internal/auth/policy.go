@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// CommandAuthorizer extends Authorizer with per-command policy checks.
+type CommandAuthorizer interface {
+	Authorizer
+
+	// IsAllowedCommand returns true if the chat and user may run the command.
+	IsAllowedCommand(chatID, userID int64, cmdName string) bool
+}
+
+// Request describes who wants to run which command.
+type Request struct {
+	ChatID      int64
+	UserID      int64
+	Username    string // Optional; enables username-based rules
+	CommandName string
+}
+
+// Policy decides whether a request may run a command.
+// A non-nil error denies the request; its message is shown to the user.
+type Policy interface {
+	Check(req Request, cmd pkgcmd.Command) error
+}
+
+// PolicyFunc adapts a function to Policy.
+type PolicyFunc func(req Request, cmd pkgcmd.Command) error
+
+// Check calls f(req, cmd).
+func (f PolicyFunc) Check(req Request, cmd pkgcmd.Command) error {
+	return f(req, cmd)
+}
+
+// PolicyAuthorizer checks the chat allowlist and then every policy in order.
+// Used for direct commands, menu taps and callbacks alike.
+type PolicyAuthorizer struct {
+	Authorizer
+	lookup   func(name string) pkgcmd.Command
+	policies []Policy
+}
+
+// NewPolicyAuthorizer wraps base with command policies. lookup resolves
+// command names (e.g. Registry.Get); unknown commands skip the policies.
+func NewPolicyAuthorizer(base Authorizer, lookup func(name string) pkgcmd.Command, policies ...Policy) *PolicyAuthorizer {
+	return &PolicyAuthorizer{
+		Authorizer: base,
+		lookup:     lookup,
+		policies:   policies,
+	}
+}
+
+// IsAllowedCommand returns true if the chat and user may run the command.
+func (a *PolicyAuthorizer) IsAllowedCommand(chatID, userID int64, cmdName string) bool {
+	return a.CheckCommand(Request{ChatID: chatID, UserID: userID, CommandName: cmdName}) == nil
+}
+
+// CheckCommand returns an error describing why the request is denied, or nil.
+func (a *PolicyAuthorizer) CheckCommand(req Request) error {
+	if !a.IsAllowed(req.ChatID) {
+		return fmt.Errorf("this chat is not authorized")
+	}
+
+	cmd := a.lookup(req.CommandName)
+	if cmd == nil {
+		return nil
+	}
+
+	for _, p := range a.policies {
+		if err := p.Check(req, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RolePolicy denies commands whose required_role exceeds the user's role.
+func RolePolicy(roles *RoleMap) Policy {
+	return PolicyFunc(func(req Request, cmd pkgcmd.Command) error {
+		required := pkgcmd.RequiredRole(cmd)
+		if required == "" {
+			return nil
+		}
+
+		need, err := ParseRole(required)
+		if err != nil {
+			need = RoleAdmin // Unknown role: fail closed
+		}
+		if have := roles.RoleOf(req.ChatID, req.UserID, req.Username); have < need {
+			return fmt.Errorf("/%s requires the %s role; you have %s.", cmd.Name(), need, have)
+		}
+		return nil
+	})
+}
+
+// CommandAllowlistPolicy enforces per-command chat and user restrictions.
+func CommandAllowlistPolicy() Policy {
+	return PolicyFunc(func(req Request, cmd pkgcmd.Command) error {
+		restricted, ok := cmd.(pkgcmd.WithAccess)
+		if !ok || restricted.Permits(req.ChatID, req.UserID, req.Username) {
+			return nil
+		}
+		return fmt.Errorf("You are not allowed to run /%s here.", cmd.Name())
+	})
+}
+
+// TimeWindowPolicy enforces per-command time-of-day restrictions.
+// now is injectable for testing; nil means time.Now.
+func TimeWindowPolicy(now func() time.Time) Policy {
+	if now == nil {
+		now = time.Now
+	}
+	return PolicyFunc(func(req Request, cmd pkgcmd.Command) error {
+		windowed, ok := cmd.(pkgcmd.WithTimeWindow)
+		if !ok || windowed.AllowedAt(now()) {
+			return nil
+		}
+		return fmt.Errorf("/%s can only be run during %s.", cmd.Name(), windowed.AllowedHours())
+	})
+}
+
+// Approve forwards runtime chat approvals to the base authorizer, if it
+// supports them.
+func (p *PolicyAuthorizer) Approve(id int64) {
+	if a, ok := p.Authorizer.(interface{ Approve(id int64) }); ok {
+		a.Approve(id)
+	}
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+type fakeCommand struct {
+	name  string
+	role  string
+	users []string
+	hours bool // true if AllowedAt should allow
+}
+
+func (f *fakeCommand) Name() string        { return f.name }
+func (f *fakeCommand) Description() string { return "" }
+func (f *fakeCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	return nil
+}
+func (f *fakeCommand) Metadata() pkgcmd.Metadata {
+	return pkgcmd.Metadata{RequiredRole: f.role}
+}
+func (f *fakeCommand) Permits(chatID, userID int64, username string) bool {
+	if len(f.users) == 0 {
+		return true
+	}
+	for _, u := range f.users {
+		if u == username {
+			return true
+		}
+	}
+	return false
+}
+func (f *fakeCommand) AllowedAt(t time.Time) bool { return f.hours }
+func (f *fakeCommand) AllowedHours() string       { return "09:00-18:00" }
+
+func TestPolicyAuthorizer(t *testing.T) {
+	roles, err := NewRoleMap("viewer", map[int64]string{2: "operator"}, map[string]string{"@alice": "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmds := map[string]pkgcmd.Command{
+		"open":    &fakeCommand{name: "open", hours: true},
+		"deploy":  &fakeCommand{name: "deploy", role: "operator", hours: true},
+		"secret":  &fakeCommand{name: "secret", users: []string{"alice"}, hours: true},
+		"offhour": &fakeCommand{name: "offhour", hours: false},
+	}
+	lookup := func(name string) pkgcmd.Command { return cmds[name] }
+
+	a := NewPolicyAuthorizer(NewAllowlist([]int64{1, 2}), lookup,
+		RolePolicy(roles), CommandAllowlistPolicy(), TimeWindowPolicy(nil))
+
+	tests := []struct {
+		name string
+		req  Request
+		want bool
+	}{
+		{"chat not allowed", Request{ChatID: 3, CommandName: "open"}, false},
+		{"open command", Request{ChatID: 1, CommandName: "open"}, true},
+		{"unknown command", Request{ChatID: 1, CommandName: "nope"}, true},
+		{"viewer denied operator command", Request{ChatID: 1, CommandName: "deploy"}, false},
+		{"chat role grants operator", Request{ChatID: 2, CommandName: "deploy"}, true},
+		{"user role wins", Request{ChatID: 1, Username: "Alice", CommandName: "deploy"}, true},
+		{"per-command user allowed", Request{ChatID: 1, Username: "alice", CommandName: "secret"}, true},
+		{"per-command user denied", Request{ChatID: 1, Username: "bob", CommandName: "secret"}, false},
+		{"outside time window", Request{ChatID: 1, CommandName: "offhour"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.CheckCommand(tt.req)
+			if got := err == nil; got != tt.want {
+				t.Errorf("CheckCommand(%+v) allowed = %v, want %v (err: %v)", tt.req, got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseRoleUnknown(t *testing.T) {
+	if _, err := ParseRole("superuser"); err == nil {
+		t.Error("expected error for unknown role")
+	}
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Role is a permission level. Higher roles include the permissions of lower ones.
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleViewer
+	RoleOperator
+	RoleAdmin
+)
+
+// String returns the role name as used in configuration.
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// ParseRole converts a role name to a Role.
+func ParseRole(s string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "viewer":
+		return RoleViewer, nil
+	case "operator":
+		return RoleOperator, nil
+	case "admin":
+		return RoleAdmin, nil
+	case "none":
+		return RoleNone, nil
+	default:
+		return RoleNone, fmt.Errorf("unknown role %q (want admin, operator, viewer or none)", s)
+	}
+}
+
+// RoleMap assigns roles to users and chats.
+// A user assignment wins over a chat assignment, which wins over the default.
+type RoleMap struct {
+	mu        sync.RWMutex
+	def       Role
+	chats     map[int64]Role
+	userIDs   map[int64]Role
+	usernames map[string]Role
+}
+
+// NewRoleMap creates a RoleMap from role names. Keys of users are usernames
+// (with or without "@") or numeric user IDs. An empty default means admin
+// when no roles are assigned at all, so configurations without roles keep
+// full access, and viewer otherwise, so listing some users doesn't make
+// everyone else an admin.
+func NewRoleMap(def string, chats map[int64]string, users map[string]string) (*RoleMap, error) {
+	m := &RoleMap{}
+	if err := m.Reload(def, chats, users); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reload replaces all role assignments. On error the previous ones are kept.
+func (m *RoleMap) Reload(def string, chats map[int64]string, users map[string]string) error {
+	defRole := RoleAdmin
+	if len(chats) > 0 || len(users) > 0 {
+		defRole = RoleViewer
+	}
+	if def != "" {
+		r, err := ParseRole(def)
+		if err != nil {
+			return fmt.Errorf("roles.default: %w", err)
+		}
+		defRole = r
+	}
+
+	chatRoles := make(map[int64]Role, len(chats))
+	for id, name := range chats {
+		r, err := ParseRole(name)
+		if err != nil {
+			return fmt.Errorf("roles.chats[%d]: %w", id, err)
+		}
+		chatRoles[id] = r
+	}
+
+	userIDs := make(map[int64]Role)
+	usernames := make(map[string]Role)
+	for key, name := range users {
+		r, err := ParseRole(name)
+		if err != nil {
+			return fmt.Errorf("roles.users[%s]: %w", key, err)
+		}
+		if id, err := strconv.ParseInt(key, 10, 64); err == nil {
+			userIDs[id] = r
+		} else {
+			usernames[normalizeUsername(key)] = r
+		}
+	}
+
+	m.mu.Lock()
+	m.def = defRole
+	m.chats = chatRoles
+	m.userIDs = userIDs
+	m.usernames = usernames
+	m.mu.Unlock()
+	return nil
+}
+
+// RoleOf returns the role of a user in a chat.
+func (m *RoleMap) RoleOf(chatID, userID int64, username string) Role {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if r, ok := m.userIDs[userID]; ok && userID != 0 {
+		return r
+	}
+	if username != "" {
+		if r, ok := m.usernames[normalizeUsername(username)]; ok {
+			return r
+		}
+	}
+	if r, ok := m.chats[chatID]; ok {
+		return r
+	}
+	return m.def
+}
+
+// normalizeUsername strips a leading "@" and lowercases the name.
+func normalizeUsername(s string) string {
+	return strings.ToLower(strings.TrimPrefix(s, "@"))
+}
@@ -0,0 +1,42 @@
+package auth
+
+import "testing"
+
+func TestRoleMapDefault(t *testing.T) {
+	tests := []struct {
+		name  string
+		def   string
+		chats map[int64]string
+		users map[string]string
+		want  Role
+	}{
+		{"no roles configured", "", nil, nil, RoleAdmin},
+		{"only a user listed", "", nil, map[string]string{"@alice": "viewer"}, RoleViewer},
+		{"only a chat listed", "", map[int64]string{2: "operator"}, nil, RoleViewer},
+		{"explicit default", "operator", nil, map[string]string{"@alice": "admin"}, RoleOperator},
+		{"explicit admin default", "admin", map[int64]string{2: "viewer"}, nil, RoleAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewRoleMap(tt.def, tt.chats, tt.users)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.RoleOf(1, 99, "bob"); got != tt.want {
+				t.Errorf("RoleOf(unlisted) = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Reload drops back to admin once all assignments are removed
+	m, err := NewRoleMap("", nil, map[string]string{"@alice": "viewer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Reload("", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.RoleOf(1, 99, "bob"); got != RoleAdmin {
+		t.Errorf("RoleOf(unlisted) after removing roles = %s, want admin", got)
+	}
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+const (
+	// DefaultSudoDuration is how long elevation lasts when not configured.
+	DefaultSudoDuration = 15 * time.Minute
+
+	// sudoMaxFailures is how many wrong PINs lock a user out.
+	sudoMaxFailures = 5
+
+	// sudoLockout is how long a user is locked out after too many failures.
+	sudoLockout = 15 * time.Minute
+)
+
+var (
+	// ErrWrongPIN is returned by Elevate for an incorrect PIN.
+	ErrWrongPIN = errors.New("wrong PIN")
+
+	// ErrLockedOut is returned by Elevate after too many wrong PINs.
+	ErrLockedOut = errors.New("too many wrong PINs, try again later")
+)
+
+// Sudo tracks per-user PINs and time-limited elevation sessions.
+type Sudo struct {
+	mu        sync.Mutex
+	userIDs   map[int64]string
+	usernames map[string]string
+	duration  time.Duration
+	sessions  map[int64]time.Time // user ID -> elevation expiry
+	failures  map[int64]int
+	lockedTil map[int64]time.Time
+}
+
+// NewSudo creates a session tracker from PINs keyed by username (with or
+// without "@") or numeric user ID. A zero duration uses DefaultSudoDuration.
+func NewSudo(pins map[string]string, duration time.Duration) (*Sudo, error) {
+	s := &Sudo{
+		sessions:  make(map[int64]time.Time),
+		failures:  make(map[int64]int),
+		lockedTil: make(map[int64]time.Time),
+	}
+	if err := s.Reload(pins, duration); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload replaces the PINs and duration. Active sessions keep their expiry.
+// On error the previous settings are kept.
+func (s *Sudo) Reload(pins map[string]string, duration time.Duration) error {
+	if duration < 0 {
+		return fmt.Errorf("sudo duration must not be negative")
+	}
+	if duration == 0 {
+		duration = DefaultSudoDuration
+	}
+
+	userIDs := make(map[int64]string)
+	usernames := make(map[string]string)
+	for key, pin := range pins {
+		if len(pin) < 4 {
+			return fmt.Errorf("sudo PIN for %s must have at least 4 characters", key)
+		}
+		if id, err := strconv.ParseInt(key, 10, 64); err == nil {
+			userIDs[id] = pin
+		} else {
+			usernames[normalizeUsername(key)] = pin
+		}
+	}
+
+	s.mu.Lock()
+	s.userIDs = userIDs
+	s.usernames = usernames
+	s.duration = duration
+	s.mu.Unlock()
+	return nil
+}
+
+// HasPIN returns true if the user has a configured PIN.
+func (s *Sudo) HasPIN(userID int64, username string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.pinLocked(userID, username)
+	return ok
+}
+
+// Elevate starts an elevation session if pin is correct and returns its
+// expiry. Wrong PINs count towards a temporary lockout.
+func (s *Sudo) Elevate(userID int64, username, pin string, now time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Before(s.lockedTil[userID]) {
+		return time.Time{}, ErrLockedOut
+	}
+
+	want, ok := s.pinLocked(userID, username)
+	if !ok || subtle.ConstantTimeCompare([]byte(pin), []byte(want)) != 1 {
+		s.failures[userID]++
+		if s.failures[userID] >= sudoMaxFailures {
+			delete(s.failures, userID)
+			s.lockedTil[userID] = now.Add(sudoLockout)
+			return time.Time{}, ErrLockedOut
+		}
+		return time.Time{}, ErrWrongPIN
+	}
+
+	delete(s.failures, userID)
+	delete(s.lockedTil, userID)
+	expires := now.Add(s.duration)
+	s.sessions[userID] = expires
+	return expires, nil
+}
+
+// Expiry returns when the user's elevation ends, or false if not elevated.
+// Expired sessions are removed.
+func (s *Sudo) Expiry(userID int64, now time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires, ok := s.sessions[userID]
+	if !ok {
+		return time.Time{}, false
+	}
+	if !now.Before(expires) {
+		delete(s.sessions, userID)
+		return time.Time{}, false
+	}
+	return expires, true
+}
+
+// Drop ends the user's elevation. Returns false if there was none.
+func (s *Sudo) Drop(userID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.sessions[userID]
+	delete(s.sessions, userID)
+	return ok
+}
+
+// pinLocked looks up the PIN by user ID, then username. Caller must hold s.mu.
+func (s *Sudo) pinLocked(userID int64, username string) (string, bool) {
+	if pin, ok := s.userIDs[userID]; ok && userID != 0 {
+		return pin, true
+	}
+	if username != "" {
+		if pin, ok := s.usernames[normalizeUsername(username)]; ok {
+			return pin, true
+		}
+	}
+	return "", false
+}
+
+// ElevationPolicy denies elevated commands unless the user has an active
+// sudo session.
+func ElevationPolicy(s *Sudo) Policy {
+	return PolicyFunc(func(req Request, cmd pkgcmd.Command) error {
+		if !pkgcmd.RequiresElevation(cmd) {
+			return nil
+		}
+		if _, ok := s.Expiry(req.UserID, time.Now()); !ok {
+			return fmt.Errorf("/%s is an elevated command. Run /sudo and enter your PIN first.", cmd.Name())
+		}
+		return nil
+	})
+}
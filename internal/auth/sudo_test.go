@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSudoElevate(t *testing.T) {
+	s, err := NewSudo(map[string]string{"@alice": "4321"}, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	if _, err := s.Elevate(1, "alice", "0000", now); !errors.Is(err, ErrWrongPIN) {
+		t.Fatalf("wrong PIN error = %v, want ErrWrongPIN", err)
+	}
+	if _, ok := s.Expiry(1, now); ok {
+		t.Fatal("expected no session after wrong PIN")
+	}
+
+	expires, err := s.Elevate(1, "alice", "4321", now)
+	if err != nil {
+		t.Fatalf("Elevate() error = %v", err)
+	}
+	if !expires.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("expires = %v, want now+10m", expires)
+	}
+	if _, ok := s.Expiry(1, now.Add(5*time.Minute)); !ok {
+		t.Error("expected session to be active within the window")
+	}
+	if _, ok := s.Expiry(1, now.Add(10*time.Minute)); ok {
+		t.Error("expected session to expire after the window")
+	}
+
+	s.Elevate(1, "alice", "4321", now)
+	if !s.Drop(1) || s.Drop(1) {
+		t.Error("expected Drop to end the session once")
+	}
+}
+
+func TestSudoLockout(t *testing.T) {
+	s, err := NewSudo(map[string]string{"42": "secret"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	for i := 1; i < sudoMaxFailures; i++ {
+		if _, err := s.Elevate(42, "", "nope", now); !errors.Is(err, ErrWrongPIN) {
+			t.Fatalf("attempt %d error = %v, want ErrWrongPIN", i, err)
+		}
+	}
+	if _, err := s.Elevate(42, "", "nope", now); !errors.Is(err, ErrLockedOut) {
+		t.Fatalf("final attempt error = %v, want ErrLockedOut", err)
+	}
+	if _, err := s.Elevate(42, "", "secret", now); !errors.Is(err, ErrLockedOut) {
+		t.Errorf("correct PIN while locked error = %v, want ErrLockedOut", err)
+	}
+	if _, err := s.Elevate(42, "", "secret", now.Add(sudoLockout)); err != nil {
+		t.Errorf("correct PIN after lockout error = %v", err)
+	}
+}
+
+func TestNewSudoRejectsShortPIN(t *testing.T) {
+	if _, err := NewSudo(map[string]string{"bob": "12"}, 0); err == nil {
+		t.Error("expected error for short PIN")
+	}
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// totpStep is the TOTP time step (RFC 6238 default).
+	totpStep = 30 * time.Second
+
+	// totpSkew is how many steps before/after now are accepted.
+	totpSkew = 1
+
+	// otpMaxFailures is how many wrong codes lock a user out.
+	otpMaxFailures = 5
+
+	// otpLockout is how long a user is locked out after too many failures.
+	otpLockout = 15 * time.Minute
+)
+
+var (
+	// ErrWrongCode is returned by Verify for an invalid or reused code.
+	ErrWrongCode = errors.New("wrong code")
+
+	// ErrOTPLockedOut is returned by Verify after too many wrong codes.
+	ErrOTPLockedOut = errors.New("too many wrong codes, try again later")
+)
+
+// OTPVerifier checks TOTP codes against per-user secrets.
+// Each code can be used once to prevent replay.
+type OTPVerifier struct {
+	mu        sync.Mutex
+	userIDs   map[int64][]byte
+	usernames map[string][]byte
+	lastUsed  map[string]int64 // secret key -> last accepted counter
+	failures  map[int64]int
+	lockedTil map[int64]time.Time
+}
+
+// NewOTPVerifier creates a verifier from base32 secrets keyed by username
+// (with or without "@") or numeric user ID.
+func NewOTPVerifier(secrets map[string]string) (*OTPVerifier, error) {
+	v := &OTPVerifier{
+		lastUsed:  make(map[string]int64),
+		failures:  make(map[int64]int),
+		lockedTil: make(map[int64]time.Time),
+	}
+	if err := v.Reload(secrets); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Reload replaces the secrets. On error the previous ones are kept.
+func (v *OTPVerifier) Reload(secrets map[string]string) error {
+	userIDs := make(map[int64][]byte)
+	usernames := make(map[string][]byte)
+	for key, secret := range secrets {
+		raw, err := decodeSecret(secret)
+		if err != nil {
+			return fmt.Errorf("otp secret for %s: %w", key, err)
+		}
+		if id, err := strconv.ParseInt(key, 10, 64); err == nil {
+			userIDs[id] = raw
+		} else {
+			usernames[normalizeUsername(key)] = raw
+		}
+	}
+
+	v.mu.Lock()
+	v.userIDs = userIDs
+	v.usernames = usernames
+	v.mu.Unlock()
+	return nil
+}
+
+// HasSecret returns true if the user has a configured secret.
+func (v *OTPVerifier) HasSecret(userID int64, username string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, _, ok := v.secretLocked(userID, username)
+	return ok
+}
+
+// Verify returns nil if code is valid for the user at now and has not
+// been used before. Wrong codes count towards a temporary lockout, across
+// prompts, so codes can't be guessed by running the command again.
+func (v *OTPVerifier) Verify(userID int64, username, code string, now time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if now.Before(v.lockedTil[userID]) {
+		return ErrOTPLockedOut
+	}
+
+	if !v.checkLocked(userID, username, strings.TrimSpace(code), now) {
+		v.failures[userID]++
+		if v.failures[userID] >= otpMaxFailures {
+			delete(v.failures, userID)
+			v.lockedTil[userID] = now.Add(otpLockout)
+			return ErrOTPLockedOut
+		}
+		return ErrWrongCode
+	}
+
+	delete(v.failures, userID)
+	delete(v.lockedTil, userID)
+	return nil
+}
+
+// checkLocked returns true if code is valid and unused, and marks it used.
+// Caller must hold v.mu.
+func (v *OTPVerifier) checkLocked(userID int64, username, code string, now time.Time) bool {
+	if len(code) != 6 {
+		return false
+	}
+	key, secret, ok := v.secretLocked(userID, username)
+	if !ok {
+		return false
+	}
+
+	counter := now.Unix() / int64(totpStep.Seconds())
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		c := counter + offset
+		if c <= v.lastUsed[key] {
+			continue
+		}
+		if hmac.Equal([]byte(hotp(secret, c)), []byte(code)) {
+			v.lastUsed[key] = c
+			return true
+		}
+	}
+	return false
+}
+
+// secretLocked finds the user's secret. Caller must hold v.mu.
+func (v *OTPVerifier) secretLocked(userID int64, username string) (string, []byte, bool) {
+	if s, ok := v.userIDs[userID]; ok && userID != 0 {
+		return strconv.FormatInt(userID, 10), s, true
+	}
+	if username != "" {
+		name := normalizeUsername(username)
+		if s, ok := v.usernames[name]; ok {
+			return "@" + name, s, true
+		}
+	}
+	return "", nil, false
+}
+
+// hotp computes a 6-digit HOTP code (RFC 4226).
+func hotp(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// decodeSecret decodes a base32 secret as shown by authenticator apps,
+// ignoring spaces, case and padding.
+func decodeSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	s = strings.TrimRight(s, "=")
+	raw, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base32: %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty secret")
+	}
+	return raw, nil
+}
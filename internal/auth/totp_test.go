@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHOTPRFC4226(t *testing.T) {
+	// Test vectors from RFC 4226 Appendix D
+	secret := []byte("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314"}
+	for i, w := range want {
+		if got := hotp(secret, int64(i)); got != w {
+			t.Errorf("hotp(counter=%d) = %s, want %s", i, got, w)
+		}
+	}
+}
+
+func TestOTPVerifier(t *testing.T) {
+	// Base32 of "12345678901234567890"
+	v, err := NewOTPVerifier(map[string]string{"@alice": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(59, 0) // counter 1
+	code := hotp([]byte("12345678901234567890"), 1)
+
+	if !v.HasSecret(0, "Alice") {
+		t.Fatal("expected secret for alice")
+	}
+	if v.Verify(0, "bob", code, now) == nil {
+		t.Error("expected failure for user without secret")
+	}
+	if v.Verify(0, "alice", "000000", now) == nil {
+		t.Error("expected failure for wrong code")
+	}
+	if err := v.Verify(0, "alice", code, now); err != nil {
+		t.Errorf("expected valid code to verify, got %v", err)
+	}
+	if v.Verify(0, "alice", code, now) == nil {
+		t.Error("expected replayed code to be rejected")
+	}
+
+	if _, err := NewOTPVerifier(map[string]string{"1": "not base32!"}); err == nil {
+		t.Error("expected error for invalid secret")
+	}
+}
+
+func TestOTPLockout(t *testing.T) {
+	v, err := NewOTPVerifier(map[string]string{"42": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("12345678901234567890")
+	now := time.Unix(59, 0)
+
+	for i := 1; i < otpMaxFailures; i++ {
+		if err := v.Verify(42, "", "000000", now); !errors.Is(err, ErrWrongCode) {
+			t.Fatalf("attempt %d error = %v, want ErrWrongCode", i, err)
+		}
+	}
+	if err := v.Verify(42, "", "000000", now); !errors.Is(err, ErrOTPLockedOut) {
+		t.Fatalf("final attempt error = %v, want ErrOTPLockedOut", err)
+	}
+	if err := v.Verify(42, "", hotp(secret, 1), now); !errors.Is(err, ErrOTPLockedOut) {
+		t.Errorf("correct code while locked error = %v, want ErrOTPLockedOut", err)
+	}
+
+	later := now.Add(otpLockout)
+	counter := later.Unix() / int64(totpStep.Seconds())
+	if err := v.Verify(42, "", hotp(secret, counter), later); err != nil {
+		t.Errorf("correct code after lockout error = %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+// Package backup archives the bot's configuration and state into a
+// gzip-compressed tarball, and uploads archives to S3-compatible storage.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Snapshotter writes a consistent copy of a database to path.
+type Snapshotter interface {
+	Snapshot(ctx context.Context, path string) error
+}
+
+// Sources lists what goes into a backup.
+type Sources struct {
+	Config      string      // config.yaml
+	CommandDirs []string    // Command directories, archived recursively
+	Files       []string    // Further state files such as the message store; missing ones are skipped
+	Database    Snapshotter // The SQLite database (nil = left out)
+}
+
+// Layout of the archive.
+const (
+	databaseName = "database.db"
+	commandsDir  = "commands"
+	stateDir     = "state"
+)
+
+// Create writes a backup of src to w as a gzip-compressed tarball. The
+// config file sits at the top, command directories under commands/, state
+// files under state/ and the database as database.db.
+func Create(ctx context.Context, w io.Writer, src Sources) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := addArchive(ctx, tw, src); err != nil {
+		tw.Close()
+		gz.Close()
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("finish archive: %w", err)
+	}
+	return nil
+}
+
+// addArchive adds every source to tw.
+func addArchive(ctx context.Context, tw *tar.Writer, src Sources) error {
+	if src.Config != "" {
+		if err := addFile(tw, src.Config, filepath.Base(src.Config)); err != nil {
+			return err
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, dir := range src.CommandDirs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := addDir(tw, dir, uniqueName(used, path.Join(commandsDir, filepath.Base(dir)))); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range src.Files {
+		err := addFile(tw, file, uniqueName(used, path.Join(stateDir, filepath.Base(file))))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	if src.Database != nil {
+		return addDatabase(ctx, tw, src.Database)
+	}
+	return nil
+}
+
+// addDatabase snapshots the database to a temporary file and adds it.
+func addDatabase(ctx context.Context, tw *tar.Writer, db Snapshotter) error {
+	dir, err := os.MkdirTemp("", "pako-backup-db-*")
+	if err != nil {
+		return fmt.Errorf("snapshot database: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	snapshot := filepath.Join(dir, databaseName)
+	if err := db.Snapshot(ctx, snapshot); err != nil {
+		return fmt.Errorf("snapshot database: %w", err)
+	}
+	return addFile(tw, snapshot, databaseName)
+}
+
+// addDir adds the regular files under dir as name/...; other entries such
+// as sockets are skipped.
+func addDir(tw *tar.Writer, dir, name string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		return addFile(tw, p, path.Join(name, filepath.ToSlash(rel)))
+	})
+}
+
+// addFile adds the file at p as name.
+func addFile(tw *tar.Writer, p, name string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return fmt.Errorf("archive %s: %w", p, err)
+	}
+	hdr.Name = name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("archive %s: %w", p, err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("archive %s: %w", p, err)
+	}
+	return nil
+}
+
+// uniqueName returns name, or name with a numeric suffix if it was already
+// used, and records it.
+func uniqueName(used map[string]bool, name string) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	used[unique] = true
+	return unique
+}
@@ -0,0 +1,153 @@
+package backup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 uploads backups to an S3-compatible bucket with path-style requests
+// signed with AWS Signature Version 4.
+type S3 struct {
+	Endpoint  string // e.g. https://s3.eu-central-1.amazonaws.com
+	Region    string
+	Bucket    string
+	Prefix    string // Prepended to object names, e.g. "pako/"
+	AccessKey string
+	SecretKey string
+	Client    *http.Client // nil = http.DefaultClient
+}
+
+// Upload stores the file at p as Prefix plus its base name and returns the
+// object's URL.
+func (s S3) Upload(ctx context.Context, p string) (string, error) {
+	hash, size, err := fileSHA256(p)
+	if err != nil {
+		return "", fmt.Errorf("upload backup: %w", err)
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return "", fmt.Errorf("upload backup: %w", err)
+	}
+	defer f.Close()
+
+	objectURL := strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + s.Prefix + filepath.Base(p)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, f)
+	if err != nil {
+		return "", fmt.Errorf("upload backup: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/gzip")
+	s.sign(req, hash, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload backup: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("upload backup: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return objectURL, nil
+}
+
+// sign adds SigV4 authentication to req, covering the host and all headers
+// already set. payloadHash is the hex SHA-256 of the body.
+func (s S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), day)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+// canonicalPath URI-encodes each segment of the request path as SigV4
+// requires for S3.
+func canonicalPath(u *url.URL) string {
+	segments := strings.Split(u.Path, "/")
+	for i, seg := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(seg), "+", "%2B")
+	}
+	p := strings.Join(segments, "/")
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// fileSHA256 returns the hex SHA-256 and size of the file at p.
+func fileSHA256(p string) (string, int64, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,220 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/msgstore"
+)
+
+const (
+	// Callback data for access requests from unknown chats
+	accessRequestData = "access:request"
+	accessApprovePre  = "access:approve:"
+	accessDenyPre     = "access:deny:"
+
+	// accessRequestTTL bounds how long a request waits for an admin and
+	// how often one chat can ask again.
+	accessRequestTTL = 24 * time.Hour
+)
+
+// IsAccessCallback checks if the callback belongs to the access request flow.
+func IsAccessCallback(data string) bool {
+	return strings.HasPrefix(data, "access:")
+}
+
+// AccessRequest is a pending request from an unknown chat.
+type AccessRequest struct {
+	ChatID    int64
+	ChatTitle string // Group title, empty for private chats
+	UserID    int64
+	Username  string
+	Requested time.Time
+}
+
+// AccessRequests tracks pending access requests, one per chat.
+type AccessRequests struct {
+	mu      sync.Mutex
+	pending map[int64]AccessRequest
+}
+
+// NewAccessRequests creates an empty request tracker.
+func NewAccessRequests() *AccessRequests {
+	return &AccessRequests{pending: make(map[int64]AccessRequest)}
+}
+
+// Submit records a request. Returns false if the chat already has one
+// waiting, so repeated button presses don't flood the admin chat.
+func (a *AccessRequests) Submit(req AccessRequest) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if prev, ok := a.pending[req.ChatID]; ok && time.Since(prev.Requested) < accessRequestTTL {
+		return false
+	}
+	a.pending[req.ChatID] = req
+	return true
+}
+
+// Resolve removes and returns the pending request for a chat.
+func (a *AccessRequests) Resolve(chatID int64) (AccessRequest, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	req, ok := a.pending[chatID]
+	delete(a.pending, chatID)
+	if !ok || time.Since(req.Requested) >= accessRequestTTL {
+		return AccessRequest{}, false
+	}
+	return req, true
+}
+
+// chatApprover is implemented by authorizers that can allow chats at runtime.
+type chatApprover interface {
+	Approve(id int64)
+}
+
+// rejectChat tells an unknown chat it is not allowed and, when an admin chat
+// is configured, offers a button to request access.
+func (b *Bot) rejectChat(chatID int64) {
+	text := b.t(chatID, i18n.ChatUnauthorized, chatID)
+
+	b.settingsMu.RLock()
+	adminChatID := b.adminChatID
+	b.settingsMu.RUnlock()
+
+	_, canApprove := b.authorizer.(chatApprover)
+	if adminChatID == 0 || !canApprove {
+		b.sendText(chatID, text)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.RequestAccess), accessRequestData),
+		),
+	)
+	if _, err := b.api.Send(msg); err != nil {
+		slog.Error("failed to send access request button", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleAccessCallback handles "Request access" presses from unknown chats
+// and Approve/Deny presses in the admin chat.
+func (b *Bot) handleAccessCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	b.settingsMu.RLock()
+	adminChatID := b.adminChatID
+	b.settingsMu.RUnlock()
+
+	approver, canApprove := b.authorizer.(chatApprover)
+	if adminChatID == 0 || !canApprove {
+		return
+	}
+
+	if query.Data == accessRequestData {
+		b.submitAccessRequest(query, adminChatID)
+		return
+	}
+
+	// Approve/Deny only count from admins in the admin chat
+	if chatID != adminChatID || !b.authorizer.IsAllowed(chatID) || !b.isAdmin(chatID, query.From) {
+		slog.Warn("access decision rejected", "chat_id", chatID, "user_id", query.From.ID, "callback", query.Data)
+		return
+	}
+
+	approve := strings.HasPrefix(query.Data, accessApprovePre)
+	idStr := strings.TrimPrefix(strings.TrimPrefix(query.Data, accessApprovePre), accessDenyPre)
+	reqChatID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return
+	}
+
+	req, ok := b.accessReqs.Resolve(reqChatID)
+	if !ok {
+		edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, b.t(chatID, i18n.AccessRequestGone))
+		b.api.Send(edit)
+		return
+	}
+
+	decidedBy := userDisplayName(query.From)
+	var result string
+	if approve {
+		approver.Approve(req.ChatID)
+		slog.Info("access request approved", "chat_id", req.ChatID, "by", decidedBy)
+		result = b.t(chatID, i18n.AccessApprovedBy, decidedBy, accessRequestLabel(req), req.ChatID)
+		b.sendText(req.ChatID, b.t(req.ChatID, i18n.AccessGranted))
+		b.sendMenu(req.ChatID)
+	} else {
+		slog.Info("access request denied", "chat_id", req.ChatID, "by", decidedBy)
+		result = b.t(chatID, i18n.AccessDeniedBy, decidedBy, accessRequestLabel(req))
+		b.sendText(req.ChatID, b.t(req.ChatID, i18n.AccessDenied))
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, result)
+	b.api.Send(edit)
+}
+
+// submitAccessRequest forwards a request from an unknown chat to the admin chat.
+func (b *Bot) submitAccessRequest(query *tgbotapi.CallbackQuery, adminChatID int64) {
+	chatID := query.Message.Chat.ID
+	if b.authorizer.IsAllowed(chatID) {
+		b.sendText(chatID, b.t(chatID, i18n.AccessAlreadyAllowed))
+		return
+	}
+
+	req := AccessRequest{
+		ChatID:    chatID,
+		ChatTitle: query.Message.Chat.Title,
+		UserID:    query.From.ID,
+		Username:  query.From.UserName,
+		Requested: time.Now(),
+	}
+	if !b.accessReqs.Submit(req) {
+		b.sendText(chatID, b.t(chatID, i18n.AccessPending))
+		return
+	}
+
+	slog.Info("access requested", "chat_id", chatID, "user_id", req.UserID, "username", req.Username)
+
+	id := strconv.FormatInt(chatID, 10)
+	msg := tgbotapi.NewMessage(adminChatID, b.t(adminChatID, i18n.AccessRequestFrom, accessRequestLabel(req)))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ "+b.t(adminChatID, i18n.Approve), accessApprovePre+id),
+			tgbotapi.NewInlineKeyboardButtonData("❌ "+b.t(adminChatID, i18n.Deny), accessDenyPre+id),
+		),
+	)
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		slog.Error("failed to forward access request", "chat_id", chatID, "error", err)
+		b.accessReqs.Resolve(chatID)
+		b.sendText(chatID, b.t(chatID, i18n.AccessRequestFailed))
+		return
+	}
+	b.trackMessage(adminChatID, sent.MessageID, msgstore.TypeConfirmation)
+
+	b.sendText(chatID, b.t(chatID, i18n.AccessRequested))
+}
+
+// accessRequestLabel describes the requesting chat and user for admins.
+func accessRequestLabel(req AccessRequest) string {
+	user := fmt.Sprintf("user %d", req.UserID)
+	if req.Username != "" {
+		user = "@" + req.Username
+	}
+	if req.ChatTitle != "" {
+		return fmt.Sprintf("%s in %q (chat %d)", user, req.ChatTitle, req.ChatID)
+	}
+	return fmt.Sprintf("%s (chat %d)", user, req.ChatID)
+}
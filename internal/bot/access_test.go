@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccessRequests(t *testing.T) {
+	a := NewAccessRequests()
+	req := AccessRequest{ChatID: 42, Username: "alice", Requested: time.Now()}
+
+	if !a.Submit(req) {
+		t.Fatal("expected first request to be accepted")
+	}
+	if a.Submit(req) {
+		t.Error("expected duplicate request to be rejected while pending")
+	}
+
+	got, ok := a.Resolve(42)
+	if !ok || got.Username != "alice" {
+		t.Fatalf("Resolve() = %+v, %v; want alice's request", got, ok)
+	}
+	if _, ok := a.Resolve(42); ok {
+		t.Error("expected request to be resolved only once")
+	}
+
+	stale := AccessRequest{ChatID: 7, Requested: time.Now().Add(-2 * accessRequestTTL)}
+	a.pending[7] = stale
+	if !a.Submit(AccessRequest{ChatID: 7, Requested: time.Now()}) {
+		t.Error("expected new request to replace an expired one")
+	}
+}
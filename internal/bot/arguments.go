@@ -3,34 +3,69 @@ package bot
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"os"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"text/template"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
 const (
 	defaultArgumentTimeout = 120 * time.Second
-	maxInlineChoices       = 4
+	choicesPerPage         = 8
+	choicesCommandTimeout  = 15 * time.Second
+	dateLayout             = "2006-01-02"
+	maskedValue            = "****"
+
+	// Argument navigation callback data
+	argNavBack   = "argnav:back"
+	argNavSkip   = "argnav:skip"
+	argNavCancel = "argnav:cancel"
+
+	// argPagePrefix is the callback prefix for choice keyboard pagination.
+	argPagePrefix = "argpage:"
 )
 
+// ArgumentCommand is a command whose arguments are collected interactively:
+// YAML commands with arguments, and built-ins such as /podcast.
+type ArgumentCommand interface {
+	pkgcmd.Command
+	Arguments() []command.ArgumentDef
+	HasArguments() bool
+	ArgumentTimeout() time.Duration
+	RunChoicesCommand(ctx context.Context, choicesCmd string) ([]string, error)
+}
+
+// ArgumentBypass is implemented by argument commands that handle some input
+// without collecting arguments, e.g. "/podcast cancel". The input is then
+// passed as the only argument.
+type ArgumentBypass interface {
+	SkipsArguments(text string) bool
+}
+
 // ArgumentSession tracks in-progress argument collection for a chat.
 type ArgumentSession struct {
 	ChatID          int64
-	Command         *command.YAMLCommand
+	Command         ArgumentCommand
 	Arguments       []command.ArgumentDef
 	Collected       map[string]string
 	CurrentIdx      int
 	StartedAt       time.Time
 	TimeoutDur      time.Duration
-	LastPromptMsgID int // Message ID of the last prompt (for editing)
+	LastPromptMsgID int    // Message ID of the last prompt (for editing)
+	Lang            string // Language of prompts and buttons
 }
 
 // CurrentArg returns the argument currently being collected.
@@ -56,6 +91,7 @@ type ArgumentCollector struct {
 	mu             sync.RWMutex
 	sessions       map[int64]*ArgumentSession
 	defaultTimeout time.Duration
+	lang           func(chatID int64) string
 }
 
 // NewArgumentCollector creates a new argument collector.
@@ -66,9 +102,20 @@ func NewArgumentCollector() *ArgumentCollector {
 	}
 }
 
+// SetLanguage sets the function choosing each chat's message language.
+func (c *ArgumentCollector) SetLanguage(fn func(chatID int64) string) {
+	c.lang = fn
+}
+
 // StartSession begins argument collection for a command.
-// Returns the list of arguments to prompt for (skipping those with defaults).
-func (c *ArgumentCollector) StartSession(chatID int64, cmd *command.YAMLCommand) *ArgumentSession {
+// Prefilled values (e.g. from inline key=value pairs) are stored as collected
+// and their arguments are not prompted.
+func (c *ArgumentCollector) StartSession(chatID int64, cmd ArgumentCommand, prefilled map[string]string) *ArgumentSession {
+	lang := i18n.Default
+	if c.lang != nil {
+		lang = c.lang(chatID)
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -81,9 +128,17 @@ func (c *ArgumentCollector) StartSession(chatID int64, cmd *command.YAMLCommand)
 		timeout = c.defaultTimeout
 	}
 
-	// All arguments are prompted (defaults are shown as highlighted options)
-	toPrompt := slices.Clone(args)
-	collected := make(map[string]string)
+	// All arguments without a prefilled value are prompted
+	// (defaults are shown as highlighted options)
+	collected := make(map[string]string, len(prefilled))
+	var toPrompt []command.ArgumentDef
+	for _, arg := range args {
+		if v, ok := prefilled[arg.Name]; ok {
+			collected[arg.Name] = v
+			continue
+		}
+		toPrompt = append(toPrompt, arg)
+	}
 
 	session := &ArgumentSession{
 		ChatID:     chatID,
@@ -93,8 +148,10 @@ func (c *ArgumentCollector) StartSession(chatID int64, cmd *command.YAMLCommand)
 		CurrentIdx: 0,
 		StartedAt:  time.Now(),
 		TimeoutDur: timeout,
+		Lang:       lang,
 	}
 
+	skipInapplicable(session)
 	c.sessions[chatID] = session
 	return session
 }
@@ -111,6 +168,20 @@ func (c *ArgumentCollector) GetSession(chatID int64) *ArgumentSession {
 	return session
 }
 
+// Count returns the number of unexpired sessions.
+func (c *ArgumentCollector) Count() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	n := 0
+	for _, session := range c.sessions {
+		if !session.IsExpired() {
+			n++
+		}
+	}
+	return n
+}
+
 // HasSession returns true if there's an active session for the chat.
 func (c *ArgumentCollector) HasSession(chatID int64) bool {
 	return c.GetSession(chatID) != nil
@@ -153,13 +224,70 @@ func (c *ArgumentCollector) ProcessInput(chatID int64, input string) (errMsg str
 	// Store the value
 	session.Collected[arg.Name] = value
 	session.CurrentIdx++
+	skipInapplicable(session)
 
 	return ""
 }
 
+// skipInapplicable advances past arguments whose when condition is false.
+func skipInapplicable(session *ArgumentSession) {
+	for !session.IsComplete() && !argumentApplies(session.CurrentArg(), session.Collected) {
+		session.CurrentIdx++
+	}
+}
+
+// argumentApplies evaluates an argument's when condition against collected values.
+// Arguments without a condition always apply; evaluation errors also count as applying
+// so the user is asked rather than silently skipped.
+func argumentApplies(arg *command.ArgumentDef, collected map[string]string) bool {
+	if arg.When == "" {
+		return true
+	}
+
+	result, err := RenderCommand(arg.When, collected)
+	if err != nil {
+		return true
+	}
+
+	switch strings.ToLower(strings.TrimSpace(result)) {
+	case "", "false", "0", "no", "<no value>":
+		return false
+	}
+	return true
+}
+
+// GoBack steps back to the previously prompted argument, discarding its value.
+// Returns false if the session is at the first argument or doesn't exist.
+func (c *ArgumentCollector) GoBack(chatID int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	session := c.sessions[chatID]
+	if session == nil || session.IsExpired() {
+		return false
+	}
+
+	// Find the previous answered argument (skipping ones whose when was false)
+	for i := session.CurrentIdx - 1; i >= 0; i-- {
+		name := session.Arguments[i].Name
+		if _, ok := session.Collected[name]; ok {
+			session.CurrentIdx = i
+			delete(session.Collected, name)
+			return true
+		}
+	}
+	return false
+}
+
+// Skip leaves the current argument empty (or at its default) and advances.
+// Returns an error message if the argument is required without a default.
+func (c *ArgumentCollector) Skip(chatID int64) (errMsg string) {
+	return c.ProcessInput(chatID, "")
+}
+
 // CompleteSession finalizes the session and returns collected arguments.
 // Removes the session from active tracking.
-func (c *ArgumentCollector) CompleteSession(chatID int64) (map[string]string, *command.YAMLCommand) {
+func (c *ArgumentCollector) CompleteSession(chatID int64) (map[string]string, ArgumentCommand) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -172,9 +300,29 @@ func (c *ArgumentCollector) CompleteSession(chatID int64) (map[string]string, *c
 	cmd := session.Command
 	delete(c.sessions, chatID)
 
+	// Arguments skipped by their when condition render as their default
+	for _, arg := range session.Arguments {
+		if _, ok := collected[arg.Name]; !ok {
+			collected[arg.Name] = arg.Default
+		}
+	}
+
 	return collected, cmd
 }
 
+// SetCurrentChoices replaces the choice list of the argument currently being collected.
+// Used for arguments whose choices are produced by a choices_command.
+func (c *ArgumentCollector) SetCurrentChoices(chatID int64, choices []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if session := c.sessions[chatID]; session != nil {
+		if arg := session.CurrentArg(); arg != nil {
+			arg.Choices = choices
+		}
+	}
+}
+
 // SetLastPromptMsgID stores the message ID of the last prompt sent.
 func (c *ArgumentCollector) SetLastPromptMsgID(chatID int64, msgID int) {
 	c.mu.Lock()
@@ -210,9 +358,32 @@ func validateArgument(arg *command.ArgumentDef, input string) error {
 
 	switch arg.Type {
 	case "int":
-		if _, err := strconv.Atoi(input); err != nil {
+		n, err := strconv.Atoi(input)
+		if err != nil {
 			return fmt.Errorf("please enter a valid integer")
 		}
+		if err := validateRange(arg, float64(n)); err != nil {
+			return err
+		}
+
+	case "float":
+		f, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+		if err != nil {
+			return fmt.Errorf("please enter a valid number")
+		}
+		if err := validateRange(arg, f); err != nil {
+			return err
+		}
+
+	case "duration":
+		if _, err := time.ParseDuration(strings.TrimSpace(input)); err != nil {
+			return fmt.Errorf("please enter a duration like 30s, 5m or 1h30m")
+		}
+
+	case "date":
+		if _, err := time.Parse(dateLayout, strings.TrimSpace(input)); err != nil {
+			return fmt.Errorf("please enter a date as YYYY-MM-DD")
+		}
 
 	case "bool":
 		lower := strings.ToLower(strings.TrimSpace(input))
@@ -231,12 +402,209 @@ func validateArgument(arg *command.ArgumentDef, input string) error {
 		}
 
 	case "string", "":
-		// String type accepts anything non-empty (if required)
+		if err := validateLength(arg, input); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateRange checks a numeric value against the argument's min/max bounds.
+func validateRange(arg *command.ArgumentDef, v float64) error {
+	if (arg.Min != nil && v < *arg.Min) || (arg.Max != nil && v > *arg.Max) {
+		return fmt.Errorf("value must be %s", rangeHint(i18n.Default, arg))
 	}
+	return nil
+}
 
+// validateLength checks a string value against the argument's length bounds.
+func validateLength(arg *command.ArgumentDef, input string) error {
+	n := utf8.RuneCountInString(input)
+	if (arg.MinLength > 0 && n < arg.MinLength) || (arg.MaxLength > 0 && n > arg.MaxLength) {
+		return fmt.Errorf("length must be %s characters (got %d)", lengthHint(i18n.Default, arg), n)
+	}
 	return nil
 }
 
+// boundsHint describes optional lower and upper bounds in lang, or "" if
+// both are unset.
+func boundsHint(lang, lower, upper string) string {
+	switch {
+	case lower != "" && upper != "":
+		return i18n.T(lang, i18n.HintBetween, lower, upper)
+	case lower != "":
+		return i18n.T(lang, i18n.HintAtLeast, lower)
+	case upper != "":
+		return i18n.T(lang, i18n.HintAtMost, upper)
+	}
+	return ""
+}
+
+// rangeHint describes the numeric bounds of an argument, or "" if unbounded.
+func rangeHint(lang string, arg *command.ArgumentDef) string {
+	format := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	return boundsHint(lang, format(arg.Min), format(arg.Max))
+}
+
+// lengthHint describes the length bounds of an argument, or "" if unbounded.
+func lengthHint(lang string, arg *command.ArgumentDef) string {
+	format := func(n int) string {
+		if n <= 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	return boundsHint(lang, format(arg.MinLength), format(arg.MaxLength))
+}
+
+// constraintHint returns a prompt line describing the argument's constraints.
+func constraintHint(lang string, arg *command.ArgumentDef) string {
+	switch arg.Type {
+	case "int", "float":
+		if hint := rangeHint(lang, arg); hint != "" {
+			return i18n.T(lang, i18n.HintValue, hint)
+		}
+	case "duration":
+		return i18n.T(lang, i18n.HintDuration)
+	case "date":
+		return i18n.T(lang, i18n.HintDate)
+	case "string", "":
+		if hint := lengthHint(lang, arg); hint != "" {
+			return i18n.T(lang, i18n.HintLength, hint)
+		}
+	}
+	return ""
+}
+
+// ParseInlineArguments parses "name=value" pairs given after a command
+// (e.g. "/deploy env=prod version=1.2.3") and validates them against the
+// argument definitions. Values may be quoted to include spaces.
+func ParseInlineArguments(defs []command.ArgumentDef, input string) (map[string]string, error) {
+	tokens, err := splitQuoted(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	values := make(map[string]string, len(tokens))
+	for _, token := range tokens {
+		name, value, ok := strings.Cut(token, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected name=value, got %q", token)
+		}
+
+		idx := slices.IndexFunc(defs, func(d command.ArgumentDef) bool { return d.Name == name })
+		if idx < 0 {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+
+		arg := &defs[idx]
+		if value == "" && arg.Default != "" {
+			value = arg.Default
+		}
+		if err := validateArgument(arg, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// ParseLeadingArguments parses "name=value" pairs at the start of text and
+// gives the rest of it, newlines included, to the first argument. Pairs
+// stop at the first word that isn't one.
+func ParseLeadingArguments(defs []command.ArgumentDef, text string) (map[string]string, error) {
+	values := make(map[string]string)
+	rest := strings.TrimSpace(text)
+	for rest != "" {
+		word := rest
+		end := strings.IndexAny(rest, " \t\n")
+		if end >= 0 {
+			word = rest[:end]
+		}
+		name, value, ok := strings.Cut(word, "=")
+		idx := slices.IndexFunc(defs, func(d command.ArgumentDef) bool { return d.Name == name })
+		if !ok || idx <= 0 {
+			break // Not an option; the first argument is given as free text
+		}
+		if err := validateArgument(&defs[idx], value); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		values[name] = value
+		if end < 0 {
+			rest = ""
+		} else {
+			rest = strings.TrimLeft(rest[end:], " \t\n")
+		}
+	}
+
+	if rest = strings.TrimSpace(rest); rest != "" && len(defs) > 0 {
+		values[defs[0].Name] = rest
+	}
+	return values, nil
+}
+
+// collectedArgs formats collected values as "name=value" arguments in
+// definition order, for built-in commands.
+func collectedArgs(cmd ArgumentCommand, collected map[string]string) []string {
+	var args []string
+	for _, arg := range cmd.Arguments() {
+		if v, ok := collected[arg.Name]; ok {
+			args = append(args, arg.Name+"="+v)
+		}
+	}
+	return args
+}
+
+// splitQuoted splits s on whitespace, keeping single- or double-quoted
+// sections together and stripping the quotes.
+func splitQuoted(s string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	var quote rune
+	inToken := false
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case unicode.IsSpace(r):
+			if inToken {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				inToken = false
+			}
+		default:
+			cur.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inToken {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
 // RenderCommand applies collected arguments to the command template.
 func RenderCommand(cmdTemplate string, args map[string]string) (string, error) {
 	tmpl, err := template.New("cmd").Parse(cmdTemplate)
@@ -252,57 +620,201 @@ func RenderCommand(cmdTemplate string, args map[string]string) (string, error) {
 	return buf.String(), nil
 }
 
-// BuildArgumentPrompt creates a message for prompting an argument.
-func BuildArgumentPrompt(arg *command.ArgumentDef) string {
+// BuildRenderedPreview formats what cmd runs and where (the rendered command,
+// workdir and target) in lang, masking sensitive argument values. It is
+// Markdown for confirmation dialogs, or plain text for approval requests.
+func BuildRenderedPreview(lang string, cmd *command.YAMLCommand, rendered string, collected map[string]string, markdown bool) string {
+	masked := strings.TrimSpace(RedactValues(rendered, SensitiveValues(cmd, collected)))
+	code := func(s string) string { return s }
+	text := masked
+	if markdown {
+		code = func(s string) string { return "`" + s + "`" }
+		text = "```\n" + masked + "\n```"
+	}
+
+	if cmd.Workdir() != "" {
+		text += "\n" + i18n.T(lang, i18n.PreviewWorkdir, code(cmd.Workdir()))
+	}
+	target := code(cmd.Target())
+	if cmd.Target() == "" {
+		host, _ := os.Hostname()
+		target = i18n.T(lang, i18n.PreviewLocalHost, code(host))
+	}
+	return text + "\n" + i18n.T(lang, i18n.PreviewTarget, target)
+}
+
+// BuildArgumentPrompt creates a message in lang for prompting an argument.
+func BuildArgumentPrompt(lang string, arg *command.ArgumentDef) string {
+	text := arg.Description
+	if hint := constraintHint(lang, arg); hint != "" {
+		text += "\n" + hint
+	}
 	if arg.Default != "" {
-		return fmt.Sprintf("%s\n\nDefault: %s (press Enter to use)", arg.Description, arg.Default)
+		return text + "\n\n" + i18n.T(lang, i18n.DefaultValue, DisplayValue(arg, arg.Default))
 	}
-	return arg.Description
+	return text
+}
+
+// DisplayValue returns the value as it may be shown to users, masking sensitive arguments.
+func DisplayValue(arg *command.ArgumentDef, value string) string {
+	if arg != nil && arg.Sensitive && value != "" {
+		return maskedValue
+	}
+	return value
+}
+
+// SensitiveValues returns the non-empty collected values of sensitive arguments.
+func SensitiveValues(cmd *command.YAMLCommand, args map[string]string) []string {
+	var values []string
+	for _, arg := range cmd.Arguments() {
+		if v := args[arg.Name]; arg.Sensitive && v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// MaskArgs formats collected arguments as "name=value" pairs in definition order,
+// masking sensitive values. Used for logs and the audit trail.
+func MaskArgs(cmd *command.YAMLCommand, args map[string]string) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range cmd.Arguments() {
+		v, ok := args[arg.Name]
+		if !ok {
+			continue
+		}
+		parts = append(parts, arg.Name+"="+DisplayValue(&arg, v))
+	}
+	return strings.Join(parts, " ")
+}
+
+// RedactValues replaces every occurrence of the given values in s with a mask.
+func RedactValues(s string, values []string) string {
+	for _, v := range values {
+		if v != "" {
+			s = strings.ReplaceAll(s, v, maskedValue)
+		}
+	}
+	return s
 }
 
 // BuildChoiceKeyboard creates an inline keyboard for choice arguments.
-// Returns nil if there are too many choices (use text list instead).
-// The default option (if any) is highlighted with a checkmark.
-func BuildChoiceKeyboard(arg *command.ArgumentDef) *tgbotapi.InlineKeyboardMarkup {
-	if arg.Type != "choice" || len(arg.Choices) == 0 || len(arg.Choices) > maxInlineChoices {
+// Large choice lists are paginated with Prev/Next buttons; page is zero-based
+// and clamped to the valid range. The default option (if any) is highlighted
+// with a checkmark.
+func BuildChoiceKeyboard(lang string, arg *command.ArgumentDef, page int) *tgbotapi.InlineKeyboardMarkup {
+	if arg.Type != "choice" || len(arg.Choices) == 0 {
 		return nil
 	}
 
+	pages := (len(arg.Choices) + choicesPerPage - 1) / choicesPerPage
+	page = max(0, min(page, pages-1))
+	start := page * choicesPerPage
+	end := min(start+choicesPerPage, len(arg.Choices))
+
 	var rows [][]tgbotapi.InlineKeyboardButton
-	for _, choice := range arg.Choices {
+	for _, choice := range arg.Choices[start:end] {
 		label := choice
 		if choice == arg.Default {
-			label = "✓ " + choice + " (default)"
+			label = i18n.T(lang, i18n.DefaultChoice, choice)
 		}
 		btn := tgbotapi.NewInlineKeyboardButtonData(label, "arg:"+choice)
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(btn))
 	}
 
+	// Pagination row
+	if pages > 1 {
+		var pager []tgbotapi.InlineKeyboardButton
+		if page > 0 {
+			pager = append(pager, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.PrevPage), argPagePrefix+strconv.Itoa(page-1)))
+		}
+		pager = append(pager, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%d/%d", page+1, pages), argPagePrefix+strconv.Itoa(page)))
+		if page < pages-1 {
+			pager = append(pager, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.NextPage), argPagePrefix+strconv.Itoa(page+1)))
+		}
+		rows = append(rows, pager)
+	}
+
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 	return &keyboard
 }
 
-// BuildChoiceTextList creates a text list for choice arguments with many options.
-// The default option (if any) is highlighted.
-func BuildChoiceTextList(arg *command.ArgumentDef) string {
-	if arg.Type != "choice" || len(arg.Choices) <= maxInlineChoices {
-		return ""
+// DefaultChoicePage returns the keyboard page that contains the default choice.
+func DefaultChoicePage(arg *command.ArgumentDef) int {
+	if idx := slices.Index(arg.Choices, arg.Default); idx >= 0 {
+		return idx / choicesPerPage
+	}
+	return 0
+}
+
+// BuildDateKeyboard creates a quick-pick keyboard for date arguments (today/tomorrow).
+func BuildDateKeyboard(lang string, arg *command.ArgumentDef) *tgbotapi.InlineKeyboardMarkup {
+	if arg.Type != "date" {
+		return nil
 	}
 
-	var sb strings.Builder
-	sb.WriteString(arg.Description)
-	sb.WriteString("\n\nOptions:\n")
-	for i, choice := range arg.Choices {
-		if choice == arg.Default {
-			fmt.Fprintf(&sb, "%d. ✓ %s (default)\n", i+1, choice)
-		} else {
-			fmt.Fprintf(&sb, "%d. %s\n", i+1, choice)
+	now := time.Now()
+	today := now.Format(dateLayout)
+	tomorrow := now.AddDate(0, 0, 1).Format(dateLayout)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.Today, today), "arg:"+today),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.Tomorrow, tomorrow), "arg:"+tomorrow),
+		),
+	)
+	return &keyboard
+}
+
+// BuildPromptKeyboard creates the full keyboard for the current argument:
+// choice buttons (at the given page) or date quick-picks, followed by the
+// Back/Skip/Cancel navigation row.
+func BuildPromptKeyboard(session *ArgumentSession, page int) tgbotapi.InlineKeyboardMarkup {
+	var keyboard *tgbotapi.InlineKeyboardMarkup
+	if arg := session.CurrentArg(); arg != nil {
+		keyboard = BuildChoiceKeyboard(session.Lang, arg, page)
+		if keyboard == nil {
+			keyboard = BuildDateKeyboard(session.Lang, arg)
 		}
 	}
-	if arg.Default != "" {
-		sb.WriteString("\nPress Enter to use default.")
+
+	navRow := BuildNavigationRow(session)
+	if keyboard == nil {
+		return tgbotapi.NewInlineKeyboardMarkup(navRow)
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, navRow)
+	return *keyboard
+}
+
+// BuildNavigationRow creates Back/Skip/Cancel buttons for an argument prompt.
+// Back is shown after the first argument, Skip only for skippable arguments.
+func BuildNavigationRow(session *ArgumentSession) []tgbotapi.InlineKeyboardButton {
+	var row []tgbotapi.InlineKeyboardButton
+	if session.CurrentIdx > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(session.Lang, i18n.PrevArg), argNavBack))
+	}
+	if arg := session.CurrentArg(); arg != nil && (!arg.Required || arg.Default != "") {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(session.Lang, i18n.Skip), argNavSkip))
 	}
-	return sb.String()
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(session.Lang, i18n.CancelArgs), argNavCancel))
+	return row
+}
+
+// IsArgumentNavCallback checks if a callback is a Back/Skip/Cancel button press.
+func IsArgumentNavCallback(data string) bool {
+	return strings.HasPrefix(data, "argnav:")
+}
+
+// IsArgumentPageCallback checks if a callback is a choice keyboard page switch.
+func IsArgumentPageCallback(data string) bool {
+	return strings.HasPrefix(data, argPagePrefix)
+}
+
+// ParseArgumentPageCallback extracts the requested page from a pagination callback.
+func ParseArgumentPageCallback(data string) int {
+	page, _ := strconv.Atoi(strings.TrimPrefix(data, argPagePrefix))
+	return page
 }
 
 // IsArgumentCallback checks if a callback is an argument selection.
@@ -1,10 +1,14 @@
 package bot
 
 import (
+	"maps"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/config"
 )
 
 func TestValidateArgument(t *testing.T) {
@@ -74,6 +78,84 @@ func TestValidateArgument(t *testing.T) {
 			input:   "d",
 			wantErr: true,
 		},
+		{
+			name:    "int within range",
+			arg:     command.ArgumentDef{Name: "test", Type: "int", Min: ptr(1.0), Max: ptr(10.0)},
+			input:   "10",
+			wantErr: false,
+		},
+		{
+			name:    "int below min",
+			arg:     command.ArgumentDef{Name: "test", Type: "int", Min: ptr(1.0)},
+			input:   "0",
+			wantErr: true,
+		},
+		{
+			name:    "int above max",
+			arg:     command.ArgumentDef{Name: "test", Type: "int", Max: ptr(10.0)},
+			input:   "11",
+			wantErr: true,
+		},
+		{
+			name:    "valid float",
+			arg:     command.ArgumentDef{Name: "test", Type: "float", Min: ptr(0.0), Max: ptr(1.0)},
+			input:   "0.75",
+			wantErr: false,
+		},
+		{
+			name:    "float out of range",
+			arg:     command.ArgumentDef{Name: "test", Type: "float", Max: ptr(1.0)},
+			input:   "1.5",
+			wantErr: true,
+		},
+		{
+			name:    "invalid float",
+			arg:     command.ArgumentDef{Name: "test", Type: "float"},
+			input:   "abc",
+			wantErr: true,
+		},
+		{
+			name:    "valid duration",
+			arg:     command.ArgumentDef{Name: "test", Type: "duration"},
+			input:   "1h30m",
+			wantErr: false,
+		},
+		{
+			name:    "invalid duration",
+			arg:     command.ArgumentDef{Name: "test", Type: "duration"},
+			input:   "90",
+			wantErr: true,
+		},
+		{
+			name:    "valid date",
+			arg:     command.ArgumentDef{Name: "test", Type: "date"},
+			input:   "2025-02-28",
+			wantErr: false,
+		},
+		{
+			name:    "invalid date",
+			arg:     command.ArgumentDef{Name: "test", Type: "date"},
+			input:   "2025-02-30",
+			wantErr: true,
+		},
+		{
+			name:    "string within length",
+			arg:     command.ArgumentDef{Name: "test", Type: "string", MinLength: 2, MaxLength: 4},
+			input:   "héé",
+			wantErr: false,
+		},
+		{
+			name:    "string too short",
+			arg:     command.ArgumentDef{Name: "test", Type: "string", MinLength: 2},
+			input:   "a",
+			wantErr: true,
+		},
+		{
+			name:    "string too long",
+			arg:     command.ArgumentDef{Name: "test", MaxLength: 3},
+			input:   "abcd",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -208,57 +290,153 @@ func TestArgumentCollector(t *testing.T) {
 	}
 }
 
+func TestConditionalArguments(t *testing.T) {
+	collector := NewArgumentCollector()
+	session := &ArgumentSession{
+		ChatID: 1,
+		Arguments: []command.ArgumentDef{
+			{Name: "target", Type: "choice", Choices: []string{"prod", "custom"}},
+			{Name: "host", When: `{{eq .target "custom"}}`, Default: "localhost"},
+			{Name: "note"},
+		},
+		Collected:  make(map[string]string),
+		StartedAt:  time.Now(),
+		TimeoutDur: time.Minute,
+	}
+	collector.sessions[1] = session
+
+	// Choosing prod skips host
+	if errMsg := collector.ProcessInput(1, "prod"); errMsg != "" {
+		t.Fatalf("ProcessInput() = %q", errMsg)
+	}
+	if arg := session.CurrentArg(); arg == nil || arg.Name != "note" {
+		t.Fatalf("CurrentArg() = %v, want note", arg)
+	}
+
+	// Back skips over the unanswered host argument
+	if !collector.GoBack(1) || session.CurrentArg().Name != "target" {
+		t.Fatalf("GoBack() should return to target, got %v", session.CurrentArg())
+	}
+
+	// Choosing custom asks for host
+	collector.ProcessInput(1, "custom")
+	if arg := session.CurrentArg(); arg == nil || arg.Name != "host" {
+		t.Fatalf("CurrentArg() = %v, want host", arg)
+	}
+
+	collector.GoBack(1)
+	collector.ProcessInput(1, "prod")
+	collector.ProcessInput(1, "")
+	collected, _ := collector.CompleteSession(1)
+	if collected["host"] != "localhost" {
+		t.Errorf("skipped argument = %q, want default", collected["host"])
+	}
+}
+
 func TestBuildChoiceKeyboard(t *testing.T) {
-	// Test with few choices - should return keyboard
+	// Test with few choices - single page without pager
 	arg := &command.ArgumentDef{
 		Name:    "model",
 		Type:    "choice",
 		Choices: []string{"a", "b", "c"},
 	}
-	keyboard := BuildChoiceKeyboard(arg)
-	if keyboard == nil {
-		t.Error("BuildChoiceKeyboard() = nil, want keyboard for few choices")
+	keyboard := BuildChoiceKeyboard("en", arg, 0)
+	if keyboard == nil || len(keyboard.InlineKeyboard) != 3 {
+		t.Fatalf("BuildChoiceKeyboard() = %v, want 3 choice rows", keyboard)
 	}
 
-	// Test with many choices - should return nil
-	arg.Choices = []string{"a", "b", "c", "d", "e", "f"}
-	keyboard = BuildChoiceKeyboard(arg)
-	if keyboard != nil {
-		t.Error("BuildChoiceKeyboard() should return nil for many choices")
+	// Test with many choices - paginated
+	arg.Choices = nil
+	for i := range 20 {
+		arg.Choices = append(arg.Choices, string(rune('a'+i)))
+	}
+	keyboard = BuildChoiceKeyboard("en", arg, 0)
+	if keyboard == nil || len(keyboard.InlineKeyboard) != choicesPerPage+1 {
+		t.Fatalf("BuildChoiceKeyboard() page 0 rows = %d, want %d", len(keyboard.InlineKeyboard), choicesPerPage+1)
+	}
+	pager := keyboard.InlineKeyboard[choicesPerPage]
+	if len(pager) != 2 || *pager[1].CallbackData != argPagePrefix+"1" {
+		t.Errorf("page 0 pager = %d buttons, want indicator and Next", len(pager))
+	}
+
+	// Last page has remaining choices and Prev only
+	keyboard = BuildChoiceKeyboard("en", arg, 99)
+	if len(keyboard.InlineKeyboard) != 20-2*choicesPerPage+1 {
+		t.Errorf("last page rows = %d, want %d", len(keyboard.InlineKeyboard), 20-2*choicesPerPage+1)
+	}
+	if data := *keyboard.InlineKeyboard[0][0].CallbackData; data != "arg:"+arg.Choices[2*choicesPerPage] {
+		t.Errorf("last page first choice = %s", data)
+	}
+
+	// Default choice page
+	arg.Default = arg.Choices[choicesPerPage+1]
+	if got := DefaultChoicePage(arg); got != 1 {
+		t.Errorf("DefaultChoicePage() = %d, want 1", got)
 	}
 
 	// Test with non-choice type - should return nil
 	arg.Type = "string"
-	keyboard = BuildChoiceKeyboard(arg)
+	keyboard = BuildChoiceKeyboard("en", arg, 0)
 	if keyboard != nil {
 		t.Error("BuildChoiceKeyboard() should return nil for non-choice type")
 	}
 }
 
-func TestBuildChoiceTextList(t *testing.T) {
-	// Test with few choices - should return empty
-	arg := &command.ArgumentDef{
-		Name:        "model",
-		Description: "Select a model",
-		Type:        "choice",
-		Choices:     []string{"a", "b", "c"},
+func TestParseArgumentPageCallback(t *testing.T) {
+	if !IsArgumentPageCallback("argpage:2") || IsArgumentCallback("argpage:2") {
+		t.Error("page callbacks must not be treated as argument selections")
+	}
+	if got := ParseArgumentPageCallback("argpage:2"); got != 2 {
+		t.Errorf("ParseArgumentPageCallback() = %d, want 2", got)
+	}
+}
+
+func TestBuildDateKeyboard(t *testing.T) {
+	arg := &command.ArgumentDef{Name: "day", Type: "date"}
+	keyboard := BuildDateKeyboard("en", arg)
+	if keyboard == nil || len(keyboard.InlineKeyboard[0]) != 2 {
+		t.Fatalf("BuildDateKeyboard() = %v, want today/tomorrow buttons", keyboard)
+	}
+	today := "arg:" + time.Now().Format("2006-01-02")
+	if data := keyboard.InlineKeyboard[0][0].CallbackData; data == nil || *data != today {
+		t.Errorf("first button data = %v, want %s", data, today)
+	}
+
+	arg.Type = "string"
+	if BuildDateKeyboard("en", arg) != nil {
+		t.Error("BuildDateKeyboard() should return nil for non-date type")
 	}
-	text := BuildChoiceTextList(arg)
-	if text != "" {
-		t.Error("BuildChoiceTextList() should return empty for few choices")
+}
+
+func TestBuildNavigationRow(t *testing.T) {
+	session := &ArgumentSession{
+		Arguments: []command.ArgumentDef{
+			{Name: "env", Required: true},
+			{Name: "note"},
+		},
+		Collected: make(map[string]string),
+	}
+
+	// First required argument: Cancel only
+	row := BuildNavigationRow(session)
+	if len(row) != 1 || *row[0].CallbackData != argNavCancel {
+		t.Errorf("BuildNavigationRow() first arg = %d buttons, want Cancel only", len(row))
 	}
 
-	// Test with many choices - should return text list
-	arg.Choices = []string{"a", "b", "c", "d", "e", "f"}
-	text = BuildChoiceTextList(arg)
-	if text == "" {
-		t.Error("BuildChoiceTextList() should return text list for many choices")
+	// Second optional argument: Back, Skip, Cancel
+	session.CurrentIdx = 1
+	row = BuildNavigationRow(session)
+	if len(row) != 3 || *row[0].CallbackData != argNavBack || *row[1].CallbackData != argNavSkip {
+		t.Errorf("BuildNavigationRow() second arg = %d buttons, want Back/Skip/Cancel", len(row))
 	}
-	if !contains(text, "Select a model") {
-		t.Error("BuildChoiceTextList() should contain description")
+}
+
+func TestIsArgumentNavCallback(t *testing.T) {
+	if !IsArgumentNavCallback(argNavBack) || IsArgumentCallback(argNavBack) {
+		t.Error("navigation callbacks must not be treated as argument selections")
 	}
-	if !contains(text, "1. a") {
-		t.Error("BuildChoiceTextList() should contain numbered options")
+	if IsArgumentNavCallback("arg:value") {
+		t.Error("IsArgumentNavCallback(arg:value) = true, want false")
 	}
 }
 
@@ -302,6 +480,106 @@ func TestParseArgumentCallback(t *testing.T) {
 	}
 }
 
+func TestBuildArgumentPromptConstraints(t *testing.T) {
+	arg := &command.ArgumentDef{Description: "Lines", Type: "int", Min: ptr(1.0), Max: ptr(500.0)}
+	if got := BuildArgumentPrompt("en", arg); !contains(got, "between 1 and 500") {
+		t.Errorf("BuildArgumentPrompt() = %q, want range hint", got)
+	}
+
+	arg = &command.ArgumentDef{Description: "Name", MaxLength: 20}
+	if got := BuildArgumentPrompt("en", arg); !contains(got, "at most 20 characters") {
+		t.Errorf("BuildArgumentPrompt() = %q, want length hint", got)
+	}
+}
+
+func TestSensitiveMasking(t *testing.T) {
+	secret := &command.ArgumentDef{Name: "token", Sensitive: true, Default: "s3cr3t"}
+	if got := DisplayValue(secret, "s3cr3t"); got != maskedValue {
+		t.Errorf("DisplayValue() = %q, want mask", got)
+	}
+	if got := BuildArgumentPrompt("en", secret); contains(got, "s3cr3t") {
+		t.Errorf("BuildArgumentPrompt() = %q, leaks sensitive default", got)
+	}
+
+	plain := &command.ArgumentDef{Name: "env"}
+	if got := DisplayValue(plain, "prod"); got != "prod" {
+		t.Errorf("DisplayValue() = %q, want prod", got)
+	}
+
+	got := RedactValues("login with s3cr3t and s3cr3t", []string{"s3cr3t", ""})
+	if got != "login with **** and ****" {
+		t.Errorf("RedactValues() = %q", got)
+	}
+}
+
+func TestBuildRenderedPreview(t *testing.T) {
+	dir := t.TempDir()
+	def := "name: login\ncommand: login {{.token}}\nworkdir: /srv\narguments:\n  - name: token\n    sensitive: true\n"
+	if err := os.WriteFile(filepath.Join(dir, "login.yaml"), []byte(def), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmds, err := command.NewLoader([]string{dir}, config.DefaultsConfig{}, nil).Load()
+	if err != nil || len(cmds) != 1 {
+		t.Fatalf("Load() = %v, %v", cmds, err)
+	}
+	cmd := cmds[0].(*command.YAMLCommand)
+
+	host, _ := os.Hostname()
+	got := BuildRenderedPreview("en", cmd, "login s3cr3t", map[string]string{"token": "s3cr3t"}, false)
+	want := "login ****\nWorkdir: /srv\nRuns on: shell on " + host
+	if got != want {
+		t.Errorf("BuildRenderedPreview() = %q, want %q", got, want)
+	}
+}
+
+func TestParseInlineArguments(t *testing.T) {
+	defs := []command.ArgumentDef{
+		{Name: "env", Type: "choice", Choices: []string{"staging", "prod"}},
+		{Name: "version", Type: "string"},
+		{Name: "replicas", Type: "int", Default: "2"},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: nil},
+		{name: "pairs", input: "env=prod version=1.2.3", want: map[string]string{"env": "prod", "version": "1.2.3"}},
+		{name: "quoted value", input: `version="1.2 beta"`, want: map[string]string{"version": "1.2 beta"}},
+		{name: "empty uses default", input: "replicas=", want: map[string]string{"replicas": "2"}},
+		{name: "invalid choice", input: "env=dev", wantErr: true},
+		{name: "unknown argument", input: "region=eu", wantErr: true},
+		{name: "missing equals", input: "prod", wantErr: true},
+		{name: "unterminated quote", input: `version="1.2`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInlineArguments(defs, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseInlineArguments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseInlineArguments() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("ParseInlineArguments()[%q] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -314,3 +592,37 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestParseLeadingArguments(t *testing.T) {
+	defs := []command.ArgumentDef{
+		{Name: "text", Type: "string"},
+		{Name: "voice", Type: "choice", Choices: []string{"alice", "bob"}},
+		{Name: "speed", Type: "choice", Choices: []string{"1.0", "1.5"}},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: map[string]string{}},
+		{name: "text only", input: "Hello\nworld", want: map[string]string{"text": "Hello\nworld"}},
+		{name: "options then text", input: "voice=bob speed=1.5\nHello\nworld", want: map[string]string{"voice": "bob", "speed": "1.5", "text": "Hello\nworld"}},
+		{name: "options only", input: "voice=alice", want: map[string]string{"voice": "alice"}},
+		{name: "text is not an option", input: "text=x y", want: map[string]string{"text": "text=x y"}},
+		{name: "invalid choice", input: "voice=eve Hello", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLeadingArguments(defs, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLeadingArguments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !maps.Equal(got, tt.want) {
+				t.Errorf("ParseLeadingArguments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
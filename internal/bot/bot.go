@@ -2,47 +2,114 @@
 package bot
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"github.com/rashpile/pako-telegram/internal/audit"
 	"github.com/rashpile/pako-telegram/internal/auth"
 	"github.com/rashpile/pako-telegram/internal/command"
 	"github.com/rashpile/pako-telegram/internal/command/builtin"
 	"github.com/rashpile/pako-telegram/internal/config"
 	"github.com/rashpile/pako-telegram/internal/fileref"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/inbox"
+	"github.com/rashpile/pako-telegram/internal/logbuf"
 	"github.com/rashpile/pako-telegram/internal/msgstore"
+	"github.com/rashpile/pako-telegram/internal/ratelimit"
 	"github.com/rashpile/pako-telegram/internal/scheduler"
+	"github.com/rashpile/pako-telegram/internal/settings"
+	"github.com/rashpile/pako-telegram/internal/transcribe"
 	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
 // Config holds dependencies for Bot construction.
 type Config struct {
-	Token          string
-	Authorizer     auth.Authorizer
-	Registry       *command.Registry
-	Defaults       config.DefaultsConfig
-	AllowedChatIDs []int64 // Chat IDs to notify on startup
-	MessageStore   *msgstore.Store
+	Token           string
+	Authorizer      auth.Authorizer
+	Registry        *command.Registry
+	Defaults        config.DefaultsConfig
+	AllowedChatIDs  []int64 // Chat IDs to notify on startup
+	MessageStore    *msgstore.Store
+	AuditLogger     audit.Logger           // Optional, defaults to no-op
+	AdminChatID     int64                  // Chat for operational notices (0 = disabled)
+	Roles           *auth.RoleMap          // Optional, used to identify admins for approvals (nil = everyone)
+	ApprovalsChatID int64                  // Chat for multi-person approval requests (0 = requesting chat)
+	OTP             *auth.OTPVerifier      // Optional, needed for require_otp commands
+	RateLimits      config.RateLimitConfig // Global per-user and per-chat limits (zero = unlimited)
+	Sudo            *auth.Sudo             // Optional, needed for elevated commands
+	Downloader      *fileref.Downloader    // Optional, needed for [url:...] references
+	Inbox           *inbox.Inbox           // Optional, needed for commands with input
+	// Transcriber turns voice messages into a command's text argument
+	// (nil = voice messages are ignored).
+	Transcriber   transcribe.Transcriber
+	Transcription config.TranscriptionConfig
+	Menus         map[int64]config.MenuConfig // Per-chat menu layouts
+	Language      string                      // Default message language (default: en)
+	ChatLanguages map[int64]string            // Per-chat message languages
+	Settings      *settings.Store             // Optional, per-chat /settings (nil = /settings unavailable)
+	Logs          *logbuf.Buffer              // Optional, recent log records for /logs (nil = /logs unavailable)
+	Shell         config.ShellConfig          // Admin /shell sessions (disabled unless Shell.Enabled)
+	// TrackUserCommands records users' /command messages so cleanup can
+	// delete them too (needs message deletion rights in groups).
+	TrackUserCommands bool
 }
 
 // Bot handles Telegram updates and routes commands to handlers.
 type Bot struct {
-	api            *tgbotapi.BotAPI
-	authorizer     auth.Authorizer
-	registry       *command.Registry
-	defaults       config.DefaultsConfig
-	confirmMgr     *ConfirmationManager
-	menuBuilder    *MenuBuilder
-	argCollector   *ArgumentCollector
-	allowedChatIDs []int64
-	msgStore       *msgstore.Store
-	cleanupCmd     *builtin.CleanupCommand
-	scheduler      *scheduler.Scheduler
+	api             *tgbotapi.BotAPI
+	authorizer      auth.Authorizer
+	registry        *command.Registry
+	defaults        config.DefaultsConfig
+	confirmMgr      *ConfirmationManager
+	menuBuilder     *MenuBuilder
+	argCollector    *ArgumentCollector
+	allowedChatIDs  []int64
+	msgStore        *msgstore.Store
+	trackCommands   bool
+	cleanupCmd      *builtin.CleanupCommand
+	scheduler       *scheduler.Scheduler
+	auditLogger     audit.Logger
+	adminChatID     int64
+	roles           *auth.RoleMap
+	approvalsChatID int64
+	otp             *auth.OTPVerifier
+	otpMgr          *OTPManager
+	sudo            *auth.Sudo
+	pinMgr          *OTPManager // Pending /sudo PIN prompts
+	downloader      *fileref.Downloader
+	inbox           *inbox.Inbox
+	transcriber     transcribe.Transcriber
+	transcription   config.TranscriptionConfig
+	members         memberCache // Inline query membership checks
+	menus           menuTracker // Open menus, redrawn after reloads
+	accessReqs      *AccessRequests
+	limiter         *ratelimit.Limiter
+	rateLimits      config.RateLimitConfig
+	settings        *settings.Store
+	logs            *logbuf.Buffer
+	language        string
+	chatLanguages   map[int64]string
+	started         time.Time
+	lastRun         lastRun       // Most recent command, for the heartbeat
+	outputs         outputHistory // Last output of notify_on_change commands
+	runs            runTracker    // Executions in progress, drained before a restart
+	cooldowns       cooldowns     // Last runs of commands with a cooldown
+	maintenance     atomic.Bool   // Dry-run commands that aren't read_only
+	shell           config.ShellConfig
+	shells          shellSessions // Open /shell sessions by chat
+
+	// settingsMu guards settings that can change on config reload
+	settingsMu sync.RWMutex
 }
 
 // New creates a Bot with the given dependencies.
@@ -60,36 +127,173 @@ func New(cfg Config) (*Bot, error) {
 	}
 
 	menuBuilder := NewMenuBuilder(registry)
+	menuBuilder.SetChatMenus(cfg.Menus)
 
-	b := &Bot{
-		api:            api,
-		authorizer:     cfg.Authorizer,
-		registry:       registry,
-		defaults:       cfg.Defaults,
-		confirmMgr:     NewConfirmationManager(),
-		menuBuilder:    menuBuilder,
-		argCollector:   NewArgumentCollector(),
-		allowedChatIDs: cfg.AllowedChatIDs,
-		msgStore:       cfg.MessageStore,
+	auditLogger := cfg.AuditLogger
+	if auditLogger == nil {
+		auditLogger = audit.NopLogger{}
 	}
 
+	b := &Bot{
+		api:             api,
+		started:         time.Now(),
+		authorizer:      cfg.Authorizer,
+		registry:        registry,
+		defaults:        cfg.Defaults,
+		confirmMgr:      NewConfirmationManager(),
+		menuBuilder:     menuBuilder,
+		argCollector:    NewArgumentCollector(),
+		allowedChatIDs:  cfg.AllowedChatIDs,
+		msgStore:        cfg.MessageStore,
+		trackCommands:   cfg.TrackUserCommands,
+		auditLogger:     auditLogger,
+		adminChatID:     cfg.AdminChatID,
+		roles:           cfg.Roles,
+		approvalsChatID: cfg.ApprovalsChatID,
+		otp:             cfg.OTP,
+		otpMgr:          NewOTPManager(),
+		sudo:            cfg.Sudo,
+		pinMgr:          NewOTPManager(),
+		downloader:      cfg.Downloader,
+		inbox:           cfg.Inbox,
+		transcriber:     cfg.Transcriber,
+		transcription:   cfg.Transcription,
+		accessReqs:      NewAccessRequests(),
+		limiter:         ratelimit.New(),
+		rateLimits:      cfg.RateLimits,
+		settings:        cfg.Settings,
+		logs:            cfg.Logs,
+		shell:           cfg.Shell,
+		language:        cfg.Language,
+		chatLanguages:   cfg.ChatLanguages,
+	}
+	if b.language == "" {
+		b.language = i18n.Default
+	}
+
+	// Menus, confirmations and prompts use each chat's language
+	menuBuilder.SetLanguage(b.lang)
+	b.confirmMgr.SetLanguage(b.lang)
+	b.argCollector.SetLanguage(b.lang)
+
 	// Create cleanup command if message store is enabled
 	if cfg.MessageStore != nil && cfg.MessageStore.Enabled() {
 		b.cleanupCmd = builtin.NewCleanupCommand(cfg.MessageStore, b)
 		menuBuilder.SetCleanupEnabled(true)
 	}
 
+	// Track confirmation and approval messages for cleanup
+	b.confirmMgr.SetOnSent(func(chatID int64, messageID int) {
+		b.trackMessage(chatID, messageID, msgstore.TypeConfirmation)
+	})
+	b.confirmMgr.SetOnExpired(func(p *PendingConfirmation) {
+		b.auditConfirmation(p, audit.StatusExpired, nil)
+	})
+
+	// Open menus follow commands added, removed or replaced
+	registry.OnChange(func([]command.Change) {
+		b.RefreshMenus()
+	})
+
 	return b, nil
 }
 
-// NotifyStartup sends a startup message with menu to all allowed chats.
+// NotifyStartup sends a startup message with menu to all allowed chats,
+// except those that turned startup notifications off in /settings.
 func (b *Bot) NotifyStartup() {
-	for _, chatID := range b.allowedChatIDs {
-		b.sendText(chatID, "Bot restarted")
+	b.settingsMu.RLock()
+	chatIDs := b.allowedChatIDs
+	b.settingsMu.RUnlock()
+
+	for _, chatID := range chatIDs {
+		if b.chatSetting(chatID, settings.Startup) == "off" {
+			continue
+		}
+		b.sendText(chatID, b.t(chatID, i18n.BotRestarted))
 		b.sendMenu(chatID)
 	}
 }
 
+// NotifyAdmin sends an operational notice to the admin chat, if configured.
+func (b *Bot) NotifyAdmin(text string) {
+	b.settingsMu.RLock()
+	adminChatID := b.adminChatID
+	b.settingsMu.RUnlock()
+
+	if adminChatID == 0 {
+		return
+	}
+	b.sendText(adminChatID, text)
+}
+
+// NotifyChats sends an operational notice to the given chats, or to the admin
+// chat when none are given.
+func (b *Bot) NotifyChats(chatIDs []int64, text string) {
+	if len(chatIDs) == 0 {
+		b.NotifyAdmin(text)
+		return
+	}
+	for _, chatID := range chatIDs {
+		b.sendText(chatID, text)
+	}
+}
+
+// UpdateSettings applies reloaded configuration values without interrupting
+// in-flight executions (they keep the values they started with).
+func (b *Bot) UpdateSettings(defaults config.DefaultsConfig, allowedChatIDs []int64, adminChatID int64) {
+	b.settingsMu.Lock()
+	defer b.settingsMu.Unlock()
+
+	b.defaults = defaults
+	b.allowedChatIDs = allowedChatIDs
+	b.adminChatID = adminChatID
+}
+
+// SetLanguages replaces the default and per-chat message languages from
+// config. Languages chosen with /settings still take precedence.
+func (b *Bot) SetLanguages(def string, chats map[int64]string) {
+	if def == "" {
+		def = i18n.Default
+	}
+	b.settingsMu.Lock()
+	defer b.settingsMu.Unlock()
+	b.language = def
+	b.chatLanguages = chats
+}
+
+// lang returns the chat's message language: its /settings choice, then its
+// configured language, then the default.
+func (b *Bot) lang(chatID int64) string {
+	if b.settings != nil {
+		if lang := b.settings.Get(chatID, settings.Language); i18n.Supported(lang) {
+			return lang
+		}
+	}
+	b.settingsMu.RLock()
+	defer b.settingsMu.RUnlock()
+	if lang, ok := b.chatLanguages[chatID]; ok {
+		return lang
+	}
+	return b.language
+}
+
+// t returns a message in the chat's language.
+func (b *Bot) t(chatID int64, key i18n.Key, args ...any) string {
+	return i18n.T(b.lang(chatID), key, args...)
+}
+
+// SetChatMenus replaces the per-chat menu layouts.
+func (b *Bot) SetChatMenus(menus map[int64]config.MenuConfig) {
+	b.menuBuilder.SetChatMenus(menus)
+}
+
+// currentDefaults returns the execution defaults.
+func (b *Bot) currentDefaults() config.DefaultsConfig {
+	b.settingsMu.RLock()
+	defer b.settingsMu.RUnlock()
+	return b.defaults
+}
+
 // Registry returns the command registry for registration.
 func (b *Bot) Registry() *command.Registry {
 	return b.registry
@@ -107,6 +311,9 @@ func (b *Bot) Run(ctx context.Context) error {
 
 	updates := b.api.GetUpdatesChan(u)
 
+	pruneTicker := time.NewTicker(time.Hour)
+	defer pruneTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -114,6 +321,19 @@ func (b *Bot) Run(ctx context.Context) error {
 			slog.Info("bot stopped")
 			return nil
 
+		case <-pruneTicker.C:
+			// Drop rate limit buckets that have long since refilled
+			b.limiter.Prune(24 * time.Hour)
+
+			// Drop uploaded files past their retention
+			if b.inbox != nil {
+				if n, err := b.inbox.Prune(); err != nil {
+					slog.Warn("failed to prune inbox", "error", err)
+				} else if n > 0 {
+					slog.Info("pruned inbox", "removed", n)
+				}
+			}
+
 		case update := <-updates:
 			// Handle callback queries (menu navigation, confirmation buttons, argument selection)
 			if update.CallbackQuery != nil {
@@ -121,6 +341,12 @@ func (b *Bot) Run(ctx context.Context) error {
 				continue
 			}
 
+			// Handle inline queries (command search)
+			if update.InlineQuery != nil {
+				go b.handleInlineQuery(update.InlineQuery)
+				continue
+			}
+
 			// Handle messages
 			if update.Message != nil {
 				chatID := update.Message.Chat.ID
@@ -138,10 +364,81 @@ func (b *Bot) Run(ctx context.Context) error {
 						go b.handleMenuCommand(update.Message)
 						continue
 					}
+					if cmdName == "sudo" {
+						go b.handleSudoCommand(update.Message)
+						continue
+					}
+					if cmdName == "settings" {
+						go b.handleSettingsCommand(update.Message)
+						continue
+					}
+					if cmdName == "whoami" {
+						go b.handleWhoamiCommand(update.Message)
+						continue
+					}
+					if cmdName == "ping" {
+						go b.handlePingCommand(update.Message)
+						continue
+					}
+					// A configured command named logs takes precedence
+					if cmdName == "logs" && b.registry.Get("logs") == nil {
+						go b.handleLogsCommand(update.Message)
+						continue
+					}
+					if cmdName == "history" {
+						go b.handleHistoryCommand(ctx, update.Message)
+						continue
+					}
+					// A configured command named shell takes precedence
+					if cmdName == "shell" && b.registry.Get("shell") == nil {
+						go b.handleShellCommand(ctx, update.Message)
+						continue
+					}
+					if cmdName == "exit" && b.shells.get(chatID) != nil {
+						go b.handleExitCommand(update.Message)
+						continue
+					}
 					go b.handleCommand(ctx, update.Message)
 					continue
 				}
 
+				// Handle documents and photos captioned with a command (file inbox)
+				if (update.Message.Document != nil || len(update.Message.Photo) > 0) && captionCommand(update.Message.Caption) != "" {
+					go b.handleUpload(ctx, update.Message)
+					continue
+				}
+
+				// Transcribe voice messages into a command's text argument
+				if update.Message.Voice != nil && b.transcriber != nil {
+					go b.handleVoiceMessage(ctx, update.Message)
+					continue
+				}
+
+				// Handle one-time codes for commands with require_otp
+				if update.Message.From != nil && b.otpMgr.Waiting(chatID, update.Message.From.ID) {
+					go b.handleOTPInput(update.Message)
+					continue
+				}
+
+				// Handle PINs for /sudo
+				if update.Message.From != nil && b.pinMgr.Waiting(chatID, update.Message.From.ID) {
+					go b.handlePINInput(update.Message)
+					continue
+				}
+
+				// Handle typed confirmation phrases
+				if b.confirmMgr.WaitingPhrase(chatID) {
+					go b.handlePhraseInput(ctx, update.Message)
+					continue
+				}
+
+				// Queue messages from a shell session's admin for the shell,
+				// in order, so not in a goroutine
+				if update.Message.From != nil && b.shells.owns(chatID, update.Message.From.ID) {
+					b.queueShellInput(update.Message)
+					continue
+				}
+
 				// Handle non-command text messages for argument collection
 				if b.argCollector.HasSession(chatID) {
 					go b.handleArgumentInput(ctx, update.Message)
@@ -158,21 +455,37 @@ func (b *Bot) handleMenuCommand(msg *tgbotapi.Message) {
 	// Check authorization
 	if !b.authorizer.IsAllowed(chatID) {
 		slog.Warn("unauthorized access attempt", "chat_id", chatID)
-		b.sendText(chatID, fmt.Sprintf("Unauthorized. Your chat ID (%d) is not in the allowlist.", chatID))
+		b.logUnauthorized(chatID, msg.From, msg.Command())
+		b.rejectChat(chatID)
 		return
 	}
+	if b.rejectUser(chatID, msg.From, msg.Command(), true) {
+		return
+	}
+	b.trackUserCommand(msg)
 
 	b.sendMenu(chatID)
 }
 
 // handleCallback processes menu navigation and confirmation button presses.
 func (b *Bot) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	ctx = withUser(ctx, query.From)
 	chatID := query.Message.Chat.ID
 	logger := slog.With("chat_id", chatID, "callback", query.Data)
 
+	// Access requests come from unknown chats, so they bypass the allowlist
+	if IsAccessCallback(query.Data) {
+		b.handleAccessCallback(query)
+		return
+	}
+
 	// Check authorization
 	if !b.authorizer.IsAllowed(chatID) {
 		logger.Warn("unauthorized callback attempt")
+		b.logUnauthorized(chatID, query.From, callbackAttempt(query.Data))
+		return
+	}
+	if b.rejectUser(chatID, query.From, callbackAttempt(query.Data), false) {
 		return
 	}
 
@@ -180,6 +493,18 @@ func (b *Bot) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery)
 	callback := tgbotapi.NewCallback(query.ID, "")
 	b.api.Request(callback)
 
+	// Check if this is an argument navigation callback (Back/Skip/Cancel)
+	if IsArgumentNavCallback(query.Data) {
+		b.handleArgumentNavCallback(ctx, query)
+		return
+	}
+
+	// Check if this is a choice keyboard page switch
+	if IsArgumentPageCallback(query.Data) {
+		b.handleArgumentPageCallback(query)
+		return
+	}
+
 	// Check if this is an argument selection callback
 	if IsArgumentCallback(query.Data) {
 		b.handleArgumentCallback(ctx, query)
@@ -198,37 +523,110 @@ func (b *Bot) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery)
 		return
 	}
 
+	// Check if this is a /settings button
+	if IsSettingsCallback(query.Data) {
+		b.handleSettingsCallback(query)
+		return
+	}
+
+	// Check if this is a /history re-run button
+	if IsHistoryCallback(query.Data) {
+		b.handleHistoryCallback(ctx, query)
+		return
+	}
+
+	// Check if this is a /logs level or refresh button
+	if IsLogsCallback(query.Data) {
+		b.handleLogsCallback(query)
+		return
+	}
+
 	// Handle confirmation callbacks
-	pending, confirmed := b.confirmMgr.HandleCallback(query.Data)
+	approver := Approver{
+		ID:    query.From.ID,
+		Name:  userDisplayName(query.From),
+		Admin: b.isAdmin(chatID, query.From),
+	}
+	pending, status := b.confirmMgr.HandleCallback(query.Data, approver)
+	if status == ConfirmDryRun {
+		b.runDryRun(ctx, pending)
+		return
+	}
+	switch status {
+	case ConfirmPending, ConfirmApproved:
+		b.auditConfirmation(pending, audit.StatusApproved, query.From)
+	case ConfirmCancelled:
+		b.auditConfirmation(pending, audit.StatusCancelled, query.From)
+	case ConfirmExpired:
+		b.auditConfirmation(pending, audit.StatusExpired, nil)
+	}
 
 	// Update the message to show result
 	var resultText string
-	if pending == nil {
-		resultText = "Confirmation expired or invalid."
-	} else if !confirmed {
-		resultText = "Command cancelled."
-	} else {
-		resultText = fmt.Sprintf("Executing /%s...", pending.Command)
+	switch status {
+	case ConfirmInvalid, ConfirmExpired:
+		resultText = b.t(chatID, i18n.ConfirmExpired)
+	case ConfirmCancelled:
+		resultText = b.t(chatID, i18n.CommandCancelled)
+		if pending.Required > 1 {
+			resultText = b.t(chatID, i18n.DeniedBy, pending.Command, approver.Name)
+			if pending.ChatID != chatID {
+				b.sendText(pending.ChatID, b.t(pending.ChatID, i18n.DeniedBy, pending.Command, approver.Name))
+			}
+		}
+	case ConfirmForbidden:
+		b.sendText(chatID, b.t(chatID, i18n.AdminsOnly, pending.Command))
+		return
+	case ConfirmDuplicate:
+		b.sendText(chatID, b.t(chatID, i18n.AlreadyApproved, approver.Name, pending.Command))
+		return
+	case ConfirmSelfApproval:
+		b.sendText(chatID, b.t(chatID, i18n.SelfApprovalForbidden, approver.Name, pending.Command))
+		return
+	case ConfirmPending:
+		edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, approvalText(b.lang(chatID), pending))
+		edit.ReplyMarkup = query.Message.ReplyMarkup
+		b.api.Send(edit)
+		return
+	case ConfirmApproved:
+		resultText = b.t(chatID, i18n.Executing, pending.Command)
+		if pending.Required > 1 {
+			resultText = b.t(chatID, i18n.ApprovedExecuting, strings.Join(pending.ApproverNames(), ", "), pending.Command)
+		}
 	}
 
 	edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, resultText)
 	b.api.Send(edit)
 
-	// Execute if confirmed
-	if confirmed && pending != nil {
-		cmd := b.registry.Get(pending.Command)
-		if cmd != nil {
-			// Check if this is a rendered command (from argument collection)
-			if pending.RenderedCommand != "" {
-				if yamlCmd, ok := cmd.(*command.YAMLCommand); ok {
-					b.executeRenderedCommand(ctx, chatID, yamlCmd, pending.RenderedCommand)
-				}
-			} else {
-				b.executeCommand(ctx, chatID, cmd, pending.Args)
-			}
-			b.sendMenu(chatID)
+	if status != ConfirmApproved {
+		return
+	}
+	b.runConfirmed(ctx, pending)
+}
+
+// runConfirmed runs a command whose confirmation or approvals are complete.
+func (b *Bot) runConfirmed(ctx context.Context, pending *PendingConfirmation) {
+	// Multi-person approvals run in the requesting chat on the requester's behalf
+	runChatID := pending.ChatID
+	if pending.Required > 1 {
+		ctx = withUser(ctx, pending.Requester)
+		ctx = withApprovers(ctx, pending.ApproverNames())
+	}
+
+	cmd := b.registry.Get(pending.Command)
+	if cmd == nil || b.rejectDisabled(runChatID, cmd) || b.rejectRestricted(runChatID, userFromContext(ctx), cmd) {
+		return
+	}
+
+	// Check if this is a rendered command (from argument collection)
+	if pending.RenderedCommand != "" {
+		if yamlCmd, ok := cmd.(*command.YAMLCommand); ok {
+			b.executeRenderedCommand(ctx, runChatID, yamlCmd, pending.RenderedCommand, pending.CollectedArgs)
 		}
+	} else {
+		b.executeCommand(ctx, runChatID, cmd, pending.Args)
 	}
+	b.sendMenu(runChatID)
 }
 
 // handleMenuCallback processes menu navigation callbacks.
@@ -240,29 +638,31 @@ func (b *Bot) handleMenuCallback(ctx context.Context, query *tgbotapi.CallbackQu
 	callbackType, value := ParseCallback(query.Data)
 
 	switch callbackType {
-	case "menu":
-		// Show main menu
-		text, keyboard := b.menuBuilder.BuildMainMenu()
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
-		edit.ReplyMarkup = &keyboard
-		if _, err := b.api.Send(edit); err != nil {
-			logger.Error("failed to edit menu", "error", err)
+	case "menu", "category", "group":
+		// Show main menu ("menu:main[:<page>]"), a category ("cat:<name>[:<page>]")
+		// or a command's subcommands ("grp:<name>[:<page>]")
+		text, keyboard, ok := b.renderMenu(chatID, query.Data)
+		if !ok {
+			logger.Info("stale menu callback")
+			b.showOutdatedMenu(chatID, messageID)
+			return
 		}
-
-	case "category":
-		// Show category commands
-		text, keyboard := b.menuBuilder.BuildCategoryMenu(value)
 		edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
 		edit.ReplyMarkup = &keyboard
 		if _, err := b.api.Send(edit); err != nil {
-			logger.Error("failed to show category", "error", err)
+			logger.Error("failed to edit menu", "error", err)
+			return
 		}
+		b.menus.set(chatID, messageID, query.Data)
 
 	case "cleanup":
 		// Handle cleanup option selection
 		b.handleCleanupCallback(chatID, messageID, value, logger)
 
 	case "command":
+		// The message stops being a menu: it is replaced or deleted below
+		b.menus.remove(chatID, messageID)
+
 		// Check if this is the cleanup command
 		if value == "cleanup" {
 			b.showCleanupMenu(chatID, messageID)
@@ -272,6 +672,10 @@ func (b *Bot) handleMenuCallback(ctx context.Context, query *tgbotapi.CallbackQu
 		cmd := b.registry.Get(value)
 		if cmd == nil {
 			logger.Warn("command not found from menu", "command", value)
+			b.showOutdatedMenu(chatID, messageID)
+			return
+		}
+		if b.rejectDisabled(chatID, cmd) || b.rejectRestricted(chatID, query.From, cmd) || b.rejectThrottled(ctx, chatID, cmd) {
 			return
 		}
 
@@ -285,16 +689,16 @@ func (b *Bot) handleMenuCallback(ctx context.Context, query *tgbotapi.CallbackQu
 			}
 		}
 
-		// Check if command is a YAMLCommand with arguments
-		if yamlCmd, ok := cmd.(*command.YAMLCommand); ok && yamlCmd.HasArguments() {
+		// Check if command has arguments to collect
+		if argCmd, ok := cmd.(ArgumentCommand); ok && argCmd.HasArguments() {
 			// Delete the menu message and start argument collection
 			deleteMsg := tgbotapi.NewDeleteMessage(chatID, messageID)
 			b.api.Request(deleteMsg)
 
 			logger.Info("starting argument collection from menu", "command", value)
-			session := b.argCollector.StartSession(chatID, yamlCmd)
+			session := b.argCollector.StartSession(chatID, argCmd, nil)
 			if session != nil && !session.IsComplete() {
-				b.promptNextArgument(chatID, session)
+				b.promptNextArgument(ctx, chatID, session)
 				return
 			}
 			// All arguments have defaults, proceed with execution
@@ -302,20 +706,24 @@ func (b *Bot) handleMenuCallback(ctx context.Context, query *tgbotapi.CallbackQu
 			return
 		}
 
+		// Check if command requires approvals from several admins
+		if pkgcmd.RequiredApprovals(cmd) > 1 {
+			b.api.Request(tgbotapi.NewDeleteMessage(chatID, messageID))
+			b.requestApproval(ctx, ApprovalRequest{ChatID: chatID, Command: value})
+			return
+		}
+
 		// Check if command requires confirmation
-		if withMeta, ok := cmd.(pkgcmd.WithMetadata); ok {
-			meta := withMeta.Metadata()
-			if meta.RequireConfirm {
-				// Delete the menu message and request confirmation
-				deleteMsg := tgbotapi.NewDeleteMessage(chatID, messageID)
-				b.api.Request(deleteMsg)
+		if b.requiresConfirm(chatID, cmd) {
+			// Delete the menu message and request confirmation
+			deleteMsg := tgbotapi.NewDeleteMessage(chatID, messageID)
+			b.api.Request(deleteMsg)
 
-				logger.Info("requesting confirmation from menu", "command", value)
-				if err := b.confirmMgr.RequestConfirmation(b.api, chatID, value, nil); err != nil {
-					logger.Error("failed to request confirmation", "error", err)
-				}
-				return
+			logger.Info("requesting confirmation from menu", "command", value)
+			if err := b.requestConfirmation(ctx, b.confirmRequest(chatID, cmd, nil, "", nil)); err != nil {
+				logger.Error("failed to request confirmation", "error", err)
 			}
+			return
 		}
 
 		// Check if quiet mode
@@ -330,7 +738,7 @@ func (b *Bot) handleMenuCallback(ctx context.Context, query *tgbotapi.CallbackQu
 			b.api.Request(deleteMsg)
 		} else {
 			// Update message to show execution
-			edit := tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf("Running /%s...", value))
+			edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.Running, value))
 			b.api.Send(edit)
 		}
 
@@ -345,14 +753,19 @@ func (b *Bot) handleMenuCallback(ctx context.Context, query *tgbotapi.CallbackQu
 
 // sendMenu sends the interactive menu to a chat.
 func (b *Bot) sendMenu(chatID int64) {
-	text, keyboard := b.menuBuilder.BuildMainMenu()
+	text, keyboard := b.menuBuilder.BuildMainMenu(chatID)
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	msg.DisableNotification = b.silent(chatID)
+	if sent, err := b.api.Send(msg); err == nil {
+		b.trackMessage(chatID, sent.MessageID, msgstore.TypePrompt)
+		b.menus.set(chatID, sent.MessageID, backToMenu)
+	}
 }
 
 // handleCommand processes a single command message.
 func (b *Bot) handleCommand(ctx context.Context, msg *tgbotapi.Message) {
+	ctx = withUser(ctx, msg.From)
 	chatID := msg.Chat.ID
 	cmdName := msg.Command()
 
@@ -361,15 +774,40 @@ func (b *Bot) handleCommand(ctx context.Context, msg *tgbotapi.Message) {
 	// Check authorization
 	if !b.authorizer.IsAllowed(chatID) {
 		logger.Warn("unauthorized access attempt")
-		b.sendText(chatID, fmt.Sprintf("Unauthorized. Your chat ID (%d) is not in the allowlist.", chatID))
+		b.logUnauthorized(chatID, msg.From, cmdName)
+		b.rejectChat(chatID)
 		return
 	}
+	if b.rejectUser(chatID, msg.From, cmdName, true) {
+		return
+	}
+	b.trackUserCommand(msg)
 
-	// Look up command
-	cmd := b.registry.Get(cmdName)
+	// Look up command; the first argument may name a subcommand
+	cmd, argText := b.registry.Resolve(cmdName, msg.CommandArguments())
 	if cmd == nil {
 		logger.Debug("unknown command")
-		b.sendText(chatID, fmt.Sprintf("Unknown command: /%s\nUse /help to see available commands.", cmdName))
+		b.sendText(chatID, b.t(chatID, i18n.UnknownCommand, cmdName))
+		return
+	}
+	_, rawText := b.registry.Resolve(cmdName, extractRawText(msg.Text, cmdName))
+
+	if b.rejectDisabled(chatID, cmd) {
+		logger.Info("rejected disabled command")
+		return
+	}
+
+	if b.rejectRestricted(chatID, msg.From, cmd) {
+		return
+	}
+
+	// A namespace without a subcommand given offers its subcommands
+	if len(pkgcmd.Subcommands(cmd)) > 0 {
+		b.showSubcommands(chatID, cmd)
+		return
+	}
+
+	if b.rejectThrottled(ctx, chatID, cmd) {
 		return
 	}
 
@@ -383,14 +821,36 @@ func (b *Bot) handleCommand(ctx context.Context, msg *tgbotapi.Message) {
 
 	// Check if command is a YAMLCommand with arguments that need collection
 	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok && yamlCmd.HasArguments() {
-		logger.Info("starting argument collection")
-		session := b.argCollector.StartSession(chatID, yamlCmd)
-		if session != nil && !session.IsComplete() {
-			b.promptNextArgument(chatID, session)
+		// Inline name=value pairs bypass prompting for those arguments
+		prefilled, err := ParseInlineArguments(yamlCmd.Arguments(), argText)
+		if err != nil {
+			b.sendText(chatID, b.t(chatID, i18n.InvalidArgs, err, cmd.Name()))
 			return
 		}
-		// All arguments have defaults, proceed with execution
-		b.executeWithArguments(ctx, chatID)
+
+		// Don't leave inline sensitive values visible in the chat
+		if len(SensitiveValues(yamlCmd, prefilled)) > 0 {
+			deleteMsg := tgbotapi.NewDeleteMessage(chatID, msg.MessageID)
+			b.api.Request(deleteMsg)
+		}
+
+		b.collectArguments(ctx, chatID, yamlCmd, prefilled)
+		return
+	}
+
+	// Built-ins with arguments take name=value options, then free text for
+	// their first argument (e.g. "/podcast voice=alice Some text")
+	if argCmd, ok := cmd.(ArgumentCommand); ok && argCmd.HasArguments() {
+		if bypass, ok := cmd.(ArgumentBypass); ok && bypass.SkipsArguments(rawText) {
+			b.dispatchCommand(ctx, chatID, cmd, []string{rawText})
+			return
+		}
+		prefilled, err := ParseLeadingArguments(argCmd.Arguments(), rawText)
+		if err != nil {
+			b.sendText(chatID, b.t(chatID, i18n.InvalidArgs, err, cmd.Name()))
+			return
+		}
+		b.collectArguments(ctx, chatID, argCmd, prefilled)
 		return
 	}
 
@@ -398,25 +858,49 @@ func (b *Bot) handleCommand(ctx context.Context, msg *tgbotapi.Message) {
 	// For commands that implement WithFileResponse, preserve raw text (including newlines)
 	var args []string
 	if _, ok := cmd.(pkgcmd.WithFileResponse); ok {
-		// Extract raw text after command, preserving newlines
-		rawText := extractRawText(msg.Text, cmdName)
+		// Raw text after command, preserving newlines
 		if rawText != "" {
 			args = []string{rawText}
 		}
 	} else {
-		args = parseArgs(msg.CommandArguments())
+		args = parseArgs(argText)
+	}
+
+	b.dispatchCommand(ctx, chatID, cmd, args)
+}
+
+// collectArguments starts argument collection for cmd, prompting for every
+// argument not in prefilled, and runs it once all values are known.
+func (b *Bot) collectArguments(ctx context.Context, chatID int64, cmd ArgumentCommand, prefilled map[string]string) {
+	slog.Info("starting argument collection", "chat_id", chatID, "command", cmd.Name(), "prefilled", len(prefilled))
+	session := b.argCollector.StartSession(chatID, cmd, prefilled)
+	if session != nil && !session.IsComplete() {
+		b.promptNextArgument(ctx, chatID, session)
+		return
+	}
+	// All arguments have defaults, proceed with execution
+	b.executeWithArguments(ctx, chatID)
+}
+
+// dispatchCommand runs an authorized command with parsed args, asking for
+// approvals or confirmation first if the command requires them.
+func (b *Bot) dispatchCommand(ctx context.Context, chatID int64, cmd pkgcmd.Command, args []string) {
+	cmdName := cmd.Name()
+	logger := slog.With("chat_id", chatID, "command", cmdName)
+
+	// Check if command requires approvals from several admins
+	if pkgcmd.RequiredApprovals(cmd) > 1 {
+		b.requestApproval(ctx, ApprovalRequest{ChatID: chatID, Command: cmdName, Args: args})
+		return
 	}
 
 	// Check if command requires confirmation
-	if withMeta, ok := cmd.(pkgcmd.WithMetadata); ok {
-		meta := withMeta.Metadata()
-		if meta.RequireConfirm {
-			logger.Info("requesting confirmation", "args", args)
-			if err := b.confirmMgr.RequestConfirmation(b.api, chatID, cmdName, args); err != nil {
-				logger.Error("failed to request confirmation", "error", err)
-			}
-			return
+	if b.requiresConfirm(chatID, cmd) {
+		logger.Info("requesting confirmation", "args", args)
+		if err := b.requestConfirmation(ctx, b.confirmRequest(chatID, cmd, args, "", nil)); err != nil {
+			logger.Error("failed to request confirmation", "error", err)
 		}
+		return
 	}
 
 	logger.Info("executing command", "args_count", len(args))
@@ -426,6 +910,16 @@ func (b *Bot) handleCommand(ctx context.Context, msg *tgbotapi.Message) {
 	b.sendMenu(chatID)
 }
 
+// requiresConfirm reports whether cmd must be confirmed before running in
+// the chat, either by its own confirm: true or the chat's /settings.
+func (b *Bot) requiresConfirm(chatID int64, cmd pkgcmd.Command) bool {
+	if b.confirmAll(chatID) {
+		return true
+	}
+	withMeta, ok := cmd.(pkgcmd.WithMetadata)
+	return ok && withMeta.Metadata().RequireConfirm
+}
+
 // executeCommand runs a command and streams output.
 func (b *Bot) executeCommand(ctx context.Context, chatID int64, cmd pkgcmd.Command, args []string) {
 	b.executeCommandWithOptions(ctx, chatID, cmd, args, false)
@@ -436,8 +930,24 @@ func (b *Bot) executeCommand(ctx context.Context, chatID int64, cmd pkgcmd.Comma
 func (b *Bot) executeCommandWithOptions(ctx context.Context, chatID int64, cmd pkgcmd.Command, args []string, quiet bool) {
 	logger := slog.With("chat_id", chatID, "command", cmd.Name())
 
+	if b.awaitOTP(ctx, chatID, cmd, func(ctx context.Context) {
+		b.executeCommandWithOptions(ctx, chatID, cmd, args, quiet)
+	}) {
+		return
+	}
+	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok && b.skipForMaintenance(ctx, chatID, cmd, yamlCmd.CommandLine(args), quiet) {
+		return
+	}
+	if !b.beginRun(chatID, quiet) {
+		return
+	}
+	defer b.runs.done()
+	if !b.claimCooldown(ctx, chatID, cmd, quiet) {
+		return
+	}
+
 	// Get timeout from metadata or use default
-	timeout := b.defaults.Timeout
+	timeout := b.currentDefaults().Timeout
 	if withMeta, ok := cmd.(pkgcmd.WithMetadata); ok {
 		meta := withMeta.Metadata()
 		if meta.Timeout > 0 {
@@ -446,12 +956,10 @@ func (b *Bot) executeCommandWithOptions(ctx context.Context, chatID int64, cmd p
 	}
 
 	// Execute command with streaming output
-	var streamer *MessageStreamer
-	if quiet {
-		streamer = NewQuietMessageStreamer(b.api, chatID)
-	} else {
-		streamer = NewMessageStreamer(b.api, chatID)
-	}
+	streamer := b.newStreamer(chatID, quiet)
+	streamer.SetRedactRules(b.redactRules(cmd))
+	streamer.SetTruncate(pkgcmd.Truncation(cmd))
+	streamer.SetTerminal(runsInTerminal(cmd))
 	if err := streamer.Start(ctx); err != nil {
 		logger.Error("failed to start streamer", "error", err)
 		return
@@ -462,10 +970,21 @@ func (b *Bot) executeCommandWithOptions(ctx context.Context, chatID int64, cmd p
 		b.trackMessage(chatID, streamer.MessageID(), msgstore.TypeText)
 	}
 
-	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	execCtx, runFile := pkgcmd.WithFileResponseSlot(pkgcmd.WithChatID(ctx, chatID))
+	execCtx, cancel := context.WithTimeout(execCtx, timeout)
 	defer cancel()
 
-	execErr := cmd.Execute(execCtx, args, streamer)
+	start := time.Now()
+	execCtx, gen, execErr := startGenerator(execCtx, cmd, streamer)
+	defer gen.cleanup()
+	if execErr == nil {
+		execErr = cmd.Execute(execCtx, args, streamer)
+	}
+	var generated fileref.ParseResult
+	if execErr == nil && gen != nil {
+		generated, execErr = gen.collect(nil)
+	}
+	b.logAudit(ctx, chatID, cmd.Name(), strings.Join(args, " "), execErr, time.Since(start))
 	if execErr != nil {
 		logger.Error("command execution failed", "error", execErr)
 		fmt.Fprintf(streamer, "\n\nError: %v", execErr)
@@ -485,7 +1004,7 @@ func (b *Bot) executeCommandWithOptions(ctx context.Context, chatID int64, cmd p
 	if execErr == nil {
 		output := streamer.Content()
 		if fileref.HasFiles(output) {
-			result := fileref.ParseOutput(output, workdir)
+			result := fileref.ParseOutputWithURLs(output, workdir, b.downloader)
 
 			// In quiet mode with file-only output, delete the streamer message if it exists
 			if quiet && strings.TrimSpace(result.Text) == "" && len(result.Files) > 0 && streamer.MessageID() != 0 {
@@ -498,38 +1017,95 @@ func (b *Bot) executeCommandWithOptions(ctx context.Context, chatID int64, cmd p
 		}
 	}
 
-	// Handle file response if command supports it
-	if execErr == nil {
-		if withFile, ok := cmd.(pkgcmd.WithFileResponse); ok {
-			if resp := withFile.FileResponse(); resp != nil && resp.Path != "" {
-				b.sendAudioFile(chatID, resp)
-			}
-		}
+	// Send a generator's files
+	if execErr == nil && gen != nil {
+		b.handleFileReferencesWithResult(chatID, generated)
+	}
+
+	// Handle file response: this run's, or the command's if it supports it
+	resp := runFile()
+	if withFile, ok := cmd.(pkgcmd.WithFileResponse); ok && resp == nil {
+		resp = withFile.FileResponse()
+	}
+	if execErr == nil && resp != nil && resp.Path != "" {
+		b.sendFileResponse(chatID, resp)
 	}
 }
 
-// sendAudioFile sends an audio file to the chat.
-func (b *Bot) sendAudioFile(chatID int64, resp *pkgcmd.FileResponse) {
+// sendFileResponse sends a command's file response to the chat: images as a
+// photo, voice responses as a voice message, audio as audio and anything
+// else as a document.
+func (b *Bot) sendFileResponse(chatID int64, resp *pkgcmd.FileResponse) {
 	logger := slog.With("chat_id", chatID, "file", resp.Path)
 
-	audio := tgbotapi.NewAudio(chatID, tgbotapi.FilePath(resp.Path))
-	if resp.Caption != "" {
+	// Cleanup if requested
+	defer func() {
+		if !resp.Cleanup {
+			return
+		}
+		if err := os.Remove(resp.Path); err != nil {
+			logger.Warn("failed to cleanup file", "error", err)
+		}
+	}()
+
+	if resp.Voice {
+		voice, err := fileref.PrepareVoice(resp.Path)
+		if err == nil {
+			defer fileref.RemoveTemp([]fileref.FileRef{voice})
+			if b.sendVoice(chatID, voice, resp.Caption) == nil {
+				logger.Info("file response sent as voice message")
+			}
+			return
+		}
+		logger.Warn("cannot send file response as voice, sending as audio", "error", err)
+	}
+
+	var msg tgbotapi.Chattable
+	failed := i18n.SendAudioFailed
+	switch fileref.DetectType(resp.Path) {
+	case fileref.FileTypePhoto:
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FilePath(resp.Path))
+		photo.DisableNotification = b.silent(chatID)
+		photo.Caption = resp.Caption
+		msg, failed = photo, i18n.SendPhotoFailed
+	case fileref.FileTypeDocument:
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FilePath(resp.Path))
+		doc.DisableNotification = b.silent(chatID)
+		doc.Caption = resp.Caption
+		msg, failed = doc, i18n.SendFileFailed
+	default:
+		audio := tgbotapi.NewAudio(chatID, tgbotapi.FilePath(resp.Path))
+		audio.DisableNotification = b.silent(chatID)
 		audio.Caption = resp.Caption
+		msg = audio
 	}
 
-	if _, err := b.api.Send(audio); err != nil {
-		logger.Error("failed to send audio file", "error", err)
-		b.sendText(chatID, fmt.Sprintf("Failed to send audio: %v", err))
+	if sent, err := b.api.Send(msg); err != nil {
+		logger.Error("failed to send file response", "error", err)
+		b.sendText(chatID, b.t(chatID, failed, err))
 	} else {
-		logger.Info("audio file sent successfully")
+		logger.Info("file response sent successfully")
+		b.trackMessage(chatID, sent.MessageID, msgstore.TypeFile)
 	}
+}
 
-	// Cleanup if requested
-	if resp.Cleanup {
-		if err := os.Remove(resp.Path); err != nil {
-			logger.Warn("failed to cleanup file", "error", err)
-		}
+// sendVoice sends an audio file as a voice message.
+func (b *Bot) sendVoice(chatID int64, file fileref.FileRef, caption string) error {
+	logger := slog.With("chat_id", chatID, "file", file.Path)
+
+	voice := tgbotapi.NewVoice(chatID, tgbotapi.FilePath(file.Path))
+	voice.DisableNotification = b.silent(chatID)
+	voice.Caption = caption
+	voice.Duration = file.Duration
+
+	sent, err := b.api.Send(voice)
+	if err != nil {
+		logger.Error("failed to send voice message", "error", err)
+		b.sendText(chatID, b.t(chatID, i18n.SendVoiceFailed, err))
+		return err
 	}
+	b.trackMessage(chatID, sent.MessageID, msgstore.TypeFile)
+	return nil
 }
 
 // sendMediaGroup sends files as a Telegram media group.
@@ -556,6 +1132,11 @@ func (b *Bot) sendMediaGroup(chatID int64, files []fileref.FileRef, caption stri
 		case fileref.FileTypeVideo:
 			m := tgbotapi.NewInputMediaVideo(tgbotapi.FilePath(f.Path))
 			m.Caption = itemCaption
+			m.SupportsStreaming = true
+			m.Width, m.Height, m.Duration = f.Width, f.Height, f.Duration
+			if f.Thumb != "" {
+				m.Thumb = tgbotapi.FilePath(f.Thumb)
+			}
 			media[i] = m
 		case fileref.FileTypeAudio:
 			m := tgbotapi.NewInputMediaAudio(tgbotapi.FilePath(f.Path))
@@ -569,6 +1150,7 @@ func (b *Bot) sendMediaGroup(chatID int64, files []fileref.FileRef, caption stri
 	}
 
 	mediaGroup := tgbotapi.NewMediaGroup(chatID, media)
+	mediaGroup.DisableNotification = b.silent(chatID)
 	msgs, err := b.api.SendMediaGroup(mediaGroup)
 	if err != nil {
 		logger.Error("failed to send media group", "error", err)
@@ -604,7 +1186,7 @@ func (b *Bot) handleFileReferences(chatID int64, output string, workdir string)
 		return
 	}
 
-	result := fileref.ParseOutput(output, workdir)
+	result := fileref.ParseOutputWithURLs(output, workdir, b.downloader)
 	b.handleFileReferencesWithResult(chatID, result)
 }
 
@@ -612,7 +1194,7 @@ func (b *Bot) handleFileReferences(chatID int64, output string, workdir string)
 func (b *Bot) handleFileReferencesWithResult(chatID int64, result fileref.ParseResult) {
 	logger := slog.With("chat_id", chatID)
 
-	// If there are errors (missing files), send them as a message
+	// If there are errors (missing or failed files), send them as a message
 	if len(result.Errors) > 0 {
 		errorText := strings.Join(result.Errors, "\n")
 		b.sendText(chatID, errorText)
@@ -623,17 +1205,50 @@ func (b *Bot) handleFileReferencesWithResult(chatID int64, result fileref.ParseR
 		return
 	}
 
+	// Remove compressed copies and downloads once sent
+	defer fileref.RemoveTemp(result.Files)
+
+	// Voice messages can't be part of a media group; send them separately
+	var files, voices []fileref.FileRef
+	for _, f := range result.Files {
+		if f.Type == fileref.FileTypeVoice {
+			voices = append(voices, f)
+		} else {
+			files = append(files, f)
+		}
+	}
+
+	// Caption goes to the first media group, or the first voice message
+	caption := result.Text
+	for _, v := range voices {
+		voiceCaption := ""
+		if len(files) == 0 {
+			voiceCaption, caption = caption, ""
+		}
+		if err := b.sendVoice(chatID, v, voiceCaption); err != nil {
+			continue
+		}
+		if err := fileref.RemoveCleanup([]fileref.FileRef{v}); err != nil {
+			logger.Warn("failed to cleanup file", "error", err)
+		}
+	}
+
 	// Group files and send each group
-	groups := fileref.GroupFiles(result.Files, b.defaults.MaxFilesPerGroup)
+	groups := fileref.GroupFiles(files, b.currentDefaults().MaxFilesPerGroup)
 	for i, group := range groups {
-		caption := ""
-		if i == 0 && result.Text != "" {
-			// First group gets the caption (cleaned text)
-			caption = result.Text
+		if i > 0 {
+			// Only the first group gets the caption (cleaned text)
+			caption = ""
 		}
 
 		if err := b.sendMediaGroup(chatID, group, caption); err != nil {
 			logger.Error("failed to send media group", "group", i, "error", err)
+			continue
+		}
+
+		// Delete files marked |cleanup now that they are uploaded
+		if err := fileref.RemoveCleanup(group); err != nil {
+			logger.Warn("failed to cleanup file", "error", err)
 		}
 	}
 }
@@ -641,6 +1256,7 @@ func (b *Bot) handleFileReferencesWithResult(chatID int64, result fileref.ParseR
 // sendText sends a simple text message and tracks it for cleanup.
 func (b *Bot) sendText(chatID int64, text string) {
 	msg := tgbotapi.NewMessage(chatID, text)
+	msg.DisableNotification = b.silent(chatID)
 	sent, err := b.api.Send(msg)
 	if err != nil {
 		slog.Error("failed to send message", "error", err, "chat_id", chatID)
@@ -651,12 +1267,282 @@ func (b *Bot) sendText(chatID int64, text string) {
 	b.trackMessage(chatID, sent.MessageID, msgstore.TypeText)
 }
 
-// parseArgs splits command arguments into a slice.
-func parseArgs(argString string) []string {
-	if argString == "" {
-		return nil
+// rejectDisabled notifies the chat and returns true if the command is disabled.
+func (b *Bot) rejectDisabled(chatID int64, cmd pkgcmd.Command) bool {
+	if !pkgcmd.IsDisabled(cmd) {
+		return false
 	}
-	return strings.Fields(argString)
+	b.sendText(chatID, b.t(chatID, i18n.CommandDisabled, cmd.Name()))
+	return true
+}
+
+// rejectUser returns true if user restrictions exclude the sender.
+// With notify, the chat is told why; output still goes to the whole chat.
+func (b *Bot) rejectUser(chatID int64, user *tgbotapi.User, attempt string, notify bool) bool {
+	var userID int64
+	var username string
+	if user != nil {
+		userID = user.ID
+		username = user.UserName
+	}
+
+	if b.authorizer.IsAllowedUser(userID, username) {
+		return false
+	}
+
+	slog.Warn("unauthorized user", "chat_id", chatID, "user_id", userID, "username", username)
+	if attempt != "" {
+		b.logUnauthorized(chatID, user, attempt)
+	}
+	if notify {
+		b.sendText(chatID, b.t(chatID, i18n.UserUnauthorized, userID))
+	}
+	return true
+}
+
+// isAdmin returns true if the user has the admin role in the chat.
+// Without role configuration everyone is an admin.
+func (b *Bot) isAdmin(chatID int64, user *tgbotapi.User) bool {
+	if b.roles == nil {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	return b.roles.RoleOf(chatID, user.ID, user.UserName) >= auth.RoleAdmin
+}
+
+// requestApproval asks admins to approve a command in the approvals chat.
+// The requester is taken from ctx.
+func (b *Bot) requestApproval(ctx context.Context, req ApprovalRequest) {
+	cmd := b.registry.Get(req.Command)
+	if cmd == nil {
+		return
+	}
+	req.Required = pkgcmd.RequiredApprovals(cmd)
+	req.ApprovalsChatID = b.approvalsChatID
+	req.Requester = userFromContext(ctx)
+	req.NoSelfApproval = pkgcmd.ExcludesRequester(cmd)
+	req.TTL = b.confirmTTL(cmd)
+	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok {
+		lang := b.lang(cmp.Or(req.ApprovalsChatID, req.ChatID))
+		req.Preview = BuildRenderedPreview(lang, yamlCmd, commandLine(yamlCmd, req.Args, req.RenderedCommand), req.CollectedArgs, false)
+	}
+
+	slog.Info("requesting approvals", "chat_id", req.ChatID, "command", req.Command, "required", req.Required)
+	if err := b.confirmMgr.RequestApproval(b.api, req); err != nil {
+		slog.Error("failed to request approval", "chat_id", req.ChatID, "error", err)
+		b.sendText(req.ChatID, b.t(req.ChatID, i18n.ApprovalRequestFailed, err))
+		return
+	}
+	b.auditConfirmation(&PendingConfirmation{
+		ChatID:          req.ChatID,
+		Command:         req.Command,
+		Args:            req.Args,
+		RenderedCommand: req.RenderedCommand,
+		CollectedArgs:   req.CollectedArgs,
+	}, audit.StatusConfirmRequested, req.Requester)
+
+	if b.approvalsChatID != 0 && b.approvalsChatID != req.ChatID {
+		b.sendText(req.ChatID, b.t(req.ChatID, i18n.ApprovalSent, req.Command, req.Required))
+	}
+}
+
+// awaitOTP returns true if cmd needs a one-time code that ctx has not
+// verified yet. The user is then prompted and run is called with a verified
+// context once the correct code arrives.
+func (b *Bot) awaitOTP(ctx context.Context, chatID int64, cmd pkgcmd.Command, run func(ctx context.Context)) bool {
+	if !pkgcmd.RequiresOTP(cmd) || otpVerified(ctx) {
+		return false
+	}
+
+	user := userFromContext(ctx)
+	if b.otp == nil || user == nil || !b.otp.HasSecret(user.ID, user.UserName) {
+		b.sendText(chatID, b.t(chatID, i18n.OTPNotConfigured, cmd.Name()))
+		return true
+	}
+
+	b.otpMgr.Start(chatID, user.ID, cmd.Name(), func() {
+		run(withOTPVerified(ctx))
+		b.sendMenu(chatID)
+	})
+	b.sendText(chatID, b.t(chatID, i18n.OTPPrompt, cmd.Name()))
+	return true
+}
+
+// handleOTPInput verifies a one-time code and runs the waiting command.
+func (b *Bot) handleOTPInput(msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+
+	// Don't leave codes in the chat history
+	b.api.Request(tgbotapi.NewDeleteMessage(chatID, msg.MessageID))
+
+	pending := b.otpMgr.Take(chatID)
+	if pending == nil {
+		b.sendText(chatID, b.t(chatID, i18n.OTPExpired))
+		return
+	}
+
+	err := b.otp.Verify(msg.From.ID, msg.From.UserName, msg.Text, time.Now())
+	switch {
+	case err == nil:
+		slog.Info("otp verified", "chat_id", chatID, "user_id", msg.From.ID, "command", pending.Command)
+		pending.resume()
+	case errors.Is(err, auth.ErrOTPLockedOut):
+		slog.Warn("otp locked out", "chat_id", chatID, "user_id", msg.From.ID, "command", pending.Command)
+		b.sendText(chatID, b.t(chatID, i18n.OTPLockedOut, pending.Command))
+		b.NotifyAdmin(fmt.Sprintf("⚠️ %s was locked out of one-time codes after repeated wrong codes.", userDisplayName(msg.From)))
+	default:
+		slog.Warn("invalid otp", "chat_id", chatID, "user_id", msg.From.ID, "command", pending.Command)
+		if b.otpMgr.Retry(chatID, pending) {
+			b.sendText(chatID, b.t(chatID, i18n.OTPInvalid))
+			return
+		}
+		b.sendText(chatID, b.t(chatID, i18n.OTPTooMany, pending.Command))
+	}
+}
+
+// handlePhraseInput checks a typed confirmation phrase and runs the waiting
+// command if it matches. Anything else cancels the command.
+func (b *Bot) handlePhraseInput(ctx context.Context, msg *tgbotapi.Message) {
+	ctx = withUser(ctx, msg.From)
+	chatID := msg.Chat.ID
+
+	// Ignore input from group members who may not run commands
+	if b.rejectUser(chatID, msg.From, "", false) {
+		return
+	}
+
+	pending, ok := b.confirmMgr.TakePhrase(chatID, msg.Text)
+	if pending == nil {
+		return
+	}
+	if !ok {
+		slog.Info("confirmation phrase mismatch", "chat_id", chatID, "command", pending.Command)
+		b.auditConfirmation(pending, audit.StatusCancelled, msg.From)
+		b.api.Send(tgbotapi.NewEditMessageText(chatID, pending.MessageID, b.t(chatID, i18n.ConfirmPhraseMismatch, pending.Command)))
+		return
+	}
+
+	b.auditConfirmation(pending, audit.StatusApproved, msg.From)
+	b.api.Send(tgbotapi.NewEditMessageText(chatID, pending.MessageID, b.t(chatID, i18n.Executing, pending.Command)))
+	b.runConfirmed(ctx, pending)
+}
+
+// rejectThrottled notifies the chat, records an audit entry and returns true
+// if the command is cooling down or the user, chat or command rate limit is
+// exhausted.
+func (b *Bot) rejectThrottled(ctx context.Context, chatID int64, cmd pkgcmd.Command) bool {
+	if b.rejectCoolingDown(ctx, chatID, cmd) {
+		return true
+	}
+
+	b.settingsMu.RLock()
+	limits := b.rateLimits
+	b.settingsMu.RUnlock()
+
+	var userID int64
+	if user := userFromContext(ctx); user != nil {
+		userID = user.ID
+	}
+
+	checks := []struct {
+		key  string
+		rule ratelimit.Rule
+	}{
+		{fmt.Sprintf("user:%d", userID), limits.PerUser},
+		{fmt.Sprintf("chat:%d", chatID), limits.PerChat},
+	}
+	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok {
+		checks = append(checks, struct {
+			key  string
+			rule ratelimit.Rule
+		}{fmt.Sprintf("cmd:%s:user:%d", cmd.Name(), userID), yamlCmd.RateLimit()})
+	}
+
+	for _, c := range checks {
+		ok, wait := b.limiter.Allow(c.key, c.rule)
+		if ok {
+			continue
+		}
+
+		slog.Warn("command throttled", "chat_id", chatID, "user_id", userID, "command", cmd.Name(), "key", c.key)
+		b.sendText(chatID, b.t(chatID, i18n.TooManyRequests, cmd.Name(), wait.Round(time.Second)))
+
+		entry := newAuditEntry(ctx, chatID, cmd.Name())
+		entry.ExitCode = -1
+		entry.Status = audit.StatusThrottled
+		b.writeAudit(ctx, entry)
+		return true
+	}
+	return false
+}
+
+// SetRateLimits replaces the global rate limits.
+func (b *Bot) SetRateLimits(limits config.RateLimitConfig) {
+	b.settingsMu.Lock()
+	defer b.settingsMu.Unlock()
+	b.rateLimits = limits
+}
+
+// logUnauthorized records a command or callback attempt from a chat or user
+// outside the allowlist. attempt is the command name or callbackAttempt.
+func (b *Bot) logUnauthorized(chatID int64, user *tgbotapi.User, attempt string) {
+	entry := audit.Entry{
+		Timestamp: time.Now(),
+		ChatID:    chatID,
+		Command:   attempt,
+		ExitCode:  -1,
+		Status:    audit.StatusUnauthorized,
+	}
+	if user != nil {
+		entry.Username = auditUsername(user)
+	}
+	b.writeAudit(context.Background(), entry)
+}
+
+// callbackAttempt names a button press for the audit log.
+func callbackAttempt(data string) string {
+	return "callback:" + data
+}
+
+// commandChecker is implemented by authorizers that enforce per-command
+// policies (see auth.PolicyAuthorizer).
+type commandChecker interface {
+	CheckCommand(req auth.Request) error
+}
+
+// rejectRestricted notifies the chat and returns true if the authorizer's
+// command policies (roles, per-command allowlists, time windows) deny the
+// command for this chat and user.
+func (b *Bot) rejectRestricted(chatID int64, user *tgbotapi.User, cmd pkgcmd.Command) bool {
+	checker, ok := b.authorizer.(commandChecker)
+	if !ok {
+		return false
+	}
+
+	req := auth.Request{ChatID: chatID, CommandName: cmd.Name()}
+	if user != nil {
+		req.UserID = user.ID
+		req.Username = user.UserName
+	}
+
+	err := checker.CheckCommand(req)
+	if err == nil {
+		return false
+	}
+
+	slog.Warn("command denied by policy", "chat_id", chatID, "user_id", req.UserID, "command", cmd.Name(), "reason", err)
+	b.sendText(chatID, err.Error())
+	return true
+}
+
+// parseArgs splits command arguments into a slice.
+func parseArgs(argString string) []string {
+	if argString == "" {
+		return nil
+	}
+	return strings.Fields(argString)
 }
 
 // extractRawText extracts text after the command, preserving newlines.
@@ -693,23 +1579,36 @@ func extractRawText(fullText, cmdName string) string {
 func (b *Bot) handleCancelCommand(msg *tgbotapi.Message) {
 	chatID := msg.Chat.ID
 
-	if !b.authorizer.IsAllowed(chatID) {
+	if !b.authorizer.IsAllowed(chatID) || b.rejectUser(chatID, msg.From, "", false) {
 		return
 	}
 
-	if b.argCollector.HasSession(chatID) {
+	if b.otpMgr.Cancel(chatID) || b.pinMgr.Cancel(chatID) {
+		b.sendText(chatID, b.t(chatID, i18n.CommandCancelled))
+	} else if cancelled := b.confirmMgr.CancelPhrase(chatID); len(cancelled) > 0 {
+		for _, p := range cancelled {
+			b.auditConfirmation(p, audit.StatusCancelled, msg.From)
+			b.api.Send(tgbotapi.NewEditMessageText(chatID, p.MessageID, b.t(chatID, i18n.CommandCancelled)))
+		}
+	} else if b.argCollector.HasSession(chatID) {
 		b.argCollector.CancelSession(chatID)
-		b.sendText(chatID, "Command cancelled.")
+		b.sendText(chatID, b.t(chatID, i18n.CommandCancelled))
 	} else {
-		b.sendText(chatID, "No active command to cancel.")
+		b.sendText(chatID, b.t(chatID, i18n.NothingToCancel))
 	}
 }
 
 // handleArgumentInput processes text input for argument collection.
 func (b *Bot) handleArgumentInput(ctx context.Context, msg *tgbotapi.Message) {
+	ctx = withUser(ctx, msg.From)
 	chatID := msg.Chat.ID
 	logger := slog.With("chat_id", chatID)
 
+	// Ignore input from group members who may not run commands
+	if b.rejectUser(chatID, msg.From, "", false) {
+		return
+	}
+
 	session := b.argCollector.GetSession(chatID)
 	if session == nil {
 		return
@@ -718,27 +1617,27 @@ func (b *Bot) handleArgumentInput(ctx context.Context, msg *tgbotapi.Message) {
 	// Check if session expired
 	if session.IsExpired() {
 		b.argCollector.CancelSession(chatID)
-		b.sendText(chatID, "Argument collection timed out. Please try again.")
+		b.sendText(chatID, b.t(chatID, i18n.ArgsTimedOut))
 		return
 	}
 
 	// Get current argument for sensitive check before processing
 	currentArg := session.CurrentArg()
 
+	// Delete sensitive message right away, even if the value turns out invalid
+	if currentArg != nil && currentArg.Sensitive {
+		deleteMsg := tgbotapi.NewDeleteMessage(chatID, msg.MessageID)
+		b.api.Request(deleteMsg)
+	}
+
 	// Process the input
 	errMsg := b.argCollector.ProcessInput(chatID, msg.Text)
 	if errMsg != "" {
 		// Validation failed, re-prompt
-		b.sendText(chatID, fmt.Sprintf("Invalid input: %s\n\n%s", errMsg, currentArg.Description))
+		b.sendText(chatID, b.t(chatID, i18n.InvalidInput, errMsg, BuildArgumentPrompt(b.lang(chatID), currentArg)))
 		return
 	}
 
-	// Delete sensitive message after capturing
-	if currentArg != nil && currentArg.Sensitive {
-		deleteMsg := tgbotapi.NewDeleteMessage(chatID, msg.MessageID)
-		b.api.Request(deleteMsg)
-	}
-
 	// Check if all arguments collected
 	session = b.argCollector.GetSession(chatID)
 	if session == nil || session.IsComplete() {
@@ -747,7 +1646,7 @@ func (b *Bot) handleArgumentInput(ctx context.Context, msg *tgbotapi.Message) {
 	}
 
 	// Prompt for next argument
-	b.promptNextArgument(chatID, session)
+	b.promptNextArgument(ctx, chatID, session)
 	logger.Debug("prompted for next argument")
 }
 
@@ -758,7 +1657,7 @@ func (b *Bot) handleArgumentCallback(ctx context.Context, query *tgbotapi.Callba
 
 	session := b.argCollector.GetSession(chatID)
 	if session == nil {
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, "Session expired. Please start over.")
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.SessionExpired))
 		b.api.Send(edit)
 		return
 	}
@@ -770,7 +1669,7 @@ func (b *Bot) handleArgumentCallback(ctx context.Context, query *tgbotapi.Callba
 	errMsg := b.argCollector.ProcessInput(chatID, value)
 	if errMsg != "" {
 		// Shouldn't happen with button selection, but handle it
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf("Invalid selection: %s", errMsg))
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.InvalidSelection, errMsg))
 		b.api.Send(edit)
 		return
 	}
@@ -781,7 +1680,7 @@ func (b *Bot) handleArgumentCallback(ctx context.Context, query *tgbotapi.Callba
 	if currentArg != nil {
 		argName = currentArg.Name
 	}
-	edit := tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf("Selected %s: %s", argName, value))
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.Selected, argName, DisplayValue(currentArg, value)))
 	b.api.Send(edit)
 
 	// Check if all arguments collected
@@ -792,45 +1691,122 @@ func (b *Bot) handleArgumentCallback(ctx context.Context, query *tgbotapi.Callba
 	}
 
 	// Prompt for next argument
-	b.promptNextArgument(chatID, session)
+	b.promptNextArgument(ctx, chatID, session)
+}
+
+// handleArgumentNavCallback processes Back/Skip/Cancel buttons on argument prompts.
+func (b *Bot) handleArgumentNavCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+
+	session := b.argCollector.GetSession(chatID)
+	if session == nil {
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.SessionExpired))
+		b.api.Send(edit)
+		return
+	}
+
+	currentArg := session.CurrentArg()
+
+	switch query.Data {
+	case argNavCancel:
+		b.argCollector.CancelSession(chatID)
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.CommandCancelled))
+		b.api.Send(edit)
+		b.sendMenu(chatID)
+		return
+
+	case argNavBack:
+		if !b.argCollector.GoBack(chatID) {
+			return
+		}
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.PrevArg))
+		b.api.Send(edit)
+
+	case argNavSkip:
+		if errMsg := b.argCollector.Skip(chatID); errMsg != "" {
+			b.sendText(chatID, b.t(chatID, i18n.CannotSkip, errMsg))
+			return
+		}
+		argName := "argument"
+		if currentArg != nil {
+			argName = currentArg.Name
+		}
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.Skipped, argName))
+		b.api.Send(edit)
+
+	default:
+		return
+	}
+
+	session = b.argCollector.GetSession(chatID)
+	if session == nil || session.IsComplete() {
+		b.executeWithArguments(ctx, chatID)
+		return
+	}
+	b.promptNextArgument(ctx, chatID, session)
+}
+
+// handleArgumentPageCallback switches the page of a paginated choice keyboard.
+func (b *Bot) handleArgumentPageCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+
+	session := b.argCollector.GetSession(chatID)
+	if session == nil {
+		edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, b.t(chatID, i18n.SessionExpired))
+		b.api.Send(edit)
+		return
+	}
+
+	keyboard := BuildPromptKeyboard(session, ParseArgumentPageCallback(query.Data))
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, keyboard)
+	b.api.Send(edit)
 }
 
 // promptNextArgument sends the prompt for the current argument.
-func (b *Bot) promptNextArgument(chatID int64, session *ArgumentSession) {
+func (b *Bot) promptNextArgument(ctx context.Context, chatID int64, session *ArgumentSession) {
 	arg := session.CurrentArg()
 	if arg == nil {
 		return
 	}
 
-	// Build prompt text and keyboard
-	var text string
-	var keyboard *tgbotapi.InlineKeyboardMarkup
-
-	if arg.Type == "choice" && len(arg.Choices) > 0 {
-		if len(arg.Choices) <= maxInlineChoices {
-			text = BuildArgumentPrompt(arg)
-			keyboard = BuildChoiceKeyboard(arg)
-		} else {
-			text = BuildChoiceTextList(arg)
+	// Populate dynamic choices right before prompting so they are always fresh
+	if arg.ChoicesCommand != "" {
+		choicesCtx, cancel := context.WithTimeout(ctx, choicesCommandTimeout)
+		choices, err := session.Command.RunChoicesCommand(choicesCtx, arg.ChoicesCommand)
+		cancel()
+		if err == nil && len(choices) == 0 {
+			err = fmt.Errorf("no choices available")
 		}
-	} else {
-		text = BuildArgumentPrompt(arg)
+		if err != nil {
+			slog.Error("failed to load choices", "chat_id", chatID, "argument", arg.Name, "error", err)
+			b.argCollector.CancelSession(chatID)
+			b.sendText(chatID, b.t(chatID, i18n.ChoicesFailed, arg.Name, err))
+			return
+		}
+		b.argCollector.SetCurrentChoices(chatID, choices)
 	}
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	if keyboard != nil {
-		msg.ReplyMarkup = keyboard
-	}
+	// Build prompt text and keyboard (choices start at the page holding the default)
+	msg := tgbotapi.NewMessage(chatID, BuildArgumentPrompt(session.Lang, arg))
+	msg.ReplyMarkup = BuildPromptKeyboard(session, DefaultChoicePage(arg))
 
 	if sent, err := b.api.Send(msg); err == nil {
 		b.argCollector.SetLastPromptMsgID(chatID, sent.MessageID)
+		b.trackMessage(chatID, sent.MessageID, msgstore.TypePrompt)
 	}
 }
 
 // executeWithArguments executes a command with collected arguments.
 func (b *Bot) executeWithArguments(ctx context.Context, chatID int64) {
-	collected, cmd := b.argCollector.CompleteSession(chatID)
-	if cmd == nil {
+	collected, argCmd := b.argCollector.CompleteSession(chatID)
+	if argCmd == nil {
+		return
+	}
+	cmd, ok := argCmd.(*command.YAMLCommand)
+	if !ok {
+		// Built-ins get the values as name=value arguments
+		b.dispatchCommand(ctx, chatID, argCmd, collectedArgs(argCmd, collected))
 		return
 	}
 
@@ -840,52 +1816,96 @@ func (b *Bot) executeWithArguments(ctx context.Context, chatID int64) {
 	rendered, err := RenderCommand(cmd.CommandTemplate(), collected)
 	if err != nil {
 		logger.Error("failed to render command template", "error", err)
-		b.sendText(chatID, fmt.Sprintf("Failed to process command: %v", err))
+		b.sendText(chatID, b.t(chatID, i18n.ProcessFailed, err))
 		return
 	}
 
-	logger.Info("executing command with arguments", "args", collected)
+	logger.Info("executing command with arguments", "args", MaskArgs(cmd, collected))
 
-	// Check if command requires confirmation
-	if cmd.Metadata().RequireConfirm {
+	// Check if command requires approvals from several admins
+	if pkgcmd.RequiredApprovals(cmd) > 1 {
+		b.requestApproval(ctx, ApprovalRequest{
+			ChatID:          chatID,
+			Command:         cmd.Name(),
+			RenderedCommand: rendered,
+			CollectedArgs:   collected,
+		})
+		return
+	}
+
+	// Check if command requires confirmation (confirm_rendered always does)
+	if b.requiresConfirm(chatID, cmd) || cmd.ConfirmRendered() {
 		// Store rendered command for execution after confirmation
-		if err := b.confirmMgr.RequestConfirmationWithRendered(b.api, chatID, cmd.Name(), rendered); err != nil {
+		if err := b.requestConfirmation(ctx, b.confirmRequest(chatID, cmd, nil, rendered, collected)); err != nil {
 			logger.Error("failed to request confirmation", "error", err)
 		}
 		return
 	}
 
 	// Execute the rendered command
-	b.executeRenderedCommand(ctx, chatID, cmd, rendered)
+	b.executeRenderedCommand(ctx, chatID, cmd, rendered, collected)
 	b.sendMenu(chatID)
 }
 
 // executeRenderedCommand runs a command with a pre-rendered command string.
-func (b *Bot) executeRenderedCommand(ctx context.Context, chatID int64, cmd *command.YAMLCommand, rendered string) {
+// Collected arguments are used to mask sensitive values in output and the audit log.
+func (b *Bot) executeRenderedCommand(ctx context.Context, chatID int64, cmd *command.YAMLCommand, rendered string, collected map[string]string) {
 	logger := slog.With("chat_id", chatID, "command", cmd.Name())
 
+	if b.awaitOTP(ctx, chatID, cmd, func(ctx context.Context) {
+		b.executeRenderedCommand(ctx, chatID, cmd, rendered, collected)
+	}) {
+		return
+	}
+	if b.skipForMaintenance(ctx, chatID, cmd, RedactValues(rendered, SensitiveValues(cmd, collected)), false) {
+		return
+	}
+	if !b.beginRun(chatID, false) {
+		return
+	}
+	defer b.runs.done()
+	if !b.claimCooldown(ctx, chatID, cmd, false) {
+		return
+	}
+
 	// Get timeout from metadata or use default
-	timeout := b.defaults.Timeout
+	timeout := b.currentDefaults().Timeout
 	meta := cmd.Metadata()
 	if meta.Timeout > 0 {
 		timeout = meta.Timeout
 	}
 
 	// Execute command with streaming output
-	streamer := NewMessageStreamer(b.api, chatID)
+	streamer := b.newStreamer(chatID, false)
+	streamer.SetRedactions(SensitiveValues(cmd, collected))
+	streamer.SetRedactRules(cmd.Redactions())
+	streamer.SetTruncate(pkgcmd.Truncation(cmd))
+	streamer.SetTerminal(cmd.PTY())
 	if err := streamer.Start(ctx); err != nil {
 		logger.Error("failed to start streamer", "error", err)
 		return
 	}
 
-	// Track the output message for cleanup
-	b.trackMessage(chatID, streamer.MessageID(), msgstore.TypeText)
+	// Track the output message for cleanup (only if message was created)
+	if streamer.MessageID() != 0 {
+		b.trackMessage(chatID, streamer.MessageID(), msgstore.TypeText)
+	}
 
-	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	execCtx, cancel := context.WithTimeout(pkgcmd.WithChatID(ctx, chatID), timeout)
 	defer cancel()
 
 	// Execute with rendered command
-	execErr := cmd.ExecuteRendered(execCtx, rendered, streamer)
+	start := time.Now()
+	execCtx, gen, execErr := startGenerator(execCtx, cmd, streamer)
+	defer gen.cleanup()
+	if execErr == nil {
+		execErr = cmd.ExecuteRendered(execCtx, rendered, collected, streamer)
+	}
+	var generated fileref.ParseResult
+	if execErr == nil && gen != nil {
+		generated, execErr = gen.collect(collected)
+	}
+	b.logAudit(ctx, chatID, cmd.Name(), MaskArgs(cmd, collected), execErr, time.Since(start))
 	if execErr != nil {
 		logger.Error("command execution failed", "error", execErr)
 		fmt.Fprintf(streamer, "\n\nError: %v", execErr)
@@ -900,10 +1920,15 @@ func (b *Bot) executeRenderedCommand(ctx context.Context, chatID int64, cmd *com
 		b.handleFileReferences(chatID, streamer.Content(), cmd.Workdir())
 	}
 
+	// Send a generator's files
+	if execErr == nil && gen != nil {
+		b.handleFileReferencesWithResult(chatID, generated)
+	}
+
 	// Handle file response if command supports it
 	if execErr == nil {
 		if resp := cmd.FileResponse(); resp != nil && resp.Path != "" {
-			b.sendAudioFile(chatID, resp)
+			b.sendFileResponse(chatID, resp)
 		}
 	}
 }
@@ -917,12 +1942,16 @@ func (b *Bot) ExecuteScheduled(ctx context.Context, chatID int64, cmd pkgcmd.Com
 	// Check if quiet mode
 	quiet := false
 	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok {
+		if yamlCmd.NotifyOnChange() {
+			b.executeOnChange(ctx, chatID, yamlCmd)
+			return nil
+		}
 		quiet = yamlCmd.Quiet()
 	}
 
 	// Send notification unless quiet
 	if !quiet {
-		b.sendText(chatID, fmt.Sprintf("Scheduled: Running /%s...", cmd.Name()))
+		b.sendText(chatID, b.t(chatID, i18n.ScheduledRunning, cmd.Name()))
 	}
 
 	// Execute command (confirmation is skipped for scheduled runs)
@@ -934,7 +1963,7 @@ func (b *Bot) ExecuteScheduled(ctx context.Context, chatID int64, cmd pkgcmd.Com
 // showCleanupMenu displays the cleanup options menu.
 func (b *Bot) showCleanupMenu(chatID int64, messageID int) {
 	if b.cleanupCmd == nil || !b.cleanupCmd.Enabled() {
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, "Cleanup is not enabled. Set message_store_path in config.")
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.CleanupDisabledHint))
 		b.api.Send(edit)
 		return
 	}
@@ -942,7 +1971,7 @@ func (b *Bot) showCleanupMenu(chatID int64, messageID int) {
 	// Get tracked message count
 	count := b.cleanupCmd.Count(chatID)
 
-	text := fmt.Sprintf("Cleanup tracked files\n\nTracked messages: %d\n\nSelect what to delete:", count)
+	text := b.t(chatID, i18n.CleanupMenu, count)
 
 	// Build options keyboard
 	options := builtin.CleanupOptions()
@@ -951,13 +1980,13 @@ func (b *Bot) showCleanupMenu(chatID int64, messageID int) {
 	for _, opt := range options {
 		// Get count for this option
 		entries := b.cleanupCmd.GetEntriesToDelete(chatID, opt.Option)
-		label := fmt.Sprintf("%s (%d)", opt.Label, len(entries))
+		label := fmt.Sprintf("%s (%d)", cleanupLabel(b.lang(chatID), opt), len(entries))
 		btn := tgbotapi.NewInlineKeyboardButtonData(label, CleanupCallbackData(string(opt.Option)))
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{btn})
 	}
 
 	// Back button
-	backBtn := tgbotapi.NewInlineKeyboardButtonData("<< Back to Menu", backToMenu)
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.BackToMenu), backToMenu)
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{backBtn})
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
@@ -970,25 +1999,32 @@ func (b *Bot) showCleanupMenu(chatID int64, messageID int) {
 // handleCleanupCallback processes a cleanup option selection.
 func (b *Bot) handleCleanupCallback(chatID int64, messageID int, option string, logger *slog.Logger) {
 	if b.cleanupCmd == nil || !b.cleanupCmd.Enabled() {
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, "Cleanup is not enabled.")
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.CleanupDisabled))
 		b.api.Send(edit)
 		return
 	}
 
+	// First selection shows what would be deleted; deletion needs confirmation
+	confirmed, ok := strings.CutPrefix(option, cleanupConfirm)
+	if !ok {
+		b.showCleanupPreview(chatID, messageID, builtin.CleanupOption(option))
+		return
+	}
+
 	// Execute cleanup
-	cleanupOption := builtin.CleanupOption(option)
+	cleanupOption := builtin.CleanupOption(confirmed)
 	deleted, failed, err := b.cleanupCmd.ExecuteCleanup(chatID, cleanupOption)
 
 	var resultText string
 	if err != nil {
-		resultText = fmt.Sprintf("Cleanup failed: %v", err)
+		resultText = b.t(chatID, i18n.CleanupFailed, err)
 		logger.Error("cleanup failed", "error", err)
 	} else if deleted == 0 && failed == 0 {
-		resultText = "No messages to delete."
+		resultText = b.t(chatID, i18n.CleanupNothing)
 	} else {
-		resultText = fmt.Sprintf("Cleanup complete.\n\nDeleted: %d messages", deleted)
+		resultText = b.t(chatID, i18n.CleanupDone, deleted)
 		if failed > 0 {
-			resultText += fmt.Sprintf("\nFailed: %d (messages may already be deleted or too old)", failed)
+			resultText += "\n" + b.t(chatID, i18n.CleanupSomeFailed, failed)
 		}
 	}
 
@@ -999,15 +2035,104 @@ func (b *Bot) handleCleanupCallback(chatID int64, messageID int, option string,
 	b.sendMenu(chatID)
 }
 
+// cleanupLabels are the message keys of the cleanup options' button labels.
+var cleanupLabels = map[builtin.CleanupOption]i18n.Key{
+	builtin.CleanupAllMsgsLastHour: i18n.CleanupAllMsgsHour,
+	builtin.CleanupAllMsgsLastDay:  i18n.CleanupAllMsgsDay,
+	builtin.CleanupLastHour:        i18n.CleanupFilesHour,
+	builtin.CleanupLastDay:         i18n.CleanupFilesDay,
+	builtin.CleanupBeforeLastDay:   i18n.CleanupFilesOld1d,
+	builtin.CleanupBeforeLastWeek:  i18n.CleanupFilesOld1w,
+	builtin.CleanupBeforeLastMonth: i18n.CleanupFilesOld1mo,
+	builtin.CleanupAll:             i18n.CleanupAllFiles,
+	builtin.CleanupUserCommands:    i18n.CleanupUserCmds,
+}
+
+// cleanupLabel returns a cleanup option's button label in lang.
+func cleanupLabel(lang string, opt builtin.CleanupOptionInfo) string {
+	if key, ok := cleanupLabels[opt.Option]; ok {
+		return i18n.T(lang, key)
+	}
+	return opt.Label
+}
+
+// cleanupSummary describes a cleanup preview in lang, like
+// builtin.CleanupPreview.Summary.
+func cleanupSummary(lang string, p builtin.CleanupPreview) string {
+	if p.Total == 0 {
+		return i18n.T(lang, i18n.CleanupNothing)
+	}
+
+	types := []struct {
+		t   msgstore.MessageType
+		key i18n.Key
+	}{
+		{msgstore.TypeFile, i18n.CleanupFiles},
+		{msgstore.TypeText, i18n.CleanupTexts},
+		{msgstore.TypePrompt, i18n.CleanupPrompts},
+		{msgstore.TypeConfirmation, i18n.CleanupConfirms},
+		{msgstore.TypeCommand, i18n.CleanupCommands},
+	}
+	var parts []string
+	for _, tt := range types {
+		if n := p.ByType[tt.t]; n > 0 {
+			parts = append(parts, i18n.N(lang, tt.key, n, n))
+		}
+	}
+
+	var ages []string
+	if p.LastHour > 0 {
+		ages = append(ages, i18n.T(lang, i18n.CleanupLastHour, p.LastHour))
+	}
+	if p.LastDay > 0 {
+		ages = append(ages, i18n.T(lang, i18n.CleanupLastDay, p.LastDay))
+	}
+	if p.Older > 0 {
+		ages = append(ages, i18n.T(lang, i18n.CleanupOlder, p.Older))
+	}
+	return i18n.T(lang, i18n.CleanupWillDelete, strings.Join(parts, ", ")) + "\n(" + strings.Join(ages, ", ") + ")"
+}
+
+// showCleanupPreview shows counts for a cleanup option with Confirm/Cancel buttons.
+func (b *Bot) showCleanupPreview(chatID int64, messageID int, option builtin.CleanupOption) {
+	preview := b.cleanupCmd.Preview(chatID, option)
+
+	lang := b.lang(chatID)
+	label := string(option)
+	for _, opt := range builtin.CleanupOptions() {
+		if opt.Option == option {
+			label = cleanupLabel(lang, opt)
+		}
+	}
+	text := i18n.T(lang, i18n.CleanupPreview, label, cleanupSummary(lang, preview))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if preview.Total > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.CleanupDelete), CleanupCallbackData(cleanupConfirm+string(option))),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.Cancel), commandPrefix+"cleanup"),
+		))
+	} else {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.Back), commandPrefix+"cleanup"),
+		))
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	edit.ReplyMarkup = &keyboard
+	b.api.Send(edit)
+}
+
 // showScheduleMenu displays options for a scheduled command.
 func (b *Bot) showScheduleMenu(chatID int64, cmd *command.YAMLCommand) {
 	// Build status text
 	var statusParts []string
 	if len(cmd.Schedule()) > 0 {
-		statusParts = append(statusParts, fmt.Sprintf("Schedule: %s", strings.Join(cmd.Schedule(), ", ")))
+		statusParts = append(statusParts, b.t(chatID, i18n.ScheduleTimes, strings.Join(cmd.Schedule(), ", ")))
 	}
 	if cmd.Interval() > 0 {
-		statusParts = append(statusParts, fmt.Sprintf("Interval: %s", cmd.Interval()))
+		statusParts = append(statusParts, b.t(chatID, i18n.ScheduleInterval, cmd.Interval()))
 	}
 
 	// Check pause state
@@ -1017,38 +2142,40 @@ func (b *Bot) showScheduleMenu(chatID int64, cmd *command.YAMLCommand) {
 	}
 
 	if isPaused {
-		statusParts = append(statusParts, "Status: Paused")
+		statusParts = append(statusParts, b.t(chatID, i18n.SchedulePaused))
 	} else {
-		statusParts = append(statusParts, "Status: Running")
+		statusParts = append(statusParts, b.t(chatID, i18n.ScheduleActive))
 	}
 
-	text := fmt.Sprintf("/%s\n\n%s\n\nSelect action:", cmd.Name(), strings.Join(statusParts, "\n"))
+	text := b.t(chatID, i18n.ScheduleAction, cmd.Name(), strings.Join(statusParts, "\n"))
 
 	// Build keyboard
 	var rows [][]tgbotapi.InlineKeyboardButton
 
 	// Run now button
-	runBtn := tgbotapi.NewInlineKeyboardButtonData("▶ Run now", ScheduleCallbackData("run", cmd.Name()))
+	runBtn := tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.RunNow), ScheduleCallbackData("run", cmd.Name()))
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{runBtn})
 
 	// Pause/Resume button
 	if isPaused {
-		resumeBtn := tgbotapi.NewInlineKeyboardButtonData("▶ Resume schedule", ScheduleCallbackData("resume", cmd.Name()))
+		resumeBtn := tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.ResumeSchedule), ScheduleCallbackData("resume", cmd.Name()))
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{resumeBtn})
 	} else {
-		pauseBtn := tgbotapi.NewInlineKeyboardButtonData("⏸ Pause schedule", ScheduleCallbackData("pause", cmd.Name()))
+		pauseBtn := tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.PauseSchedule), ScheduleCallbackData("pause", cmd.Name()))
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{pauseBtn})
 	}
 
 	// Back button
-	backBtn := tgbotapi.NewInlineKeyboardButtonData("<< Back to Menu", backToMenu)
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.BackToMenu), backToMenu)
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{backBtn})
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	if sent, err := b.api.Send(msg); err == nil {
+		b.trackMessage(chatID, sent.MessageID, msgstore.TypePrompt)
+	}
 }
 
 // handleScheduleCallback processes schedule menu callbacks.
@@ -1066,7 +2193,7 @@ func (b *Bot) handleScheduleCallback(ctx context.Context, query *tgbotapi.Callba
 	cmd := b.registry.Get(cmdName)
 	if cmd == nil {
 		logger.Warn("command not found", "command", cmdName)
-		edit := tgbotapi.NewEditMessageText(chatID, messageID, "Command not found.")
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, b.t(chatID, i18n.CommandNotFound))
 		b.api.Send(edit)
 		return
 	}
@@ -1083,10 +2210,14 @@ func (b *Bot) handleScheduleCallback(ctx context.Context, query *tgbotapi.Callba
 		deleteMsg := tgbotapi.NewDeleteMessage(chatID, messageID)
 		b.api.Request(deleteMsg)
 
+		if b.rejectDisabled(chatID, cmd) || b.rejectRestricted(chatID, query.From, cmd) || b.rejectThrottled(ctx, chatID, cmd) {
+			return
+		}
+
 		logger.Info("executing scheduled command manually", "command", cmdName)
 		quiet := yamlCmd.Quiet()
 		if !quiet {
-			b.sendText(chatID, fmt.Sprintf("Running /%s...", cmdName))
+			b.sendText(chatID, b.t(chatID, i18n.Running, cmdName))
 		}
 		b.executeCommandWithOptions(ctx, chatID, cmd, nil, quiet)
 		b.sendMenu(chatID)
@@ -1115,6 +2246,22 @@ func (b *Bot) handleScheduleCallback(ctx context.Context, query *tgbotapi.Callba
 	}
 }
 
+// CleanupOlderThan deletes tracked messages of the given types sent before
+// the cutoff in a chat. Fails if the message store is not enabled.
+func (b *Bot) CleanupOlderThan(chatID int64, before time.Time, types []msgstore.MessageType) (deleted, failed int, err error) {
+	if b.cleanupCmd == nil {
+		return 0, 0, fmt.Errorf("cleanup not enabled")
+	}
+	return b.cleanupCmd.DeleteOlderThan(chatID, before, types)
+}
+
+// trackUserCommand records a user's /command message for cleanup, if enabled.
+func (b *Bot) trackUserCommand(msg *tgbotapi.Message) {
+	if b.trackCommands {
+		b.trackMessage(msg.Chat.ID, msg.MessageID, msgstore.TypeCommand)
+	}
+}
+
 // trackMessage stores a message ID for later cleanup.
 func (b *Bot) trackMessage(chatID int64, messageID int, msgType msgstore.MessageType) {
 	if b.msgStore == nil || !b.msgStore.Enabled() {
@@ -1124,3 +2271,102 @@ func (b *Bot) trackMessage(chatID int64, messageID int, msgType msgstore.Message
 		slog.Warn("failed to track message", "chat_id", chatID, "message_id", messageID, "error", err)
 	}
 }
+
+// logAudit records a command execution in the audit log.
+func (b *Bot) logAudit(ctx context.Context, chatID int64, cmdName, args string, execErr error, duration time.Duration) {
+	b.lastRun.set(cmdName, time.Now())
+
+	entry := newAuditEntry(ctx, chatID, cmdName)
+	entry.Args = args
+	entry.ExitCode = command.ExitCode(execErr)
+	entry.DurationMs = duration.Milliseconds()
+	b.writeAudit(ctx, entry)
+}
+
+// newAuditEntry creates an audit entry with the user and approvers from ctx.
+func newAuditEntry(ctx context.Context, chatID int64, cmdName string) audit.Entry {
+	entry := audit.Entry{
+		Timestamp: time.Now(),
+		ChatID:    chatID,
+		Command:   cmdName,
+		Approvers: strings.Join(approversFromContext(ctx), ","),
+	}
+	if user := userFromContext(ctx); user != nil {
+		entry.Username = user.UserName
+	}
+	return entry
+}
+
+// auditUsername identifies user in the audit log: the username, or the
+// numeric ID for users without one.
+func auditUsername(user *tgbotapi.User) string {
+	if user.UserName != "" {
+		return user.UserName
+	}
+	return fmt.Sprintf("id:%d", user.ID)
+}
+
+// writeAudit persists an audit entry, logging failures. Its arguments are
+// masked by the command's redact rules.
+func (b *Bot) writeAudit(ctx context.Context, entry audit.Entry) {
+	entry.Args = b.redactRules(b.registry.Get(entry.Command)).Apply(entry.Args)
+	if err := b.auditLogger.Log(context.WithoutCancel(ctx), entry); err != nil {
+		slog.Warn("failed to write audit log", "chat_id", entry.ChatID, "command", entry.Command, "error", err)
+	}
+}
+
+// userKey is the context key for the Telegram user who triggered an action.
+type userKey struct{}
+
+// withUser returns a context carrying the Telegram user.
+func withUser(ctx context.Context, user *tgbotapi.User) context.Context {
+	if user == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// userFromContext returns the Telegram user stored in ctx, or nil.
+func userFromContext(ctx context.Context) *tgbotapi.User {
+	user, _ := ctx.Value(userKey{}).(*tgbotapi.User)
+	return user
+}
+
+// otpKey is the context key marking a verified one-time code.
+type otpKey struct{}
+
+// withOTPVerified returns a context marking the one-time code as verified.
+func withOTPVerified(ctx context.Context) context.Context {
+	return context.WithValue(ctx, otpKey{}, true)
+}
+
+// otpVerified returns true if ctx carries a verified one-time code.
+func otpVerified(ctx context.Context) bool {
+	ok, _ := ctx.Value(otpKey{}).(bool)
+	return ok
+}
+
+// approversKey is the context key for the users who approved an execution.
+type approversKey struct{}
+
+// withApprovers returns a context carrying the approvers for audit logging.
+func withApprovers(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, approversKey{}, names)
+}
+
+// approversFromContext returns the approvers stored in ctx, or nil.
+func approversFromContext(ctx context.Context) []string {
+	names, _ := ctx.Value(approversKey{}).([]string)
+	return names
+}
+
+// userDisplayName returns "@username", or the first name and ID if unset.
+func userDisplayName(user *tgbotapi.User) string {
+	if user == nil {
+		return "unknown"
+	}
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+	return fmt.Sprintf("%s (%d)", user.FirstName, user.ID)
+}
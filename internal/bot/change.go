@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/diff"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/msgstore"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// changeContext is how many unchanged lines surround each change in a diff.
+const changeContext = 2
+
+// outputHistory remembers the last output of notify_on_change commands per
+// chat. It is kept in memory, so the first run after a restart reports the
+// full output again.
+type outputHistory struct {
+	mu   sync.Mutex
+	last map[string]string
+}
+
+// swap stores output under key and returns the previous output, if any.
+func (h *outputHistory) swap(key, output string) (prev string, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.last == nil {
+		h.last = make(map[string]string)
+	}
+	prev, ok = h.last[key]
+	h.last[key] = output
+	return prev, ok
+}
+
+// executeOnChange runs a notify_on_change command and reports its output
+// only if it changed since the previous run in the chat: as a unified diff,
+// or in full on the first run. A failure counts as output.
+func (b *Bot) executeOnChange(ctx context.Context, chatID int64, cmd *command.YAMLCommand) {
+	logger := slog.With("chat_id", chatID, "command", cmd.Name())
+
+	if b.skipForMaintenance(ctx, chatID, cmd, cmd.CommandLine(nil), true) {
+		return
+	}
+	if !b.beginRun(chatID, true) {
+		return
+	}
+	defer b.runs.done()
+
+	timeout := b.currentDefaults().Timeout
+	if meta := cmd.Metadata(); meta.Timeout > 0 {
+		timeout = meta.Timeout
+	}
+	execCtx, cancel := context.WithTimeout(pkgcmd.WithChatID(ctx, chatID), timeout)
+	defer cancel()
+
+	var buf bytes.Buffer
+	start := time.Now()
+	execErr := cmd.Execute(execCtx, nil, &buf)
+	b.logAudit(ctx, chatID, cmd.Name(), "", execErr, time.Since(start))
+	output := buf.String()
+	if cmd.PTY() {
+		output = terminalText(output)
+	}
+	output = cmd.Redactions().Apply(output)
+	if execErr != nil {
+		logger.Error("command execution failed", "error", execErr)
+		output += fmt.Sprintf("\n\nError: %v", execErr)
+	}
+
+	prev, seen := b.outputs.swap(fmt.Sprintf("%d/%s", chatID, cmd.Name()), output)
+	text := output
+	if seen {
+		text = diff.Unified(prev, output, changeContext)
+		if strings.TrimSpace(text) == "" {
+			logger.Debug("scheduled output unchanged")
+			return
+		}
+		logger.Info("scheduled output changed")
+		b.sendText(chatID, b.t(chatID, i18n.OutputChanged, cmd.Name()))
+	} else if !cmd.Quiet() {
+		b.sendText(chatID, b.t(chatID, i18n.ScheduledRunning, cmd.Name()))
+	}
+
+	streamer := b.newStreamer(chatID, false)
+	streamer.SetTruncate(pkgcmd.Truncation(cmd))
+	if err := streamer.Start(ctx); err != nil {
+		logger.Error("failed to start streamer", "error", err)
+		return
+	}
+	if streamer.MessageID() != 0 {
+		b.trackMessage(chatID, streamer.MessageID(), msgstore.TypeText)
+	}
+	streamer.WriteString(text)
+	if err := streamer.Flush(); err != nil {
+		logger.Error("failed to flush output", "error", err)
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/i18n"
 )
 
 const (
-	// confirmationTTL is how long a confirmation request remains valid.
+	// confirmationTTL is how long a confirmation request remains valid by
+	// default.
 	confirmationTTL = 5 * time.Minute
 
 	// callbackConfirm is the prefix for confirm callbacks.
@@ -19,6 +24,9 @@ const (
 
 	// callbackCancel is the prefix for cancel callbacks.
 	callbackCancel = "cancel:"
+
+	// callbackDryRun is the prefix for dry run callbacks.
+	callbackDryRun = "dryrun:"
 )
 
 // PendingConfirmation tracks a command awaiting user confirmation.
@@ -27,14 +35,87 @@ type PendingConfirmation struct {
 	MessageID       int
 	Command         string
 	Args            []string
-	RenderedCommand string // Pre-rendered command for argument-based execution
+	RenderedCommand string            // Pre-rendered command for argument-based execution
+	CollectedArgs   map[string]string // Collected arguments behind RenderedCommand
+	Phrase          string            // Text to type to confirm instead of pressing a button
+	Preview         string            // What runs where, shown in approval requests
+	DryRun          bool              // A Dry run button runs the command's dry_run_command
 	ExpiresAt       time.Time
+
+	// Multi-person approval (Required > 1)
+	Required       int            // Distinct admin approvals needed
+	Approvers      []Approver     // Approvals collected so far, in order
+	Requester      *tgbotapi.User // User who requested the command
+	NoSelfApproval bool           // The requester may not approve
+	approved       map[int64]bool // Approver IDs, for distinctness
+}
+
+// Approver is a user pressing a confirmation button.
+type Approver struct {
+	ID    int64
+	Name  string
+	Admin bool // Whether the user may approve multi-person confirmations
+}
+
+// ConfirmStatus is the outcome of a confirmation button press.
+type ConfirmStatus int
+
+const (
+	ConfirmInvalid      ConfirmStatus = iota // Unknown, e.g. already handled
+	ConfirmCancelled                         // Cancel pressed
+	ConfirmPending                           // Approval recorded, more needed
+	ConfirmApproved                          // Ready to execute
+	ConfirmForbidden                         // Presser may not approve or deny
+	ConfirmDuplicate                         // Presser already approved
+	ConfirmSelfApproval                      // Presser requested the command and may not approve it
+	ConfirmExpired                           // Not confirmed in time
+	ConfirmDryRun                            // Dry run pressed, still awaiting confirmation
+)
+
+// ConfirmRequest describes a command needing confirmation before it runs.
+type ConfirmRequest struct {
+	ChatID          int64
+	Command         string
+	Args            []string
+	RenderedCommand string            // Pre-rendered command for argument-based execution
+	CollectedArgs   map[string]string // Collected arguments behind RenderedCommand
+	Text            string            // Dialog text in Markdown (default: "Confirm execution of ...")
+	Phrase          string            // Text to type to confirm instead of pressing a button
+	DryRun          bool              // Offer a Dry run button running the command's dry_run_command
+	TTL             time.Duration     // How long the dialog stays valid (default: 5m)
+}
+
+// ApprovalRequest describes a command needing approvals from several admins.
+type ApprovalRequest struct {
+	ChatID          int64 // Chat the command runs in
+	ApprovalsChatID int64 // Chat that gets the approval buttons (0 = ChatID)
+	Command         string
+	Args            []string
+	RenderedCommand string
+	CollectedArgs   map[string]string
+	Required        int
+	Requester       *tgbotapi.User
+	NoSelfApproval  bool          // The requester may not approve
+	TTL             time.Duration // How long approvals are collected (default: 5m)
+	Preview         string        // What runs where, shown to approvers
+}
+
+// ApproverNames returns the names of users who approved so far.
+func (p *PendingConfirmation) ApproverNames() []string {
+	names := make([]string, len(p.Approvers))
+	for i, a := range p.Approvers {
+		names[i] = a.Name
+	}
+	return names
 }
 
 // ConfirmationManager handles confirmation dialogs.
 type ConfirmationManager struct {
-	mu      sync.Mutex
-	pending map[string]*PendingConfirmation // key: unique ID
+	mu        sync.Mutex
+	pending   map[string]*PendingConfirmation // key: unique ID
+	onSent    func(chatID int64, messageID int)
+	onExpired func(p *PendingConfirmation)
+	lang      func(chatID int64) string
 }
 
 // NewConfirmationManager creates a confirmation manager.
@@ -49,100 +130,216 @@ func NewConfirmationManager() *ConfirmationManager {
 	return cm
 }
 
-// RequestConfirmation sends an inline keyboard and stores pending state.
-func (cm *ConfirmationManager) RequestConfirmation(
-	api *tgbotapi.BotAPI,
-	chatID int64,
-	cmdName string,
-	args []string,
-) error {
-	id := generateID()
+// Count returns the number of pending confirmations and approvals.
+func (cm *ConfirmationManager) Count() int {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return len(cm.pending)
+}
 
-	// Create inline keyboard
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+// SetOnSent sets a function called with every confirmation and approval
+// message sent, e.g. to track it for cleanup.
+func (cm *ConfirmationManager) SetOnSent(fn func(chatID int64, messageID int)) {
+	cm.onSent = fn
+}
+
+// SetOnExpired sets a function called with every confirmation or approval
+// request that expired unanswered.
+func (cm *ConfirmationManager) SetOnExpired(fn func(p *PendingConfirmation)) {
+	cm.onExpired = fn
+}
+
+// SetLanguage sets the function choosing each chat's message language.
+func (cm *ConfirmationManager) SetLanguage(fn func(chatID int64) string) {
+	cm.lang = fn
+}
+
+// language returns the chat's message language.
+func (cm *ConfirmationManager) language(chatID int64) string {
+	if cm.lang == nil {
+		return i18n.Default
+	}
+	return cm.lang(chatID)
+}
+
+// confirmKeyboard creates Confirm/Cancel buttons for a pending confirmation.
+func confirmKeyboard(lang, id string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Confirm", callbackConfirm+id),
-			tgbotapi.NewInlineKeyboardButtonData("Cancel", callbackCancel+id),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.Confirm), callbackConfirm+id),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.Cancel), callbackCancel+id),
 		),
 	)
+}
 
-	text := fmt.Sprintf("Confirm execution of `/%s`?", cmdName)
-	if len(args) > 0 {
-		text = fmt.Sprintf("Confirm execution of `/%s %v`?", cmdName, args)
-	}
+// phraseKeyboard creates the Cancel button for a confirmation by typed phrase.
+func phraseKeyboard(lang, id string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.Cancel), callbackCancel+id),
+		),
+	)
+}
 
+// confirmMessage builds a confirmation dialog. With a phrase, the user is
+// asked to type it and only Cancel is a button. With dryRun, a Dry run
+// button comes first.
+func confirmMessage(chatID int64, lang, id, text, phrase string, dryRun bool) tgbotapi.MessageConfig {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "Markdown"
+	keyboard := confirmKeyboard(lang, id)
+	if phrase != "" {
+		msg.Text += "\n\n" + i18n.T(lang, i18n.ConfirmPhrasePrompt, phrase)
+		keyboard = phraseKeyboard(lang, id)
+	}
+	if dryRun {
+		row := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.DryRun), callbackDryRun+id))
+		keyboard.InlineKeyboard = append([][]tgbotapi.InlineKeyboardButton{row}, keyboard.InlineKeyboard...)
+	}
 	msg.ReplyMarkup = keyboard
+	return msg
+}
+
+// sent reports a sent message to the onSent hook, if any.
+func (cm *ConfirmationManager) sent(chatID int64, messageID int) {
+	if cm.onSent != nil {
+		cm.onSent(chatID, messageID)
+	}
+}
+
+// RequestConfirmation sends a confirmation dialog and stores pending state.
+// With a phrase, it must be typed to confirm instead of pressing a button.
+func (cm *ConfirmationManager) RequestConfirmation(api *tgbotapi.BotAPI, req ConfirmRequest) error {
+	id := generateID()
+	lang := cm.language(req.ChatID)
+
+	text := req.Text
+	if text == "" {
+		shown := req.Command
+		if len(req.Args) > 0 {
+			shown = fmt.Sprintf("%s %v", req.Command, req.Args)
+		}
+		text = i18n.T(lang, i18n.ConfirmExecution, shown)
+	}
+	msg := confirmMessage(req.ChatID, lang, id, text, req.Phrase, req.DryRun)
 
 	sent, err := api.Send(msg)
 	if err != nil {
-		return err
+		// Show text that isn't valid Markdown (e.g. from a description) as is
+		msg.ParseMode = ""
+		if sent, err = api.Send(msg); err != nil {
+			return err
+		}
+	}
+	cm.sent(msg.ChatID, sent.MessageID)
+
+	ttl := req.TTL
+	if ttl <= 0 {
+		ttl = confirmationTTL
 	}
 
-	// Store pending confirmation
 	cm.mu.Lock()
 	cm.pending[id] = &PendingConfirmation{
-		ChatID:    chatID,
-		MessageID: sent.MessageID,
-		Command:   cmdName,
-		Args:      args,
-		ExpiresAt: time.Now().Add(confirmationTTL),
+		ChatID:          req.ChatID,
+		MessageID:       sent.MessageID,
+		Command:         req.Command,
+		Args:            req.Args,
+		RenderedCommand: req.RenderedCommand,
+		CollectedArgs:   req.CollectedArgs,
+		Phrase:          req.Phrase,
+		DryRun:          req.DryRun,
+		ExpiresAt:       time.Now().Add(ttl),
 	}
 	cm.mu.Unlock()
 
 	return nil
 }
 
-// RequestConfirmationWithRendered sends a confirmation dialog for a pre-rendered command.
-func (cm *ConfirmationManager) RequestConfirmationWithRendered(
-	api *tgbotapi.BotAPI,
-	chatID int64,
-	cmdName string,
-	rendered string,
-) error {
+// RequestApproval sends Approve/Deny buttons to the approvals chat and stores
+// pending state until Required distinct admins approve.
+func (cm *ConfirmationManager) RequestApproval(api *tgbotapi.BotAPI, req ApprovalRequest) error {
 	id := generateID()
 
-	// Create inline keyboard
+	approvalsChat := req.ApprovalsChatID
+	if approvalsChat == 0 {
+		approvalsChat = req.ChatID
+	}
+	lang := cm.language(approvalsChat)
+
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Confirm", callbackConfirm+id),
-			tgbotapi.NewInlineKeyboardButtonData("Cancel", callbackCancel+id),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.Approve), callbackConfirm+id),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.Deny), callbackCancel+id),
 		),
 	)
 
-	text := fmt.Sprintf("Confirm execution of `/%s`?", cmdName)
+	ttl := req.TTL
+	if ttl <= 0 {
+		ttl = confirmationTTL
+	}
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
+	pending := &PendingConfirmation{
+		ChatID:          req.ChatID,
+		Command:         req.Command,
+		Args:            req.Args,
+		RenderedCommand: req.RenderedCommand,
+		CollectedArgs:   req.CollectedArgs,
+		Preview:         req.Preview,
+		ExpiresAt:       time.Now().Add(ttl),
+		Required:        req.Required,
+		Requester:       req.Requester,
+		NoSelfApproval:  req.NoSelfApproval,
+		approved:        make(map[int64]bool),
+	}
+
+	msg := tgbotapi.NewMessage(approvalsChat, approvalText(lang, pending))
 	msg.ReplyMarkup = keyboard
 
 	sent, err := api.Send(msg)
 	if err != nil {
 		return err
 	}
+	cm.sent(msg.ChatID, sent.MessageID)
+	pending.MessageID = sent.MessageID
 
-	// Store pending confirmation with rendered command
 	cm.mu.Lock()
-	cm.pending[id] = &PendingConfirmation{
-		ChatID:          chatID,
-		MessageID:       sent.MessageID,
-		Command:         cmdName,
-		RenderedCommand: rendered,
-		ExpiresAt:       time.Now().Add(confirmationTTL),
-	}
+	cm.pending[id] = pending
 	cm.mu.Unlock()
 
 	return nil
 }
 
-// HandleCallback processes a confirmation button press.
-// Returns the pending confirmation if confirmed, nil if cancelled or not found.
-func (cm *ConfirmationManager) HandleCallback(callbackData string) (*PendingConfirmation, bool) {
+// approvalText describes the approval state of a pending command in lang.
+func approvalText(lang string, p *PendingConfirmation) string {
+	text := i18n.T(lang, i18n.ApprovalRequired, p.Command)
+	if len(p.Args) > 0 {
+		text += " " + strings.Join(p.Args, " ")
+	}
+	if p.Preview != "" {
+		text += "\n\n" + p.Preview + "\n"
+	}
+	if p.Requester != nil {
+		text += "\n" + i18n.T(lang, i18n.ApprovalRequestedBy, userDisplayName(p.Requester), p.ChatID)
+	}
+	text += "\n" + i18n.T(lang, i18n.ApprovalCount, len(p.Approvers), p.Required)
+	if len(p.Approvers) > 0 {
+		text += " (" + strings.Join(p.ApproverNames(), ", ") + ")"
+	}
+	return text
+}
+
+// HandleCallback processes a confirmation button press by approver.
+// Single confirmations are approved by any press of Confirm. Multi-person
+// approvals need Required distinct admins; only admins may approve or deny.
+// Dry run leaves the confirmation pending.
+func (cm *ConfirmationManager) HandleCallback(callbackData string, approver Approver) (*PendingConfirmation, ConfirmStatus) {
 	var id string
-	var confirmed bool
+	var confirmed, dryRun bool
 
 	switch {
+	case len(callbackData) > len(callbackDryRun) && callbackData[:len(callbackDryRun)] == callbackDryRun:
+		id = callbackData[len(callbackDryRun):]
+		dryRun = true
 	case len(callbackData) > len(callbackConfirm) && callbackData[:len(callbackConfirm)] == callbackConfirm:
 		id = callbackData[len(callbackConfirm):]
 		confirmed = true
@@ -150,25 +347,143 @@ func (cm *ConfirmationManager) HandleCallback(callbackData string) (*PendingConf
 		id = callbackData[len(callbackCancel):]
 		confirmed = false
 	default:
-		return nil, false
+		return nil, ConfirmInvalid
 	}
 
 	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
 	pending, ok := cm.pending[id]
-	if ok {
+	if !ok {
+		return nil, ConfirmInvalid
+	}
+	if time.Now().After(pending.ExpiresAt) {
 		delete(cm.pending, id)
+		return pending.snapshot(), ConfirmExpired
+	}
+	if dryRun {
+		if !pending.DryRun {
+			return nil, ConfirmInvalid
+		}
+		return pending.snapshot(), ConfirmDryRun
 	}
-	cm.mu.Unlock()
 
-	if !ok || time.Now().After(pending.ExpiresAt) {
-		return nil, false
+	if pending.Required > 1 {
+		if !approver.Admin {
+			return pending.snapshot(), ConfirmForbidden
+		}
+		if confirmed && pending.approved[approver.ID] {
+			return pending.snapshot(), ConfirmDuplicate
+		}
+		if confirmed && pending.NoSelfApproval && pending.Requester != nil && pending.Requester.ID == approver.ID {
+			return pending.snapshot(), ConfirmSelfApproval
+		}
 	}
 
 	if !confirmed {
+		delete(cm.pending, id)
+		return pending.snapshot(), ConfirmCancelled
+	}
+
+	// Only typing the phrase confirms; there is no button to press
+	if pending.Phrase != "" {
+		delete(cm.pending, id)
+		return nil, ConfirmInvalid
+	}
+
+	if pending.Required > 1 {
+		pending.approved[approver.ID] = true
+		pending.Approvers = append(pending.Approvers, approver)
+		if len(pending.Approvers) < pending.Required {
+			return pending.snapshot(), ConfirmPending
+		}
+	}
+
+	delete(cm.pending, id)
+	return pending.snapshot(), ConfirmApproved
+}
+
+// WaitingPhrase returns true if a confirmation in the chat waits for a typed
+// phrase.
+func (cm *ConfirmationManager) WaitingPhrase(chatID int64) bool {
+	_, pending := cm.phrasePending(chatID)
+	return pending != nil
+}
+
+// TakePhrase removes the chat's newest confirmation waiting for a phrase and
+// reports whether text matches it. It returns nil if none is waiting.
+func (cm *ConfirmationManager) TakePhrase(chatID int64, text string) (*PendingConfirmation, bool) {
+	id, pending := cm.phrasePending(chatID)
+	if pending == nil {
 		return nil, false
 	}
 
-	return pending, true
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.pending[id] != pending {
+		return nil, false // Taken meanwhile
+	}
+	delete(cm.pending, id)
+	return pending.snapshot(), strings.TrimSpace(text) == pending.Phrase
+}
+
+// CancelPhrase removes confirmations in the chat waiting for a phrase and
+// returns them.
+func (cm *ConfirmationManager) CancelPhrase(chatID int64) []*PendingConfirmation {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	var cancelled []*PendingConfirmation
+	for id, p := range cm.pending {
+		if p.ChatID == chatID && p.Phrase != "" {
+			delete(cm.pending, id)
+			cancelled = append(cancelled, p.snapshot())
+		}
+	}
+	return cancelled
+}
+
+// phrasePending returns the chat's newest unexpired confirmation waiting for
+// a phrase.
+func (cm *ConfirmationManager) phrasePending(chatID int64) (string, *PendingConfirmation) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	var newestID string
+	var newest *PendingConfirmation
+	now := time.Now()
+	for id, p := range cm.pending {
+		if p.ChatID != chatID || p.Phrase == "" || now.After(p.ExpiresAt) {
+			continue
+		}
+		if newest == nil || p.ExpiresAt.After(newest.ExpiresAt) {
+			newestID, newest = id, p
+		}
+	}
+	return newestID, newest
+}
+
+// phraseWords are the words random confirmation phrases are made of.
+var phraseWords = []string{
+	"amber", "basalt", "cobalt", "delta", "ember", "falcon", "granite", "harbor",
+	"indigo", "juniper", "kelp", "lantern", "marble", "nectar", "onyx", "pepper",
+	"quartz", "raven", "saffron", "timber", "umber", "velvet", "walnut", "zephyr",
+}
+
+// RandomPhrase returns a random word and number, e.g. "falcon-42", to type
+// as a confirmation phrase.
+func RandomPhrase() string {
+	b := make([]byte, 2)
+	rand.Read(b)
+	return fmt.Sprintf("%s-%02d", phraseWords[int(b[0])%len(phraseWords)], int(b[1])%100)
+}
+
+// snapshot returns a copy safe to use after the manager's lock is released.
+func (p *PendingConfirmation) snapshot() *PendingConfirmation {
+	c := *p
+	c.Approvers = slices.Clone(p.Approvers)
+	c.approved = nil
+	return &c
 }
 
 // cleanupLoop removes expired confirmations.
@@ -179,12 +494,20 @@ func (cm *ConfirmationManager) cleanupLoop() {
 	for range ticker.C {
 		cm.mu.Lock()
 		now := time.Now()
+		var expired []*PendingConfirmation
 		for id, pending := range cm.pending {
 			if now.After(pending.ExpiresAt) {
 				delete(cm.pending, id)
+				expired = append(expired, pending.snapshot())
 			}
 		}
 		cm.mu.Unlock()
+
+		if cm.onExpired != nil {
+			for _, p := range expired {
+				cm.onExpired(p)
+			}
+		}
 	}
 }
 
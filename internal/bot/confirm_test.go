@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/config"
+)
+
+func TestHandleCallbackMultiApproval(t *testing.T) {
+	cm := &ConfirmationManager{pending: make(map[string]*PendingConfirmation)}
+	cm.pending["abc"] = &PendingConfirmation{
+		ChatID:    1,
+		Command:   "drop-db",
+		ExpiresAt: time.Now().Add(time.Minute),
+		Required:  2,
+		approved:  make(map[int64]bool),
+	}
+
+	alice := Approver{ID: 10, Name: "@alice", Admin: true}
+	bob := Approver{ID: 20, Name: "@bob", Admin: true}
+	eve := Approver{ID: 30, Name: "@eve", Admin: false}
+
+	steps := []struct {
+		name     string
+		data     string
+		approver Approver
+		want     ConfirmStatus
+	}{
+		{"non-admin approve", callbackConfirm + "abc", eve, ConfirmForbidden},
+		{"non-admin deny", callbackCancel + "abc", eve, ConfirmForbidden},
+		{"first approval", callbackConfirm + "abc", alice, ConfirmPending},
+		{"same admin again", callbackConfirm + "abc", alice, ConfirmDuplicate},
+		{"second approval", callbackConfirm + "abc", bob, ConfirmApproved},
+		{"already executed", callbackConfirm + "abc", bob, ConfirmInvalid},
+	}
+
+	for _, step := range steps {
+		pending, got := cm.HandleCallback(step.data, step.approver)
+		if got != step.want {
+			t.Fatalf("%s: status = %v, want %v", step.name, got, step.want)
+		}
+		if got == ConfirmApproved {
+			names := pending.ApproverNames()
+			if len(names) != 2 || names[0] != "@alice" || names[1] != "@bob" {
+				t.Errorf("approvers = %v, want [@alice @bob]", names)
+			}
+		}
+	}
+}
+
+func TestHandleCallbackSingleConfirm(t *testing.T) {
+	cm := &ConfirmationManager{pending: make(map[string]*PendingConfirmation)}
+	cm.pending["one"] = &PendingConfirmation{Command: "deploy", ExpiresAt: time.Now().Add(time.Minute)}
+	cm.pending["two"] = &PendingConfirmation{Command: "deploy", ExpiresAt: time.Now().Add(time.Minute)}
+
+	anyone := Approver{ID: 1}
+	if _, got := cm.HandleCallback(callbackConfirm+"one", anyone); got != ConfirmApproved {
+		t.Errorf("confirm status = %v, want ConfirmApproved", got)
+	}
+	if _, got := cm.HandleCallback(callbackCancel+"two", anyone); got != ConfirmCancelled {
+		t.Errorf("cancel status = %v, want ConfirmCancelled", got)
+	}
+}
+
+func TestConfirmPhrase(t *testing.T) {
+	cm := &ConfirmationManager{pending: make(map[string]*PendingConfirmation)}
+	add := func(id, phrase string) {
+		cm.pending[id] = &PendingConfirmation{ChatID: 1, Command: "wipe", Phrase: phrase, ExpiresAt: time.Now().Add(time.Minute)}
+	}
+
+	add("btn", "wipe")
+	if _, got := cm.HandleCallback(callbackConfirm+"btn", Approver{ID: 1}); got != ConfirmInvalid {
+		t.Errorf("confirm button on phrase = %v, want ConfirmInvalid", got)
+	}
+	if cm.WaitingPhrase(1) {
+		t.Error("WaitingPhrase() after rejected button = true, want false")
+	}
+
+	add("ok", "wipe")
+	if !cm.WaitingPhrase(1) || cm.WaitingPhrase(2) {
+		t.Error("WaitingPhrase() should match only the prompting chat")
+	}
+	if p, ok := cm.TakePhrase(1, "  wipe \n"); p == nil || !ok {
+		t.Errorf("TakePhrase(matching) = %v, %v; want pending, true", p, ok)
+	}
+
+	add("bad", "falcon-42")
+	if p, ok := cm.TakePhrase(1, "wipe"); p == nil || ok {
+		t.Errorf("TakePhrase(mismatch) = %v, %v; want pending, false", p, ok)
+	}
+	if p, _ := cm.TakePhrase(1, "falcon-42"); p != nil {
+		t.Error("TakePhrase() after mismatch should find nothing")
+	}
+}
+
+func TestConfirmRequest(t *testing.T) {
+	cmd := searchCmd{name: "wipe", desc: "Wipe the cache"}
+
+	b := &Bot{defaults: config.DefaultsConfig{ConfirmTTL: time.Minute}}
+	req := b.confirmRequest(1, cmd, []string{"all"}, "", nil)
+	if req.TTL != time.Minute || req.Phrase != "" {
+		t.Errorf("request = %+v, want default TTL and no phrase", req)
+	}
+	if !strings.Contains(req.Text, "/wipe all") || !strings.Contains(req.Text, "Wipe the cache") {
+		t.Errorf("text = %q, want command, args and description", req.Text)
+	}
+
+	b.defaults.ConfirmMessage = "Really {{.Description}} ({{.Command}} {{.Args}})?"
+	if req := b.confirmRequest(1, cmd, []string{"all"}, "", nil); req.Text != "Really Wipe the cache (wipe all)?" {
+		t.Errorf("templated text = %q", req.Text)
+	}
+
+	b.defaults.ConfirmMessage = "{{.Missing}}"
+	if req := b.confirmRequest(1, cmd, nil, "", nil); !strings.Contains(req.Text, "/wipe") {
+		t.Errorf("failing template should fall back to built-in text, got %q", req.Text)
+	}
+}
+
+func TestHandleCallbackNoSelfApproval(t *testing.T) {
+	cm := &ConfirmationManager{pending: make(map[string]*PendingConfirmation)}
+	cm.pending["abc"] = &PendingConfirmation{
+		ChatID:         1,
+		Command:        "drop-db",
+		ExpiresAt:      time.Now().Add(time.Minute),
+		Required:       2,
+		Requester:      &tgbotapi.User{ID: 10},
+		NoSelfApproval: true,
+		approved:       make(map[int64]bool),
+	}
+
+	alice := Approver{ID: 10, Name: "@alice", Admin: true}
+	if _, got := cm.HandleCallback(callbackConfirm+"abc", alice); got != ConfirmSelfApproval {
+		t.Fatalf("requester approval = %v, want ConfirmSelfApproval", got)
+	}
+	if _, got := cm.HandleCallback(callbackConfirm+"abc", Approver{ID: 20, Admin: true}); got != ConfirmPending {
+		t.Fatalf("first approval = %v, want ConfirmPending", got)
+	}
+	if _, got := cm.HandleCallback(callbackCancel+"abc", alice); got != ConfirmCancelled {
+		t.Errorf("requester deny = %v, want ConfirmCancelled", got)
+	}
+}
+
+// auditRecorder collects audit entries.
+type auditRecorder struct {
+	entries []audit.Entry
+}
+
+func (r *auditRecorder) Log(_ context.Context, e audit.Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *auditRecorder) Close() error { return nil }
+
+func TestAuditConfirmation(t *testing.T) {
+	rec := &auditRecorder{}
+	b := &Bot{auditLogger: rec, registry: command.NewRegistry()}
+
+	p := &PendingConfirmation{
+		ChatID:    1,
+		Command:   "drop-db",
+		Args:      []string{"prod"},
+		Approvers: []Approver{{ID: 10, Name: "@alice"}},
+	}
+	b.auditConfirmation(p, audit.StatusApproved, &tgbotapi.User{ID: 20})
+	b.auditConfirmation(p, audit.StatusExpired, nil)
+
+	if len(rec.entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(rec.entries))
+	}
+	got := rec.entries[0]
+	if got.Status != audit.StatusApproved || got.Username != "id:20" || got.Args != "prod" || got.Approvers != "@alice" || got.ExitCode != -1 {
+		t.Errorf("approved entry = %+v", got)
+	}
+	if got := rec.entries[1]; got.Status != audit.StatusExpired || got.Username != "" {
+		t.Errorf("expired entry = %+v", got)
+	}
+}
+
+func TestHandleCallbackDryRun(t *testing.T) {
+	cm := &ConfirmationManager{pending: make(map[string]*PendingConfirmation)}
+	cm.pending["plan"] = &PendingConfirmation{Command: "apply", DryRun: true, ExpiresAt: time.Now().Add(time.Minute)}
+	cm.pending["plain"] = &PendingConfirmation{Command: "deploy", ExpiresAt: time.Now().Add(time.Minute)}
+
+	anyone := Approver{ID: 1}
+	for range 2 {
+		if p, got := cm.HandleCallback(callbackDryRun+"plan", anyone); got != ConfirmDryRun || p.Command != "apply" {
+			t.Fatalf("dry run status = %v, want ConfirmDryRun", got)
+		}
+	}
+	if _, got := cm.HandleCallback(callbackConfirm+"plan", anyone); got != ConfirmApproved {
+		t.Errorf("confirm after dry run = %v, want ConfirmApproved", got)
+	}
+	if _, got := cm.HandleCallback(callbackDryRun+"plain", anyone); got != ConfirmInvalid {
+		t.Errorf("dry run without dry_run_command = %v, want ConfirmInvalid", got)
+	}
+}
+
+func TestConfirmMessageDryRun(t *testing.T) {
+	keyboard := func(msg tgbotapi.MessageConfig) [][]tgbotapi.InlineKeyboardButton {
+		return msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard
+	}
+
+	if rows := keyboard(confirmMessage(1, "en", "id", "Apply?", "", false)); len(rows) != 1 {
+		t.Errorf("rows without dry run = %d, want 1", len(rows))
+	}
+	rows := keyboard(confirmMessage(1, "en", "id", "Apply?", "", true))
+	if len(rows) != 2 || *rows[0][0].CallbackData != callbackDryRun+"id" || len(rows[1]) != 2 {
+		t.Errorf("keyboard with dry run = %+v", rows)
+	}
+	rows = keyboard(confirmMessage(1, "en", "id", "Apply?", "apply", true))
+	if len(rows) != 2 || len(rows[1]) != 1 || *rows[1][0].CallbackData != callbackCancel+"id" {
+		t.Errorf("phrase keyboard with dry run = %+v", rows)
+	}
+}
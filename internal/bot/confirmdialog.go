@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// ConfirmData is the data of confirm_message templates.
+type ConfirmData struct {
+	Command     string // Command name
+	Args        string // Arguments, space separated
+	Description string
+	Danger      string // The command's danger note
+	Preview     string // What runs where: command line, workdir and target
+}
+
+// confirmRequest builds the confirmation dialog for running cmd in chatID
+// with args, or the command rendered from collected arguments: its text,
+// phrase and expiry, from the command's settings or the defaults.
+func (b *Bot) confirmRequest(chatID int64, cmd pkgcmd.Command, args []string, rendered string, collected map[string]string) ConfirmRequest {
+	req := ConfirmRequest{
+		ChatID:          chatID,
+		Command:         cmd.Name(),
+		Args:            args,
+		RenderedCommand: rendered,
+		CollectedArgs:   collected,
+		Phrase:          confirmPhrase(cmd),
+		TTL:             b.confirmTTL(cmd),
+	}
+
+	data := ConfirmData{
+		Command:     cmd.Name(),
+		Args:        strings.Join(args, " "),
+		Description: cmd.Description(),
+	}
+	tmpl := b.currentDefaults().ConfirmMessage
+	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok {
+		if yamlCmd.ConfirmMessage() != "" {
+			tmpl = yamlCmd.ConfirmMessage()
+		}
+		data.Danger = yamlCmd.Danger()
+		req.DryRun = yamlCmd.DryRunCommand() != ""
+		data.Preview = BuildRenderedPreview(b.lang(chatID), yamlCmd, commandLine(yamlCmd, args, rendered), collected, true)
+	}
+
+	req.Text = b.confirmText(chatID, tmpl, data)
+	return req
+}
+
+// confirmTTL returns how long confirmations and approvals of cmd stay valid.
+func (b *Bot) confirmTTL(cmd pkgcmd.Command) time.Duration {
+	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok && yamlCmd.ConfirmTTL() > 0 {
+		return yamlCmd.ConfirmTTL()
+	}
+	return b.currentDefaults().ConfirmTTL
+}
+
+// confirmText renders the confirmation dialog from tmpl, or the built-in
+// text if tmpl is empty or fails.
+func (b *Bot) confirmText(chatID int64, tmpl string, data ConfirmData) string {
+	if tmpl != "" {
+		var sb strings.Builder
+		t, err := template.New("confirm_message").Parse(tmpl)
+		if err == nil {
+			err = t.Execute(&sb, data)
+		}
+		if err == nil {
+			return sb.String()
+		}
+		slog.Warn("confirm_message template failed", "command", data.Command, "error", err)
+	}
+
+	shown := data.Command
+	if data.Args != "" {
+		shown = data.Command + " " + data.Args
+	}
+	text := b.t(chatID, i18n.ConfirmExecution, shown)
+	if data.Description != "" {
+		text += "\n" + data.Description
+	}
+	if data.Preview != "" {
+		text += "\n\n" + data.Preview
+	}
+	if data.Danger != "" {
+		text += "\n\n" + b.t(chatID, i18n.ConfirmDanger, data.Danger)
+	}
+	return text
+}
+
+// requestConfirmation sends a confirmation dialog and records the request
+// in the audit log.
+func (b *Bot) requestConfirmation(ctx context.Context, req ConfirmRequest) error {
+	if err := b.confirmMgr.RequestConfirmation(b.api, req); err != nil {
+		return err
+	}
+	b.auditConfirmation(&PendingConfirmation{
+		ChatID:          req.ChatID,
+		Command:         req.Command,
+		Args:            req.Args,
+		RenderedCommand: req.RenderedCommand,
+		CollectedArgs:   req.CollectedArgs,
+	}, audit.StatusConfirmRequested, userFromContext(ctx))
+	return nil
+}
+
+// auditConfirmation records a step of a confirmation or approval in the
+// audit log: status, and who acted (nil for expiry).
+func (b *Bot) auditConfirmation(p *PendingConfirmation, status string, user *tgbotapi.User) {
+	entry := audit.Entry{
+		Timestamp: time.Now(),
+		ChatID:    p.ChatID,
+		Command:   p.Command,
+		Args:      strings.Join(p.Args, " "),
+		ExitCode:  -1,
+		Approvers: strings.Join(p.ApproverNames(), ","),
+		Status:    status,
+	}
+	if yamlCmd, ok := b.registry.Get(p.Command).(*command.YAMLCommand); ok && p.CollectedArgs != nil {
+		entry.Args = MaskArgs(yamlCmd, p.CollectedArgs)
+	}
+	if user != nil {
+		entry.Username = auditUsername(user)
+	}
+	b.writeAudit(context.Background(), entry)
+}
+
+// commandLine returns what cmd runs: the rendered command, or its command
+// with args.
+func commandLine(cmd *command.YAMLCommand, args []string, rendered string) string {
+	if rendered != "" {
+		return rendered
+	}
+	return cmd.CommandLine(args)
+}
+
+// confirmPhrase returns the phrase to type to confirm cmd, or "" if a button
+// press confirms it.
+func confirmPhrase(cmd pkgcmd.Command) string {
+	yamlCmd, ok := cmd.(*command.YAMLCommand)
+	if !ok {
+		return ""
+	}
+	switch yamlCmd.ConfirmPhrase() {
+	case command.ConfirmPhraseName:
+		return cmd.Name()
+	case command.ConfirmPhraseRandom:
+		return RandomPhrase()
+	}
+	return ""
+}
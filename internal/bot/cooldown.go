@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// cooldownRun is when a command last ran and who ran it.
+type cooldownRun struct {
+	at time.Time
+	by string
+}
+
+// cooldowns remembers the last run of each command with a cooldown.
+type cooldowns struct {
+	mu   sync.Mutex
+	last map[string]cooldownRun
+}
+
+// remaining returns how long the command still cools down at now, and its
+// last run. It is zero once the cooldown is over.
+func (c *cooldowns) remaining(name string, cooldown time.Duration, now time.Time) (time.Duration, cooldownRun) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.remainingLocked(name, cooldown, now)
+}
+
+func (c *cooldowns) remainingLocked(name string, cooldown time.Duration, now time.Time) (time.Duration, cooldownRun) {
+	last, ok := c.last[name]
+	if !ok {
+		return 0, cooldownRun{}
+	}
+	return max(0, last.at.Add(cooldown).Sub(now)), last
+}
+
+// claim records a run of the command by who at now, unless it is still
+// cooling down. It returns the remaining time and last run on refusal.
+func (c *cooldowns) claim(name, who string, cooldown time.Duration, now time.Time) (time.Duration, cooldownRun, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if wait, last := c.remainingLocked(name, cooldown, now); wait > 0 {
+		return wait, last, false
+	}
+	if c.last == nil {
+		c.last = make(map[string]cooldownRun)
+	}
+	c.last[name] = cooldownRun{at: now, by: who}
+	return 0, cooldownRun{}, true
+}
+
+// commandCooldown returns the command's cooldown, or 0 without one.
+func commandCooldown(cmd pkgcmd.Command) time.Duration {
+	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok {
+		return yamlCmd.Cooldown()
+	}
+	return 0
+}
+
+// rejectCoolingDown tells the chat and returns true if a user asks for a
+// command that is still cooling down. Runs without a user, such as
+// scheduled ones, are not subject to cooldowns.
+func (b *Bot) rejectCoolingDown(ctx context.Context, chatID int64, cmd pkgcmd.Command) bool {
+	cooldown := commandCooldown(cmd)
+	if cooldown == 0 || userFromContext(ctx) == nil {
+		return false
+	}
+	wait, last := b.cooldowns.remaining(cmd.Name(), cooldown, time.Now())
+	if wait == 0 {
+		return false
+	}
+	b.refuseCoolingDown(ctx, chatID, cmd, wait, last, false)
+	return true
+}
+
+// claimCooldown starts the command's cooldown as a user's run begins. It
+// returns false, telling the chat unless quiet, if another run started the
+// cooldown in the meantime.
+func (b *Bot) claimCooldown(ctx context.Context, chatID int64, cmd pkgcmd.Command, quiet bool) bool {
+	cooldown := commandCooldown(cmd)
+	user := userFromContext(ctx)
+	if cooldown == 0 || user == nil {
+		return true
+	}
+	wait, last, ok := b.cooldowns.claim(cmd.Name(), userDisplayName(user), cooldown, time.Now())
+	if !ok {
+		b.refuseCoolingDown(ctx, chatID, cmd, wait, last, quiet)
+	}
+	return ok
+}
+
+// refuseCoolingDown logs and audits a refused run, and tells the chat who
+// ran the command and when it may run again unless quiet.
+func (b *Bot) refuseCoolingDown(ctx context.Context, chatID int64, cmd pkgcmd.Command, wait time.Duration, last cooldownRun, quiet bool) {
+	slog.Info("command cooling down", "chat_id", chatID, "command", cmd.Name(), "last_run_by", last.by, "remaining", wait)
+	if !quiet {
+		ago := time.Since(last.at).Round(time.Second)
+		wait = (wait + time.Second - 1).Truncate(time.Second) // Round up: never "0s"
+		b.sendText(chatID, b.t(chatID, i18n.CoolingDown, cmd.Name(), last.by, ago, wait))
+	}
+
+	entry := newAuditEntry(ctx, chatID, cmd.Name())
+	entry.ExitCode = -1
+	entry.Status = audit.StatusCooldown
+	b.writeAudit(ctx, entry)
+}
@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCooldowns(t *testing.T) {
+	var c cooldowns
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if wait, _ := c.remaining("failover", 10*time.Minute, start); wait != 0 {
+		t.Fatalf("remaining before any run = %v, want 0", wait)
+	}
+	if _, _, ok := c.claim("failover", "@alice", 10*time.Minute, start); !ok {
+		t.Fatal("first claim refused")
+	}
+
+	wait, last, ok := c.claim("failover", "@bob", 10*time.Minute, start.Add(4*time.Minute))
+	if ok {
+		t.Fatal("claim during cooldown allowed")
+	}
+	if wait != 6*time.Minute || last.by != "@alice" || !last.at.Equal(start) {
+		t.Errorf("refusal = %v, %+v; want 6m by @alice", wait, last)
+	}
+
+	// Other commands have their own cooldown
+	if _, _, ok := c.claim("deploy", "@bob", 10*time.Minute, start.Add(time.Minute)); !ok {
+		t.Error("claim of another command refused")
+	}
+
+	if _, _, ok := c.claim("failover", "@bob", 10*time.Minute, start.Add(10*time.Minute)); !ok {
+		t.Error("claim after cooldown refused")
+	}
+	if _, last := c.remaining("failover", 10*time.Minute, start.Add(11*time.Minute)); last.by != "@bob" {
+		t.Errorf("last run by %q, want @bob", last.by)
+	}
+}
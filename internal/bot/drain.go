@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/settings"
+)
+
+// runTracker counts command executions in progress so a restart can let
+// them finish. Once draining, it refuses new ones.
+type runTracker struct {
+	mu       sync.Mutex
+	running  int
+	draining bool
+	idle     chan struct{} // Closed when the last execution ends while draining
+}
+
+// start registers an execution. It returns false while draining.
+func (t *runTracker) start() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.running++
+	return true
+}
+
+// done ends an execution registered with start.
+func (t *runTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running--
+	if t.draining && t.running == 0 {
+		close(t.idle)
+	}
+}
+
+// drain refuses new executions and returns a channel closed once the
+// running ones have ended.
+func (t *runTracker) drain() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.draining {
+		t.draining = true
+		t.idle = make(chan struct{})
+		if t.running == 0 {
+			close(t.idle)
+		}
+	}
+	return t.idle
+}
+
+// count returns how many executions are running.
+func (t *runTracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.running
+}
+
+// beginRun registers an execution in chatID. While the bot is draining
+// for a restart it refuses, telling the chat unless quiet.
+func (b *Bot) beginRun(chatID int64, quiet bool) bool {
+	if b.runs.start() {
+		return true
+	}
+	slog.Info("refusing command while restarting", "chat_id", chatID)
+	if !quiet {
+		b.sendText(chatID, b.t(chatID, i18n.RestartInProgress))
+	}
+	return false
+}
+
+// Drain stops new command executions and waits up to timeout for running
+// ones to finish. It returns how many are still running.
+func (b *Bot) Drain(timeout time.Duration) int {
+	idle := b.runs.drain()
+	running := b.runs.count()
+	if running > 0 {
+		slog.Info("waiting for running commands", "running", running, "timeout", timeout)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-idle:
+		return 0
+	case <-timer.C:
+		return b.runs.count()
+	}
+}
+
+// NotifyRestart tells the allowed chats the bot is restarting, except
+// those that turned startup notifications off in /settings.
+func (b *Bot) NotifyRestart() {
+	b.settingsMu.RLock()
+	chatIDs := b.allowedChatIDs
+	b.settingsMu.RUnlock()
+
+	for _, chatID := range chatIDs {
+		if b.chatSetting(chatID, settings.Startup) == "off" {
+			continue
+		}
+		b.sendText(chatID, b.t(chatID, i18n.Restarting))
+	}
+}
@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunTrackerDrain(t *testing.T) {
+	var runs runTracker
+	if !runs.start() || !runs.start() {
+		t.Fatal("start() refused before draining")
+	}
+
+	idle := runs.drain()
+	if runs.start() {
+		t.Error("start() accepted an execution while draining")
+	}
+
+	runs.done()
+	select {
+	case <-idle:
+		t.Fatal("idle before the last execution ended")
+	default:
+	}
+
+	runs.done()
+	select {
+	case <-idle:
+	case <-time.After(time.Second):
+		t.Fatal("not idle after the last execution ended")
+	}
+	if runs.count() != 0 {
+		t.Errorf("count() = %d, want 0", runs.count())
+	}
+}
+
+func TestRunTrackerDrainIdle(t *testing.T) {
+	var runs runTracker
+	select {
+	case <-runs.drain():
+	default:
+		t.Error("drain() with nothing running is not idle")
+	}
+}
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/msgstore"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// runDryRun runs the dry_run_command of a command awaiting confirmation,
+// with the same arguments, in its chat. The dialog stays open to confirm or
+// cancel the command itself.
+func (b *Bot) runDryRun(ctx context.Context, pending *PendingConfirmation) {
+	chatID := pending.ChatID
+	logger := slog.With("chat_id", chatID, "command", pending.Command)
+
+	cmd, ok := b.registry.Get(pending.Command).(*command.YAMLCommand)
+	if !ok || cmd.DryRunCommand() == "" {
+		// Removed or changed by a reload since the dialog was sent
+		b.sendText(chatID, b.t(chatID, i18n.ConfirmExpired))
+		return
+	}
+	if b.rejectDisabled(chatID, cmd) || b.rejectRestricted(chatID, userFromContext(ctx), cmd) {
+		return
+	}
+
+	line, args := cmd.DryRunCommand(), pending.Args
+	auditArgs := strings.Join(args, " ")
+	if pending.RenderedCommand != "" {
+		rendered, err := RenderCommand(line, pending.CollectedArgs)
+		if err != nil {
+			logger.Error("failed to render dry_run_command", "error", err)
+			b.sendText(chatID, b.t(chatID, i18n.ProcessFailed, err))
+			return
+		}
+		line, args = rendered, nil
+		auditArgs = MaskArgs(cmd, pending.CollectedArgs)
+	}
+
+	if !b.beginRun(chatID, false) {
+		return
+	}
+	defer b.runs.done()
+
+	timeout := b.currentDefaults().Timeout
+	if meta := cmd.Metadata(); meta.Timeout > 0 {
+		timeout = meta.Timeout
+	}
+
+	logger.Info("running dry run")
+	b.sendText(chatID, b.t(chatID, i18n.DryRunRunning, cmd.Name()))
+
+	streamer := b.newStreamer(chatID, false)
+	streamer.SetRedactions(SensitiveValues(cmd, pending.CollectedArgs))
+	streamer.SetRedactRules(cmd.Redactions())
+	streamer.SetTruncate(pkgcmd.Truncation(cmd))
+	streamer.SetTerminal(cmd.PTY())
+	if err := streamer.Start(ctx); err != nil {
+		logger.Error("failed to start streamer", "error", err)
+		return
+	}
+	if streamer.MessageID() != 0 {
+		b.trackMessage(chatID, streamer.MessageID(), msgstore.TypeText)
+	}
+
+	execCtx, cancel := context.WithTimeout(pkgcmd.WithChatID(ctx, chatID), timeout)
+	defer cancel()
+
+	start := time.Now()
+	execErr := cmd.ExecuteDryRun(execCtx, line, args, streamer)
+
+	entry := newAuditEntry(ctx, chatID, cmd.Name())
+	entry.Args = auditArgs
+	entry.ExitCode = command.ExitCode(execErr)
+	entry.DurationMs = time.Since(start).Milliseconds()
+	entry.Status = audit.StatusDryRun
+	b.writeAudit(ctx, entry)
+
+	if execErr != nil {
+		logger.Error("dry run failed", "error", execErr)
+		fmt.Fprintf(streamer, "\n\nError: %v", execErr)
+	}
+	if err := streamer.Flush(); err != nil {
+		logger.Error("failed to flush output", "error", err)
+	}
+}
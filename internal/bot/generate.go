@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/fileref"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// generatorRun is one run of a generator command and its output directory.
+type generatorRun struct {
+	def *command.GenerateDef
+	dir string
+}
+
+// startGenerator prepares a run of a generator command: it creates the
+// output directory and shows the progress line in output. For other
+// commands it returns ctx unchanged and a nil run.
+func startGenerator(ctx context.Context, cmd pkgcmd.Command, output io.Writer) (context.Context, *generatorRun, error) {
+	yamlCmd, ok := cmd.(*command.YAMLCommand)
+	if !ok || yamlCmd.Generate() == nil {
+		return ctx, nil, nil
+	}
+
+	dir, err := os.MkdirTemp("", "pako-generate-*")
+	if err != nil {
+		return ctx, nil, fmt.Errorf("create output directory: %w", err)
+	}
+	fmt.Fprintln(output, yamlCmd.Generate().ProgressText())
+	return command.WithOutputDir(ctx, dir), &generatorRun{def: yamlCmd.Generate(), dir: dir}, nil
+}
+
+// collect finds the generated files, ready to send with the rendered
+// caption. Audio files are sent as voice messages if the generator asks.
+func (g *generatorRun) collect(collected map[string]string) (fileref.ParseResult, error) {
+	files, err := g.def.OutputFiles(g.dir, collected)
+	if err != nil {
+		return fileref.ParseResult{}, err
+	}
+	caption, err := g.def.RenderCaption(collected)
+	if err != nil {
+		return fileref.ParseResult{}, err
+	}
+
+	var refs strings.Builder
+	for _, f := range files {
+		kind := "file"
+		if g.def.Voice && fileref.DetectType(f) == fileref.FileTypeAudio {
+			kind = "voice"
+		}
+		fmt.Fprintf(&refs, "[%s:%s]\n", kind, f)
+	}
+	result := fileref.ParseOutput(refs.String(), "")
+	result.Text = caption
+	return result, nil
+}
+
+// cleanup removes the output directory. Safe to call on a nil run.
+func (g *generatorRun) cleanup() {
+	if g != nil {
+		os.RemoveAll(g.dir)
+	}
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/config"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+)
+
+// lastRun records the most recently executed command.
+type lastRun struct {
+	mu   sync.Mutex
+	name string
+	at   time.Time
+}
+
+// set records a command run.
+func (l *lastRun) set(name string, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.name, l.at = name, at
+}
+
+// get returns the last command and when it ran; at is zero if none ran yet.
+func (l *lastRun) get() (name string, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.name, l.at
+}
+
+// RunHeartbeat posts a liveness message to the heartbeat chat every interval
+// until the context is cancelled. In "edit" mode one message is kept up to
+// date; it is posted again if it was deleted. Does nothing without a chat.
+func (b *Bot) RunHeartbeat(ctx context.Context, cfg config.HeartbeatConfig) {
+	if cfg.ChatID == 0 {
+		return
+	}
+	slog.Info("heartbeat enabled", "chat_id", cfg.ChatID, "interval", cfg.Interval, "mode", cfg.Mode)
+
+	messageID := 0
+	beat := func() {
+		text := b.heartbeatText(cfg.ChatID, time.Now())
+		if cfg.Mode == "edit" && messageID != 0 {
+			_, err := b.api.Send(tgbotapi.NewEditMessageText(cfg.ChatID, messageID, text))
+			if err == nil {
+				return
+			}
+			if !strings.Contains(err.Error(), "not found") {
+				slog.Warn("failed to update heartbeat", "chat_id", cfg.ChatID, "error", err)
+				return
+			}
+		}
+
+		msg := tgbotapi.NewMessage(cfg.ChatID, text)
+		msg.DisableNotification = true
+		sent, err := b.api.Send(msg)
+		if err != nil {
+			slog.Warn("failed to send heartbeat", "chat_id", cfg.ChatID, "error", err)
+			return
+		}
+		messageID = sent.MessageID
+	}
+
+	beat()
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			beat()
+		}
+	}
+}
+
+// heartbeatText describes the bot's uptime and last command as of now.
+func (b *Bot) heartbeatText(chatID int64, now time.Time) string {
+	last := b.t(chatID, i18n.HeartbeatNever)
+	if name, at := b.lastRun.get(); !at.IsZero() {
+		last = "/" + name + ", " + b.t(chatID, i18n.HeartbeatAgo, now.Sub(at).Round(time.Second))
+	}
+	uptime := now.Sub(b.started).Round(time.Second)
+	return b.t(chatID, i18n.Heartbeat, uptime, last, now.Format("2006-01-02 15:04:05"))
+}
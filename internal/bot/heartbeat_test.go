@@ -0,0 +1,24 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeartbeatText(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	b := &Bot{started: start}
+	b.SetLanguages("", nil)
+
+	now := start.Add(26*time.Hour + 5*time.Second)
+	want := "💓 Bot alive\nUptime: 26h0m5s\nLast command: none since start\nUpdated: 2026-03-02 12:00:05"
+	if got := b.heartbeatText(1, now); got != want {
+		t.Errorf("heartbeatText() = %q, want %q", got, want)
+	}
+
+	b.lastRun.set("deploy", now.Add(-90*time.Second))
+	want = "💓 Bot alive\nUptime: 26h0m5s\nLast command: /deploy, 1m30s ago\nUpdated: 2026-03-02 12:00:05"
+	if got := b.heartbeatText(1, now); got != want {
+		t.Errorf("heartbeatText() = %q, want %q", got, want)
+	}
+}
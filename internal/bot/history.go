@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/msgstore"
+	"github.com/rashpile/pako-telegram/internal/redact"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// historyPrefix is the callback prefix for /history re-run buttons.
+const historyPrefix = "hist:"
+
+// Limits for /history.
+const (
+	defaultHistoryCount = 10
+	maxHistoryCount     = 30
+	historyArgsLength   = 60 // Longer arguments are shortened in the list
+)
+
+// historyReader reads past executions back from the audit log.
+type historyReader interface {
+	Recent(ctx context.Context, chatID int64, limit int) ([]audit.Entry, error)
+	Execution(ctx context.Context, chatID, id int64) (audit.Entry, error)
+}
+
+// IsHistoryCallback checks if the callback is a /history re-run button.
+func IsHistoryCallback(data string) bool {
+	return strings.HasPrefix(data, historyPrefix)
+}
+
+// handleHistoryCommand handles /history [count]: the chat's last executions
+// from the audit log, with a button to re-run each.
+func (b *Bot) handleHistoryCommand(ctx context.Context, msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+
+	if !b.authorizer.IsAllowed(chatID) {
+		b.logUnauthorized(chatID, msg.From, "history")
+		b.rejectChat(chatID)
+		return
+	}
+	if b.rejectUser(chatID, msg.From, "history", true) {
+		return
+	}
+	b.trackUserCommand(msg)
+
+	reader, ok := b.auditLogger.(historyReader)
+	if !ok {
+		b.sendText(chatID, b.t(chatID, i18n.HistoryUnavailable))
+		return
+	}
+
+	count := defaultHistoryCount
+	if arg := strings.TrimSpace(msg.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			b.sendText(chatID, b.t(chatID, i18n.HistoryUsage, maxHistoryCount))
+			return
+		}
+		count = min(n, maxHistoryCount)
+	}
+
+	entries, err := reader.Recent(ctx, chatID, count)
+	if err != nil {
+		slog.Error("failed to read history", "chat_id", chatID, "error", err)
+		b.sendText(chatID, b.t(chatID, i18n.HistoryFailed, err))
+		return
+	}
+	if len(entries) == 0 {
+		b.sendText(chatID, b.t(chatID, i18n.HistoryEmpty))
+		return
+	}
+
+	lang := b.lang(chatID)
+	reply := tgbotapi.NewMessage(chatID, historyText(lang, entries))
+	reply.ReplyMarkup = historyKeyboard(entries)
+	if sent, err := b.api.Send(reply); err == nil {
+		b.trackMessage(chatID, sent.MessageID, msgstore.TypePrompt)
+	}
+}
+
+// historyText lists entries, newest first, numbered to match the buttons.
+func historyText(lang string, entries []audit.Entry) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, i18n.HistoryTitle, len(entries)))
+	for i, e := range entries {
+		status := "✅"
+		if e.ExitCode != 0 {
+			status = "❌ " + i18n.T(lang, i18n.HistoryExit, e.ExitCode)
+		}
+		line := "/" + e.Command
+		if e.Args != "" {
+			line += " " + shortenArgs(e.Args)
+		}
+		fmt.Fprintf(&sb, "\n\n%d. %s %s\n", i+1, status, line)
+
+		details := []string{e.Timestamp.Local().Format("2006-01-02 15:04")}
+		if e.Username != "" {
+			details = append([]string{e.Username}, details...)
+		}
+		details = append(details, (time.Duration(e.DurationMs) * time.Millisecond).Round(10*time.Millisecond).String())
+		sb.WriteString("    " + strings.Join(details, " · "))
+	}
+	return sb.String()
+}
+
+// shortenArgs cuts arguments to historyArgsLength characters.
+func shortenArgs(args string) string {
+	args = strings.Join(strings.Fields(args), " ")
+	if utf8.RuneCountInString(args) <= historyArgsLength {
+		return args
+	}
+	return string([]rune(args)[:historyArgsLength-1]) + "…"
+}
+
+// historyKeyboard has a re-run button per entry, two per row.
+func historyKeyboard(entries []audit.Entry) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, e := range entries {
+		label := fmt.Sprintf("🔁 %d. %s", i+1, e.Command)
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, historyPrefix+strconv.FormatInt(e.ID, 10)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleHistoryCallback re-runs an execution from /history with its logged
+// arguments. The command goes through the usual checks; commands with
+// arguments prompt for any that can't be reused, such as sensitive ones.
+func (b *Bot) handleHistoryCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	logger := slog.With("chat_id", chatID, "callback", query.Data)
+
+	reader, ok := b.auditLogger.(historyReader)
+	id, err := strconv.ParseInt(strings.TrimPrefix(query.Data, historyPrefix), 10, 64)
+	if !ok || err != nil {
+		return
+	}
+	entry, err := reader.Execution(ctx, chatID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		b.sendText(chatID, b.t(chatID, i18n.HistoryGone))
+		return
+	}
+	if err != nil {
+		logger.Error("failed to read history entry", "error", err)
+		b.sendText(chatID, b.t(chatID, i18n.HistoryFailed, err))
+		return
+	}
+
+	cmd := b.registry.Get(entry.Command)
+	if cmd == nil {
+		b.sendText(chatID, b.t(chatID, i18n.UnknownCommand, entry.Command))
+		return
+	}
+	if b.rejectDisabled(chatID, cmd) || b.rejectRestricted(chatID, query.From, cmd) || b.rejectThrottled(ctx, chatID, cmd) {
+		return
+	}
+	logger.Info("re-running command from history", "command", entry.Command)
+
+	if argCmd, ok := cmd.(ArgumentCommand); ok && argCmd.HasArguments() {
+		b.collectArguments(ctx, chatID, argCmd, rerunPrefilled(argCmd.Arguments(), entry.Args))
+		return
+	}
+
+	args, ok := rerunArgs(cmd, entry.Args)
+	if !ok {
+		b.sendText(chatID, b.t(chatID, i18n.HistoryRedacted, entry.Command))
+		return
+	}
+	b.dispatchCommand(ctx, chatID, cmd, args)
+}
+
+// rerunPrefilled returns the logged arguments to prefill when re-running
+// a command with defs. Values that were masked in the log, sensitive ones
+// or matches of redact rules, are left out so they're asked for again.
+func rerunPrefilled(defs []command.ArgumentDef, logged string) map[string]string {
+	prefilled, err := ParseInlineArguments(defs, logged)
+	if err != nil {
+		return nil // Ask for everything again
+	}
+	for _, def := range defs {
+		if def.Sensitive || strings.Contains(prefilled[def.Name], redact.Mask) {
+			delete(prefilled, def.Name)
+		}
+	}
+	return prefilled
+}
+
+// rerunArgs returns the logged arguments to run cmd with again. Returns
+// false if redact rules masked them, as they can't run as logged.
+func rerunArgs(cmd pkgcmd.Command, logged string) ([]string, bool) {
+	if strings.Contains(logged, redact.Mask) {
+		return nil, false
+	}
+	if _, ok := cmd.(pkgcmd.WithFileResponse); ok {
+		if logged == "" {
+			return nil, true
+		}
+		return []string{logged}, true
+	}
+	return parseArgs(logged), true
+}
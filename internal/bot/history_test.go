@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+)
+
+func TestHistoryText(t *testing.T) {
+	at := time.Date(2026, 3, 1, 14, 5, 0, 0, time.Local)
+	entries := []audit.Entry{
+		{ID: 9, Timestamp: at, Username: "alice", Command: "deploy", Args: "env=prod", ExitCode: 2, DurationMs: 1234},
+		{ID: 7, Timestamp: at, Command: "uptime", Args: strings.Repeat("x", 100), DurationMs: 5},
+		{ID: 3, Timestamp: at, Command: "status"},
+	}
+
+	text := historyText("en", entries)
+	for _, want := range []string{
+		"Last 3 commands",
+		"1. ❌ exit 2 /deploy env=prod\n    alice · 2026-03-01 14:05 · 1.23s",
+		"2. ✅ /uptime " + strings.Repeat("x", historyArgsLength-1) + "…",
+		"3. ✅ /status\n    2026-03-01 14:05 · 0s",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text missing %q:\n%s", want, text)
+		}
+	}
+
+	keyboard := historyKeyboard(entries)
+	if len(keyboard.InlineKeyboard) != 2 || len(keyboard.InlineKeyboard[0]) != 2 || len(keyboard.InlineKeyboard[1]) != 1 {
+		t.Fatalf("keyboard rows = %+v", keyboard.InlineKeyboard)
+	}
+	first := keyboard.InlineKeyboard[0][0]
+	if first.Text != "🔁 1. deploy" || first.CallbackData == nil || *first.CallbackData != "hist:9" {
+		t.Errorf("first button = %q %v", first.Text, first.CallbackData)
+	}
+	if !IsHistoryCallback(*first.CallbackData) {
+		t.Error("IsHistoryCallback(first button) = false")
+	}
+}
+
+func TestRerunArgs(t *testing.T) {
+	cmd := roleCommand{name: "ping"}
+	tests := []struct {
+		logged string
+		want   []string
+		ok     bool
+	}{
+		{"", nil, true},
+		{"host 10.0.0.1", []string{"host", "10.0.0.1"}, true},
+		{"--token ****", nil, false},
+		{"password=****x", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := rerunArgs(cmd, tt.logged)
+		if ok != tt.ok || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("rerunArgs(%q) = %q, %v; want %q, %v", tt.logged, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRerunPrefilled(t *testing.T) {
+	defs := []command.ArgumentDef{
+		{Name: "env"},
+		{Name: "dsn"},
+		{Name: "key", Sensitive: true},
+	}
+	got := rerunPrefilled(defs, "env=prod dsn=postgres://app:****@db key=****")
+	if got["env"] != "prod" || len(got) != 1 {
+		t.Errorf("rerunPrefilled() = %v, want only env=prod", got)
+	}
+}
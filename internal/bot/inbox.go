@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+const (
+	// maxDownloadSize is the largest file the Bot API lets bots download.
+	maxDownloadSize = 20 << 20
+
+	// downloadTimeout bounds fetching an uploaded file from Telegram.
+	downloadTimeout = 2 * time.Minute
+)
+
+// captionCommand returns the command name from a caption such as
+// "/restore" or "/restore@mybot now", or "" if it doesn't start with one.
+func captionCommand(caption string) string {
+	fields := strings.Fields(caption)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+	name, _, _ := strings.Cut(fields[0][1:], "@")
+	return name
+}
+
+// upload describes a file attached to a message.
+type upload struct {
+	kind   string // pkgcmd.InputDocument or pkgcmd.InputPhoto
+	fileID string
+	name   string
+	size   int
+}
+
+// messageUpload returns the document or largest photo size in msg.
+func messageUpload(msg *tgbotapi.Message) (upload, bool) {
+	if msg.Document != nil {
+		return upload{
+			kind:   pkgcmd.InputDocument,
+			fileID: msg.Document.FileID,
+			name:   msg.Document.FileName,
+			size:   msg.Document.FileSize,
+		}, true
+	}
+	if len(msg.Photo) > 0 {
+		// Photo sizes are listed smallest first
+		largest := msg.Photo[len(msg.Photo)-1]
+		return upload{
+			kind:   pkgcmd.InputPhoto,
+			fileID: largest.FileID,
+			name:   "photo.jpg",
+			size:   largest.FileSize,
+		}, true
+	}
+	return upload{}, false
+}
+
+// handleUpload saves a document or photo sent with a /command caption to the
+// inbox and runs the command with the saved file's path as its argument.
+func (b *Bot) handleUpload(ctx context.Context, msg *tgbotapi.Message) {
+	ctx = withUser(ctx, msg.From)
+	chatID := msg.Chat.ID
+	cmdName := captionCommand(msg.Caption)
+	logger := slog.With("chat_id", chatID, "command", cmdName)
+
+	file, ok := messageUpload(msg)
+	if !ok {
+		return
+	}
+
+	if !b.authorizer.IsAllowed(chatID) {
+		logger.Warn("unauthorized upload attempt")
+		b.logUnauthorized(chatID, msg.From, cmdName)
+		b.rejectChat(chatID)
+		return
+	}
+	if b.rejectUser(chatID, msg.From, cmdName, true) {
+		return
+	}
+
+	cmd := b.registry.Get(cmdName)
+	if cmd == nil {
+		b.sendText(chatID, b.t(chatID, i18n.UnknownCommand, cmdName))
+		return
+	}
+	if b.rejectDisabled(chatID, cmd) {
+		return
+	}
+	if !pkgcmd.AcceptsInput(cmd, file.kind) {
+		key := i18n.NoDocumentInput
+		if file.kind == pkgcmd.InputPhoto {
+			key = i18n.NoPhotoInput
+		}
+		b.sendText(chatID, b.t(chatID, key, cmdName))
+		return
+	}
+	if b.inbox == nil {
+		b.sendText(chatID, b.t(chatID, i18n.UploadsDisabled))
+		return
+	}
+	if b.rejectRestricted(chatID, msg.From, cmd) || b.rejectThrottled(ctx, chatID, cmd) {
+		return
+	}
+
+	path, err := b.saveUpload(chatID, file)
+	if err != nil {
+		logger.Error("failed to save upload", "error", err)
+		b.sendText(chatID, b.t(chatID, i18n.ReceiveFailed, err))
+		return
+	}
+	logger.Info("saved upload", "kind", file.kind, "path", path)
+
+	b.dispatchCommand(ctx, chatID, cmd, []string{path})
+}
+
+// RecordPut writes an audit entry for a file /put saved from src, an
+// uploaded file, to dest. The chat and user are taken from ctx.
+func (b *Bot) RecordPut(ctx context.Context, src, dest string) {
+	chatID, _ := pkgcmd.ChatID(ctx)
+	entry := newAuditEntry(ctx, chatID, "put")
+	entry.Args = src + " -> " + dest
+	entry.Status = audit.StatusPut
+	b.writeAudit(ctx, entry)
+}
+
+// saveUpload downloads a Telegram file into the chat's inbox and returns
+// the local path.
+func (b *Bot) saveUpload(chatID int64, file upload) (string, error) {
+	if file.size > maxDownloadSize {
+		return "", fmt.Errorf("files over %d MB can't be downloaded by bots", maxDownloadSize>>20)
+	}
+
+	body, err := b.openTelegramFile(file.fileID)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	return b.inbox.Save(chatID, file.name, body, maxDownloadSize)
+}
+
+// openTelegramFile starts downloading a file sent to the bot.
+// The caller must close the returned body.
+func (b *Bot) openTelegramFile(fileID string) (io.ReadCloser, error) {
+	fileURL, err := b.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: downloadTimeout}
+	resp, err := client.Get(fileURL)
+	if err != nil {
+		// The URL contains the bot token; keep it out of chat messages
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
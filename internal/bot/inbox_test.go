@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+func TestCaptionCommand(t *testing.T) {
+	tests := map[string]string{
+		"/restore":              "restore",
+		"/restore@pakobot":      "restore",
+		"  /restore now please": "restore",
+		"restore":               "",
+		"":                      "",
+		"see /restore":          "",
+	}
+	for caption, want := range tests {
+		if got := captionCommand(caption); got != want {
+			t.Errorf("captionCommand(%q) = %q, want %q", caption, got, want)
+		}
+	}
+}
+
+func TestMessageUpload(t *testing.T) {
+	photo := &tgbotapi.Message{Photo: []tgbotapi.PhotoSize{
+		{FileID: "small", FileSize: 1000},
+		{FileID: "large", FileSize: 90000},
+	}}
+	got, ok := messageUpload(photo)
+	if !ok || got.kind != pkgcmd.InputPhoto || got.fileID != "large" || got.size != 90000 {
+		t.Errorf("photo upload = %+v, %v; want largest size", got, ok)
+	}
+
+	doc := &tgbotapi.Message{Document: &tgbotapi.Document{FileID: "doc", FileName: "dump.sql"}}
+	got, ok = messageUpload(doc)
+	if !ok || got.kind != pkgcmd.InputDocument || got.name != "dump.sql" {
+		t.Errorf("document upload = %+v, %v", got, ok)
+	}
+
+	if _, ok := messageUpload(&tgbotapi.Message{Text: "hi"}); ok {
+		t.Error("expected no upload for a text message")
+	}
+}
@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+const (
+	// maxInlineResults is the Bot API limit on results per inline answer.
+	maxInlineResults = 50
+
+	// memberCacheTTL is how long chat membership checks are cached.
+	memberCacheTTL = 10 * time.Minute
+)
+
+// memberCache remembers whether users belong to an allowed chat, so inline
+// queries don't call getChatMember on every keystroke.
+type memberCache struct {
+	mu      sync.Mutex
+	entries map[int64]memberEntry
+}
+
+type memberEntry struct {
+	member  bool
+	expires time.Time
+}
+
+// get returns the cached result for userID, if still fresh.
+func (c *memberCache) get(userID int64, now time.Time) (member, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[userID]
+	if !ok || now.After(e.expires) {
+		return false, false
+	}
+	return e.member, true
+}
+
+// set caches a result for userID.
+func (c *memberCache) set(userID int64, member bool, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[int64]memberEntry)
+	}
+	c.entries[userID] = memberEntry{member: member, expires: now.Add(memberCacheTTL)}
+}
+
+// handleInlineQuery answers "@bot query" with matching commands. Selecting a
+// result posts the command to the chat, where it runs with the usual checks.
+func (b *Bot) handleInlineQuery(query *tgbotapi.InlineQuery) {
+	logger := slog.With("user_id", query.From.ID)
+
+	var results []any
+	if b.knownUser(query.From) {
+		for _, cmd := range searchCommands(b.registry.All(), query.Query, maxInlineResults) {
+			article := tgbotapi.NewInlineQueryResultArticle(cmd.Name(), "/"+cmd.Name(), "/"+cmd.Name())
+			article.Description = cmd.Description()
+			results = append(results, article)
+		}
+	} else {
+		logger.Warn("inline query from unknown user", "username", query.From.UserName)
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     10,
+		IsPersonal:    true,
+	}
+	if _, err := b.api.Request(answer); err != nil {
+		logger.Error("failed to answer inline query", "error", err)
+	}
+}
+
+// knownUser returns true if the user passes user restrictions and belongs
+// to an allowed chat (a private chat with the bot, or a member of a group).
+// Inline queries carry no chat, so this stops strangers listing commands.
+func (b *Bot) knownUser(user *tgbotapi.User) bool {
+	if user == nil || !b.authorizer.IsAllowedUser(user.ID, user.UserName) {
+		return false
+	}
+	// Private chats share the user's ID
+	if b.authorizer.IsAllowed(user.ID) {
+		return true
+	}
+
+	now := time.Now()
+	if member, ok := b.members.get(user.ID, now); ok {
+		return member
+	}
+
+	b.settingsMu.RLock()
+	chatIDs := b.allowedChatIDs
+	b.settingsMu.RUnlock()
+
+	member := false
+	for _, chatID := range chatIDs {
+		if chatID > 0 {
+			continue
+		}
+		m, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+			ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: user.ID},
+		})
+		if err == nil && !m.HasLeft() && !m.WasKicked() {
+			member = true
+			break
+		}
+	}
+	b.members.set(user.ID, member, now)
+	return member
+}
+
+// searchCommands returns listed commands matching query by name or
+// description, name matches first, at most limit. An empty query matches all.
+func searchCommands(cmds []pkgcmd.Command, query string, limit int) []pkgcmd.Command {
+	query = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(query), "/"))
+
+	rank := func(cmd pkgcmd.Command) int {
+		name := strings.ToLower(cmd.Name())
+		switch {
+		case query == "" || strings.HasPrefix(name, query):
+			return 0
+		case strings.Contains(name, query):
+			return 1
+		case strings.Contains(strings.ToLower(cmd.Description()), query):
+			return 2
+		}
+		return -1
+	}
+
+	var matches []pkgcmd.Command
+	for _, cmd := range cmds {
+		if pkgcmd.IsHidden(cmd) || pkgcmd.IsDisabled(cmd) || rank(cmd) < 0 {
+			continue
+		}
+		matches = append(matches, cmd)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		ri, rj := rank(matches[i]), rank(matches[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return matches[i].Name() < matches[j].Name()
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
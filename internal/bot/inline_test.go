@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// searchCmd is a minimal command with metadata for search tests.
+type searchCmd struct {
+	name, desc string
+	hidden     bool
+}
+
+func (c searchCmd) Name() string        { return c.name }
+func (c searchCmd) Description() string { return c.desc }
+func (c searchCmd) Execute(context.Context, []string, io.Writer) error {
+	return nil
+}
+func (c searchCmd) Metadata() pkgcmd.Metadata {
+	return pkgcmd.Metadata{Hidden: c.hidden}
+}
+
+func TestSearchCommands(t *testing.T) {
+	cmds := []pkgcmd.Command{
+		searchCmd{name: "redeploy", desc: "Redeploy the app"},
+		searchCmd{name: "deploy", desc: "Deploy to production"},
+		searchCmd{name: "status", desc: "Show deploy status"},
+		searchCmd{name: "disk", desc: "Disk usage"},
+		searchCmd{name: "deploy-secret", desc: "Hidden deploy", hidden: true},
+	}
+
+	names := func(found []pkgcmd.Command) []string {
+		var out []string
+		for _, c := range found {
+			out = append(out, c.Name())
+		}
+		return out
+	}
+
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"depl", 10, []string{"deploy", "redeploy", "status"}},
+		{"/DEPLOY", 10, []string{"deploy", "redeploy", "status"}},
+		{"usage", 10, []string{"disk"}},
+		{"", 2, []string{"deploy", "disk"}},
+		{"nothing", 10, nil},
+	}
+	for _, tt := range tests {
+		if got := names(searchCommands(cmds, tt.query, tt.limit)); !slices.Equal(got, tt.want) {
+			t.Errorf("searchCommands(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMemberCache(t *testing.T) {
+	var c memberCache
+	now := time.Now()
+
+	if _, ok := c.get(1, now); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+	c.set(1, true, now)
+	if member, ok := c.get(1, now.Add(time.Minute)); !ok || !member {
+		t.Errorf("get = %v, %v; want cached member", member, ok)
+	}
+	if _, ok := c.get(1, now.Add(memberCacheTTL+time.Second)); ok {
+		t.Error("expected entry to expire")
+	}
+}
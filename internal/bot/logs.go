@@ -0,0 +1,173 @@
+package bot
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/logbuf"
+	"github.com/rashpile/pako-telegram/internal/msgstore"
+)
+
+// logsPrefix is the callback prefix for /logs buttons: "logs:<level>:<count>".
+const logsPrefix = "logs:"
+
+// Limits for /logs.
+const (
+	defaultLogsCount = 20
+	maxLogsCount     = 100
+	maxLogsLength    = 4000 // Older lines are dropped to fit one message
+)
+
+// logLevels are the /logs level filters, lowest first.
+var logLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// IsLogsCallback checks if the callback is a /logs button.
+func IsLogsCallback(data string) bool {
+	return strings.HasPrefix(data, logsPrefix)
+}
+
+// handleLogsCommand handles /logs [level] [count]: the bot's own recent log
+// records, with buttons to change the level and refresh. Admins only.
+func (b *Bot) handleLogsCommand(msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+
+	if !b.authorizer.IsAllowed(chatID) {
+		b.logUnauthorized(chatID, msg.From, "logs")
+		b.rejectChat(chatID)
+		return
+	}
+	if b.rejectUser(chatID, msg.From, "logs", true) {
+		return
+	}
+	b.trackUserCommand(msg)
+
+	if !b.isAdmin(chatID, msg.From) {
+		b.logUnauthorized(chatID, msg.From, "logs")
+		b.sendText(chatID, b.t(chatID, i18n.LogsAdminOnly))
+		return
+	}
+	if b.logs == nil {
+		b.sendText(chatID, b.t(chatID, i18n.LogsUnavailable))
+		return
+	}
+
+	level, count, ok := parseLogsArgs(strings.Fields(msg.CommandArguments()))
+	if !ok {
+		b.sendText(chatID, b.t(chatID, i18n.LogsUsage, maxLogsCount))
+		return
+	}
+
+	reply := tgbotapi.NewMessage(chatID, b.logsText(chatID, level, count))
+	reply.ReplyMarkup = logsKeyboard(b.lang(chatID), level, count)
+	if sent, err := b.api.Send(reply); err == nil {
+		b.trackMessage(chatID, sent.MessageID, msgstore.TypeText)
+	}
+}
+
+// parseLogsArgs reads an optional level and count, in any order. By
+// default every kept record is shown, up to defaultLogsCount.
+func parseLogsArgs(args []string) (slog.Level, int, bool) {
+	level, count := slog.LevelDebug, defaultLogsCount
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil {
+			if n < 1 {
+				return 0, 0, false
+			}
+			count = min(n, maxLogsCount)
+			continue
+		}
+		if err := level.UnmarshalText([]byte(arg)); err != nil {
+			return 0, 0, false
+		}
+	}
+	return level, count, true
+}
+
+// handleLogsCallback redraws a /logs message with the level and count from
+// the button, showing the newest records.
+func (b *Bot) handleLogsCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	if b.logs == nil || !b.isAdmin(chatID, query.From) {
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(query.Data, logsPrefix), ":")
+	if len(parts) != 2 {
+		return
+	}
+	level, count, ok := parseLogsArgs(parts)
+	if !ok {
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID,
+		b.logsText(chatID, level, count), logsKeyboard(b.lang(chatID), level, count))
+	if _, err := b.api.Send(edit); err != nil {
+		slog.Debug("failed to refresh logs", "chat_id", chatID, "error", err)
+	}
+}
+
+// logsText lists up to count records at or above level, oldest first,
+// dropping the oldest lines that don't fit in a message.
+func (b *Bot) logsText(chatID int64, level slog.Level, count int) string {
+	records := b.logs.Records(level, count)
+	header := b.t(chatID, i18n.LogsTitle, level, time.Now().Format("15:04:05"))
+	if len(records) == 0 {
+		return header + "\n\n" + b.t(chatID, i18n.LogsEmpty)
+	}
+	return header + "\n\n" + logLines(records, maxLogsLength-len(header))
+}
+
+// logLines joins the newest records that fit in limit bytes, oldest first.
+func logLines(records []logbuf.Record, limit int) string {
+	var lines []string
+	size := 0
+	for i := len(records) - 1; i >= 0; i-- {
+		line := records[i].String()
+		if size+len(line)+1 > limit {
+			if len(lines) == 0 { // Keep at least the newest record, shortened
+				lines = append(lines, truncateUTF8(line, limit-1))
+			}
+			break
+		}
+		lines = append(lines, line)
+		size += len(line) + 1
+	}
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// logsKeyboard has a button per level, the current one marked, and a
+// refresh button.
+func logsKeyboard(lang string, level slog.Level, count int) tgbotapi.InlineKeyboardMarkup {
+	var levels []tgbotapi.InlineKeyboardButton
+	for _, l := range logLevels {
+		label := l.String()
+		if l == level {
+			label = "• " + label
+		}
+		levels = append(levels, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("%s%s:%d", logsPrefix, l, count)))
+	}
+	refresh := tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.Refresh), fmt.Sprintf("%s%s:%d", logsPrefix, level, count))
+	return tgbotapi.NewInlineKeyboardMarkup(levels, tgbotapi.NewInlineKeyboardRow(refresh))
+}
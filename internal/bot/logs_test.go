@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/logbuf"
+)
+
+func TestParseLogsArgs(t *testing.T) {
+	tests := []struct {
+		args      string
+		wantLevel slog.Level
+		wantCount int
+		wantOK    bool
+	}{
+		{"", slog.LevelDebug, defaultLogsCount, true},
+		{"warn", slog.LevelWarn, defaultLogsCount, true},
+		{"50 error", slog.LevelError, 50, true},
+		{"500", slog.LevelDebug, maxLogsCount, true},
+		{"0", 0, 0, false},
+		{"loud", 0, 0, false},
+	}
+	for _, tt := range tests {
+		level, count, ok := parseLogsArgs(strings.Fields(tt.args))
+		if ok != tt.wantOK || (ok && (level != tt.wantLevel || count != tt.wantCount)) {
+			t.Errorf("parseLogsArgs(%q) = %s, %d, %v; want %s, %d, %v", tt.args, level, count, ok, tt.wantLevel, tt.wantCount, tt.wantOK)
+		}
+	}
+}
+
+func TestLogLinesKeepsNewest(t *testing.T) {
+	at := time.Date(2025, 1, 1, 10, 0, 0, 0, time.Local)
+	records := []logbuf.Record{
+		{Time: at, Level: slog.LevelInfo, Message: "first"},
+		{Time: at, Level: slog.LevelInfo, Message: "second"},
+		{Time: at, Level: slog.LevelWarn, Message: "third"},
+	}
+
+	got := logLines(records, 50)
+	want := "10:00:00 INFO second\n10:00:00 WARN third"
+	if got != want {
+		t.Errorf("logLines() = %q, want %q", got, want)
+	}
+	if got := logLines(records, 12); got != "10:00:00 WA" {
+		t.Errorf("logLines() with a short limit = %q", got)
+	}
+}
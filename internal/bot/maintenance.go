@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// SetMaintenance turns maintenance mode on or off. In maintenance mode
+// YAML commands not marked read_only are rendered and logged but not run.
+func (b *Bot) SetMaintenance(on bool) {
+	if b.maintenance.Swap(on) != on {
+		slog.Warn("maintenance mode changed", "on", on)
+	}
+}
+
+// Maintenance returns true while maintenance mode is on.
+func (b *Bot) Maintenance() bool {
+	return b.maintenance.Load()
+}
+
+// skipForMaintenance returns true if cmd must not run because of
+// maintenance mode. The run is audited as a dry run, and unless quiet the
+// chat is shown commandLine, what would have run.
+func (b *Bot) skipForMaintenance(ctx context.Context, chatID int64, cmd pkgcmd.Command, commandLine string, quiet bool) bool {
+	if !b.Maintenance() {
+		return false
+	}
+	yamlCmd, ok := cmd.(*command.YAMLCommand)
+	if !ok || yamlCmd.ReadOnly() {
+		return false
+	}
+
+	slog.Info("dry run in maintenance mode", "chat_id", chatID, "command", cmd.Name())
+	if !quiet {
+		b.sendText(chatID, b.t(chatID, i18n.MaintenanceDryRun, cmd.Name(), commandLine))
+	}
+
+	entry := newAuditEntry(ctx, chatID, cmd.Name())
+	entry.Args = commandLine
+	entry.ExitCode = -1
+	entry.Status = audit.StatusDryRun
+	b.writeAudit(ctx, entry)
+	return true
+}
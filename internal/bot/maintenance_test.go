@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/config"
+)
+
+func TestSkipForMaintenance(t *testing.T) {
+	dir := t.TempDir()
+	defs := map[string]string{
+		"deploy.yaml": "name: deploy\ncommand: ./deploy.sh\n",
+		"uptime.yaml": "name: uptime\ncommand: uptime\nread_only: true\n",
+	}
+	for name, def := range defs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(def), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmds, err := command.NewLoader([]string{dir}, config.DefaultsConfig{}, nil).Load()
+	if err != nil || len(cmds) != 2 {
+		t.Fatalf("Load() = %v, %v", cmds, err)
+	}
+	byName := make(map[string]*command.YAMLCommand)
+	for _, cmd := range cmds {
+		byName[cmd.Name()] = cmd.(*command.YAMLCommand)
+	}
+	deploy, uptime := byName["deploy"], byName["uptime"]
+
+	rec := &auditRecorder{}
+	b := &Bot{auditLogger: rec, registry: command.NewRegistry()}
+	ctx := context.Background()
+
+	if b.skipForMaintenance(ctx, 1, deploy, "./deploy.sh", true) {
+		t.Error("skipped outside maintenance mode")
+	}
+
+	b.SetMaintenance(true)
+	if !b.Maintenance() {
+		t.Fatal("Maintenance() = false after SetMaintenance(true)")
+	}
+	if b.skipForMaintenance(ctx, 1, uptime, "uptime", true) {
+		t.Error("read_only command skipped")
+	}
+	if !b.skipForMaintenance(ctx, 1, deploy, "./deploy.sh", true) {
+		t.Error("command not skipped in maintenance mode")
+	}
+
+	if len(rec.entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(rec.entries))
+	}
+	if got := rec.entries[0]; got.Status != audit.StatusDryRun || got.Command != "deploy" || got.Args != "./deploy.sh" {
+		t.Errorf("dry run entry = %+v", got)
+	}
+}
@@ -2,12 +2,17 @@ package bot
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/config"
+	"github.com/rashpile/pako-telegram/internal/i18n"
 	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
@@ -26,15 +31,25 @@ const (
 	menuPrefix     = "menu:"
 	categoryPrefix = "cat:"
 	commandPrefix  = "cmd:"
+	groupPrefix    = "grp:"
 	cleanupPrefix  = "cleanup:"
+	cleanupConfirm = "confirm:" // Follows cleanupPrefix once a preview is accepted
 	schedPrefix    = "sched:"
 	backToMenu     = "menu:main"
+
+	// Menu page sizes; longer menus get Prev/Next buttons
+	categoriesPerPage = 8
+	commandsPerPage   = 10
 )
 
 // MenuBuilder creates inline keyboards for the interactive menu.
 type MenuBuilder struct {
 	registry       *command.Registry
 	cleanupEnabled bool
+
+	mu        sync.RWMutex
+	chatMenus map[int64]config.MenuConfig // Per-chat visibility (missing = everything)
+	lang      func(chatID int64) string
 }
 
 // NewMenuBuilder creates a menu builder.
@@ -47,22 +62,91 @@ func (m *MenuBuilder) SetCleanupEnabled(enabled bool) {
 	m.cleanupEnabled = enabled
 }
 
-// BuildMainMenu creates the main menu keyboard with category buttons.
-func (m *MenuBuilder) BuildMainMenu() (string, tgbotapi.InlineKeyboardMarkup) {
+// SetChatMenus replaces the per-chat menu layouts.
+func (m *MenuBuilder) SetChatMenus(menus map[int64]config.MenuConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.chatMenus = menus
+}
+
+// SetLanguage sets the function choosing each chat's message language.
+func (m *MenuBuilder) SetLanguage(fn func(chatID int64) string) {
+	m.lang = fn
+}
+
+// language returns the chat's message language.
+func (m *MenuBuilder) language(chatID int64) string {
+	if m.lang == nil {
+		return i18n.Default
+	}
+	return m.lang(chatID)
+}
+
+// visibleCategories returns the chat's categories with only the commands
+// its menu layout shows. Categories left empty are dropped.
+func (m *MenuBuilder) visibleCategories(chatID int64) []command.CategoryWithCommands {
 	categories := m.registry.Categories()
 
-	var rows [][]tgbotapi.InlineKeyboardButton
-	var row []tgbotapi.InlineKeyboardButton
+	m.mu.RLock()
+	layout, ok := m.chatMenus[chatID]
+	m.mu.RUnlock()
+	if !ok {
+		return categories
+	}
 
+	var visible []command.CategoryWithCommands
 	for _, cat := range categories {
-		label := cat.Name
-		if cat.Icon != "" {
-			label = cat.Icon + " " + cat.Name
+		var cmds []pkgcmd.Command
+		for _, cmd := range cat.Commands {
+			if layout.Shows(cat.Name, cmd.Name()) {
+				cmds = append(cmds, cmd)
+			}
+		}
+		if len(cmds) > 0 {
+			cat.Commands = cmds
+			visible = append(visible, cat)
+		}
+	}
+	return visible
+}
+
+// visibleCommands returns the commands of a category shown in the chat's menu.
+func (m *MenuBuilder) visibleCommands(chatID int64, categoryName string) []pkgcmd.Command {
+	for _, cat := range m.visibleCategories(chatID) {
+		if cat.Name == categoryName {
+			return cat.Commands
+		}
+	}
+	return nil
+}
+
+// HasCategory returns true if the chat's menu has the category, either with
+// commands of its own or as the parent of sub-categories.
+func (m *MenuBuilder) HasCategory(chatID int64, name string) bool {
+	for _, cat := range m.visibleCategories(chatID) {
+		if cat.Name == name || strings.HasPrefix(cat.Name, name+"/") {
+			return true
 		}
-		// Capitalize the category name
-		label = capitalize(label)
+	}
+	return false
+}
+
+// BuildMainMenu creates the first page of the chat's main menu.
+func (m *MenuBuilder) BuildMainMenu(chatID int64) (string, tgbotapi.InlineKeyboardMarkup) {
+	return m.BuildMainMenuPage(chatID, 0)
+}
+
+// BuildMainMenuPage creates the main menu keyboard with category buttons.
+// page is zero-based and clamped to the valid range.
+func (m *MenuBuilder) BuildMainMenuPage(chatID int64, page int) (string, tgbotapi.InlineKeyboardMarkup) {
+	nodes := m.childCategories(m.visibleCategories(chatID), "")
+	start, end, page, pages := pageBounds(len(nodes), categoriesPerPage, page)
 
-		btn := tgbotapi.NewInlineKeyboardButtonData(label, categoryPrefix+cat.Name)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+
+	for _, node := range nodes[start:end] {
+		btn := tgbotapi.NewInlineKeyboardButtonData(node.buttonLabel(), categoryPrefix+node.Name)
 		row = append(row, btn)
 
 		// 2 buttons per row
@@ -77,68 +161,251 @@ func (m *MenuBuilder) BuildMainMenu() (string, tgbotapi.InlineKeyboardMarkup) {
 		rows = append(rows, row)
 	}
 
+	lang := m.language(chatID)
+	if pager := pagerRow(lang, page, pages, MainMenuPageData); pager != nil {
+		rows = append(rows, pager)
+	}
+
 	// Add cleanup button if enabled
 	if m.cleanupEnabled {
-		cleanupBtn := tgbotapi.NewInlineKeyboardButtonData("🗑️ Cleanup", commandPrefix+"cleanup")
+		cleanupBtn := tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.CleanupButton), commandPrefix+"cleanup")
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{cleanupBtn})
 	}
 
-	text := "Select a category:"
+	text := i18n.T(lang, i18n.SelectCategory)
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
 	return text, keyboard
 }
 
-// BuildCategoryMenu creates a keyboard showing commands in a category.
-func (m *MenuBuilder) BuildCategoryMenu(categoryName string) (string, tgbotapi.InlineKeyboardMarkup) {
-	cmds := m.registry.ByCategory(categoryName)
+// BuildCategoryMenu creates the first page of a category menu.
+func (m *MenuBuilder) BuildCategoryMenu(chatID int64, categoryName string) (string, tgbotapi.InlineKeyboardMarkup) {
+	return m.BuildCategoryMenuPage(chatID, categoryName, 0)
+}
+
+// BuildCategoryMenuPage creates a keyboard showing a category's
+// sub-categories followed by its commands visible in the chat.
+// page is zero-based and clamped to the valid range.
+func (m *MenuBuilder) BuildCategoryMenuPage(chatID int64, categoryName string, page int) (string, tgbotapi.InlineKeyboardMarkup) {
+	categories := m.visibleCategories(chatID)
+
+	var items []tgbotapi.InlineKeyboardButton
+	for _, node := range m.childCategories(categories, categoryName) {
+		items = append(items, tgbotapi.NewInlineKeyboardButtonData("📂 "+node.buttonLabel(), categoryPrefix+node.Name))
+	}
+
+	for _, cmd := range m.visibleCommands(chatID, categoryName) {
+		items = append(items, commandButton(cmd))
+	}
+
+	start, end, page, pages := pageBounds(len(items), commandsPerPage, page)
 
 	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, btn := range items[start:end] {
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{btn})
+	}
 
-	for _, cmd := range cmds {
-		label := "/" + cmd.Name()
+	pageData := func(p int) string { return CategoryPageData(categoryName, p) }
+	lang := m.language(chatID)
+	if pager := pagerRow(lang, page, pages, pageData); pager != nil {
+		rows = append(rows, pager)
+	}
 
-		// Add icon if available
-		if withCat, ok := cmd.(pkgcmd.WithCategory); ok {
-			info := withCat.Category()
-			if info.Icon != "" {
-				label = info.Icon + " " + label
-			}
+	// Back button: to the parent category, or the main menu at the top level
+	trail := m.categoryTrail(categories, categoryName)
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.BackToMenu), backToMenu)
+	if len(trail) > 1 {
+		parent := trail[len(trail)-2]
+		backBtn = tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.BackTo, capitalize(parent.Label)), categoryPrefix+parent.Name)
+	}
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{backBtn})
+
+	// Build header text as a breadcrumb, e.g. "🐳 Docker › Prod"
+	labels := make([]string, len(trail))
+	for i, node := range trail {
+		labels[i] = capitalize(node.Label)
+	}
+	header := strings.Join(labels, " › ")
+	if icon := trail[len(trail)-1].Icon; icon != "" {
+		header = icon + " " + header
+	}
+
+	text := i18n.T(lang, i18n.CategoryCommands, header)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	return text, keyboard
+}
+
+// commandButton returns the menu button running cmd, or opening its
+// subcommands if it has any.
+func commandButton(cmd pkgcmd.Command) tgbotapi.InlineKeyboardButton {
+	label := "/" + cmd.Name()
+
+	// Add icon if available
+	if withCat, ok := cmd.(pkgcmd.WithCategory); ok {
+		info := withCat.Category()
+		if info.Icon != "" {
+			label = info.Icon + " " + label
 		}
+	}
+
+	if len(pkgcmd.Subcommands(cmd)) > 0 {
+		return tgbotapi.NewInlineKeyboardButtonData(label+" ›", groupPrefix+cmd.Name())
+	}
 
-		// Add warning for confirmation-required commands
-		if withMeta, ok := cmd.(pkgcmd.WithMetadata); ok {
-			if withMeta.Metadata().RequireConfirm {
-				label += " (!)"
+	// Add warning for confirmation-required commands
+	if withMeta, ok := cmd.(pkgcmd.WithMetadata); ok {
+		if withMeta.Metadata().RequireConfirm {
+			label += " (!)"
+		}
+	}
+
+	return tgbotapi.NewInlineKeyboardButtonData(label, commandPrefix+cmd.Name())
+}
+
+// BuildGroupMenu creates the first page of a command's subcommand menu.
+func (m *MenuBuilder) BuildGroupMenu(chatID int64, name string) (string, tgbotapi.InlineKeyboardMarkup, bool) {
+	return m.BuildGroupMenuPage(chatID, name, 0)
+}
+
+// BuildGroupMenuPage creates a keyboard with the listed subcommands of the
+// command name, if the chat's menu shows it. page is zero-based and
+// clamped to the valid range.
+func (m *MenuBuilder) BuildGroupMenuPage(chatID int64, name string, page int) (string, tgbotapi.InlineKeyboardMarkup, bool) {
+	var group pkgcmd.Command
+	var categoryName string
+	for _, cat := range m.visibleCategories(chatID) {
+		for _, cmd := range cat.Commands {
+			if cmd.Name() == name && len(pkgcmd.Subcommands(cmd)) > 0 {
+				group, categoryName = cmd, cat.Name
 			}
 		}
+	}
+	if group == nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, false
+	}
+
+	var items []tgbotapi.InlineKeyboardButton
+	for _, sub := range pkgcmd.Subcommands(group) {
+		if !pkgcmd.IsHidden(sub) && !pkgcmd.IsDisabled(sub) {
+			items = append(items, commandButton(sub))
+		}
+	}
+
+	start, end, page, pages := pageBounds(len(items), commandsPerPage, page)
 
-		btn := tgbotapi.NewInlineKeyboardButtonData(label, commandPrefix+cmd.Name())
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, btn := range items[start:end] {
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{btn})
 	}
 
-	// Back button
-	backBtn := tgbotapi.NewInlineKeyboardButtonData("<< Back to Menu", backToMenu)
+	pageData := func(p int) string { return GroupPageData(name, p) }
+	lang := m.language(chatID)
+	if pager := pagerRow(lang, page, pages, pageData); pager != nil {
+		rows = append(rows, pager)
+	}
+
+	// Back to the category the command is listed in
+	parent := m.nodeFor(m.visibleCategories(chatID), categoryName)
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.BackTo, capitalize(parent.Label)), categoryPrefix+categoryName)
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{backBtn})
 
-	// Build header text with category info
-	icon := ""
-	for _, cat := range m.registry.Categories() {
-		if cat.Name == categoryName {
-			icon = cat.Icon
-			break
+	text := fmt.Sprintf("/%s - %s", group.Name(), group.Description())
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...), true
+}
+
+// categoryNode is one level of the category tree, e.g. "docker" or
+// "docker/prod". Intermediate levels need not have commands of their own.
+type categoryNode struct {
+	Name  string // Full path
+	Label string
+	Icon  string
+	Order int
+}
+
+// buttonLabel returns the capitalized label with the icon, if any.
+func (n categoryNode) buttonLabel() string {
+	label := capitalize(n.Label)
+	if n.Icon != "" {
+		label = n.Icon + " " + label
+	}
+	return label
+}
+
+// nodeFor describes the category at path. Label, icon and order come from
+// the category's commands or config, falling back to the last path segment.
+func (m *MenuBuilder) nodeFor(categories []command.CategoryWithCommands, path string) categoryNode {
+	node := categoryNode{Name: path, Label: path[strings.LastIndex(path, "/")+1:]}
+	for _, cat := range categories {
+		if cat.Name == path {
+			node.Icon = cat.Icon
+			node.Order = cat.Order
+			if cat.DisplayName != "" {
+				node.Label = cat.DisplayName
+			}
+			return node
+		}
+	}
+	// Intermediate level without commands of its own
+	if cfg, ok := m.registry.CategoryConfig(path); ok {
+		node.Icon = cfg.Icon
+		node.Order = cfg.Order
+		if cfg.DisplayName != "" {
+			node.Label = cfg.DisplayName
 		}
 	}
+	return node
+}
 
-	header := capitalize(categoryName)
-	if icon != "" {
-		header = icon + " " + header
+// childCategories returns the direct sub-categories of parent ("" for the
+// top level) sorted by order, then name, like the registry's categories.
+func (m *MenuBuilder) childCategories(categories []command.CategoryWithCommands, parent string) []categoryNode {
+	var nodes []categoryNode
+	seen := make(map[string]bool)
+	for _, cat := range categories {
+		rest := cat.Name
+		if parent != "" {
+			var ok bool
+			if rest, ok = strings.CutPrefix(cat.Name, parent+"/"); !ok {
+				continue
+			}
+		}
+		segment, _, _ := strings.Cut(rest, "/")
+		path := segment
+		if parent != "" {
+			path = parent + "/" + segment
+		}
+		if segment == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		nodes = append(nodes, m.nodeFor(categories, path))
 	}
 
-	text := fmt.Sprintf("%s commands:\n\nTap a command to run it.", header)
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	// Categories arrive sorted; only intermediate levels may be out of place
+	_, otherConfigured := m.registry.CategoryConfig("other")
+	sort.SliceStable(nodes, func(i, j int) bool {
+		iOther := nodes[i].Name == "other" && !otherConfigured
+		jOther := nodes[j].Name == "other" && !otherConfigured
+		if iOther != jOther {
+			return jOther
+		}
+		if nodes[i].Order != nodes[j].Order {
+			return nodes[i].Order < nodes[j].Order
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
 
-	return text, keyboard
+// categoryTrail returns the nodes from the top level down to path.
+func (m *MenuBuilder) categoryTrail(categories []command.CategoryWithCommands, path string) []categoryNode {
+	var trail []categoryNode
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		trail = append(trail, m.nodeFor(categories, strings.Join(segments[:i+1], "/")))
+	}
+	return trail
 }
 
 // BuildCommandConfirmMenu creates a confirmation menu for a command.
@@ -164,6 +431,68 @@ func (m *MenuBuilder) BuildCommandConfirmMenu(cmdName string) (string, tgbotapi.
 	return text, tgbotapi.InlineKeyboardMarkup{}, false
 }
 
+// pageBounds returns the item range for page (clamped to the valid range),
+// the clamped page and the number of pages.
+func pageBounds(total, perPage, page int) (start, end, clamped, pages int) {
+	pages = max(1, (total+perPage-1)/perPage)
+	clamped = max(0, min(page, pages-1))
+	start = clamped * perPage
+	end = min(start+perPage, total)
+	return start, end, clamped, pages
+}
+
+// pagerRow returns Prev / page indicator / Next buttons, or nil for a
+// single page. data builds the callback data for a page.
+func pagerRow(lang string, page, pages int, data func(page int) string) []tgbotapi.InlineKeyboardButton {
+	if pages <= 1 {
+		return nil
+	}
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.PrevPage), data(page-1)))
+	}
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", page+1, pages), data(page)))
+	if page < pages-1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.NextPage), data(page+1)))
+	}
+	return row
+}
+
+// MainMenuPageData creates callback data for a main menu page.
+func MainMenuPageData(page int) string {
+	if page == 0 {
+		return backToMenu
+	}
+	return backToMenu + ":" + strconv.Itoa(page)
+}
+
+// CategoryPageData creates callback data for a category menu page.
+func CategoryPageData(category string, page int) string {
+	if page == 0 {
+		return categoryPrefix + category
+	}
+	return categoryPrefix + category + ":" + strconv.Itoa(page)
+}
+
+// GroupPageData creates callback data for a subcommand menu page.
+func GroupPageData(name string, page int) string {
+	if page == 0 {
+		return groupPrefix + name
+	}
+	return groupPrefix + name + ":" + strconv.Itoa(page)
+}
+
+// ParsePage splits a trailing ":<page>" from a menu or category callback
+// value, e.g. "docker:2" -> ("docker", 2). Values without one are page 0.
+func ParsePage(value string) (string, int) {
+	if i := strings.LastIndex(value, ":"); i >= 0 {
+		if page, err := strconv.Atoi(value[i+1:]); err == nil && page >= 0 {
+			return value[:i], page
+		}
+	}
+	return value, 0
+}
+
 // ParseCallback extracts the type and value from a callback data string.
 func ParseCallback(data string) (callbackType, value string) {
 	if strings.HasPrefix(data, categoryPrefix) {
@@ -172,6 +501,9 @@ func ParseCallback(data string) (callbackType, value string) {
 	if strings.HasPrefix(data, commandPrefix) {
 		return "command", strings.TrimPrefix(data, commandPrefix)
 	}
+	if strings.HasPrefix(data, groupPrefix) {
+		return "group", strings.TrimPrefix(data, groupPrefix)
+	}
 	if strings.HasPrefix(data, cleanupPrefix) {
 		return "cleanup", strings.TrimPrefix(data, cleanupPrefix)
 	}
@@ -189,6 +521,7 @@ func IsMenuCallback(data string) bool {
 	return strings.HasPrefix(data, menuPrefix) ||
 		strings.HasPrefix(data, categoryPrefix) ||
 		strings.HasPrefix(data, commandPrefix) ||
+		strings.HasPrefix(data, groupPrefix) ||
 		strings.HasPrefix(data, cleanupPrefix)
 }
 
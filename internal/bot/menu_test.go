@@ -0,0 +1,238 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/config"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		value    string
+		wantName string
+		wantPage int
+	}{
+		{"main", "main", 0},
+		{"main:2", "main", 2},
+		{"docker", "docker", 0},
+		{"docker:10", "docker", 10},
+		{"a:b", "a:b", 0},
+		{"x:-1", "x:-1", 0},
+	}
+	for _, tt := range tests {
+		name, page := ParsePage(tt.value)
+		if name != tt.wantName || page != tt.wantPage {
+			t.Errorf("ParsePage(%q) = %q, %d; want %q, %d", tt.value, name, page, tt.wantName, tt.wantPage)
+		}
+	}
+}
+
+func TestCategoryMenuPages(t *testing.T) {
+	registry := command.NewRegistry()
+	for i := range 25 {
+		registry.Register(searchCmd{name: fmt.Sprintf("cmd%02d", i)})
+	}
+	menu := NewMenuBuilder(registry)
+
+	// Page 1 of 3: 10 commands, pager, back button
+	_, keyboard := menu.BuildCategoryMenuPage(1, "other", 1)
+	rows := keyboard.InlineKeyboard
+	if len(rows) != commandsPerPage+2 {
+		t.Fatalf("rows = %d, want %d", len(rows), commandsPerPage+2)
+	}
+	if got := *rows[0][0].CallbackData; got != commandPrefix+"cmd10" {
+		t.Errorf("first command = %q, want cmd10", got)
+	}
+	pager := rows[commandsPerPage]
+	want := []string{"other", "other:1", "other:2"}
+	if len(pager) != len(want) {
+		t.Fatalf("pager = %d buttons, want %d", len(pager), len(want))
+	}
+	for i, w := range want {
+		if got := *pager[i].CallbackData; got != categoryPrefix+w {
+			t.Errorf("pager[%d] = %q, want %q", i, got, categoryPrefix+w)
+		}
+	}
+	if pager[1].Text != "2/3" {
+		t.Errorf("indicator = %q, want 2/3", pager[1].Text)
+	}
+
+	// Out-of-range pages are clamped to the last page
+	_, keyboard = menu.BuildCategoryMenuPage(1, "other", 9)
+	if got := *keyboard.InlineKeyboard[0][0].CallbackData; got != commandPrefix+"cmd20" {
+		t.Errorf("clamped first command = %q, want cmd20", got)
+	}
+	if hasButton(keyboard, "Next ›") {
+		t.Error("last page should not have a Next button")
+	}
+}
+
+func TestMainMenuSinglePage(t *testing.T) {
+	registry := command.NewRegistry()
+	registry.Register(searchCmd{name: "status"})
+	_, keyboard := NewMenuBuilder(registry).BuildMainMenu(1)
+	if hasButton(keyboard, "1/1") || hasButton(keyboard, "Next ›") {
+		t.Error("single page menu should not have a pager")
+	}
+}
+
+func hasButton(keyboard tgbotapi.InlineKeyboardMarkup, text string) bool {
+	for _, row := range keyboard.InlineKeyboard {
+		for _, btn := range row {
+			if btn.Text == text {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestChatMenuLayouts(t *testing.T) {
+	registry := command.NewRegistry()
+	registry.Register(categorizedCmd{searchCmd{name: "deploy"}, "deploy"})
+	registry.Register(categorizedCmd{searchCmd{name: "rollback"}, "deploy"})
+	registry.Register(categorizedCmd{searchCmd{name: "disk"}, "diagnostics"})
+	registry.Register(categorizedCmd{searchCmd{name: "logs"}, "diagnostics"})
+
+	menu := NewMenuBuilder(registry)
+	menu.SetChatMenus(map[int64]config.MenuConfig{
+		-100: {Categories: []string{"diagnostics"}, Hide: []string{"logs"}},
+		-200: {Commands: []string{"rollback"}},
+	})
+
+	categoryNames := func(chatID int64) []string {
+		var names []string
+		for _, cat := range menu.visibleCategories(chatID) {
+			for _, cmd := range cat.Commands {
+				names = append(names, cat.Name+"/"+cmd.Name())
+			}
+		}
+		return names
+	}
+
+	tests := map[int64][]string{
+		-100: {"diagnostics/disk"},
+		-200: {"deploy/rollback"},
+		-300: {"deploy/deploy", "deploy/rollback", "diagnostics/disk", "diagnostics/logs"},
+	}
+	for chatID, want := range tests {
+		if got := categoryNames(chatID); !slices.Equal(got, want) {
+			t.Errorf("chat %d menu = %v, want %v", chatID, got, want)
+		}
+	}
+
+	_, keyboard := menu.BuildCategoryMenu(-100, "deploy")
+	if hasButton(keyboard, "/deploy") {
+		t.Error("hidden category should have no command buttons")
+	}
+}
+
+func TestNestedCategories(t *testing.T) {
+	registry := command.NewRegistry()
+	registry.SetCategories(map[string]config.CategoryConfig{
+		"docker":         {Icon: "🐳", Order: 1},
+		"docker/staging": {Order: -1},
+	})
+	registry.Register(categorizedCmd{searchCmd{name: "ps"}, "docker"})
+	registry.Register(categorizedCmd{searchCmd{name: "prod-restart"}, "docker/prod"})
+	registry.Register(categorizedCmd{searchCmd{name: "stage-restart"}, "docker/staging"})
+	registry.Register(categorizedCmd{searchCmd{name: "disk"}, "system"})
+	menu := NewMenuBuilder(registry)
+
+	// Sub-categories only appear under their parent
+	_, keyboard := menu.BuildMainMenu(1)
+	if !hasButton(keyboard, "🐳 Docker") || !hasButton(keyboard, "System") || hasButton(keyboard, "Prod") {
+		t.Errorf("main menu = %v", keyboard.InlineKeyboard)
+	}
+
+	// Sub-categories by order, before the category's own commands
+	_, keyboard = menu.BuildCategoryMenu(1, "docker")
+	var labels []string
+	for _, row := range keyboard.InlineKeyboard {
+		labels = append(labels, row[0].Text)
+	}
+	want := []string{"📂 Staging", "📂 Prod", "/ps", "<< Back to Menu"}
+	if !slices.Equal(labels, want) {
+		t.Errorf("docker menu = %v, want %v", labels, want)
+	}
+
+	text, keyboard := menu.BuildCategoryMenu(1, "docker/prod")
+	if text != "Docker › Prod commands:\n\nTap a command to run it." {
+		t.Errorf("header = %q", text)
+	}
+	if !hasButton(keyboard, "/prod-restart") || !hasButton(keyboard, "<< Back to Docker") {
+		t.Errorf("docker/prod menu = %v", keyboard.InlineKeyboard)
+	}
+
+	// A parent without commands of its own still shows in the main menu
+	registry.Unregister("ps")
+	_, keyboard = menu.BuildMainMenu(1)
+	if !hasButton(keyboard, "🐳 Docker") {
+		t.Errorf("main menu = %v", keyboard.InlineKeyboard)
+	}
+	if !menu.HasCategory(1, "docker") || !menu.HasCategory(1, "docker/prod") || menu.HasCategory(1, "dock") {
+		t.Error("HasCategory mismatch")
+	}
+}
+
+// categorizedCmd adds a category to searchCmd.
+type categorizedCmd struct {
+	searchCmd
+	category string
+}
+
+func (c categorizedCmd) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{Name: c.category}
+}
+
+// groupCmd is a namespace of subcommands for menu tests.
+type groupCmd struct {
+	searchCmd
+	subs []pkgcmd.Command
+}
+
+func (c groupCmd) Subcommands() []pkgcmd.Command { return c.subs }
+
+func TestSubcommandMenu(t *testing.T) {
+	registry := command.NewRegistry()
+	registry.Register(groupCmd{
+		searchCmd: searchCmd{name: "docker", desc: "Docker"},
+		subs: []pkgcmd.Command{
+			searchCmd{name: "docker ps"},
+			searchCmd{name: "docker prune", hidden: true},
+			searchCmd{name: "docker restart"},
+		},
+	})
+	menu := NewMenuBuilder(registry)
+
+	_, keyboard := menu.BuildCategoryMenu(1, "other")
+	if got := *keyboard.InlineKeyboard[0][0].CallbackData; got != groupPrefix+"docker" {
+		t.Errorf("group button = %q, want %q", got, groupPrefix+"docker")
+	}
+
+	_, keyboard, ok := menu.BuildGroupMenu(1, "docker")
+	if !ok {
+		t.Fatal("group menu not built")
+	}
+	var got []string
+	for _, row := range keyboard.InlineKeyboard {
+		got = append(got, *row[0].CallbackData)
+	}
+	want := []string{commandPrefix + "docker ps", commandPrefix + "docker restart", categoryPrefix + "other"}
+	if !slices.Equal(got, want) {
+		t.Errorf("buttons = %q, want %q", got, want)
+	}
+
+	if _, _, ok := menu.BuildGroupMenu(1, "missing"); ok {
+		t.Error("menu built for unknown command")
+	}
+	if kind, value := ParseCallback(GroupPageData("docker", 1)); kind != "group" || value != "docker:1" {
+		t.Errorf("ParseCallback() = %q, %q", kind, value)
+	}
+}
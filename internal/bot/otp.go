@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// otpTTL is how long the bot waits for a one-time code.
+	otpTTL = 2 * time.Minute
+
+	// otpMaxAttempts is how many wrong codes are accepted before giving up.
+	otpMaxAttempts = 3
+)
+
+// pendingOTP is a command waiting for the requester's one-time code.
+type pendingOTP struct {
+	UserID    int64
+	Command   string
+	ExpiresAt time.Time
+	Attempts  int
+	resume    func() // Runs the command once verified
+}
+
+// OTPManager tracks commands awaiting a TOTP code, one per chat.
+type OTPManager struct {
+	mu      sync.Mutex
+	pending map[int64]*pendingOTP // key: chat ID
+}
+
+// NewOTPManager creates an OTP manager.
+func NewOTPManager() *OTPManager {
+	return &OTPManager{pending: make(map[int64]*pendingOTP)}
+}
+
+// Count returns the number of commands awaiting a code.
+func (m *OTPManager) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending)
+}
+
+// Start records a command waiting for userID's code, replacing any previous one.
+func (m *OTPManager) Start(chatID, userID int64, cmdName string, resume func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pending[chatID] = &pendingOTP{
+		UserID:    userID,
+		Command:   cmdName,
+		ExpiresAt: time.Now().Add(otpTTL),
+		resume:    resume,
+	}
+}
+
+// Waiting returns true if the chat has a pending code request from userID.
+func (m *OTPManager) Waiting(chatID, userID int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.pending[chatID]
+	return ok && p.UserID == userID
+}
+
+// Take removes and returns the chat's pending request. Expired requests
+// are removed and reported as nil.
+func (m *OTPManager) Take(chatID int64) *pendingOTP {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.pending[chatID]
+	if !ok {
+		return nil
+	}
+	delete(m.pending, chatID)
+	if time.Now().After(p.ExpiresAt) {
+		return nil
+	}
+	return p
+}
+
+// Retry puts back a request after a wrong code. Returns false once the
+// attempt limit is reached. A newer request started since Take is kept.
+func (m *OTPManager) Retry(chatID int64, p *pendingOTP) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p.Attempts++
+	if p.Attempts >= otpMaxAttempts {
+		return false
+	}
+	if cur, ok := m.pending[chatID]; ok && cur != p {
+		return true
+	}
+	m.pending[chatID] = p
+	return true
+}
+
+// Cancel drops the chat's pending request. Returns true if there was one.
+func (m *OTPManager) Cancel(chatID int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.pending[chatID]
+	delete(m.pending, chatID)
+	return ok
+}
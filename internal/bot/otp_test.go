@@ -0,0 +1,34 @@
+package bot
+
+import "testing"
+
+func TestOTPManagerRetry(t *testing.T) {
+	m := NewOTPManager()
+
+	m.Start(1, 7, "deploy", nil)
+	p := m.Take(1)
+	if p == nil {
+		t.Fatal("Take() = nil for a fresh request")
+	}
+	if !m.Retry(1, p) || !m.Waiting(1, 7) {
+		t.Fatal("Retry() didn't put the request back")
+	}
+
+	// A newer request started while the code was being checked wins
+	p = m.Take(1)
+	m.Start(1, 7, "restart", nil)
+	m.Retry(1, p)
+	if got := m.Take(1); got == nil || got.Command != "restart" {
+		t.Errorf("Retry() replaced the newer request, got %+v", got)
+	}
+
+	m.Start(1, 7, "deploy", nil)
+	for i := 1; i < otpMaxAttempts; i++ {
+		if !m.Retry(1, m.Take(1)) {
+			t.Fatalf("Retry() = false after %d wrong codes", i)
+		}
+	}
+	if m.Retry(1, m.Take(1)) || m.Waiting(1, 7) {
+		t.Error("Retry() kept the request past the attempt limit")
+	}
+}
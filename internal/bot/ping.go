@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/msgstore"
+)
+
+// handlePingCommand answers /ping with the Telegram API round-trip time and
+// the update lag, the time between the message being sent and the bot
+// handling it. A slow round trip points at Telegram or the network; a long
+// lag with a fast round trip points at the bot.
+func (b *Bot) handlePingCommand(msg *tgbotapi.Message) {
+	received := time.Now()
+	chatID := msg.Chat.ID
+
+	if !b.authorizer.IsAllowed(chatID) {
+		b.logUnauthorized(chatID, msg.From, "ping")
+		b.rejectChat(chatID)
+		return
+	}
+	if b.rejectUser(chatID, msg.From, "ping", true) {
+		return
+	}
+	b.trackUserCommand(msg)
+
+	start := time.Now()
+	sent, err := b.api.Send(tgbotapi.NewMessage(chatID, b.t(chatID, i18n.Pong)))
+	if err != nil {
+		slog.Error("failed to send message", "error", err, "chat_id", chatID)
+		return
+	}
+	rtt := time.Since(start)
+	b.trackMessage(chatID, sent.MessageID, msgstore.TypeText)
+
+	lag := updateLag(msg.Time(), received)
+	slog.Debug("ping", "chat_id", chatID, "rtt", rtt, "lag", lag)
+	edit := tgbotapi.NewEditMessageText(chatID, sent.MessageID, b.t(chatID, i18n.PingResult, rtt.Round(time.Millisecond), lag))
+	b.api.Send(edit)
+}
+
+// updateLag returns how long after sent a message was received, to the
+// second as message dates have no finer resolution. Clock skew can make
+// the difference negative; that counts as no lag.
+func updateLag(sent, received time.Time) time.Duration {
+	return max(received.Sub(sent).Truncate(time.Second), 0)
+}
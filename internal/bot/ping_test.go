@@ -0,0 +1,23 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateLag(t *testing.T) {
+	sent := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		received time.Time
+		want     time.Duration
+	}{
+		{sent.Add(300 * time.Millisecond), 0},
+		{sent.Add(2500 * time.Millisecond), 2 * time.Second},
+		{sent.Add(-time.Second), 0},
+	}
+	for _, tt := range tests {
+		if got := updateLag(sent, tt.received); got != tt.want {
+			t.Errorf("updateLag(%s) = %s, want %s", tt.received.Sub(sent), got, tt.want)
+		}
+	}
+}
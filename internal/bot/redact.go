@@ -0,0 +1,18 @@
+package bot
+
+import (
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/redact"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// redactRules returns the rules masking secrets in cmd's output and audit
+// log arguments: a YAML command's own, which include defaults.redact, or
+// defaults.redact for other commands and nil.
+func (b *Bot) redactRules(cmd pkgcmd.Command) redact.Rules {
+	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok {
+		return yamlCmd.Redactions()
+	}
+	rules, _ := redact.Compile(b.currentDefaults().Redact) // Validated when the config is loaded
+	return rules
+}
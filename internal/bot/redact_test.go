@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/config"
+)
+
+func TestAuditRedaction(t *testing.T) {
+	dir := t.TempDir()
+	def := "name: connect\ncommand: psql\nredact:\n  - 'postgres://[^:]+:([^@]+)@'\n"
+	if err := os.WriteFile(filepath.Join(dir, "connect.yaml"), []byte(def), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defaults := config.DefaultsConfig{Redact: []string{`ghp_\w+`}}
+	cmds, err := command.NewLoader([]string{dir}, defaults, nil).Load()
+	if err != nil || len(cmds) != 1 {
+		t.Fatalf("Load() = %v, %v", cmds, err)
+	}
+
+	registry := command.NewRegistry()
+	registry.Register(cmds[0])
+	rec := &auditRecorder{}
+	b := &Bot{auditLogger: rec, registry: registry, defaults: defaults}
+
+	b.writeAudit(context.Background(), audit.Entry{Command: "connect", Args: "postgres://app:s3cr3t@db ghp_abc"})
+	b.writeAudit(context.Background(), audit.Entry{Command: "status", Args: "ghp_abc postgres://app:s3cr3t@db"})
+
+	if got := rec.entries[0].Args; got != "postgres://app:****@db ****" {
+		t.Errorf("command args = %q", got)
+	}
+	// Commands without their own rules get defaults.redact only
+	if got := rec.entries[1].Args; got != "**** postgres://app:s3cr3t@db" {
+		t.Errorf("built-in args = %q", got)
+	}
+}
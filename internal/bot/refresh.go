@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/i18n"
+)
+
+// maxTrackedMenus bounds how many open menus per chat are refreshed after a
+// reload; older ones fall back to the "menu outdated" response.
+const maxTrackedMenus = 10
+
+// menuTracker remembers which view (callback data such as "menu:main" or
+// "cat:docker:1") each open menu message shows, so menus can be redrawn
+// when commands are reloaded.
+type menuTracker struct {
+	mu    sync.Mutex
+	chats map[int64]map[int]string // chatID -> messageID -> view
+}
+
+// set records that a menu message shows view.
+func (t *menuTracker) set(chatID int64, messageID int, view string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.chats == nil {
+		t.chats = make(map[int64]map[int]string)
+	}
+	menus := t.chats[chatID]
+	if menus == nil {
+		menus = make(map[int]string)
+		t.chats[chatID] = menus
+	}
+	menus[messageID] = view
+
+	// Message IDs increase over time, so the smallest is the oldest
+	for len(menus) > maxTrackedMenus {
+		oldest := messageID
+		for id := range menus {
+			oldest = min(oldest, id)
+		}
+		delete(menus, oldest)
+	}
+}
+
+// remove forgets a message that no longer shows a menu.
+func (t *menuTracker) remove(chatID int64, messageID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.chats[chatID], messageID)
+	if len(t.chats[chatID]) == 0 {
+		delete(t.chats, chatID)
+	}
+}
+
+// trackedMenu is an open menu message.
+type trackedMenu struct {
+	chatID    int64
+	messageID int
+	view      string
+}
+
+// all returns the open menus, ordered by chat and message.
+func (t *menuTracker) all() []trackedMenu {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var menus []trackedMenu
+	for chatID, views := range t.chats {
+		for messageID, view := range views {
+			menus = append(menus, trackedMenu{chatID, messageID, view})
+		}
+	}
+	sort.Slice(menus, func(i, j int) bool {
+		if menus[i].chatID != menus[j].chatID {
+			return menus[i].chatID < menus[j].chatID
+		}
+		return menus[i].messageID < menus[j].messageID
+	})
+	return menus
+}
+
+// renderMenu builds the menu for a "menu:" or "cat:" view. ok is false if
+// the view no longer exists, e.g. its category was removed by a reload.
+func (b *Bot) renderMenu(chatID int64, view string) (text string, keyboard tgbotapi.InlineKeyboardMarkup, ok bool) {
+	callbackType, value := ParseCallback(view)
+	switch callbackType {
+	case "menu":
+		_, page := ParsePage(value)
+		text, keyboard = b.menuBuilder.BuildMainMenuPage(chatID, page)
+		return text, keyboard, true
+	case "category":
+		name, page := ParsePage(value)
+		if !b.menuBuilder.HasCategory(chatID, name) {
+			return "", keyboard, false
+		}
+		text, keyboard = b.menuBuilder.BuildCategoryMenuPage(chatID, name, page)
+		return text, keyboard, true
+	case "group":
+		name, page := ParsePage(value)
+		return b.menuBuilder.BuildGroupMenuPage(chatID, name, page)
+	}
+	return "", keyboard, false
+}
+
+// showOutdatedMenu replaces a stale menu with a prompt to refresh it.
+func (b *Bot) showOutdatedMenu(chatID int64, messageID int) {
+	b.menus.remove(chatID, messageID)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(chatID, i18n.Refresh), backToMenu),
+	))
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, b.t(chatID, i18n.MenuOutdated), keyboard)
+	if _, err := b.api.Send(edit); err != nil {
+		slog.Warn("failed to mark menu outdated", "chat_id", chatID, "error", err)
+	}
+}
+
+// RefreshMenus redraws open menus after commands or menu settings are
+// reloaded. Menus whose category disappeared fall back to the main menu;
+// menus that can't be edited (e.g. deleted) are forgotten.
+func (b *Bot) RefreshMenus() {
+	for _, menu := range b.menus.all() {
+		text, keyboard, ok := b.renderMenu(menu.chatID, menu.view)
+		if !ok {
+			menu.view = backToMenu
+			text, keyboard = b.menuBuilder.BuildMainMenu(menu.chatID)
+		}
+
+		edit := tgbotapi.NewEditMessageTextAndMarkup(menu.chatID, menu.messageID, text, keyboard)
+		if _, err := b.api.Send(edit); err != nil {
+			// Telegram rejects edits that change nothing; the menu is still current
+			if strings.Contains(err.Error(), "message is not modified") {
+				continue
+			}
+			slog.Debug("forgetting menu that can't be refreshed", "chat_id", menu.chatID, "message_id", menu.messageID, "error", err)
+			b.menus.remove(menu.chatID, menu.messageID)
+			continue
+		}
+		b.menus.set(menu.chatID, menu.messageID, menu.view)
+	}
+}
@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"testing"
+)
+
+func TestMenuTracker(t *testing.T) {
+	var tracker menuTracker
+	tracker.set(1, 10, backToMenu)
+	tracker.set(1, 11, "cat:docker")
+	tracker.set(2, 5, backToMenu)
+	tracker.set(1, 10, "cat:system:1") // Navigation updates the view
+	tracker.remove(1, 11)
+
+	got := tracker.all()
+	want := []trackedMenu{{1, 10, "cat:system:1"}, {2, 5, backToMenu}}
+	if len(got) != len(want) {
+		t.Fatalf("all() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("all()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMenuTrackerDropsOldest(t *testing.T) {
+	var tracker menuTracker
+	for id := 1; id <= maxTrackedMenus+2; id++ {
+		tracker.set(1, id, backToMenu)
+	}
+
+	menus := tracker.all()
+	if len(menus) != maxTrackedMenus {
+		t.Fatalf("tracked %d menus, want %d", len(menus), maxTrackedMenus)
+	}
+	if menus[0].messageID != 3 {
+		t.Errorf("oldest tracked menu = %d, want 3", menus[0].messageID)
+	}
+}
@@ -0,0 +1,16 @@
+package bot
+
+import (
+	"github.com/rashpile/pako-telegram/internal/status"
+)
+
+// SelfStats reports the bot's own resource usage and activity for /status.
+func (b *Bot) SelfStats() status.SelfStats {
+	stats := status.RuntimeStats(b.started)
+	stats.Commands = len(b.registry.All())
+	stats.Sessions = b.argCollector.Count() + b.confirmMgr.Count() + b.otpMgr.Count() + b.pinMgr.Count()
+	if b.scheduler != nil {
+		stats.ScheduledJobs = len(b.scheduler.ListActive())
+	}
+	return stats
+}
@@ -0,0 +1,303 @@
+package bot
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/msgstore"
+	"github.com/rashpile/pako-telegram/internal/settings"
+)
+
+// Callback data for /settings buttons.
+const (
+	settingsPrefix     = "set:"
+	settingsLangPrefix = "set:lang:"
+	settingsStartup    = "set:startup"
+	settingsOutput     = "set:output"
+	settingsQuiet      = "set:quiet"
+	settingsConfirm    = "set:confirm"
+)
+
+// quietPresets are the quiet hours the settings button cycles through.
+var quietPresets = []string{"", "22:00-07:00", "23:00-08:00", "00:00-08:00"}
+
+// outputLabels names the output formats in the settings menu.
+var outputLabels = map[string]i18n.Key{
+	settings.OutputCode:     i18n.OutputCode,
+	settings.OutputPlain:    i18n.OutputPlain,
+	settings.OutputMarkdown: i18n.OutputMarkdown,
+	settings.OutputHTML:     i18n.OutputHTML,
+}
+
+// errNoSettingsStore is reported when settings cannot be persisted.
+var errNoSettingsStore = errors.New("settings storage is not configured")
+
+// IsSettingsCallback checks if the callback is a /settings button.
+func IsSettingsCallback(data string) bool {
+	return strings.HasPrefix(data, settingsPrefix)
+}
+
+// handleSettingsCommand handles /settings (show the settings menu) and
+// /settings <name> <value>.
+func (b *Bot) handleSettingsCommand(msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+
+	if !b.authorizer.IsAllowed(chatID) {
+		b.logUnauthorized(chatID, msg.From, "settings")
+		b.rejectChat(chatID)
+		return
+	}
+	if b.rejectUser(chatID, msg.From, "settings", true) {
+		return
+	}
+	b.trackUserCommand(msg)
+
+	if fields := strings.Fields(msg.CommandArguments()); len(fields) > 0 {
+		if len(fields) < 2 {
+			b.sendText(chatID, b.t(chatID, i18n.SettingsUsage))
+			return
+		}
+		if !b.applySetting(chatID, fields[0], strings.Join(fields[1:], "")) {
+			return
+		}
+	}
+
+	text, keyboard := b.settingsMenu(chatID)
+	reply := tgbotapi.NewMessage(chatID, text)
+	reply.ReplyMarkup = keyboard
+	if sent, err := b.api.Send(reply); err == nil {
+		b.trackMessage(chatID, sent.MessageID, msgstore.TypePrompt)
+	}
+}
+
+// applySetting handles /settings <name> <value>, reporting invalid input to
+// the chat. It returns true if the setting was saved.
+func (b *Bot) applySetting(chatID int64, name, value string) bool {
+	value = strings.ToLower(value)
+
+	var key string
+	switch name {
+	case "language":
+		key = settings.Language
+		if value == "default" {
+			value = ""
+		}
+		if value != "" && !i18n.Supported(value) {
+			b.sendText(chatID, b.t(chatID, i18n.UnknownLanguage, value, strings.Join(i18n.Languages(), ", ")))
+			return false
+		}
+	case "startup":
+		key = settings.Startup
+		switch value {
+		case "on":
+			value = ""
+		case "off":
+		default:
+			key = ""
+		}
+	case "output":
+		key = settings.Output
+		if !slices.Contains(settings.OutputFormats, value) {
+			key = ""
+		} else if value == settings.OutputCode {
+			value = ""
+		}
+	case "quiet":
+		key = settings.QuietHours
+		if value == "off" {
+			value = ""
+		} else if hours, err := settings.ParseHours(value); err != nil {
+			key = ""
+		} else {
+			value = hours.String()
+		}
+	case "confirm":
+		key = settings.Confirm
+		switch value {
+		case "default":
+			value = ""
+		case settings.ConfirmAlways:
+		default:
+			key = ""
+		}
+	}
+	if key == "" {
+		b.sendText(chatID, b.t(chatID, i18n.SettingsUsage))
+		return false
+	}
+
+	if err := b.saveSetting(chatID, key, value); err != nil {
+		b.sendText(chatID, b.t(chatID, i18n.SettingsFailed, err))
+		return false
+	}
+	return true
+}
+
+// handleSettingsCallback applies a settings button press and redraws the menu.
+func (b *Bot) handleSettingsCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+
+	var key, value string
+	switch query.Data {
+	case settingsStartup:
+		key = settings.Startup
+		if b.chatSetting(chatID, key) == "" {
+			value = "off"
+		}
+	case settingsOutput:
+		key = settings.Output
+		value = nextOf(settings.OutputFormats, b.outputFormat(chatID))
+		if value == settings.OutputCode {
+			value = ""
+		}
+	case settingsQuiet:
+		key = settings.QuietHours
+		value = nextOf(quietPresets, b.chatSetting(chatID, key))
+	case settingsConfirm:
+		key = settings.Confirm
+		if b.chatSetting(chatID, key) == "" {
+			value = settings.ConfirmAlways
+		}
+	default:
+		key = settings.Language
+		value = strings.TrimPrefix(query.Data, settingsLangPrefix)
+		if !strings.HasPrefix(query.Data, settingsLangPrefix) || (value != "" && !i18n.Supported(value)) {
+			return
+		}
+	}
+
+	if err := b.saveSetting(chatID, key, value); err != nil {
+		b.sendText(chatID, b.t(chatID, i18n.SettingsFailed, err))
+		return
+	}
+
+	text, keyboard := b.settingsMenu(chatID)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID, text, keyboard)
+	b.api.Send(edit)
+}
+
+// nextOf returns the value after current in values, wrapping around. Unknown
+// values start over at the first.
+func nextOf(values []string, current string) string {
+	return values[(slices.Index(values, current)+1)%len(values)]
+}
+
+// saveSetting stores a chat's setting. An empty value restores the default.
+func (b *Bot) saveSetting(chatID int64, key, value string) error {
+	if b.settings == nil {
+		return errNoSettingsStore
+	}
+	if err := b.settings.Set(chatID, key, value); err != nil {
+		return err
+	}
+	slog.Info("chat setting changed", "chat_id", chatID, "key", key, "value", value)
+	return nil
+}
+
+// chatSetting returns a chat's stored setting, or "" if unset.
+func (b *Bot) chatSetting(chatID int64, key string) string {
+	if b.settings == nil {
+		return ""
+	}
+	return b.settings.Get(chatID, key)
+}
+
+// outputFormat returns how command output is displayed in the chat.
+func (b *Bot) outputFormat(chatID int64) string {
+	if format := b.chatSetting(chatID, settings.Output); format != "" {
+		return format
+	}
+	return settings.OutputCode
+}
+
+// silent reports whether the chat is in its quiet hours, when messages are
+// delivered without a notification sound.
+func (b *Bot) silent(chatID int64) bool {
+	hours, err := settings.ParseHours(b.chatSetting(chatID, settings.QuietHours))
+	return err == nil && hours.Contains(time.Now())
+}
+
+// confirmAll reports whether the chat asks for confirmation before every
+// command, not only those with confirm: true.
+func (b *Bot) confirmAll(chatID int64) bool {
+	return b.chatSetting(chatID, settings.Confirm) == settings.ConfirmAlways
+}
+
+// newStreamer creates an output streamer using the chat's output settings.
+func (b *Bot) newStreamer(chatID int64, quiet bool) *MessageStreamer {
+	streamer := NewMessageStreamer(b.api, chatID)
+	if quiet {
+		streamer = NewQuietMessageStreamer(b.api, chatID)
+	}
+	streamer.SetFormat(b.outputFormat(chatID))
+	streamer.SetSilent(b.silent(chatID))
+	return streamer
+}
+
+// settingsMenu builds the /settings text and buttons for a chat.
+func (b *Bot) settingsMenu(chatID int64) (string, tgbotapi.InlineKeyboardMarkup) {
+	lang := b.lang(chatID)
+	chosen := b.chatSetting(chatID, settings.Language)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, code := range i18n.Languages() {
+		label := i18n.Name(code)
+		if code == chosen {
+			label = "✓ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, settingsLangPrefix+code))
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	b.settingsMu.RLock()
+	configured := b.language
+	if l, ok := b.chatLanguages[chatID]; ok {
+		configured = l
+	}
+	b.settingsMu.RUnlock()
+
+	label := i18n.T(lang, i18n.LanguageDefault, i18n.Name(configured))
+	if chosen == "" {
+		label = "✓ " + label
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(label, settingsLangPrefix),
+	))
+
+	startup := i18n.T(lang, i18n.On)
+	if b.chatSetting(chatID, settings.Startup) == "off" {
+		startup = i18n.T(lang, i18n.Off)
+	}
+	output := i18n.T(lang, outputLabels[b.outputFormat(chatID)])
+	quiet := b.chatSetting(chatID, settings.QuietHours)
+	if quiet == "" {
+		quiet = i18n.T(lang, i18n.Off)
+	}
+	confirm := i18n.T(lang, i18n.ConfirmDefault)
+	if b.confirmAll(chatID) {
+		confirm = i18n.T(lang, i18n.ConfirmAlways)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.StartupButton, startup), settingsStartup)),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.OutputButton, output), settingsOutput)),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.QuietButton, quiet), settingsQuiet)),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, i18n.ConfirmButton, confirm), settingsConfirm)),
+	)
+
+	text := i18n.T(lang, i18n.SettingsMenu, i18n.Name(lang), startup, output, quiet, confirm)
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
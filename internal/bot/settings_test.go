@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/settings"
+)
+
+func TestLanguagePrecedence(t *testing.T) {
+	store, err := settings.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	b := &Bot{settings: store}
+	b.SetLanguages("", map[int64]string{2: "de", 3: "de"})
+	if err := b.saveSetting(3, settings.Language, "ru"); err != nil {
+		t.Fatalf("saveSetting() error = %v", err)
+	}
+
+	tests := []struct {
+		chatID int64
+		want   string
+	}{
+		{1, "en"}, // Default
+		{2, "de"}, // Configured
+		{3, "ru"}, // Chosen with /settings
+	}
+	for _, tt := range tests {
+		if got := b.lang(tt.chatID); got != tt.want {
+			t.Errorf("lang(%d) = %q, want %q", tt.chatID, got, tt.want)
+		}
+	}
+
+	// Clearing the choice restores the configured language
+	if err := b.saveSetting(3, settings.Language, ""); err != nil {
+		t.Fatalf("saveSetting() error = %v", err)
+	}
+	if got := b.lang(3); got != "de" {
+		t.Errorf("lang(3) after reset = %q, want de", got)
+	}
+}
+
+func TestSettingsMenu(t *testing.T) {
+	b := &Bot{}
+	b.SetLanguages("de", nil)
+
+	text, keyboard := b.settingsMenu(1)
+	want := "Einstellungen für diesen Chat\n\nSprache: Deutsch\nStart-Benachrichtigungen: an\nAusgabeformat: Codeblock\nRuhezeiten: aus\nBefehle bestätigen: wie konfiguriert"
+	if text != want {
+		t.Errorf("settingsMenu() text = %q", text)
+	}
+	rows := keyboard.InlineKeyboard
+	def := rows[len(rows)-5][0]
+	if def.Text != "✓ Standard (Deutsch)" || *def.CallbackData != settingsLangPrefix {
+		t.Errorf("default button = %q (%s)", def.Text, *def.CallbackData)
+	}
+	if quiet := rows[len(rows)-2][0]; *quiet.CallbackData != settingsQuiet {
+		t.Errorf("quiet hours button = %q (%s)", quiet.Text, *quiet.CallbackData)
+	}
+}
+
+func TestChatPreferences(t *testing.T) {
+	store, err := settings.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	b := &Bot{settings: store}
+	cmd := searchCmd{name: "uptime"}
+	if b.requiresConfirm(1, cmd) || b.outputFormat(1) != settings.OutputCode || b.silent(1) {
+		t.Fatal("unset settings should keep the defaults")
+	}
+
+	store.Set(1, settings.Confirm, settings.ConfirmAlways)
+	store.Set(1, settings.Output, settings.OutputPlain)
+	store.Set(1, settings.QuietHours, "00:00-23:59")
+	if !b.requiresConfirm(1, cmd) {
+		t.Error("confirm: always should confirm every command")
+	}
+	if got := b.outputFormat(1); got != settings.OutputPlain {
+		t.Errorf("outputFormat() = %q, want plain", got)
+	}
+	if !b.silent(1) && time.Now().Format("15:04") != "23:59" {
+		t.Error("silent() = false during quiet hours")
+	}
+}
+
+func TestNextOf(t *testing.T) {
+	tests := []struct {
+		current, want string
+	}{
+		{"", "22:00-07:00"},
+		{"22:00-07:00", "23:00-08:00"},
+		{"00:00-08:00", ""}, // Wraps around
+		{"01:00-02:00", ""}, // Custom hours start over
+	}
+	for _, tt := range tests {
+		if got := nextOf(quietPresets, tt.current); got != tt.want {
+			t.Errorf("nextOf(%q) = %q, want %q", tt.current, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/msgstore"
+)
+
+const (
+	// shellExitGrace is how long a shell gets to exit after its input is
+	// closed before it is killed.
+	shellExitGrace = 2 * time.Second
+
+	// shellInputQueue is how many messages may wait for the shell to read
+	// its input; more are dropped.
+	shellInputQueue = 32
+)
+
+// shellSession is a shell process bound to a chat and fed by the messages
+// of the admin who opened it.
+type shellSession struct {
+	chatID int64
+	user   *tgbotapi.User
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	idle   *time.Timer
+	done   chan struct{}          // Closed once the shell has exited
+	input  chan *tgbotapi.Message // Messages waiting to be sent to the shell
+
+	mu        sync.Mutex
+	out       *MessageStreamer        // Output since the latest input; nil until some arrives
+	newOutput func() *MessageStreamer // Starts the message showing output
+	closing   string                  // Why the session is being closed, for the final notice
+}
+
+// write shows output from the shell, starting a new message for the first
+// output after an input.
+func (s *shellSession) write(p []byte, stderr bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.out == nil {
+		s.out = s.newOutput()
+	}
+	if stderr {
+		return s.out.Stderr().Write(p)
+	}
+	return s.out.Write(p)
+}
+
+// flush shows output held back by message edit throttling. With next, the
+// following output starts a new message.
+func (s *shellSession) flush(next bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.out == nil {
+		return
+	}
+	s.out.Flush()
+	if next {
+		s.out = nil
+	}
+}
+
+// close ends the session: the shell's input is closed, and it is killed
+// unless it exits within shellExitGrace. Notice is what the chat is told.
+func (s *shellSession) close(notice string) {
+	s.mu.Lock()
+	if s.closing == "" {
+		s.closing = notice
+	}
+	s.mu.Unlock()
+
+	s.stdin.Close()
+	select {
+	case <-s.done:
+	case <-time.After(shellExitGrace):
+		s.cmd.Process.Kill()
+	}
+}
+
+// queue hands msg to the session's input writer without waiting. It returns
+// false if too many messages are waiting.
+func (s *shellSession) queue(msg *tgbotapi.Message) bool {
+	select {
+	case s.input <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// shellWriter writes a shell's stdout or stderr to its session.
+type shellWriter struct {
+	s      *shellSession
+	stderr bool
+}
+
+func (w shellWriter) Write(p []byte) (int, error) {
+	return w.s.write(p, w.stderr)
+}
+
+// shellSessions tracks the open shell session of each chat.
+type shellSessions struct {
+	mu       sync.Mutex
+	sessions map[int64]*shellSession
+}
+
+// get returns the chat's session, or nil.
+func (ss *shellSessions) get(chatID int64) *shellSession {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.sessions[chatID]
+}
+
+// add makes s the chat's session. It returns false if one is already open.
+func (ss *shellSessions) add(s *shellSession) bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.sessions[s.chatID] != nil {
+		return false
+	}
+	if ss.sessions == nil {
+		ss.sessions = make(map[int64]*shellSession)
+	}
+	ss.sessions[s.chatID] = s
+	return true
+}
+
+// remove forgets s if it is still its chat's session.
+func (ss *shellSessions) remove(s *shellSession) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.sessions[s.chatID] == s {
+		delete(ss.sessions, s.chatID)
+	}
+}
+
+// owns returns true if the chat has a session opened by the user.
+func (ss *shellSessions) owns(chatID, userID int64) bool {
+	s := ss.get(chatID)
+	return s != nil && s.user.ID == userID
+}
+
+// handleShellCommand handles /shell: opens a shell session in the chat for
+// the admin sending it, if shell.enabled is set.
+func (b *Bot) handleShellCommand(ctx context.Context, msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+
+	if !b.authorizer.IsAllowed(chatID) {
+		b.logUnauthorized(chatID, msg.From, "shell")
+		b.rejectChat(chatID)
+		return
+	}
+	if b.rejectUser(chatID, msg.From, "shell", true) {
+		return
+	}
+	b.trackUserCommand(msg)
+
+	if !b.shell.Enabled {
+		b.sendText(chatID, b.t(chatID, i18n.ShellDisabled))
+		return
+	}
+	// Without roles isAdmin lets everyone in
+	if msg.From == nil || b.roles == nil || !b.isAdmin(chatID, msg.From) {
+		b.logUnauthorized(chatID, msg.From, "shell")
+		b.sendText(chatID, b.t(chatID, i18n.ShellAdminOnly))
+		return
+	}
+	if b.Maintenance() {
+		b.sendText(chatID, b.t(chatID, i18n.ShellMaintenance))
+		return
+	}
+	if b.shells.get(chatID) != nil {
+		b.sendText(chatID, b.t(chatID, i18n.ShellAlreadyOpen))
+		return
+	}
+	b.openShell(withUser(ctx, msg.From), chatID, msg.From)
+}
+
+// openShell starts a shell session in the chat for user.
+func (b *Bot) openShell(ctx context.Context, chatID int64, user *tgbotapi.User) {
+	logger := slog.With("chat_id", chatID, "user_id", user.ID)
+
+	argv := strings.Fields(b.shell.Command)
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = b.shell.Workdir
+	cmd.WaitDelay = shellExitGrace // Don't wait on background children holding the output open
+
+	s := &shellSession{
+		chatID: chatID,
+		user:   user,
+		cmd:    cmd,
+		done:   make(chan struct{}),
+		input:  make(chan *tgbotapi.Message, shellInputQueue),
+		newOutput: func() *MessageStreamer {
+			streamer := b.newStreamer(chatID, false)
+			streamer.SetRedactRules(b.redactRules(nil))
+			if err := streamer.Start(ctx); err != nil {
+				logger.Error("failed to start streamer", "error", err)
+			} else if streamer.MessageID() != 0 {
+				b.trackMessage(chatID, streamer.MessageID(), msgstore.TypeText)
+			}
+			return streamer
+		},
+	}
+	cmd.Stdout = shellWriter{s: s}
+	cmd.Stderr = shellWriter{s: s, stderr: true}
+
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		s.stdin = stdin
+		err = cmd.Start()
+	}
+	if err != nil {
+		logger.Error("failed to start shell", "error", err)
+		b.sendText(chatID, b.t(chatID, i18n.ShellStartFailed, err))
+		return
+	}
+
+	idle := b.shell.IdleTimeout
+	s.idle = time.AfterFunc(idle, func() {
+		logger.Info("closing idle shell session")
+		s.close(b.t(chatID, i18n.ShellIdleClosed, idle))
+	})
+	if !b.shells.add(s) {
+		// Another /shell won the race
+		s.idle.Stop()
+		stdin.Close()
+		cmd.Process.Kill()
+		cmd.Wait()
+		b.sendText(chatID, b.t(chatID, i18n.ShellAlreadyOpen))
+		return
+	}
+
+	logger.Warn("shell session opened", "shell", b.shell.Command)
+	b.auditShell(ctx, chatID, audit.StatusShellOpen, b.shell.Command, -1)
+	b.sendText(chatID, b.t(chatID, i18n.ShellOpened, b.shell.Command, b.shell.IdleTimeout))
+
+	// Send input in order without holding up the update loop when the
+	// shell doesn't read it
+	go func() {
+		for {
+			select {
+			case <-s.done:
+				return
+			case msg := <-s.input:
+				if !b.writeShellInput(ctx, s, msg) {
+					return
+				}
+			}
+		}
+	}()
+
+	// Show output held back by edit throttling while the shell runs
+	go func() {
+		ticker := time.NewTicker(throttleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.flush(false)
+			}
+		}
+	}()
+
+	go func() {
+		err := cmd.Wait()
+		close(s.done)
+		s.idle.Stop()
+		b.shells.remove(s)
+		s.flush(true)
+
+		s.mu.Lock()
+		notice := s.closing
+		s.mu.Unlock()
+		if notice == "" {
+			status := "exit status 0"
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				status = exitErr.String()
+			} else if err != nil {
+				status = err.Error()
+			}
+			notice = b.t(chatID, i18n.ShellExited, status)
+		}
+
+		logger.Warn("shell session closed", "error", err)
+		b.auditShell(context.WithoutCancel(ctx), chatID, audit.StatusShellClose, "", command.ExitCode(err))
+		b.sendText(chatID, notice)
+	}()
+}
+
+// queueShellInput queues a message from the session's admin for the shell.
+// It is called from the update loop, so it must not block.
+func (b *Bot) queueShellInput(msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+	s := b.shells.get(chatID)
+	if s == nil || msg.From == nil || msg.From.ID != s.user.ID || msg.Text == "" {
+		return
+	}
+	if !s.queue(msg) {
+		slog.Warn("shell input dropped", "chat_id", chatID)
+		go b.sendText(chatID, b.t(chatID, i18n.ShellInputDropped))
+	}
+}
+
+// writeShellInput sends a queued message to the shell as a line of input,
+// after checking the sender may still use the shell. It returns false once
+// the session has been closed because they may not.
+func (b *Bot) writeShellInput(ctx context.Context, s *shellSession, msg *tgbotapi.Message) bool {
+	chatID := s.chatID
+	if !b.authorizer.IsAllowed(chatID) || b.rejectUser(chatID, msg.From, "", false) || !b.isAdmin(chatID, msg.From) {
+		slog.Warn("closing shell session of a user no longer allowed", "chat_id", chatID, "user_id", msg.From.ID)
+		b.logUnauthorized(chatID, msg.From, "shell")
+		s.close(b.t(chatID, i18n.ShellRevoked))
+		return false
+	}
+	if b.Maintenance() {
+		b.sendText(chatID, b.t(chatID, i18n.ShellMaintenance))
+		return true
+	}
+	b.trackUserCommand(msg)
+
+	line := msg.Text
+	s.idle.Reset(b.shell.IdleTimeout)
+	b.auditShell(withUser(ctx, msg.From), chatID, audit.StatusShellInput, line, -1)
+
+	// Output of this input goes to a message of its own
+	s.flush(true)
+	if _, err := fmt.Fprintln(s.stdin, line); err != nil {
+		slog.Warn("failed to write to shell", "chat_id", chatID, "error", err)
+	}
+	return true
+}
+
+// handleExitCommand handles /exit: closes the chat's shell session. The
+// admin who opened it or any other admin may close it.
+func (b *Bot) handleExitCommand(msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+	s := b.shells.get(chatID)
+	if s == nil || msg.From == nil {
+		return
+	}
+	if msg.From.ID != s.user.ID && !b.isAdmin(chatID, msg.From) {
+		b.sendText(chatID, b.t(chatID, i18n.ShellAdminOnly))
+		return
+	}
+	b.trackUserCommand(msg)
+	s.close(b.t(chatID, i18n.ShellClosed))
+}
+
+// auditShell records a step of a shell session.
+func (b *Bot) auditShell(ctx context.Context, chatID int64, status, args string, exitCode int) {
+	entry := newAuditEntry(ctx, chatID, "shell")
+	entry.Args = args
+	entry.ExitCode = exitCode
+	entry.Status = status
+	b.writeAudit(ctx, entry)
+}
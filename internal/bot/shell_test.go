@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestShellSessions(t *testing.T) {
+	var ss shellSessions
+	admin := &tgbotapi.User{ID: 7}
+	first := &shellSession{chatID: 1, user: admin}
+
+	if ss.get(1) != nil || ss.owns(1, 7) {
+		t.Fatal("empty tracker has a session")
+	}
+	if !ss.add(first) {
+		t.Fatal("add() = false for the chat's first session")
+	}
+	if ss.add(&shellSession{chatID: 1, user: &tgbotapi.User{ID: 8}}) {
+		t.Error("add() = true with a session already open")
+	}
+	if !ss.add(&shellSession{chatID: 2, user: admin}) {
+		t.Error("add() = false for another chat")
+	}
+
+	if !ss.owns(1, 7) {
+		t.Error("owns(1, 7) = false for the session's admin")
+	}
+	if ss.owns(1, 8) {
+		t.Error("owns(1, 8) = true for another user")
+	}
+
+	ss.remove(&shellSession{chatID: 1, user: admin}) // An older session of the chat
+	if ss.get(1) != first {
+		t.Error("remove() dropped a session it wasn't given")
+	}
+	ss.remove(first)
+	if ss.get(1) != nil {
+		t.Error("get() after remove() returned a session")
+	}
+}
+
+func TestShellSessionQueue(t *testing.T) {
+	s := &shellSession{input: make(chan *tgbotapi.Message, 2)}
+	msgs := []*tgbotapi.Message{{Text: "one"}, {Text: "two"}, {Text: "three"}}
+
+	if !s.queue(msgs[0]) || !s.queue(msgs[1]) {
+		t.Fatal("queue() = false with room left")
+	}
+	if s.queue(msgs[2]) {
+		t.Error("queue() = true with the queue full")
+	}
+	if got := (<-s.input).Text; got != "one" {
+		t.Errorf("first queued = %q, want one", got)
+	}
+	if !s.queue(msgs[2]) {
+		t.Error("queue() = false after a message was taken")
+	}
+	if got := (<-s.input).Text; got != "two" {
+		t.Errorf("second queued = %q, want two", got)
+	}
+}
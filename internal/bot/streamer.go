@@ -3,10 +3,17 @@ package bot
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/redact"
+	"github.com/rashpile/pako-telegram/internal/settings"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
 const (
@@ -15,6 +22,9 @@ const (
 
 	// maxMessageLength is Telegram's limit for message text.
 	maxMessageLength = 4096
+
+	// stderrPrefix marks lines a command wrote to stderr.
+	stderrPrefix = "⚠ "
 )
 
 // MessageStreamer handles progressive message updates for command output.
@@ -23,11 +33,18 @@ type MessageStreamer struct {
 	chatID    int64
 	messageID int
 	quiet     bool
+	silent    bool     // Send without a notification sound
+	format    string   // settings.Output* value; "" means a code block
+	redact    []string // Values masked in displayed output (sensitive arguments)
+	rules     redact.Rules
+	truncate  string // pkgcmd.Truncate* mode for output over the message limit
+	terminal  bool   // Output comes from a pseudo-terminal
 
 	mu       sync.Mutex
 	buffer   bytes.Buffer
 	lastEdit time.Time
 	dirty    bool
+	inStderr bool // The buffer ends inside a stderr line
 }
 
 // NewMessageStreamer creates a streamer that edits a message progressively.
@@ -48,6 +65,51 @@ func NewQuietMessageStreamer(api *tgbotapi.BotAPI, chatID int64) *MessageStreame
 	}
 }
 
+// SetRedactions sets values to mask in displayed and returned output.
+func (ms *MessageStreamer) SetRedactions(values []string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.redact = values
+}
+
+// SetRedactRules sets patterns to mask in displayed and returned output.
+func (ms *MessageStreamer) SetRedactRules(rules redact.Rules) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.rules = rules
+}
+
+// SetTruncate sets which part of output too long for a message is shown:
+// pkgcmd.TruncateHead (default), TruncateTail or TruncateHeadTail.
+func (ms *MessageStreamer) SetTruncate(mode string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.truncate = mode
+}
+
+// SetTerminal makes output from a pseudo-terminal display as plain text,
+// without escape sequences and with progress lines at their latest state.
+func (ms *MessageStreamer) SetTerminal(terminal bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.terminal = terminal
+}
+
+// SetFormat sets how output is displayed: settings.OutputCode (default),
+// OutputPlain, OutputMarkdown or OutputHTML.
+func (ms *MessageStreamer) SetFormat(format string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.format = format
+}
+
+// SetSilent makes the initial message arrive without a notification sound.
+func (ms *MessageStreamer) SetSilent(silent bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.silent = silent
+}
+
 // Start sends an initial "Running..." message and stores its ID.
 // In quiet mode, this is a no-op.
 func (ms *MessageStreamer) Start(ctx context.Context) error {
@@ -55,8 +117,12 @@ func (ms *MessageStreamer) Start(ctx context.Context) error {
 		return nil
 	}
 
-	msg := tgbotapi.NewMessage(ms.chatID, "```\nRunning...\n```")
-	msg.ParseMode = "Markdown"
+	msg := tgbotapi.NewMessage(ms.chatID, "Running...")
+	if ms.format == "" || ms.format == settings.OutputCode {
+		msg.Text = "```\nRunning...\n```"
+		msg.ParseMode = "Markdown"
+	}
+	msg.DisableNotification = ms.silent
 
 	sent, err := ms.api.Send(msg)
 	if err != nil {
@@ -72,15 +138,60 @@ func (ms *MessageStreamer) Write(p []byte) (n int, err error) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
+	if ms.inStderr && len(p) > 0 {
+		ms.buffer.WriteByte('\n')
+		ms.inStderr = false
+	}
 	n, err = ms.buffer.Write(p)
-	ms.dirty = true
+	ms.written()
+	return n, err
+}
+
+// Stderr returns a writer for the command's stderr. Its lines are shown
+// among the output, each starting with stderrPrefix.
+func (ms *MessageStreamer) Stderr() io.Writer {
+	return stderrWriter{ms}
+}
+
+// stderrWriter writes to a streamer as stderr.
+type stderrWriter struct {
+	ms *MessageStreamer
+}
+
+// Write buffers p as stderr lines, starting each on a line of its own.
+func (w stderrWriter) Write(p []byte) (int, error) {
+	ms := w.ms
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		if !ms.inStderr {
+			if l := ms.buffer.Len(); l > 0 && ms.buffer.Bytes()[l-1] != '\n' {
+				ms.buffer.WriteByte('\n')
+			}
+			ms.buffer.WriteString(stderrPrefix)
+			ms.inStderr = true
+		}
+		line, rest, found := bytes.Cut(p, []byte("\n"))
+		ms.buffer.Write(line)
+		if found {
+			ms.buffer.WriteByte('\n')
+			ms.inStderr = false
+		}
+		p = rest
+	}
+	ms.written()
+	return n, nil
+}
 
-	// Throttle edits
+// written marks the buffer changed and edits the message unless throttled.
+// Must be called with mutex held.
+func (ms *MessageStreamer) written() {
+	ms.dirty = true
 	if time.Since(ms.lastEdit) >= throttleInterval {
 		ms.editMessage()
 	}
-
-	return n, err
 }
 
 // WriteString is a convenience method for writing strings.
@@ -103,7 +214,17 @@ func (ms *MessageStreamer) Flush() error {
 func (ms *MessageStreamer) Content() string {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
-	return ms.buffer.String()
+	return ms.redacted()
+}
+
+// redacted returns the buffer with sensitive values and redact rule matches
+// masked, as plain text for terminal output. Must be called with mutex held.
+func (ms *MessageStreamer) redacted() string {
+	content := ms.buffer.String()
+	if ms.terminal {
+		content = terminalText(content)
+	}
+	return ms.rules.Apply(RedactValues(content, ms.redact))
 }
 
 // MessageID returns the ID of the message being edited.
@@ -120,24 +241,79 @@ func (ms *MessageStreamer) editMessage() {
 		return
 	}
 
-	content := ms.buffer.String()
+	content := ms.redacted()
 	if content == "" {
 		content = "(no output)"
 	}
 
 	// Truncate if too long
 	if len(content) > maxMessageLength-20 {
-		content = content[:maxMessageLength-30] + "\n\n[truncated]"
+		content = truncateOutput(content, maxMessageLength-30, ms.truncate)
 	}
 
-	// Wrap in code block
-	text := "```\n" + content + "\n```"
-
-	edit := tgbotapi.NewEditMessageText(ms.chatID, ms.messageID, text)
-	edit.ParseMode = "Markdown"
+	edit := tgbotapi.NewEditMessageText(ms.chatID, ms.messageID, content)
+	switch ms.format {
+	case settings.OutputPlain:
+		// No markup
+	case settings.OutputMarkdown:
+		edit.ParseMode = "Markdown"
+	case settings.OutputHTML:
+		edit.ParseMode = "HTML"
+	default:
+		// Wrap in code block
+		edit.Text = "```\n" + content + "\n```"
+		edit.ParseMode = "Markdown"
+	}
 
-	_, _ = ms.api.Send(edit) // Ignore edit errors (rate limits, etc.)
+	// Ignore edit errors (rate limits, etc.), but show output that isn't
+	// valid markup as plain text
+	_, err := ms.api.Send(edit)
+	if err != nil && edit.ParseMode != "" && edit.Text == content {
+		edit.ParseMode = ""
+		_, _ = ms.api.Send(edit)
+	}
 
 	ms.lastEdit = time.Now()
 	ms.dirty = false
 }
+
+// truncateMarker is the most bytes the gap marker of head+tail truncation
+// takes.
+const truncateMarker = 40
+
+// truncateOutput shortens content to fit limit bytes and a marker, keeping
+// the part mode selects. Cuts fall on character boundaries, so multi-byte
+// characters are never split.
+func truncateOutput(content string, limit int, mode string) string {
+	switch mode {
+	case pkgcmd.TruncateTail:
+		return "[truncated]\n\n" + content[tailStart(content, limit):]
+	case pkgcmd.TruncateHeadTail:
+		half := (limit - truncateMarker) / 2
+		head, tail := headEnd(content, half), tailStart(content, half)
+		return content[:head] + fmt.Sprintf("\n\n[... %d bytes truncated ...]\n\n", tail-head) + content[tail:]
+	}
+	return content[:headEnd(content, limit)] + "\n\n[truncated]"
+}
+
+// headEnd returns where the first n bytes of s end, moved back to the start
+// of a character.
+func headEnd(s string, n int) int {
+	if n >= len(s) {
+		return len(s)
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return n
+}
+
+// tailStart returns where the last n bytes of s start, moved forward to the
+// start of a character.
+func tailStart(s string, n int) int {
+	i := len(s) - n
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return i
+}
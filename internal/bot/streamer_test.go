@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	content := "start\n" + strings.Repeat("é", 3000) + "\nerror: build failed"
+	limit := 200
+
+	head := truncateOutput(content, limit, pkgcmd.TruncateHead)
+	if !strings.HasPrefix(head, "start\n") || !strings.HasSuffix(head, "[truncated]") {
+		t.Errorf("head = %q", head)
+	}
+
+	tail := truncateOutput(content, limit, pkgcmd.TruncateTail)
+	if !strings.HasPrefix(tail, "[truncated]") || !strings.HasSuffix(tail, "error: build failed") || len(tail) > limit+20 {
+		t.Errorf("tail = %q", tail)
+	}
+
+	both := truncateOutput(content, limit, pkgcmd.TruncateHeadTail)
+	if !strings.HasPrefix(both, "start\n") || !strings.HasSuffix(both, "error: build failed") || !strings.Contains(both, "bytes truncated") {
+		t.Errorf("head_tail = %q", both)
+	}
+	if len(both) > limit {
+		t.Errorf("head_tail is %d bytes, want at most %d", len(both), limit)
+	}
+
+	// "start\n" is 6 bytes and "é" 2, so odd limits fall inside a character
+	for _, limit := range []int{200, 201} {
+		for _, mode := range []string{pkgcmd.TruncateHead, pkgcmd.TruncateTail, pkgcmd.TruncateHeadTail} {
+			s := truncateOutput(content, limit, mode)
+			if !strings.ContainsRune(s, 'é') || !utf8.ValidString(s) {
+				t.Errorf("%s at %d: cut inside a character: %q", mode, limit, s)
+			}
+		}
+	}
+}
+
+func TestStreamerStderr(t *testing.T) {
+	ms := NewQuietMessageStreamer(nil, 1)
+	stderr := ms.Stderr()
+
+	ms.WriteString("building")
+	stderr.Write([]byte("warning: deprecated\nerror: "))
+	stderr.Write([]byte("missing file\n"))
+	ms.WriteString("done\n")
+	stderr.Write([]byte("exit 1"))
+	ms.WriteString("Error: failed")
+
+	want := "building\n⚠ warning: deprecated\n⚠ error: missing file\ndone\n⚠ exit 1\nError: failed"
+	if got := ms.Content(); got != want {
+		t.Errorf("Content() = %q, want %q", got, want)
+	}
+}
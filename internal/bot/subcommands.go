@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/msgstore"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// showSubcommands sends the menu of cmd's subcommands. If the chat's menu
+// doesn't show cmd, they are listed as text instead.
+func (b *Bot) showSubcommands(chatID int64, cmd pkgcmd.Command) {
+	text, keyboard, ok := b.menuBuilder.BuildGroupMenu(chatID, cmd.Name())
+	if !ok {
+		var list strings.Builder
+		cmd.Execute(context.Background(), nil, &list)
+		b.sendText(chatID, list.String())
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	msg.DisableNotification = b.silent(chatID)
+	if sent, err := b.api.Send(msg); err == nil {
+		b.trackMessage(chatID, sent.MessageID, msgstore.TypePrompt)
+		b.menus.set(chatID, sent.MessageID, GroupPageData(cmd.Name(), 0))
+	}
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+)
+
+// handleSudoCommand handles /sudo (prompt for PIN), /sudo off and /sudo status.
+func (b *Bot) handleSudoCommand(msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+
+	if !b.authorizer.IsAllowed(chatID) {
+		b.logUnauthorized(chatID, msg.From, "sudo")
+		b.rejectChat(chatID)
+		return
+	}
+	if b.rejectUser(chatID, msg.From, "sudo", true) {
+		return
+	}
+	if b.sudo == nil || msg.From == nil {
+		b.sendText(chatID, b.t(chatID, i18n.SudoNotConfigured))
+		return
+	}
+	user := msg.From
+
+	switch strings.TrimSpace(msg.CommandArguments()) {
+	case "off":
+		if b.sudo.Drop(user.ID) {
+			slog.Info("sudo dropped", "chat_id", chatID, "user_id", user.ID)
+			b.sendText(chatID, b.t(chatID, i18n.SudoEnded))
+		} else {
+			b.sendText(chatID, b.t(chatID, i18n.SudoNotElevated))
+		}
+		return
+
+	case "status":
+		if expires, ok := b.sudo.Expiry(user.ID, time.Now()); ok {
+			b.sendText(chatID, b.t(chatID, i18n.SudoRemaining, time.Until(expires).Round(time.Second)))
+		} else {
+			b.sendText(chatID, b.t(chatID, i18n.SudoNotElevated))
+		}
+		return
+
+	case "":
+	default:
+		// Never accept the PIN inline; it would stay in the chat history
+		b.api.Request(tgbotapi.NewDeleteMessage(chatID, msg.MessageID))
+		b.sendText(chatID, b.t(chatID, i18n.SudoUsage))
+		return
+	}
+
+	if !b.sudo.HasPIN(user.ID, user.UserName) {
+		b.sendText(chatID, b.t(chatID, i18n.SudoNoPIN))
+		return
+	}
+
+	b.pinMgr.Start(chatID, user.ID, "sudo", nil)
+	b.sendText(chatID, b.t(chatID, i18n.SudoPrompt))
+}
+
+// handlePINInput checks a sudo PIN and starts an elevation session.
+func (b *Bot) handlePINInput(msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+
+	// Don't leave PINs in the chat history
+	b.api.Request(tgbotapi.NewDeleteMessage(chatID, msg.MessageID))
+
+	pending := b.pinMgr.Take(chatID)
+	if pending == nil {
+		b.sendText(chatID, b.t(chatID, i18n.SudoExpired))
+		return
+	}
+
+	expires, err := b.sudo.Elevate(msg.From.ID, msg.From.UserName, strings.TrimSpace(msg.Text), time.Now())
+	switch {
+	case err == nil:
+		slog.Info("sudo elevated", "chat_id", chatID, "user_id", msg.From.ID, "until", expires)
+		b.sendText(chatID, b.t(chatID, i18n.SudoElevated, expires.Format("15:04")))
+	case errors.Is(err, auth.ErrLockedOut):
+		slog.Warn("sudo locked out", "chat_id", chatID, "user_id", msg.From.ID)
+		b.sendText(chatID, b.t(chatID, i18n.SudoLockedOut))
+		b.NotifyAdmin(fmt.Sprintf("⚠️ %s was locked out of /sudo after repeated wrong PINs.", userDisplayName(msg.From)))
+	default:
+		slog.Warn("wrong sudo pin", "chat_id", chatID, "user_id", msg.From.ID)
+		if b.pinMgr.Retry(chatID, pending) {
+			b.sendText(chatID, b.t(chatID, i18n.SudoWrongPIN))
+			return
+		}
+		b.sendText(chatID, b.t(chatID, i18n.SudoTooMany))
+	}
+}
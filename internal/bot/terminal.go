@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rashpile/pako-telegram/internal/command"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// terminalEscape matches ANSI escape sequences: CSI (colors, cursor
+// movement), OSC (window titles, links) and two-character escapes.
+var terminalEscape = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// runsInTerminal returns true if cmd runs under a pseudo-terminal.
+func runsInTerminal(cmd pkgcmd.Command) bool {
+	yamlCmd, ok := cmd.(*command.YAMLCommand)
+	return ok && yamlCmd.PTY()
+}
+
+// terminalText turns what a command wrote to a terminal into plain text:
+// escape sequences are dropped, and a line rewritten after carriage returns,
+// like a progress bar, shows only its latest state.
+func terminalText(s string) string {
+	s = terminalEscape.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,20 @@
+package bot
+
+import "testing"
+
+func TestTerminalText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"\x1b[32mok\x1b[0m\r\n", "ok\n"},
+		{"Downloading  10%\rDownloading  55%\rDownloading 100%\r\ndone\r\n", "Downloading 100%\ndone\n"},
+		{"progress 40%\r", "progress 40%"},
+		{"\x1b]0;title\x07\x1b[2Kline", "line"},
+		{"plain\nlines", "plain\nlines"},
+	}
+	for _, tt := range tests {
+		if got := terminalText(tt.in); got != tt.want {
+			t.Errorf("terminalText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
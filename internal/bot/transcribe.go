@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+)
+
+// handleVoiceMessage transcribes a voice message and runs the target command
+// (the /command caption, or the configured target) with the transcript as
+// its text argument.
+func (b *Bot) handleVoiceMessage(ctx context.Context, msg *tgbotapi.Message) {
+	ctx = withUser(ctx, msg.From)
+	chatID := msg.Chat.ID
+	cmdName := captionCommand(msg.Caption)
+	if cmdName == "" {
+		cmdName = b.transcription.Target
+	}
+	logger := slog.With("chat_id", chatID, "command", cmdName)
+
+	if !b.authorizer.IsAllowed(chatID) {
+		logger.Warn("unauthorized voice message")
+		b.logUnauthorized(chatID, msg.From, "voice")
+		b.rejectChat(chatID)
+		return
+	}
+	if b.rejectUser(chatID, msg.From, "voice", true) {
+		return
+	}
+	if cmdName == "" {
+		b.sendText(chatID, b.t(chatID, i18n.NoVoiceTarget))
+		return
+	}
+
+	cmd := b.registry.Get(cmdName)
+	if cmd == nil {
+		b.sendText(chatID, b.t(chatID, i18n.UnknownCommand, cmdName))
+		return
+	}
+	if b.rejectDisabled(chatID, cmd) {
+		return
+	}
+	if b.rejectRestricted(chatID, msg.From, cmd) || b.rejectThrottled(ctx, chatID, cmd) {
+		return
+	}
+
+	text, err := b.transcribeVoice(ctx, msg.Voice)
+	if err != nil {
+		logger.Error("transcription failed", "error", err)
+		b.sendText(chatID, b.t(chatID, i18n.TranscriptionFailed, err))
+		return
+	}
+	if text == "" {
+		b.sendText(chatID, b.t(chatID, i18n.NoWords))
+		return
+	}
+	logger.Info("transcribed voice message", "chars", len(text))
+	b.sendText(chatID, "🎙 "+text)
+
+	// Shell commands: fill the first argument, or append the transcript quoted
+	if yamlCmd, ok := cmd.(*command.YAMLCommand); ok {
+		if yamlCmd.HasArguments() {
+			first := yamlCmd.Arguments()[0]
+			if err := validateArgument(&first, text); err != nil {
+				b.sendText(chatID, b.t(chatID, i18n.InvalidArgument, first.Name, err))
+				return
+			}
+			b.collectArguments(ctx, chatID, yamlCmd, map[string]string{first.Name: text})
+			return
+		}
+		b.dispatchCommand(ctx, chatID, cmd, []string{shellQuote(text)})
+		return
+	}
+
+	b.dispatchCommand(ctx, chatID, cmd, []string{text})
+}
+
+// transcribeVoice downloads a voice message to a temp file and transcribes it.
+func (b *Bot) transcribeVoice(ctx context.Context, voice *tgbotapi.Voice) (string, error) {
+	if voice.FileSize > maxDownloadSize {
+		return "", fmt.Errorf("voice messages over %d MB can't be downloaded by bots", maxDownloadSize>>20)
+	}
+
+	body, err := b.openTelegramFile(voice.FileID)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	f, err := os.CreateTemp("", "pako-voice-*.ogg")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = io.Copy(f, io.LimitReader(body, maxDownloadSize))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("download: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.transcription.Timeout)
+	defer cancel()
+	return b.transcriber.Transcribe(ctx, f.Name())
+}
+
+// shellQuote wraps s in single quotes for safe use as one shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,23 @@
+package bot
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	for _, s := range []string{
+		"restart nginx",
+		"don't stop; rm -rf /",
+		`$(whoami) "quoted" \back`,
+		"",
+	} {
+		out, err := exec.Command("/bin/sh", "-c", "printf %s "+shellQuote(s)).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != s {
+			t.Errorf("shell saw %q, want %q", out, s)
+		}
+	}
+}
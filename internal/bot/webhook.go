@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/webhook"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// webhookUser stands in for the Telegram user in the audit log and rate
+// limits of webhook-triggered runs.
+var webhookUser = &tgbotapi.User{UserName: "webhook"}
+
+// Trigger runs a command requested through the webhook server. The chat
+// (default: the admin chat) and the command's policies are checked as for a
+// message without a user; commands that need a person to approve them (OTP
+// or several admins) are refused. Like scheduled runs, confirmation is
+// skipped. Arguments are given as inline name=value pairs and must be
+// complete. The command runs in the background with ctx, its output going to
+// the chat.
+func (b *Bot) Trigger(ctx context.Context, req webhook.Request) error {
+	chatID := req.ChatID
+	if chatID == 0 {
+		b.settingsMu.RLock()
+		chatID = b.adminChatID
+		b.settingsMu.RUnlock()
+	}
+	if chatID == 0 || !b.authorizer.IsAllowed(chatID) {
+		return fmt.Errorf("%w: chat %d is not allowed", webhook.ErrForbidden, chatID)
+	}
+
+	cmd := b.registry.Get(req.Command)
+	if cmd == nil {
+		return fmt.Errorf("%w: /%s", webhook.ErrUnknownCommand, req.Command)
+	}
+	if pkgcmd.IsDisabled(cmd) {
+		return fmt.Errorf("%w: /%s is disabled", webhook.ErrForbidden, cmd.Name())
+	}
+	if pkgcmd.RequiresOTP(cmd) || pkgcmd.RequiredApprovals(cmd) > 1 {
+		return fmt.Errorf("%w: /%s needs interactive approval", webhook.ErrForbidden, cmd.Name())
+	}
+	// Triggered runs skip confirmation, so only where it was asked for by name
+	if !req.Listed && b.requiresConfirm(chatID, cmd) {
+		return fmt.Errorf("%w: /%s asks for confirmation; name it in webhook.commands", webhook.ErrForbidden, cmd.Name())
+	}
+	if checker, ok := b.authorizer.(commandChecker); ok {
+		if err := checker.CheckCommand(auth.Request{ChatID: chatID, CommandName: cmd.Name()}); err != nil {
+			return fmt.Errorf("%w: %v", webhook.ErrForbidden, err)
+		}
+	}
+
+	ctx = withUser(ctx, webhookUser)
+	yamlCmd, ok := cmd.(*command.YAMLCommand)
+	if !ok || !yamlCmd.HasArguments() {
+		if b.rejectThrottled(ctx, chatID, cmd) {
+			return webhook.ErrThrottled
+		}
+		go b.runTriggered(ctx, chatID, cmd, func(ctx context.Context) {
+			b.executeCommand(ctx, chatID, cmd, parseArgs(req.Args))
+		})
+		return nil
+	}
+
+	collected, err := webhookArguments(yamlCmd.Arguments(), req.Args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", webhook.ErrInvalidArgs, err)
+	}
+	rendered, err := RenderCommand(yamlCmd.CommandTemplate(), collected)
+	if err != nil {
+		return fmt.Errorf("%w: %v", webhook.ErrInvalidArgs, err)
+	}
+	if b.rejectThrottled(ctx, chatID, cmd) {
+		return webhook.ErrThrottled
+	}
+	go b.runTriggered(ctx, chatID, cmd, func(ctx context.Context) {
+		b.executeRenderedCommand(ctx, chatID, yamlCmd, rendered, collected)
+	})
+	return nil
+}
+
+// runTriggered announces a webhook-triggered command in the chat and runs it.
+func (b *Bot) runTriggered(ctx context.Context, chatID int64, cmd pkgcmd.Command, run func(ctx context.Context)) {
+	slog.Info("executing webhook command", "chat_id", chatID, "command", cmd.Name())
+	b.sendText(chatID, b.t(chatID, i18n.WebhookRunning, cmd.Name()))
+	run(ctx)
+}
+
+// webhookArguments parses inline name=value pairs for defs. Arguments not
+// given take their default, which must satisfy required arguments.
+func webhookArguments(defs []command.ArgumentDef, input string) (map[string]string, error) {
+	collected, err := ParseInlineArguments(defs, input)
+	if err != nil {
+		return nil, err
+	}
+	if collected == nil {
+		collected = make(map[string]string)
+	}
+
+	for _, arg := range defs {
+		if _, ok := collected[arg.Name]; ok {
+			continue
+		}
+		if argumentApplies(&arg, collected) {
+			if err := validateArgument(&arg, arg.Default); err != nil {
+				return nil, fmt.Errorf("%s: %w", arg.Name, err)
+			}
+		}
+		collected[arg.Name] = arg.Default
+	}
+	return collected, nil
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	"github.com/rashpile/pako-telegram/internal/command"
+	"github.com/rashpile/pako-telegram/internal/webhook"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// confirmCommand is a command asking for confirmation.
+type confirmCommand struct{ roleCommand }
+
+func (c confirmCommand) Metadata() pkgcmd.Metadata {
+	return pkgcmd.Metadata{RequireConfirm: true}
+}
+
+func TestWebhookArguments(t *testing.T) {
+	defs := []command.ArgumentDef{
+		{Name: "env", Required: true},
+		{Name: "version", Default: "latest"},
+		{Name: "host", When: `{{eq .env "custom"}}`, Required: true},
+	}
+
+	got, err := webhookArguments(defs, "env=prod")
+	if err != nil {
+		t.Fatalf("webhookArguments() error = %v", err)
+	}
+	if got["env"] != "prod" || got["version"] != "latest" || got["host"] != "" {
+		t.Errorf("webhookArguments() = %v, want env=prod version=latest and no host", got)
+	}
+
+	if _, err := webhookArguments(defs, ""); err == nil {
+		t.Error("webhookArguments() without required env: want error")
+	}
+	if _, err := webhookArguments(defs, "env=custom"); err == nil {
+		t.Error("webhookArguments() without host required by when: want error")
+	}
+}
+
+func TestTriggerRefusesUnlistedConfirm(t *testing.T) {
+	registry := command.NewRegistry()
+	registry.Register(confirmCommand{roleCommand{name: "drop"}})
+	b := &Bot{
+		authorizer: auth.NewAllowlist([]int64{1}),
+		registry:   registry,
+	}
+
+	err := b.Trigger(context.Background(), webhook.Request{Command: "drop", ChatID: 1})
+	if !errors.Is(err, webhook.ErrForbidden) {
+		t.Errorf("Trigger() of a confirm command allowed by \"*\" = %v, want ErrForbidden", err)
+	}
+}
@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"log/slog"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	"github.com/rashpile/pako-telegram/internal/i18n"
+)
+
+// handleWhoamiCommand reports the caller's chat and user IDs and what they
+// may do. It answers in any chat, so new chats can find the IDs to
+// allowlist; chats that aren't allowed only see their IDs.
+func (b *Bot) handleWhoamiCommand(msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+	lang := b.lang(chatID)
+
+	var userID int64
+	username := "—"
+	if msg.From != nil {
+		userID = msg.From.ID
+		if msg.From.UserName != "" {
+			username = "@" + msg.From.UserName
+		}
+	}
+	chatAllowed := b.authorizer.IsAllowed(chatID)
+	userAllowed := msg.From != nil && b.authorizer.IsAllowedUser(userID, msg.From.UserName)
+
+	text := i18n.T(lang, i18n.WhoamiIdentity, chatID, userID, username, yesNo(lang, chatAllowed), yesNo(lang, userAllowed))
+	if !chatAllowed || !userAllowed {
+		slog.Info("whoami from unauthorized chat or user", "chat_id", chatID, "user_id", userID)
+		b.logUnauthorized(chatID, msg.From, "whoami")
+		b.sendText(chatID, text)
+		if !chatAllowed {
+			b.rejectChat(chatID) // Offers to request access
+		}
+		return
+	}
+	b.trackUserCommand(msg)
+
+	allowed, total := b.runnableCommands(chatID, msg.From)
+	text += "\n\n" + i18n.T(lang, i18n.WhoamiAccess, b.roleOf(chatID, msg.From), b.sudoStatus(lang, msg.From), b.otpStatus(lang, msg.From), allowed, total)
+	b.sendText(chatID, text)
+}
+
+// roleOf returns the user's role in the chat. Without configured roles
+// everyone is an admin.
+func (b *Bot) roleOf(chatID int64, user *tgbotapi.User) auth.Role {
+	if b.roles == nil {
+		return auth.RoleAdmin
+	}
+	return b.roles.RoleOf(chatID, user.ID, user.UserName)
+}
+
+// sudoStatus describes the user's /sudo elevation.
+func (b *Bot) sudoStatus(lang string, user *tgbotapi.User) string {
+	if b.sudo == nil || !b.sudo.HasPIN(user.ID, user.UserName) {
+		return i18n.T(lang, i18n.WhoamiNotSetUp)
+	}
+	if expires, ok := b.sudo.Expiry(user.ID, time.Now()); ok {
+		return i18n.T(lang, i18n.WhoamiSudoActive, time.Until(expires).Round(time.Second))
+	}
+	return i18n.T(lang, i18n.WhoamiSudoInactive)
+}
+
+// otpStatus describes whether the user can answer require_otp prompts.
+func (b *Bot) otpStatus(lang string, user *tgbotapi.User) string {
+	if b.otp == nil || !b.otp.HasSecret(user.ID, user.UserName) {
+		return i18n.T(lang, i18n.WhoamiNotSetUp)
+	}
+	return i18n.T(lang, i18n.WhoamiSetUp)
+}
+
+// runnableCommands counts the registered commands the authorizer's policies
+// let the user run in the chat.
+func (b *Bot) runnableCommands(chatID int64, user *tgbotapi.User) (allowed, total int) {
+	checker, hasPolicies := b.authorizer.(commandChecker)
+	for _, cmd := range b.registry.All() {
+		total++
+		req := auth.Request{ChatID: chatID, UserID: user.ID, Username: user.UserName, CommandName: cmd.Name()}
+		if !hasPolicies || checker.CheckCommand(req) == nil {
+			allowed++
+		}
+	}
+	return allowed, total
+}
+
+// yesNo returns "yes" or "no" in lang.
+func yesNo(lang string, v bool) string {
+	if v {
+		return i18n.T(lang, i18n.Yes)
+	}
+	return i18n.T(lang, i18n.No)
+}
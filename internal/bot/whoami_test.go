@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	"github.com/rashpile/pako-telegram/internal/command"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// roleCommand is a command requiring a role.
+type roleCommand struct {
+	name, role string
+}
+
+func (c roleCommand) Name() string        { return c.name }
+func (c roleCommand) Description() string { return c.name }
+func (c roleCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	return nil
+}
+func (c roleCommand) Metadata() pkgcmd.Metadata {
+	return pkgcmd.Metadata{RequiredRole: c.role}
+}
+
+func TestWhoamiAccess(t *testing.T) {
+	registry := command.NewRegistry()
+	registry.Register(roleCommand{name: "uptime"})
+	registry.Register(roleCommand{name: "deploy", role: "operator"})
+	registry.Register(roleCommand{name: "grant", role: "admin"})
+
+	roles, err := auth.NewRoleMap("viewer", nil, map[string]string{"alice": "operator"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bot{
+		authorizer: auth.NewPolicyAuthorizer(auth.NewAllowlist([]int64{1}), registry.Get, auth.RolePolicy(roles)),
+		registry:   registry,
+		roles:      roles,
+	}
+
+	tests := []struct {
+		user        *tgbotapi.User
+		wantRole    auth.Role
+		wantAllowed int
+	}{
+		{&tgbotapi.User{ID: 10, UserName: "alice"}, auth.RoleOperator, 2},
+		{&tgbotapi.User{ID: 11, UserName: "bob"}, auth.RoleViewer, 1},
+	}
+	for _, tt := range tests {
+		if role := b.roleOf(1, tt.user); role != tt.wantRole {
+			t.Errorf("roleOf(%s) = %s, want %s", tt.user.UserName, role, tt.wantRole)
+		}
+		allowed, total := b.runnableCommands(1, tt.user)
+		if allowed != tt.wantAllowed || total != 3 {
+			t.Errorf("runnableCommands(%s) = %d of %d, want %d of 3", tt.user.UserName, allowed, total, tt.wantAllowed)
+		}
+		if got := b.sudoStatus("en", tt.user); got != "not set up" {
+			t.Errorf("sudoStatus() = %q", got)
+		}
+	}
+
+	b.roles = nil
+	if role := b.roleOf(1, tests[1].user); role != auth.RoleAdmin {
+		t.Errorf("roleOf() without roles = %s, want admin", role)
+	}
+}
@@ -0,0 +1,228 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Limits for fetching an article for /podcast.
+const (
+	articleTimeout = 30 * time.Second
+	articleMaxSize = 5 << 20 // 5 MB of HTML
+)
+
+// articleURL returns the URL if text is a single http(s) link.
+func articleURL(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, " \t\r\n") {
+		return "", false
+	}
+	u, err := url.Parse(text)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return u.String(), true
+}
+
+// fetchArticle downloads rawURL and returns its title and readable text.
+// Plain text pages are returned as they are.
+func fetchArticle(ctx context.Context, rawURL string) (title, text string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, articleTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("server returned %s", resp.Status)
+	}
+	body := io.LimitReader(resp.Body, articleMaxSize)
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "text/plain":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return "", "", err
+		}
+		return "", strings.TrimSpace(string(data)), nil
+	case mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		doc, err := html.Parse(body)
+		if err != nil {
+			return "", "", fmt.Errorf("parse page: %w", err)
+		}
+		title, text = extractArticle(doc)
+		return title, text, nil
+	default:
+		return "", "", fmt.Errorf("unsupported content type %q", mediaType)
+	}
+}
+
+// skippedElements never contain article text.
+var skippedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Button: true, atom.Svg: true, atom.Iframe: true,
+	atom.Figure: true,
+}
+
+// blockElements are emitted as separate paragraphs.
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+	atom.H5: true, atom.H6: true, atom.Li: true, atom.Blockquote: true, atom.Pre: true,
+}
+
+// extractArticle returns the page title and the text of its main content,
+// readability-style: an <article> or <main> element if present, otherwise
+// the element whose paragraphs hold the most text. Paragraphs are separated
+// by blank lines.
+func extractArticle(doc *html.Node) (title, text string) {
+	if n := findElement(doc, atom.Title); n != nil {
+		title = collapseSpace(nodeText(n))
+	}
+
+	root := findElement(doc, atom.Article)
+	if root == nil {
+		root = findElement(doc, atom.Main)
+	}
+	if root == nil {
+		root = densestElement(doc)
+	}
+	if root == nil {
+		root = doc
+	}
+
+	var paragraphs []string
+	collectParagraphs(root, &paragraphs)
+	return title, strings.Join(paragraphs, "\n\n")
+}
+
+// findElement returns the first element of type a, skipping boilerplate.
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode {
+		if n.DataAtom == a {
+			return n
+		}
+		if skippedElements[n.DataAtom] && a != atom.Title {
+			return nil
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// densestElement returns the element whose direct <p> children hold the
+// most text, or nil if the page has no paragraphs.
+func densestElement(doc *html.Node) *html.Node {
+	var best *html.Node
+	bestScore := 0
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && skippedElements[n.DataAtom] {
+			return
+		}
+		score := 0
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.DataAtom == atom.P {
+				score += len(collapseSpace(nodeText(c)))
+			}
+			walk(c)
+		}
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	walk(doc)
+	return best
+}
+
+// collectParagraphs appends the text of block elements under n. Text
+// outside any block element, e.g. directly inside a <div>, is kept as its
+// own paragraph.
+func collectParagraphs(n *html.Node, out *[]string) {
+	var loose strings.Builder
+	flush := func() {
+		if s := collapseSpace(loose.String()); s != "" {
+			*out = append(*out, s)
+		}
+		loose.Reset()
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			loose.WriteString(c.Data)
+		case c.Type != html.ElementNode || skippedElements[c.DataAtom]:
+		case blockElements[c.DataAtom]:
+			flush()
+			if s := collapseSpace(nodeText(c)); s != "" {
+				*out = append(*out, s)
+			}
+		case c.DataAtom == atom.Br:
+			loose.WriteString(" ")
+		case isInline(c.DataAtom):
+			loose.WriteString(nodeText(c))
+		default:
+			flush()
+			collectParagraphs(c, out)
+		}
+	}
+	flush()
+}
+
+// isInline reports whether elements of type a continue the surrounding text.
+func isInline(a atom.Atom) bool {
+	switch a {
+	case atom.A, atom.B, atom.Strong, atom.I, atom.Em, atom.Span, atom.Code,
+		atom.Small, atom.Sub, atom.Sup, atom.Mark, atom.Abbr, atom.Q, atom.Time, atom.U, atom.S:
+		return true
+	}
+	return false
+}
+
+// nodeText returns all text under n, skipping boilerplate elements.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && skippedElements[n.DataAtom]:
+			return
+		case n.Type == html.ElementNode && n.DataAtom == atom.Br:
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// collapseSpace trims s and collapses runs of whitespace to single spaces.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
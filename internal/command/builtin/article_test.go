@@ -0,0 +1,139 @@
+package builtin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestExtractArticle(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      string
+		wantTitle string
+		wantText  string
+	}{
+		{"empty", "", "", ""},
+		{
+			"article with script and style",
+			`<html><head><title> The  Title </title><style>p { color: red }</style><script>var x = "Hidden.";</script></head>
+			<body><nav><p>Menu link</p></nav>
+			<article><h1>Heading</h1><p>First <b>bold</b> paragraph.<script>alert("no")</script></p>
+			<style>.ad{}</style><p>Second<br>line.</p><figure><p>Caption</p></figure></article>
+			<footer><p>Copyright</p></footer></body></html>`,
+			"The Title",
+			"Heading\n\nFirst bold paragraph.\n\nSecond line.",
+		},
+		{
+			"main element",
+			`<body><div><p>Sidebar text that is rather long, longer than the main one.</p></div><main><p>Main text.</p></main></body>`,
+			"",
+			"Main text.",
+		},
+		{
+			"densest element",
+			`<body><div class="teaser"><p>Short teaser.</p></div>
+			<div class="content"><p>A long first paragraph of the story.</p><p>And a second one.</p></div></body>`,
+			"",
+			"A long first paragraph of the story.\n\nAnd a second one.",
+		},
+		{
+			"loose text and lists",
+			`<body><article>Intro without a paragraph.<ul><li>One</li><li>Two</li></ul><div>Nested <em>text</em>.</div></article></body>`,
+			"",
+			"Intro without a paragraph.\n\nOne\n\nTwo\n\nNested text.",
+		},
+		{
+			"multibyte",
+			`<title>Новости</title><article><p>Привет,   мир!</p><p>日本語のテキスト。</p></article>`,
+			"Новости",
+			"Привет, мир!\n\n日本語のテキスト。",
+		},
+		{
+			"only script and style",
+			`<html><head><script>console.log("x")</script></head><body><style>body{}</style><noscript>Enable JS</noscript></body></html>`,
+			"",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			title, text := extractArticle(doc)
+			if title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title, tt.wantTitle)
+			}
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestArticleURL(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"https://example.com/post", true},
+		{"  http://example.com/a?b=c\n", true},
+		{"ftp://example.com/file", false},
+		{"https://", false},
+		{"see https://example.com/post", false},
+		{"Just some text.", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if _, ok := articleURL(tt.text); ok != tt.want {
+			t.Errorf("articleURL(%q) ok = %v, want %v", tt.text, ok, tt.want)
+		}
+	}
+}
+
+func TestFetchArticle(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<title>Post</title><script>x()</script><article><p>Body text.</p></article>`))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("  Plain <b>text</b>.\n"))
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		path      string
+		wantTitle string
+		wantText  string
+		wantErr   string
+	}{
+		{"/page", "Post", "Body text.", ""},
+		{"/plain", "", "Plain <b>text</b>.", ""},
+		{"/image", "", "", "unsupported content type"},
+		{"/missing", "", "", "404"},
+	}
+	for _, tt := range tests {
+		title, text, err := fetchArticle(context.Background(), srv.URL+tt.path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("fetchArticle(%s) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || title != tt.wantTitle || text != tt.wantText {
+			t.Errorf("fetchArticle(%s) = %q, %q, %v; want %q, %q", tt.path, title, text, err, tt.wantTitle, tt.wantText)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	"github.com/rashpile/pako-telegram/internal/backup"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// backupTimeout bounds archiving and uploading a backup.
+const backupTimeout = 10 * time.Minute
+
+// BackupCommand archives the bot's config, commands and state and sends the
+// archive to the chat, or uploads it to S3 when configured.
+type BackupCommand struct {
+	sources backup.Sources
+	s3      *backup.S3 // nil = send to the chat
+
+	fileMu       sync.Mutex
+	fileResponse *pkgcmd.FileResponse
+}
+
+// NewBackupCommand creates a backup command. A nil s3 sends archives to
+// the chat.
+func NewBackupCommand(sources backup.Sources, s3 *backup.S3) *BackupCommand {
+	return &BackupCommand{sources: sources, s3: s3}
+}
+
+// Name returns "backup".
+func (b *BackupCommand) Name() string {
+	return "backup"
+}
+
+// Description returns the backup command description.
+func (b *BackupCommand) Description() string {
+	if b.s3 != nil {
+		return "Back up config, commands and state to S3"
+	}
+	return "Back up config, commands and state as an archive"
+}
+
+// Category returns the command's category for menu grouping.
+func (b *BackupCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Metadata restricts the command to admins, as the archive holds the
+// config and its secrets.
+func (b *BackupCommand) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.Timeout = backupTimeout
+	meta.RequiredRole = auth.RoleAdmin.String()
+	return meta
+}
+
+// Execute writes the archive and either uploads it or leaves it for
+// FileResponse.
+func (b *BackupCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	b.setFileResponse(nil)
+
+	f, err := os.CreateTemp("", "pako-backup-"+time.Now().Format("20060102-150405")+"-*.tar.gz")
+	if err != nil {
+		return fmt.Errorf("create backup: %w", err)
+	}
+	path := f.Name()
+	size, err := b.write(ctx, f)
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if b.s3 != nil {
+		defer os.Remove(path)
+		url, err := b.s3.Upload(ctx, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(output, "Backup uploaded (%s): %s\n", formatBytes(uint64(size)), url)
+		return nil
+	}
+
+	fmt.Fprintf(output, "Backup created (%s)\n", formatBytes(uint64(size)))
+	b.setFileResponse(&pkgcmd.FileResponse{Path: path, Caption: filepath.Base(path), Cleanup: true})
+	return nil
+}
+
+// write writes the archive to f, closes it and returns its size.
+func (b *BackupCommand) write(ctx context.Context, f *os.File) (int64, error) {
+	err := backup.Create(ctx, f, b.sources)
+	size, _ := f.Seek(0, io.SeekCurrent)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("create backup: %w", closeErr)
+	}
+	return size, err
+}
+
+// FileResponse returns the archive to send, if the last run created one.
+func (b *BackupCommand) FileResponse() *pkgcmd.FileResponse {
+	b.fileMu.Lock()
+	defer b.fileMu.Unlock()
+	return b.fileResponse
+}
+
+// setFileResponse sets the file returned by FileResponse.
+func (b *BackupCommand) setFileResponse(resp *pkgcmd.FileResponse) {
+	b.fileMu.Lock()
+	defer b.fileMu.Unlock()
+	b.fileResponse = resp
+}
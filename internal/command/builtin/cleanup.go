@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/rashpile/pako-telegram/internal/msgstore"
@@ -22,6 +23,8 @@ const (
 	// All messages (text + files) options
 	CleanupAllMsgsLastHour CleanupOption = "all_msgs_last_hour"
 	CleanupAllMsgsLastDay  CleanupOption = "all_msgs_last_day"
+	// Users' own /command messages (when track_user_commands is enabled)
+	CleanupUserCommands CleanupOption = "user_commands"
 )
 
 // CleanupOptionInfo contains display information for a cleanup option.
@@ -42,6 +45,7 @@ func CleanupOptions() []CleanupOptionInfo {
 		{CleanupBeforeLastWeek, "Files older 1w", "Delete files sent more than 7 days ago"},
 		{CleanupBeforeLastMonth, "Files older 1mo", "Delete files sent more than 30 days ago"},
 		{CleanupAll, "All files", "Delete all tracked files"},
+		{CleanupUserCommands, "User commands", "Delete tracked /command messages sent by users"},
 	}
 }
 
@@ -93,19 +97,21 @@ func (c *CleanupCommand) GetEntriesToDelete(chatID int64, option CleanupOption)
 		return c.store.GetAfter(chatID, now.Add(-time.Hour))
 	case CleanupAllMsgsLastDay:
 		return c.store.GetAfter(chatID, now.Add(-24*time.Hour))
-	// File-only options (backwards compatible - GetAfterByType filters by type)
+	// File-only options (entries without a type count as files)
 	case CleanupAll:
-		return c.store.GetAll(chatID) // All tracked (mostly files for backwards compat)
+		return c.store.GetAllByType(chatID, msgstore.TypeFile)
 	case CleanupLastHour:
 		return c.store.GetAfterByType(chatID, now.Add(-time.Hour), msgstore.TypeFile)
 	case CleanupLastDay:
 		return c.store.GetAfterByType(chatID, now.Add(-24*time.Hour), msgstore.TypeFile)
 	case CleanupBeforeLastDay:
-		return c.store.GetBefore(chatID, now.Add(-24*time.Hour))
+		return c.store.GetBeforeByType(chatID, now.Add(-24*time.Hour), msgstore.TypeFile)
 	case CleanupBeforeLastWeek:
-		return c.store.GetBefore(chatID, now.Add(-7*24*time.Hour))
+		return c.store.GetBeforeByType(chatID, now.Add(-7*24*time.Hour), msgstore.TypeFile)
 	case CleanupBeforeLastMonth:
-		return c.store.GetBefore(chatID, now.Add(-30*24*time.Hour))
+		return c.store.GetBeforeByType(chatID, now.Add(-30*24*time.Hour), msgstore.TypeFile)
+	case CleanupUserCommands:
+		return c.store.GetAllByType(chatID, msgstore.TypeCommand)
 	default:
 		return nil
 	}
@@ -123,13 +129,100 @@ func (c *CleanupCommand) Execute(ctx context.Context, args []string, output io.W
 	return nil
 }
 
+// CleanupPreview counts what a cleanup option would delete.
+type CleanupPreview struct {
+	Total    int
+	ByType   map[msgstore.MessageType]int
+	LastHour int // Sent within the last hour
+	LastDay  int // Sent 1-24 hours ago
+	Older    int // Sent more than 24 hours ago
+}
+
+// Preview counts the entries an option would delete, by type and age.
+func (c *CleanupCommand) Preview(chatID int64, option CleanupOption) CleanupPreview {
+	p := CleanupPreview{ByType: make(map[msgstore.MessageType]int)}
+	now := time.Now()
+	for _, e := range c.GetEntriesToDelete(chatID, option) {
+		p.Total++
+		p.ByType[e.Kind()]++
+		switch age := now.Sub(e.SentAt); {
+		case age < time.Hour:
+			p.LastHour++
+		case age < 24*time.Hour:
+			p.LastDay++
+		default:
+			p.Older++
+		}
+	}
+	return p
+}
+
+// Summary describes the preview, e.g. "12 files, 48 text messages will be deleted".
+func (p CleanupPreview) Summary() string {
+	if p.Total == 0 {
+		return "Nothing to delete."
+	}
+
+	labels := []struct {
+		t                msgstore.MessageType
+		singular, plural string
+	}{
+		{msgstore.TypeFile, "file", "files"},
+		{msgstore.TypeText, "text message", "text messages"},
+		{msgstore.TypePrompt, "menu/prompt", "menus/prompts"},
+		{msgstore.TypeConfirmation, "confirmation", "confirmations"},
+		{msgstore.TypeCommand, "user command", "user commands"},
+	}
+	var parts []string
+	for _, l := range labels {
+		if n := p.ByType[l.t]; n > 0 {
+			label := l.plural
+			if n == 1 {
+				label = l.singular
+			}
+			parts = append(parts, fmt.Sprintf("%d %s", n, label))
+		}
+	}
+
+	text := strings.Join(parts, ", ") + " will be deleted"
+	var ages []string
+	if p.LastHour > 0 {
+		ages = append(ages, fmt.Sprintf("%d from the last hour", p.LastHour))
+	}
+	if p.LastDay > 0 {
+		ages = append(ages, fmt.Sprintf("%d from 1-24h ago", p.LastDay))
+	}
+	if p.Older > 0 {
+		ages = append(ages, fmt.Sprintf("%d older than 24h", p.Older))
+	}
+	return text + "\n(" + strings.Join(ages, ", ") + ")"
+}
+
 // ExecuteCleanup performs the actual deletion for the specified option.
 func (c *CleanupCommand) ExecuteCleanup(chatID int64, option CleanupOption) (deleted int, failed int, err error) {
 	if !c.Enabled() {
 		return 0, 0, fmt.Errorf("cleanup not enabled")
 	}
 
-	entries := c.GetEntriesToDelete(chatID, option)
+	return c.deleteEntries(chatID, c.GetEntriesToDelete(chatID, option))
+}
+
+// DeleteOlderThan deletes tracked messages of the given types sent before
+// the cutoff. Used by scheduled automatic cleanup.
+func (c *CleanupCommand) DeleteOlderThan(chatID int64, before time.Time, types []msgstore.MessageType) (deleted int, failed int, err error) {
+	if !c.Enabled() {
+		return 0, 0, fmt.Errorf("cleanup not enabled")
+	}
+
+	var entries []msgstore.Entry
+	for _, t := range types {
+		entries = append(entries, c.store.GetBeforeByType(chatID, before, t)...)
+	}
+	return c.deleteEntries(chatID, entries)
+}
+
+// deleteEntries deletes messages and stops tracking them.
+func (c *CleanupCommand) deleteEntries(chatID int64, entries []msgstore.Entry) (deleted int, failed int, err error) {
 	if len(entries) == 0 {
 		return 0, 0, nil
 	}
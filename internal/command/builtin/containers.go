@@ -0,0 +1,95 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rashpile/pako-telegram/internal/status"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// ContainerLister lists Docker containers.
+type ContainerLister interface {
+	Containers(ctx context.Context) ([]status.Container, error)
+}
+
+// ContainersCommand shows Docker container states, restarts and health.
+type ContainersCommand struct {
+	lister ContainerLister
+}
+
+// NewContainersCommand creates a containers command.
+func NewContainersCommand(lister ContainerLister) *ContainersCommand {
+	return &ContainersCommand{lister: lister}
+}
+
+// Name returns "containers".
+func (c *ContainersCommand) Name() string {
+	return "containers"
+}
+
+// Description returns the containers command description.
+func (c *ContainersCommand) Description() string {
+	return "Docker container status: /containers [problems]"
+}
+
+// Category returns the command's category for menu grouping.
+func (c *ContainersCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Execute writes the container report. With "problems", only containers
+// that are restarting, dead, unhealthy or exited with an error are listed,
+// and finding any is an error.
+func (c *ContainersCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	problemsOnly := len(args) > 0 && args[0] == "problems"
+	if len(args) > 0 && !problemsOnly {
+		return fmt.Errorf("unknown argument %q (want: problems)", args[0])
+	}
+
+	containers, err := c.lister.Containers(ctx)
+	if err != nil {
+		return err
+	}
+	if problems := WriteContainers(output, containers, problemsOnly); problems > 0 {
+		return fmt.Errorf("%d container(s) need attention", problems)
+	}
+	return nil
+}
+
+// WriteContainers writes one line per container and returns how many are
+// not OK. With problemsOnly, healthy containers are left out.
+func WriteContainers(output io.Writer, containers []status.Container, problemsOnly bool) int {
+	problems := 0
+	for _, ctr := range containers {
+		icon := "✅"
+		switch {
+		case !ctr.OK():
+			icon = "❌"
+			problems++
+		case ctr.State != "running":
+			icon = "⏹"
+		}
+		if problemsOnly && ctr.OK() {
+			continue
+		}
+
+		fmt.Fprintf(output, "%s %s (%s)\n   %s", icon, ctr.Name, ctr.Image, ctr.Status)
+		if ctr.RestartCount > 0 {
+			fmt.Fprintf(output, ", %d restarts", ctr.RestartCount)
+		}
+		fmt.Fprintln(output)
+	}
+
+	switch {
+	case len(containers) == 0:
+		fmt.Fprintln(output, "No containers")
+	case problemsOnly && problems == 0:
+		fmt.Fprintf(output, "All %d containers OK\n", len(containers))
+	}
+	return problems
+}
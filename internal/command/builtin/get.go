@@ -0,0 +1,120 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// GetCommand sends a file from the host, if it is under one of the allowed
+// paths. The file goes out through the usual [file:...] reference handling,
+// which applies size limits and picks the media type.
+type GetCommand struct {
+	allowed []string
+}
+
+// NewGetCommand creates a file fetch command limited to the allowed paths:
+// absolute directories, whose files at any depth may be sent, or files.
+func NewGetCommand(allowed []string) *GetCommand {
+	return &GetCommand{allowed: allowed}
+}
+
+// Name returns "get".
+func (g *GetCommand) Name() string {
+	return "get"
+}
+
+// Description returns the get command description.
+func (g *GetCommand) Description() string {
+	return "Send a file from the host: /get <path>"
+}
+
+// Category returns the command's category for menu grouping.
+func (g *GetCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Metadata restricts the command to operators and admins.
+func (g *GetCommand) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.RequiredRole = auth.RoleOperator.String()
+	return meta
+}
+
+// Execute writes a file reference for the requested path.
+func (g *GetCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprintln(output, "Usage: /get <path>")
+		fmt.Fprintln(output, "\nAllowed paths:")
+		for _, p := range g.allowed {
+			fmt.Fprintf(output, "  %s\n", p)
+		}
+		return nil
+	}
+
+	path, err := g.resolve(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(output, "[file:%s]\n", path)
+	return nil
+}
+
+// resolve returns the real path of the file at path, with symlinks
+// followed, or an error unless it is a regular file under an allowed path.
+func (g *GetCommand) resolve(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("not an absolute path: %s", path)
+	}
+	if strings.ContainsAny(path, "]|") {
+		return "", fmt.Errorf("unsupported characters in path: %s", path)
+	}
+
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("file not found: %s", path)
+		}
+		return "", err
+	}
+	if !g.isAllowed(real) {
+		return "", fmt.Errorf("path not allowed: %s", path)
+	}
+
+	info, err := os.Stat(real)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("not a regular file: %s", path)
+	}
+	return real, nil
+}
+
+// isAllowed returns true if the real path is one of the allowed paths or
+// lies under one. Symlinks in the allowed paths are followed too.
+func (g *GetCommand) isAllowed(real string) bool {
+	for _, allowed := range g.allowed {
+		if resolved, err := filepath.EvalSymlinks(allowed); err == nil {
+			allowed = resolved
+		}
+		rel, err := filepath.Rel(filepath.Clean(allowed), real)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
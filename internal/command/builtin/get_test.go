@@ -0,0 +1,109 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestGetResolve(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed := filepath.Join(root, "nginx")
+	outside := filepath.Join(root, "secret")
+	for _, dir := range []string{allowed, outside, filepath.Join(allowed, "sub"), filepath.Join(root, "nginx-evil")} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{
+		filepath.Join(allowed, "error.log"),
+		filepath.Join(allowed, "sub", "access.log"),
+		filepath.Join(allowed, "odd]name.log"),
+		filepath.Join(allowed, "odd|cleanup"),
+		filepath.Join(outside, "key"),
+		filepath.Join(root, "nginx-evil", "error.log"),
+		filepath.Join(root, "single.log"),
+	} {
+		if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(outside, "key"), filepath.Join(allowed, "escape.log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(allowed, "escape-dir")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(allowed, "error.log"), filepath.Join(allowed, "inside.log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Mkfifo(filepath.Join(allowed, "fifo"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGetCommand([]string{allowed, filepath.Join(root, "single.log")})
+	tests := []struct {
+		name    string
+		path    string
+		want    string // Resolved path on success
+		wantErr string
+	}{
+		{"file", allowed + "/error.log", allowed + "/error.log", ""},
+		{"nested file", allowed + "/sub/access.log", allowed + "/sub/access.log", ""},
+		{"allowed file", root + "/single.log", root + "/single.log", ""},
+		{"symlink inside", allowed + "/inside.log", allowed + "/error.log", ""},
+		{"dot segments inside", allowed + "/sub/../error.log", allowed + "/error.log", ""},
+		{"traversal", allowed + "/../secret/key", "", "not allowed"},
+		{"deep traversal", allowed + "/sub/../../secret/key", "", "not allowed"},
+		{"symlink escape", allowed + "/escape.log", "", "not allowed"},
+		{"symlinked dir escape", allowed + "/escape-dir/key", "", "not allowed"},
+		{"sibling prefix", root + "/nginx-evil/error.log", "", "not allowed"},
+		{"relative", "nginx/error.log", "", "not an absolute path"},
+		{"dot relative", "./error.log", "", "not an absolute path"},
+		{"bracket", allowed + "/odd]name.log", "", "unsupported characters"},
+		{"pipe", allowed + "/odd|cleanup", "", "unsupported characters"},
+		{"directory", allowed, "", "not a regular file"},
+		{"fifo", allowed + "/fifo", "", "not a regular file"},
+		{"missing", allowed + "/missing.log", "", "file not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.resolve(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolve(%q) = %q, %v; want error %q", tt.path, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("resolve(%q) = %q, %v; want %q", tt.path, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetExecute(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "app log.txt")
+	if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := NewGetCommand([]string{dir}).Execute(context.Background(), strings.Fields(path), &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[file:" + path + "]\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,108 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// AccessGranter manages temporary access grants.
+type AccessGranter interface {
+	Grant(id int64, username string, d time.Duration) auth.Grant
+	Revoke(id int64, username string) bool
+	Grants() []auth.Grant
+}
+
+// GrantCommand gives a chat or user temporary access.
+type GrantCommand struct {
+	granter AccessGranter
+}
+
+// NewGrantCommand creates a grant command.
+func NewGrantCommand(granter AccessGranter) *GrantCommand {
+	return &GrantCommand{granter: granter}
+}
+
+// Name returns "grant".
+func (g *GrantCommand) Name() string {
+	return "grant"
+}
+
+// Description returns the grant command description.
+func (g *GrantCommand) Description() string {
+	return "Grant temporary access: /grant <chat_id|@user> <duration>, /grant revoke <target>, /grant list"
+}
+
+// Category returns the command's category for menu grouping.
+func (g *GrantCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Metadata restricts the command to admins.
+func (g *GrantCommand) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.RequiredRole = auth.RoleAdmin.String()
+	return meta
+}
+
+// Execute grants, revokes or lists temporary access.
+func (g *GrantCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	if len(args) == 0 || args[0] == "list" {
+		return g.list(output)
+	}
+
+	if args[0] == "revoke" {
+		if len(args) != 2 {
+			return fmt.Errorf("usage: /grant revoke <chat_id|@user>")
+		}
+		id, username, err := auth.ParseGrantTarget(args[1])
+		if err != nil {
+			return err
+		}
+		if !g.granter.Revoke(id, username) {
+			return fmt.Errorf("no active grant for %s", args[1])
+		}
+		fmt.Fprintf(output, "Revoked access for %s\n", args[1])
+		return nil
+	}
+
+	if len(args) != 2 {
+		return fmt.Errorf("usage: /grant <chat_id|@user> <duration>, e.g. /grant 12345 4h")
+	}
+
+	id, username, err := auth.ParseGrantTarget(args[0])
+	if err != nil {
+		return err
+	}
+	d, err := time.ParseDuration(args[1])
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid duration %q (e.g. 30m, 4h)", args[1])
+	}
+
+	grant := g.granter.Grant(id, username, d)
+	fmt.Fprintf(output, "Granted access to %s until %s\n", grant.Target(), grant.Expires.Format("2006-01-02 15:04"))
+	return nil
+}
+
+// list writes active grants.
+func (g *GrantCommand) list(output io.Writer) error {
+	grants := g.granter.Grants()
+	if len(grants) == 0 {
+		fmt.Fprintln(output, "No active grants")
+		return nil
+	}
+
+	fmt.Fprintln(output, "Active grants:")
+	for _, grant := range grants {
+		remaining := time.Until(grant.Expires).Round(time.Minute)
+		fmt.Fprintf(output, "  %s - expires %s (in %s)\n", grant.Target(), grant.Expires.Format("2006-01-02 15:04"), remaining)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"time"
 
 	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
@@ -18,6 +19,7 @@ type CommandLister interface {
 // HelpCommand lists all available commands.
 type HelpCommand struct {
 	lister CommandLister
+	usage  StatsLister // nil = alphabetical order
 }
 
 // NewHelpCommand creates a help command.
@@ -25,6 +27,12 @@ func NewHelpCommand(lister CommandLister) *HelpCommand {
 	return &HelpCommand{lister: lister}
 }
 
+// SetUsage sets the usage statistics /help orders commands by, most-used
+// first. Without it, commands are listed alphabetically.
+func (h *HelpCommand) SetUsage(usage StatsLister) {
+	h.usage = usage
+}
+
 // Name returns "help".
 func (h *HelpCommand) Name() string {
 	return "help"
@@ -35,12 +43,24 @@ func (h *HelpCommand) Description() string {
 	return "List available commands"
 }
 
-// Execute writes the list of commands to output.
+// Execute writes the list of commands to output. Hidden commands are omitted.
 func (h *HelpCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
 	commands := h.lister.All()
 
-	// Sort by name
+	// Sort by usage, then by name; namespaces count their subcommands' runs
+	runs := h.runs(ctx)
+	usage := func(cmd pkgcmd.Command) int {
+		n := runs[cmd.Name()]
+		for _, sub := range pkgcmd.Subcommands(cmd) {
+			n += runs[sub.Name()]
+		}
+		return n
+	}
 	sort.Slice(commands, func(i, j int) bool {
+		ri, rj := usage(commands[i]), usage(commands[j])
+		if ri != rj {
+			return ri > rj
+		}
 		return commands[i].Name() < commands[j].Name()
 	})
 
@@ -48,8 +68,37 @@ func (h *HelpCommand) Execute(ctx context.Context, args []string, output io.Writ
 	fmt.Fprintln(output)
 
 	for _, cmd := range commands {
+		if pkgcmd.IsHidden(cmd) {
+			continue
+		}
+		if pkgcmd.IsDisabled(cmd) {
+			fmt.Fprintf(output, "/%s - %s (disabled)\n", cmd.Name(), cmd.Description())
+			continue
+		}
 		fmt.Fprintf(output, "/%s - %s\n", cmd.Name(), cmd.Description())
+		for _, sub := range pkgcmd.Subcommands(cmd) {
+			if !pkgcmd.IsHidden(sub) {
+				fmt.Fprintf(output, "  /%s - %s\n", sub.Name(), sub.Description())
+			}
+		}
 	}
 
 	return nil
 }
+
+// runs returns each command's run count over the default stats window. It
+// is empty without usage statistics or if they cannot be read.
+func (h *HelpCommand) runs(ctx context.Context) map[string]int {
+	runs := make(map[string]int)
+	if h.usage == nil {
+		return runs
+	}
+	stats, err := h.usage.CommandStats(ctx, time.Now().Add(-defaultStatsWindow))
+	if err != nil {
+		return runs
+	}
+	for _, st := range stats {
+		runs[st.Command] = st.Runs
+	}
+	return runs
+}
@@ -0,0 +1,74 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// MaintenanceSwitch turns maintenance mode on and off.
+type MaintenanceSwitch interface {
+	SetMaintenance(on bool)
+	Maintenance() bool
+}
+
+// MaintenanceCommand shows and toggles maintenance mode, in which commands
+// not marked read_only are rendered and logged but not run.
+type MaintenanceCommand struct {
+	sw MaintenanceSwitch
+}
+
+// NewMaintenanceCommand creates a maintenance command.
+func NewMaintenanceCommand(sw MaintenanceSwitch) *MaintenanceCommand {
+	return &MaintenanceCommand{sw: sw}
+}
+
+// Name returns "maintenance".
+func (m *MaintenanceCommand) Name() string {
+	return "maintenance"
+}
+
+// Description returns the maintenance command description.
+func (m *MaintenanceCommand) Description() string {
+	return "Dry-run commands that aren't read-only: /maintenance [on or off]"
+}
+
+// Category returns the command's category for menu grouping.
+func (m *MaintenanceCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Metadata restricts the command to admins.
+func (m *MaintenanceCommand) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.RequiredRole = auth.RoleAdmin.String()
+	return meta
+}
+
+// Execute switches maintenance mode on or off, or reports it without
+// arguments.
+func (m *MaintenanceCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "on":
+			m.sw.SetMaintenance(true)
+		case "off":
+			m.sw.SetMaintenance(false)
+		default:
+			return fmt.Errorf("usage: /maintenance [on or off]")
+		}
+	}
+
+	if m.sw.Maintenance() {
+		fmt.Fprintln(output, "🚧 Maintenance mode is on: commands not marked read_only are shown but not run")
+	} else {
+		fmt.Fprintln(output, "Maintenance mode is off")
+	}
+	return nil
+}
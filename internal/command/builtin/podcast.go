@@ -1,14 +1,21 @@
 package builtin
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/rashpile/pako-telegram/internal/command"
 	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
@@ -17,12 +24,34 @@ type PodcastConfig struct {
 	PodcastgenPath string // Path to podcastgen directory
 	ConfigPath     string // Path to TTS config.yml
 	TempDir        string // Temp directory for files
+	Voices         []string
+	Speeds         []string
+	ChunkSize      int           // Characters per podcastgen run; longer text is split (0 = never)
+	Parallel       int           // Chunks generated at the same time
+	Timeout        time.Duration // Limit for a whole /podcast run
+	VoiceNote      bool          // Send the result as a voice message instead of an MP3
+	MaxJobs        int           // Podcasts generated at the same time; others wait in a queue
 }
 
+// Podcast argument names. Text is the first argument, so text typed after
+// /podcast fills it.
+const (
+	PodcastText  = "text"
+	PodcastVoice = "voice"
+	PodcastSpeed = "speed"
+)
+
+// podcastCancel as the text cancels the chat's podcast jobs.
+const podcastCancel = "cancel"
+
+// podcastQueueWait is how long a job may wait in the queue before it
+// times out.
+const podcastQueueWait = time.Hour
+
 // PodcastCommand generates audio from text using podcastgen.
 type PodcastCommand struct {
-	cfg          PodcastConfig
-	fileResponse *pkgcmd.FileResponse
+	cfg   PodcastConfig
+	queue *podcastQueue
 }
 
 // NewPodcastCommand creates a podcast command.
@@ -31,9 +60,21 @@ func NewPodcastCommand(cfg PodcastConfig) *PodcastCommand {
 	if cfg.TempDir == "" {
 		cfg.TempDir = os.TempDir()
 	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Minute
+	}
 	os.MkdirAll(cfg.TempDir, 0755)
 
-	return &PodcastCommand{cfg: cfg}
+	return &PodcastCommand{cfg: cfg, queue: newPodcastQueue(cfg.MaxJobs)}
+}
+
+// Reconfigure returns a podcast command with cfg that shares p's job queue,
+// so jobs started before a config reload stay queued and cancellable.
+func (p *PodcastCommand) Reconfigure(cfg PodcastConfig) *PodcastCommand {
+	next := NewPodcastCommand(cfg)
+	next.queue = p.queue
+	next.queue.setLimit(cfg.MaxJobs)
+	return next
 }
 
 // Name returns "podcast".
@@ -43,82 +84,422 @@ func (p *PodcastCommand) Name() string {
 
 // Description returns the podcast description.
 func (p *PodcastCommand) Description() string {
-	return "Generate audio from text (send multi-line text after command)"
+	return "Generate audio from text or a link (send multi-line text or a URL after command)"
+}
+
+// Arguments returns the text, voice and speed arguments when voices or
+// speeds are configured to choose from. Without them, the text after
+// /podcast is the only input.
+func (p *PodcastCommand) Arguments() []command.ArgumentDef {
+	if len(p.cfg.Voices) == 0 && len(p.cfg.Speeds) == 0 {
+		return nil
+	}
+
+	args := []command.ArgumentDef{{
+		Name:        PodcastText,
+		Description: "Send the text or a link to convert to audio",
+		Required:    true,
+		Type:        "string",
+	}}
+	if len(p.cfg.Voices) > 0 {
+		args = append(args, command.ArgumentDef{
+			Name:        PodcastVoice,
+			Description: "Choose a voice",
+			Type:        "choice",
+			Choices:     p.cfg.Voices,
+			Default:     p.cfg.Voices[0],
+		})
+	}
+	if len(p.cfg.Speeds) > 0 {
+		args = append(args, command.ArgumentDef{
+			Name:        PodcastSpeed,
+			Description: "Choose the speech speed",
+			Type:        "choice",
+			Choices:     p.cfg.Speeds,
+			Default:     p.cfg.Speeds[0],
+		})
+	}
+	return args
+}
+
+// HasArguments returns true if voice or speed are chosen per run.
+func (p *PodcastCommand) HasArguments() bool {
+	return len(p.Arguments()) > 0
 }
 
-// Execute generates audio from the provided text.
+// ArgumentTimeout returns 0 to use the default argument timeout.
+func (p *PodcastCommand) ArgumentTimeout() time.Duration {
+	return 0
+}
+
+// RunChoicesCommand is not supported: podcast choices come from the config.
+func (p *PodcastCommand) RunChoicesCommand(ctx context.Context, choicesCmd string) ([]string, error) {
+	return nil, errors.New("podcast arguments have no choices_command")
+}
+
+// SkipsArguments reports whether text is "cancel", which needs no voice or
+// speed.
+func (p *PodcastCommand) SkipsArguments(text string) bool {
+	return strings.TrimSpace(text) == podcastCancel
+}
+
+// parseArgs returns the podcast options in args. Collected arguments arrive
+// as "name=value" pairs; otherwise the single argument is the text.
+func (p *PodcastCommand) parseArgs(args []string) (text, voice, speed string, err error) {
+	if len(args) == 1 && !strings.HasPrefix(args[0], PodcastText+"=") {
+		return args[0], "", "", nil
+	}
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		switch name {
+		case PodcastText:
+			text = value
+		case PodcastVoice:
+			if !slices.Contains(p.cfg.Voices, value) {
+				return "", "", "", fmt.Errorf("unknown voice %q", value)
+			}
+			voice = value
+		case PodcastSpeed:
+			if !slices.Contains(p.cfg.Speeds, value) {
+				return "", "", "", fmt.Errorf("unsupported speed %q", value)
+			}
+			speed = value
+		default:
+			return "", "", "", fmt.Errorf("unknown argument %q", name)
+		}
+	}
+	return text, voice, speed, nil
+}
+
+// Execute generates audio from the provided text, with the chosen voice and
+// speed if any. The audio is returned for this run with
+// pkgcmd.SetFileResponse, as runs from several chats overlap.
 func (p *PodcastCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
-	// Reset file response
-	p.fileResponse = nil
+	text, voice, speed, err := p.parseArgs(args)
+	if err != nil {
+		return err
+	}
 
 	// Validate input
-	if len(args) == 0 || args[0] == "" {
-		return fmt.Errorf("no text provided. Usage: /podcast followed by your text")
+	if text == "" {
+		return fmt.Errorf("no text provided. Usage: /podcast followed by your text or a link")
 	}
 
-	text := args[0]
-	fmt.Fprintf(output, "Generating audio for %d characters...\n", len(text))
+	chatID, _ := pkgcmd.ChatID(ctx)
+	if strings.TrimSpace(text) == podcastCancel {
+		if n := p.queue.cancelChat(chatID); n > 0 {
+			fmt.Fprintf(output, "Cancelled %d podcast job(s)\n", n)
+		} else {
+			fmt.Fprintln(output, "No podcast jobs to cancel")
+		}
+		return nil
+	}
 
-	// Create unique temp files
-	timestamp := time.Now().UnixNano()
-	inputPath := filepath.Join(p.cfg.TempDir, fmt.Sprintf("podcast_input_%d.txt", timestamp))
-	outputPath := filepath.Join(p.cfg.TempDir, fmt.Sprintf("podcast_output_%d.mp3", timestamp))
+	jobCtx, job := p.queue.add(ctx, chatID)
+	defer p.queue.done(job)
 
-	// Write input file
+	if link, ok := articleURL(text); ok {
+		if text, err = p.fetchText(jobCtx, link, output); err != nil {
+			return p.jobError(ctx, jobCtx, err)
+		}
+	}
+
+	if err := p.waitTurn(jobCtx, job, output); err != nil {
+		return p.jobError(ctx, jobCtx, err)
+	}
+	genCtx, cancel := context.WithTimeout(jobCtx, p.cfg.Timeout)
+	defer cancel()
+
+	chunks := splitText(text, p.cfg.ChunkSize)
+	if len(chunks) == 1 {
+		fmt.Fprintf(output, "Generating audio for %d characters...\n", len(text))
+	} else {
+		fmt.Fprintf(output, "Generating audio for %d characters in %d chunks...\n", len(text), len(chunks))
+	}
+
+	base := filepath.Join(p.cfg.TempDir, fmt.Sprintf("podcast_%d", time.Now().UnixNano()))
+	outputPath := base + ".mp3"
+	if len(chunks) == 1 {
+		err = p.generate(genCtx, text, outputPath, voice, speed, output)
+	} else {
+		err = p.generateChunks(genCtx, chunks, base, voice, speed, output)
+	}
+	if err != nil {
+		os.Remove(outputPath)
+		return p.jobError(ctx, genCtx, err)
+	}
+
+	// Check if output file exists
+	if _, err := os.Stat(outputPath); err != nil {
+		return fmt.Errorf("output file not created")
+	}
+
+	// Return the file for the bot to send
+	if !pkgcmd.SetFileResponse(ctx, &pkgcmd.FileResponse{
+		Path:    outputPath,
+		Caption: "Generated audio",
+		Cleanup: true,
+		Voice:   p.cfg.VoiceNote,
+	}) {
+		os.Remove(outputPath)
+		return fmt.Errorf("no chat to send the audio to")
+	}
+
+	fmt.Fprintln(output, "Audio generated successfully!")
+	return nil
+}
+
+// waitTurn reports the job's queue position until it may start generating.
+func (p *PodcastCommand) waitTurn(ctx context.Context, job *podcastJob, output io.Writer) error {
+	last := 0
+	for {
+		if pos := p.queue.position(job); pos > 0 && pos != last {
+			fmt.Fprintf(output, "Position %d in queue...\n", pos)
+			last = pos
+		}
+		select {
+		case <-job.ready:
+			return nil
+		case <-job.updates:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// jobError explains err if the job's context ended: cancelled with /podcast
+// cancel, or timed out.
+func (p *PodcastCommand) jobError(ctx, jobCtx context.Context, err error) error {
+	switch {
+	case jobCtx.Err() == nil:
+		return err
+	case ctx.Err() == nil && errors.Is(jobCtx.Err(), context.Canceled):
+		return fmt.Errorf("podcast cancelled")
+	default:
+		return fmt.Errorf("generation timed out or cancelled")
+	}
+}
+
+// fetchText returns the readable text of the page at link, starting with
+// its title.
+func (p *PodcastCommand) fetchText(ctx context.Context, link string, output io.Writer) (string, error) {
+	fmt.Fprintf(output, "Fetching %s...\n", link)
+	title, text, err := fetchArticle(ctx, link)
+	if err != nil {
+		return "", fmt.Errorf("fetch page: %w", err)
+	}
+	if text == "" {
+		return "", fmt.Errorf("no readable text found at %s", link)
+	}
+	if title != "" {
+		fmt.Fprintf(output, "Extracted %q\n", title)
+		if !strings.HasPrefix(text, title) {
+			text = title + "\n\n" + text
+		}
+	}
+	return text, nil
+}
+
+// generate runs podcastgen on text, writing the MP3 to outputPath and
+// podcastgen's output to log.
+func (p *PodcastCommand) generate(ctx context.Context, text, outputPath, voice, speed string, log io.Writer) error {
+	inputPath := strings.TrimSuffix(outputPath, ".mp3") + ".txt"
 	if err := os.WriteFile(inputPath, []byte(text), 0644); err != nil {
 		return fmt.Errorf("failed to create input file: %w", err)
 	}
 	defer os.Remove(inputPath) // Always cleanup input file
 
-	fmt.Fprintln(output, "Input file created, starting TTS generation...")
-
-	// Run podcastgen
-	cmd := exec.CommandContext(ctx,
-		"uv", "run", "python", "-m", "tts_gen.cli",
+	cliArgs := []string{
+		"run", "python", "-m", "tts_gen.cli",
 		"--input", inputPath,
 		"--output", outputPath,
 		"--config", p.cfg.ConfigPath,
-	)
+	}
+	if voice != "" {
+		cliArgs = append(cliArgs, "--voice", voice)
+	}
+	if speed != "" {
+		cliArgs = append(cliArgs, "--speed", speed)
+	}
+	cmd := exec.CommandContext(ctx, "uv", cliArgs...)
 	cmd.Dir = p.cfg.PodcastgenPath
-	cmd.Stdout = output
-	cmd.Stderr = output
+	cmd.Stdout = log
+	cmd.Stderr = log
 
 	if err := cmd.Run(); err != nil {
-		// Cleanup output file on error
-		os.Remove(outputPath)
-		if ctx.Err() != nil {
-			return fmt.Errorf("generation timed out or cancelled")
-		}
 		return fmt.Errorf("podcastgen failed: %w", err)
 	}
+	return nil
+}
 
-	// Check if output file exists
-	if _, err := os.Stat(outputPath); err != nil {
-		return fmt.Errorf("output file not created")
+// generateChunks generates the chunks' audio, up to Parallel at a time, and
+// joins it into base+".mp3". Progress is reported per chunk; podcastgen's
+// output is only shown for a failed chunk.
+func (p *PodcastCommand) generateChunks(ctx context.Context, chunks []string, base, voice, speed string, output io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parts := make([]string, len(chunks))
+	for i := range chunks {
+		parts[i] = fmt.Sprintf("%s_part%03d.mp3", base, i)
 	}
+	defer func() {
+		for _, part := range parts {
+			os.Remove(part)
+		}
+	}()
 
-	fmt.Fprintln(output, "Audio generated successfully!")
+	var (
+		mu       sync.Mutex
+		done     int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, max(p.cfg.Parallel, 1))
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
 
-	// Set file response for bot to send
-	p.fileResponse = &pkgcmd.FileResponse{
-		Path:    outputPath,
-		Caption: "Generated audio",
-		Cleanup: true,
+			var log bytes.Buffer
+			err := p.generate(ctx, chunk, parts[i], voice, speed, &log)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil && ctx.Err() == nil {
+					firstErr = fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+					output.Write(log.Bytes())
+				}
+				cancel()
+				return
+			}
+			done++
+			fmt.Fprintf(output, "Generating: %d%% (%d/%d chunks)\n", done*100/len(chunks), done, len(chunks))
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
+	fmt.Fprintln(output, "Joining chunks...")
+	return concatAudio(ctx, parts, base+".mp3")
+}
+
+// concatAudio joins MP3 files with ffmpeg's concat demuxer, without
+// re-encoding.
+func concatAudio(ctx context.Context, parts []string, outputPath string) error {
+	var list strings.Builder
+	for _, part := range parts {
+		fmt.Fprintf(&list, "file '%s'\n", strings.ReplaceAll(part, "'", `'\''`))
+	}
+	listPath := strings.TrimSuffix(outputPath, ".mp3") + "_parts.txt"
+	if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+		return fmt.Errorf("failed to create chunk list: %w", err)
+	}
+	defer os.Remove(listPath)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-loglevel", "error",
+		"-f", "concat", "-safe", "0", "-i", listPath, "-c", "copy", outputPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
 	return nil
 }
 
+// splitText splits text into chunks of at most size characters, breaking
+// between paragraphs, then sentences, then words. A size <= 0 keeps text
+// whole.
+func splitText(text string, size int) []string {
+	if size <= 0 || utf8.RuneCountInString(text) <= size {
+		return []string{text}
+	}
+
+	var chunks []string
+	var cur strings.Builder
+	curLen := 0
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+		cur.Reset()
+		curLen = 0
+	}
+	add := func(piece, sep string) {
+		n := utf8.RuneCountInString(piece)
+		if curLen > 0 && curLen+len(sep)+n > size {
+			flush()
+		}
+		if curLen > 0 {
+			cur.WriteString(sep)
+			curLen += len(sep)
+		}
+		cur.WriteString(piece)
+		curLen += n
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		if utf8.RuneCountInString(para) <= size {
+			add(para, "\n\n")
+			continue
+		}
+		sep := "\n\n" // Before the paragraph's first piece
+		for _, sentence := range splitSentences(para) {
+			if utf8.RuneCountInString(sentence) <= size {
+				add(sentence, sep)
+				sep = " "
+				continue
+			}
+			for _, word := range strings.Fields(sentence) {
+				add(word, sep) // A word longer than size is its own chunk
+				sep = " "
+			}
+		}
+	}
+	flush()
+	return chunks
+}
+
+// splitSentences splits text after sentence-ending punctuation followed by
+// whitespace.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(text)-1; i++ {
+		if strings.IndexByte(".!?", text[i]) >= 0 && (text[i+1] == ' ' || text[i+1] == '\n') {
+			sentences = append(sentences, strings.TrimSpace(text[start:i+1]))
+			start = i + 2
+		}
+	}
+	if rest := strings.TrimSpace(text[start:]); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
 // Metadata returns command configuration.
 func (p *PodcastCommand) Metadata() pkgcmd.Metadata {
 	return pkgcmd.Metadata{
-		Timeout:        10 * time.Minute, // TTS can take a while
+		Timeout:        podcastQueueWait + p.cfg.Timeout, // Waiting in the queue, then TTS
 		MaxOutput:      10000,
 		RequireConfirm: false,
 	}
 }
 
-// FileResponse returns the file to send after execution.
+// FileResponse returns nil: each run returns its audio through its context
+// (see Execute). Implementing it has the bot pass the text after /podcast
+// whole, with its newlines.
 func (p *PodcastCommand) FileResponse() *pkgcmd.FileResponse {
-	return p.fileResponse
+	return nil
 }
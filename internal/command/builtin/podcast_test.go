@@ -0,0 +1,205 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// fakePodcastgen puts a uv on PATH that "generates" audio by copying the
+// input text to the output file after a short delay.
+func fakePodcastgen(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--input) in="$2" ;;
+	--output) out="$2" ;;
+	esac
+	shift
+done
+sleep 0.2
+cp "$in" "$out"
+`
+	if err := os.WriteFile(filepath.Join(bin, "uv"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// syncBuffer is a bytes.Buffer safe to read while a command writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestPodcastConcurrentRuns(t *testing.T) {
+	fakePodcastgen(t)
+	p := NewPodcastCommand(PodcastConfig{TempDir: t.TempDir(), MaxJobs: 2})
+
+	texts := map[int64]string{1: "Audio for the first chat.", 2: "Audio for the second chat."}
+	files := make(map[int64]*pkgcmd.FileResponse)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for chatID, text := range texts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, runFile := pkgcmd.WithFileResponseSlot(pkgcmd.WithChatID(context.Background(), chatID))
+			var out bytes.Buffer
+			if err := p.Execute(ctx, []string{text}, &out); err != nil {
+				t.Errorf("chat %d: Execute() = %v\n%s", chatID, err, out.String())
+				return
+			}
+			mu.Lock()
+			files[chatID] = runFile()
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for chatID, text := range texts {
+		resp := files[chatID]
+		if resp == nil {
+			t.Errorf("chat %d got no file", chatID)
+			continue
+		}
+		data, err := os.ReadFile(resp.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != text {
+			t.Errorf("chat %d got %q, want %q", chatID, data, text)
+		}
+		if !resp.Cleanup {
+			t.Errorf("chat %d: file not marked for cleanup", chatID)
+		}
+	}
+	if p.FileResponse() != nil {
+		t.Error("FileResponse() shared a run's file")
+	}
+}
+
+func TestPodcastWithoutSlot(t *testing.T) {
+	fakePodcastgen(t)
+	dir := t.TempDir()
+	p := NewPodcastCommand(PodcastConfig{TempDir: dir})
+
+	var out bytes.Buffer
+	if err := p.Execute(context.Background(), []string{"Nobody to send to."}, &out); err == nil {
+		t.Fatal("Execute() = nil without a file slot")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("left %d files in the temp dir", len(entries))
+	}
+}
+
+func TestPodcastCancelQueued(t *testing.T) {
+	p := NewPodcastCommand(PodcastConfig{TempDir: t.TempDir(), MaxJobs: 1})
+	busyCtx, busy := p.queue.add(context.Background(), 1) // Another chat's podcast holds the only slot
+	defer p.queue.done(busy)
+
+	ctx := pkgcmd.WithChatID(context.Background(), 2)
+	out := &syncBuffer{}
+	errc := make(chan error, 1)
+	go func() { errc <- p.Execute(ctx, []string{"Waiting in line."}, out) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "Position 1 in queue") {
+		if time.Now().After(deadline) {
+			t.Fatalf("job not queued, output %q", out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var cancelOut bytes.Buffer
+	if err := p.Execute(ctx, []string{"cancel"}, &cancelOut); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cancelOut.String(), "Cancelled 1 podcast job(s)") {
+		t.Errorf("cancel output = %q", cancelOut.String())
+	}
+
+	select {
+	case err := <-errc:
+		if err == nil || err.Error() != "podcast cancelled" {
+			t.Errorf("queued Execute() = %v, want podcast cancelled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued job didn't end after cancel")
+	}
+	if busyCtx.Err() != nil {
+		t.Error("cancel stopped another chat's podcast")
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		size int
+		want []string
+	}{
+		{"empty", "", 10, []string{""}},
+		{"no limit", "One. Two. Three.", 0, []string{"One. Two. Three."}},
+		{"fits", "One. Two.", 20, []string{"One. Two."}},
+		{"paragraphs", "First part.\n\nSecond part.", 15, []string{"First part.", "Second part."}},
+		{"paragraphs together", "One.\n\nTwo.\n\nThree four five.", 12, []string{"One.\n\nTwo.", "Three four", "five."}},
+		{"sentences", "First one. Second one! Third one?", 24, []string{"First one. Second one!", "Third one?"}},
+		{"sentence over the limit", "This sentence is much too long for one chunk.", 16, []string{"This sentence is", "much too long", "for one chunk."}},
+		{"word over the limit", "Supercalifragilistic word", 10, []string{"Supercalifragilistic", "word"}},
+		{"multibyte", "Привет, мир. Как дела? Всё хорошо.", 12, []string{"Привет, мир.", "Как дела?", "Всё хорошо."}},
+		{"multibyte fits by characters", "Ключ. Замок.", 12, []string{"Ключ. Замок."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitText(tt.text, tt.size)
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("splitText(%q, %d) = %q, want %q", tt.text, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"No ending", []string{"No ending"}},
+		{"One. Two! Three? Four", []string{"One.", "Two!", "Three?", "Four"}},
+		{"Line one.\nLine two.", []string{"Line one.", "Line two."}},
+		{"Version 1.2 is out. Update", []string{"Version 1.2 is out.", "Update"}},
+		{"Wait... what?", []string{"Wait...", "what?"}},
+		{"Trailing space. ", []string{"Trailing space."}},
+		{"Ja. Всё ясно. 日本語。", []string{"Ja.", "Всё ясно.", "日本語。"}},
+	}
+	for _, tt := range tests {
+		if got := splitSentences(tt.text); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+			t.Errorf("splitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package builtin
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// podcastJob is a /podcast run, waiting in the queue or generating.
+type podcastJob struct {
+	chatID  int64
+	cancel  context.CancelFunc
+	ready   chan struct{} // Closed when the job may start generating
+	updates chan struct{} // Signalled when the job's queue position changes
+}
+
+// podcastQueue limits how many podcasts are generated at once. Jobs beyond
+// the limit wait in order. Safe for concurrent use.
+type podcastQueue struct {
+	mu      sync.Mutex
+	limit   int
+	running int
+	waiting []*podcastJob
+	jobs    []*podcastJob // All queued and running jobs
+}
+
+// newPodcastQueue creates a queue running up to limit jobs at once.
+func newPodcastQueue(limit int) *podcastQueue {
+	return &podcastQueue{limit: max(limit, 1)}
+}
+
+// setLimit changes how many jobs run at once, starting waiting jobs if the
+// limit grew.
+func (q *podcastQueue) setLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = max(limit, 1)
+	q.startWaiting()
+}
+
+// add registers a job for chatID. The job's context is cancelled by
+// cancelChat; call done when the job ends.
+func (q *podcastQueue) add(ctx context.Context, chatID int64) (context.Context, *podcastJob) {
+	ctx, cancel := context.WithCancel(ctx)
+	job := &podcastJob{
+		chatID:  chatID,
+		cancel:  cancel,
+		ready:   make(chan struct{}),
+		updates: make(chan struct{}, 1),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs = append(q.jobs, job)
+	if q.running < q.limit && len(q.waiting) == 0 {
+		q.running++
+		close(job.ready)
+	} else {
+		q.waiting = append(q.waiting, job)
+	}
+	return ctx, job
+}
+
+// position returns the job's 1-based place in the queue, or 0 once it may
+// start.
+func (q *podcastQueue) position(job *podcastJob) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Index(q.waiting, job) + 1
+}
+
+// done removes the job and lets the next waiting job start.
+func (q *podcastQueue) done(job *podcastJob) {
+	job.cancel()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs = slices.DeleteFunc(q.jobs, func(j *podcastJob) bool { return j == job })
+
+	if i := slices.Index(q.waiting, job); i >= 0 {
+		q.waiting = slices.Delete(q.waiting, i, i+1)
+	} else {
+		q.running--
+	}
+	q.startWaiting()
+}
+
+// startWaiting starts waiting jobs while there is room and tells the rest
+// their positions changed. Called with mu held.
+func (q *podcastQueue) startWaiting() {
+	for q.running < q.limit && len(q.waiting) > 0 {
+		next := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		q.running++
+		close(next.ready)
+	}
+	for _, j := range q.waiting {
+		select {
+		case j.updates <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// cancelChat cancels the chat's queued and running jobs and returns how
+// many there were.
+func (q *podcastQueue) cancelChat(chatID int64) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, job := range q.jobs {
+		if job.chatID == chatID {
+			job.cancel()
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,62 @@
+package builtin
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPodcastQueuePosition(t *testing.T) {
+	q := newPodcastQueue(1)
+	_, first := q.add(context.Background(), 1)
+	_, second := q.add(context.Background(), 2)
+	_, third := q.add(context.Background(), 3)
+
+	for job, want := range map[*podcastJob]int{first: 0, second: 1, third: 2} {
+		if got := q.position(job); got != want {
+			t.Errorf("chat %d position = %d, want %d", job.chatID, got, want)
+		}
+	}
+
+	q.done(first)
+	select {
+	case <-second.ready:
+	default:
+		t.Fatal("second job not started after the first was done")
+	}
+	if got := q.position(third); got != 1 {
+		t.Errorf("third position = %d after the first was done, want 1", got)
+	}
+	select {
+	case <-third.updates:
+	default:
+		t.Error("third job not told its position changed")
+	}
+
+	q.done(third) // Leaves the queue without running
+	q.done(second)
+	if q.running != 0 || len(q.waiting) != 0 || len(q.jobs) != 0 {
+		t.Errorf("queue not empty: running %d, waiting %d, jobs %d", q.running, len(q.waiting), len(q.jobs))
+	}
+}
+
+func TestPodcastQueueCancelChat(t *testing.T) {
+	q := newPodcastQueue(1)
+	runCtx, running := q.add(context.Background(), 1)
+	waitCtx, waiting := q.add(context.Background(), 2)
+
+	if n := q.cancelChat(2); n != 1 {
+		t.Errorf("cancelChat(2) = %d, want 1", n)
+	}
+	if waitCtx.Err() == nil {
+		t.Error("queued job's context not cancelled")
+	}
+	if runCtx.Err() != nil {
+		t.Error("another chat's job was cancelled")
+	}
+
+	q.done(waiting)
+	q.done(running)
+	if q.cancelChat(2) != 0 {
+		t.Error("cancelChat() counted a finished job")
+	}
+}
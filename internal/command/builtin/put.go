@@ -0,0 +1,163 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	"github.com/rashpile/pako-telegram/internal/inbox"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// UploadStore holds files uploaded to the bot.
+type UploadStore interface {
+	// Contains returns true if path is a file uploaded in the chat.
+	Contains(chatID int64, path string) bool
+}
+
+// PutRecorder records files saved by /put in the audit log. The user is
+// taken from ctx.
+type PutRecorder interface {
+	RecordPut(ctx context.Context, src, dest string)
+}
+
+// PutConfig holds the /put destination and limits.
+type PutConfig struct {
+	Dir       string // Destination directory
+	MaxSize   int64  // Largest file saved, in bytes
+	Overwrite bool   // Replace existing files instead of refusing
+}
+
+// PutCommand saves a document sent with /put as caption to the destination
+// directory under the name it was uploaded with.
+type PutCommand struct {
+	uploads  UploadStore
+	recorder PutRecorder
+	cfg      PutConfig
+}
+
+// NewPutCommand creates an upload command saving files from uploads and
+// recording them with recorder.
+func NewPutCommand(uploads UploadStore, recorder PutRecorder, cfg PutConfig) *PutCommand {
+	return &PutCommand{uploads: uploads, recorder: recorder, cfg: cfg}
+}
+
+// Name returns "put".
+func (p *PutCommand) Name() string {
+	return "put"
+}
+
+// Description returns the put command description.
+func (p *PutCommand) Description() string {
+	return "Save a document to the host: send it with /put as caption"
+}
+
+// Category returns the command's category for menu grouping.
+func (p *PutCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Metadata restricts the command to admins.
+func (p *PutCommand) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.RequiredRole = auth.RoleAdmin.String()
+	return meta
+}
+
+// Input returns pkgcmd.InputDocument: the bot saves the uploaded document
+// to the inbox and passes its path.
+func (p *PutCommand) Input() string {
+	return pkgcmd.InputDocument
+}
+
+// Execute moves the file uploaded in the chat from the inbox to the
+// destination directory.
+func (p *PutCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	// Typed arguments aren't uploads; only the chat's own files in the inbox
+	// are moved
+	chatID, ok := pkgcmd.ChatID(ctx)
+	if len(args) != 1 || !ok || !p.uploads.Contains(chatID, args[0]) {
+		fmt.Fprintf(output, "Send a document with /put as caption to save it to %s\n", p.cfg.Dir)
+		return nil
+	}
+	src := args[0]
+	defer os.Remove(src)
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if info.Size() > p.cfg.MaxSize {
+		return fmt.Errorf("file is %d bytes, over the %d byte limit", info.Size(), p.cfg.MaxSize)
+	}
+
+	dest := filepath.Join(p.cfg.Dir, inbox.Name(src))
+	replaced, err := p.save(src, dest)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("file saved by /put", "chat_id", chatID, "source", src, "path", dest, "size", info.Size(), "replaced", replaced)
+	p.recorder.RecordPut(ctx, src, dest)
+	if replaced {
+		fmt.Fprintf(output, "Replaced %s (%d bytes)\n", dest, info.Size())
+	} else {
+		fmt.Fprintf(output, "Saved %s (%d bytes)\n", dest, info.Size())
+	}
+	return nil
+}
+
+// save copies src to dest. An existing dest is an error unless overwrite is
+// configured, and is then replaced in one step. It returns true if dest
+// existed.
+func (p *PutCommand) save(src, dest string) (bool, error) {
+	if err := os.MkdirAll(p.cfg.Dir, 0755); err != nil {
+		return false, err
+	}
+
+	// Write next to dest and rename, so dest is never partly written
+	tmp, err := os.CreateTemp(p.cfg.Dir, ".put-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	in, err := os.Open(src)
+	if err != nil {
+		tmp.Close()
+		return false, err
+	}
+	defer in.Close()
+
+	_, err = io.Copy(tmp, in)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return false, err
+	}
+
+	if p.cfg.Overwrite {
+		_, statErr := os.Stat(dest)
+		return statErr == nil, os.Rename(tmp.Name(), dest)
+	}
+	// Unlike rename, link refuses to replace an existing dest
+	if err := os.Link(tmp.Name(), dest); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, fmt.Errorf("%s already exists", dest)
+		}
+		return false, err
+	}
+	return false, nil
+}
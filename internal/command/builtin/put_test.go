@@ -0,0 +1,166 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rashpile/pako-telegram/internal/inbox"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// putRecords collects the files recorded by /put.
+type putRecords []string
+
+func (r *putRecords) RecordPut(ctx context.Context, src, dest string) {
+	*r = append(*r, dest)
+}
+
+// putFixture is an inbox and a /put destination in temp directories.
+type putFixture struct {
+	t       *testing.T
+	inbox   *inbox.Inbox
+	dest    string
+	records putRecords
+}
+
+func newPutFixture(t *testing.T) *putFixture {
+	in, err := inbox.New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &putFixture{t: t, inbox: in, dest: filepath.Join(t.TempDir(), "conf")}
+}
+
+// upload saves content to the chat's inbox as name.
+func (f *putFixture) upload(chatID int64, name, content string) string {
+	path, err := f.inbox.Save(chatID, name, strings.NewReader(content), 0)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return path
+}
+
+// put runs /put in the chat with the given limits.
+func (f *putFixture) put(chatID int64, src string, maxSize int64, overwrite bool) (string, error) {
+	cmd := NewPutCommand(f.inbox, &f.records, PutConfig{Dir: f.dest, MaxSize: maxSize, Overwrite: overwrite})
+	var out bytes.Buffer
+	err := cmd.Execute(pkgcmd.WithChatID(context.Background(), chatID), []string{src}, &out)
+	return out.String(), err
+}
+
+// content returns the destination file's content, or "" if it is missing.
+func (f *putFixture) content(name string) string {
+	data, _ := os.ReadFile(filepath.Join(f.dest, name))
+	return string(data)
+}
+
+func TestPutSaves(t *testing.T) {
+	f := newPutFixture(t)
+	src := f.upload(1, "app.conf", "a=1")
+
+	out, err := f.put(1, src, 100, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "Saved ") {
+		t.Errorf("output = %q, want Saved", out)
+	}
+	if got := f.content("app.conf"); got != "a=1" {
+		t.Errorf("content = %q, want a=1", got)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("upload left in the inbox: %v", err)
+	}
+	if len(f.records) != 1 || f.records[0] != filepath.Join(f.dest, "app.conf") {
+		t.Errorf("records = %v", f.records)
+	}
+	if entries, _ := os.ReadDir(f.dest); len(entries) != 1 {
+		t.Errorf("destination has %d entries, want only the file", len(entries))
+	}
+}
+
+func TestPutRefusesOverwrite(t *testing.T) {
+	f := newPutFixture(t)
+	if _, err := f.put(1, f.upload(1, "app.conf", "a=1"), 100, false); err != nil {
+		t.Fatal(err)
+	}
+
+	src := f.upload(1, "app.conf", "a=2")
+	if _, err := f.put(1, src, 100, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("err = %v, want already exists", err)
+	}
+	if got := f.content("app.conf"); got != "a=1" {
+		t.Errorf("content = %q, want the original a=1", got)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("refused upload left in the inbox: %v", err)
+	}
+	if entries, _ := os.ReadDir(f.dest); len(entries) != 1 {
+		t.Errorf("destination has %d entries, want no temp files left", len(entries))
+	}
+	if len(f.records) != 1 {
+		t.Errorf("records = %v, want only the first save", f.records)
+	}
+}
+
+func TestPutReplaces(t *testing.T) {
+	f := newPutFixture(t)
+	if _, err := f.put(1, f.upload(1, "app.conf", "a=1"), 100, true); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := f.put(1, f.upload(1, "app.conf", "a=2"), 100, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "Replaced ") {
+		t.Errorf("output = %q, want Replaced", out)
+	}
+	if got := f.content("app.conf"); got != "a=2" {
+		t.Errorf("content = %q, want a=2", got)
+	}
+}
+
+func TestPutSizeLimit(t *testing.T) {
+	f := newPutFixture(t)
+	src := f.upload(1, "big.conf", "0123456789")
+
+	if _, err := f.put(1, src, 9, false); err == nil {
+		t.Error("expected size error")
+	}
+	if got := f.content("big.conf"); got != "" {
+		t.Errorf("oversized file saved: %q", got)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("oversized upload left in the inbox: %v", err)
+	}
+}
+
+func TestPutRejectsOtherSources(t *testing.T) {
+	f := newPutFixture(t)
+	other := f.upload(2, "theirs.conf", "secret")
+	outside := filepath.Join(t.TempDir(), "outside.conf")
+	if err := os.WriteFile(outside, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, src := range []string{other, outside, "/etc/passwd"} {
+		out, err := f.put(1, src, 100, false)
+		if err != nil || !strings.HasPrefix(out, "Send a document") {
+			t.Errorf("put(%q) = %q, %v; want usage", src, out, err)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("another chat's upload was removed: %v", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside the inbox was removed: %v", err)
+	}
+	if entries, _ := os.ReadDir(f.dest); len(entries) != 0 || len(f.records) != 0 {
+		t.Errorf("saved %d files, recorded %v", len(entries), f.records)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/rashpile/pako-telegram/internal/auth"
 	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
@@ -18,16 +19,19 @@ type CommandReloader interface {
 	Reload(commands []pkgcmd.Command)
 }
 
-// SchedulerUpdater updates scheduled commands.
-type SchedulerUpdater interface {
-	UpdateScheduledCommands(commands []pkgcmd.Command)
+// ConfigReloader re-reads the main configuration file and applies it.
+type ConfigReloader interface {
+	ReloadConfig() error
+	// ReloadAllowlist re-reads only the telegram chat and user allowlists
+	// and returns the number of allowed chats.
+	ReloadAllowlist() (int, error)
 }
 
 // ReloadCommand reloads YAML command configurations.
 type ReloadCommand struct {
-	loader    CommandLoader
-	reloader  CommandReloader
-	scheduler SchedulerUpdater
+	loader   CommandLoader
+	reloader CommandReloader
+	config   ConfigReloader
 }
 
 // NewReloadCommand creates a reload command.
@@ -38,9 +42,9 @@ func NewReloadCommand(loader CommandLoader, reloader CommandReloader) *ReloadCom
 	}
 }
 
-// SetScheduler sets the scheduler for reload updates.
-func (r *ReloadCommand) SetScheduler(s SchedulerUpdater) {
-	r.scheduler = s
+// SetConfigReloader enables "/reload config" and allowlist refresh on /reload.
+func (r *ReloadCommand) SetConfigReloader(c ConfigReloader) {
+	r.config = c
 }
 
 // Name returns "reload".
@@ -50,24 +54,56 @@ func (r *ReloadCommand) Name() string {
 
 // Description returns the reload description.
 func (r *ReloadCommand) Description() string {
-	return "Reload command configurations"
+	return "Reload command configurations (/reload config to reload config.yaml too)"
 }
 
-// Execute reloads commands from YAML files.
+// Metadata restricts the command to admins: a config reload replaces the
+// allowlist, roles, OTP secrets and sudo PINs.
+func (r *ReloadCommand) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.RequiredRole = auth.RoleAdmin.String()
+	return meta
+}
+
+// Execute reloads commands from YAML files and the chat allowlist.
+// With the "config" argument, the whole config.yaml is reloaded as well.
 func (r *ReloadCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
-	commands, err := r.loader.Load()
+	if len(args) > 0 && args[0] == "config" {
+		if r.config == nil {
+			return fmt.Errorf("config reload not available")
+		}
+		if err := r.config.ReloadConfig(); err != nil {
+			return fmt.Errorf("reload config: %w", err)
+		}
+		fmt.Fprintln(output, "Configuration reloaded")
+	} else if r.config != nil {
+		chats, err := r.config.ReloadAllowlist()
+		if err != nil {
+			return fmt.Errorf("reload allowlist: %w", err)
+		}
+		fmt.Fprintf(output, "Reloaded allowlist (%d chats)\n", chats)
+	}
+
+	count, err := r.Reload()
 	if err != nil {
-		return fmt.Errorf("load commands: %w", err)
+		return err
 	}
 
-	r.reloader.Reload(commands)
+	fmt.Fprintf(output, "Reloaded %d commands\n", count)
 
-	// Update scheduler with new commands
-	if r.scheduler != nil {
-		r.scheduler.UpdateScheduledCommands(commands)
+	return nil
+}
+
+// Reload loads commands and replaces them in the registry, whose change
+// hooks update the scheduler and menus. Returns the number of loaded
+// commands. Used by /reload and the file watcher.
+func (r *ReloadCommand) Reload() (int, error) {
+	commands, err := r.loader.Load()
+	if err != nil {
+		return 0, fmt.Errorf("load commands: %w", err)
 	}
 
-	fmt.Fprintf(output, "Reloaded %d commands\n", len(commands))
+	r.reloader.Reload(commands)
 
-	return nil
+	return len(commands), nil
 }
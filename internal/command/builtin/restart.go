@@ -0,0 +1,59 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// Restarter restarts the bot process once running commands have finished.
+type Restarter interface {
+	// Restart starts a graceful restart and returns without waiting for it.
+	Restart()
+}
+
+// RestartCommand restarts the bot.
+type RestartCommand struct {
+	restarter Restarter
+}
+
+// NewRestartCommand creates a restart command.
+func NewRestartCommand(r Restarter) *RestartCommand {
+	return &RestartCommand{restarter: r}
+}
+
+// Name returns "restart".
+func (r *RestartCommand) Name() string {
+	return "restart"
+}
+
+// Description returns the restart command description.
+func (r *RestartCommand) Description() string {
+	return "Restart the bot once running commands finish"
+}
+
+// Category returns the command's category for menu grouping.
+func (r *RestartCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Metadata restricts the command to admins and asks for confirmation.
+func (r *RestartCommand) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.RequiredRole = auth.RoleAdmin.String()
+	meta.RequireConfirm = true
+	return meta
+}
+
+// Execute starts the restart.
+func (r *RestartCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	fmt.Fprintln(output, "Restarting once running commands finish...")
+	r.restarter.Restart()
+	return nil
+}
@@ -0,0 +1,154 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/auth"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// defaultSecurityWindow is the report window when none is given.
+const defaultSecurityWindow = 24 * time.Hour
+
+// AttemptLister reports unauthorized attempts from the audit log.
+type AttemptLister interface {
+	UnauthorizedAttempts(ctx context.Context, since time.Time) ([]audit.AttemptSummary, error)
+}
+
+// SecurityCommand summarizes unauthorized command and callback attempts.
+type SecurityCommand struct {
+	lister AttemptLister
+}
+
+// NewSecurityCommand creates a security report command.
+func NewSecurityCommand(lister AttemptLister) *SecurityCommand {
+	return &SecurityCommand{lister: lister}
+}
+
+// Name returns "security".
+func (s *SecurityCommand) Name() string {
+	return "security"
+}
+
+// Description returns the security command description.
+func (s *SecurityCommand) Description() string {
+	return "Unauthorized attempts by chat and command: /security [window, e.g. 6h or 7d]"
+}
+
+// Category returns the command's category for menu grouping.
+func (s *SecurityCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Metadata restricts the command to admins.
+func (s *SecurityCommand) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.RequiredRole = auth.RoleAdmin.String()
+	return meta
+}
+
+// Execute writes the report for the requested window (default 24h).
+func (s *SecurityCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	window := defaultSecurityWindow
+	if len(args) > 0 {
+		d, err := parseWindow(args[0])
+		if err != nil {
+			return err
+		}
+		window = d
+	}
+
+	attempts, err := s.lister.UnauthorizedAttempts(ctx, time.Now().Add(-window))
+	if err != nil {
+		return err
+	}
+	if len(attempts) == 0 {
+		fmt.Fprintf(output, "No unauthorized attempts in the last %s\n", formatWindow(window))
+		return nil
+	}
+
+	total := 0
+	byChat := make(map[int64]int)
+	byCommand := make(map[string]int)
+	users := make(map[int64][]string)
+	for _, a := range attempts {
+		total += a.Count
+		byChat[a.ChatID] += a.Count
+		byCommand[a.Command] += a.Count
+		for _, u := range a.Usernames {
+			if !slices.Contains(users[a.ChatID], u) {
+				users[a.ChatID] = append(users[a.ChatID], u)
+			}
+		}
+	}
+
+	fmt.Fprintf(output, "🛡 %d unauthorized attempts in the last %s\n", total, formatWindow(window))
+
+	fmt.Fprintln(output, "\nBy chat:")
+	for _, chatID := range sortedByCount(byChat) {
+		line := fmt.Sprintf("  %d: %d", chatID, byChat[chatID])
+		if len(users[chatID]) > 0 {
+			line += " (" + strings.Join(users[chatID], ", ") + ")"
+		}
+		fmt.Fprintln(output, line)
+	}
+
+	fmt.Fprintln(output, "\nBy command:")
+	for _, name := range sortedByCount(byCommand) {
+		fmt.Fprintf(output, "  %s: %d\n", name, byCommand[name])
+	}
+
+	fmt.Fprintln(output, "\nBy chat and command:")
+	for _, a := range attempts {
+		fmt.Fprintf(output, "  %d %s x%d, last %s\n", a.ChatID, a.Command, a.Count, a.Last.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// parseWindow parses a Go duration or a whole number of days ("7d").
+func parseWindow(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid window %q (e.g. 6h, 7d)", s)
+	}
+	return d, nil
+}
+
+// formatWindow renders whole days as "Nd" and anything else as a duration.
+func formatWindow(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+// sortedByCount returns map keys ordered by descending count, then key.
+func sortedByCount[K int64 | string](counts map[K]int) []K {
+	keys := make([]K, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,70 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rashpile/pako-telegram/internal/status"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// UnitLister reports the state of monitored systemd units.
+type UnitLister interface {
+	Units(ctx context.Context) ([]status.Unit, error)
+}
+
+// ServicesCommand shows the state of the configured systemd units.
+type ServicesCommand struct {
+	lister UnitLister
+}
+
+// NewServicesCommand creates a services command.
+func NewServicesCommand(lister UnitLister) *ServicesCommand {
+	return &ServicesCommand{lister: lister}
+}
+
+// Name returns "services".
+func (s *ServicesCommand) Name() string {
+	return "services"
+}
+
+// Description returns the services command description.
+func (s *ServicesCommand) Description() string {
+	return "State of monitored systemd units"
+}
+
+// Category returns the command's category for menu grouping.
+func (s *ServicesCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Execute writes one line per unit.
+func (s *ServicesCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	units, err := s.lister.Units(ctx)
+	if err != nil {
+		return err
+	}
+	if len(units) == 0 {
+		fmt.Fprintln(output, "No units configured (status.units)")
+		return nil
+	}
+
+	for _, u := range units {
+		icon := "✅"
+		state := u.ActiveState + " (" + u.SubState + ")"
+		switch {
+		case u.LoadState == "not-found":
+			icon, state = "❔", "not found"
+		case u.Failed():
+			icon, state = "❌", "failed ("+u.Result+")"
+		case u.ActiveState != "active":
+			icon = "⏹"
+		}
+		fmt.Fprintf(output, "%s %s: %s\n", icon, u.Name, state)
+	}
+	return nil
+}
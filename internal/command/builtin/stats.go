@@ -0,0 +1,132 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/audit"
+	"github.com/rashpile/pako-telegram/internal/auth"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// defaultStatsWindow is the report window when none is given, and the
+// window /help orders commands by.
+const defaultStatsWindow = 30 * 24 * time.Hour
+
+// StatsLister reports per-command execution statistics from the audit log.
+type StatsLister interface {
+	CommandStats(ctx context.Context, since time.Time) ([]audit.CommandStats, error)
+}
+
+// StatsCommand reports how often each command ran, how often it failed and
+// how long it took, and which commands did not run at all.
+type StatsCommand struct {
+	stats  StatsLister
+	lister CommandLister
+}
+
+// NewStatsCommand creates a usage statistics command.
+func NewStatsCommand(stats StatsLister, lister CommandLister) *StatsCommand {
+	return &StatsCommand{stats: stats, lister: lister}
+}
+
+// Name returns "stats".
+func (s *StatsCommand) Name() string {
+	return "stats"
+}
+
+// Description returns the stats command description.
+func (s *StatsCommand) Description() string {
+	return "Command usage, failures and durations: /stats [window, e.g. 7d]"
+}
+
+// Category returns the command's category for menu grouping.
+func (s *StatsCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Metadata restricts the command to admins.
+func (s *StatsCommand) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.RequiredRole = auth.RoleAdmin.String()
+	return meta
+}
+
+// Execute writes the report for the requested window (default 30d).
+func (s *StatsCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	window := defaultStatsWindow
+	if len(args) > 0 {
+		d, err := parseWindow(args[0])
+		if err != nil {
+			return err
+		}
+		window = d
+	}
+
+	stats, err := s.stats.CommandStats(ctx, time.Now().Add(-window))
+	if err != nil {
+		return err
+	}
+
+	if len(stats) == 0 {
+		fmt.Fprintf(output, "No commands ran in the last %s\n", formatWindow(window))
+	} else {
+		fmt.Fprintf(output, "Command usage in the last %s\n\n", formatWindow(window))
+		fmt.Fprintf(output, "%6s %6s %8s  %-16s  %s\n", "RUNS", "FAIL%", "AVG", "LAST", "COMMAND")
+		for _, st := range stats {
+			fmt.Fprintf(output, "%6d %6.1f %8s  %-16s  %s\n",
+				st.Runs, st.FailureRate()*100, formatAvg(st.AvgDuration()),
+				st.Last.Local().Format("2006-01-02 15:04"), st.Command)
+		}
+	}
+
+	if unused := s.unused(stats); len(unused) > 0 {
+		fmt.Fprintf(output, "\nNot run in the last %s:\n", formatWindow(window))
+		for _, name := range unused {
+			fmt.Fprintf(output, "  /%s\n", name)
+		}
+	}
+	return nil
+}
+
+// unused returns the visible registered commands missing from stats,
+// sorted by name. Namespaces are represented by their subcommands.
+func (s *StatsCommand) unused(stats []audit.CommandStats) []string {
+	ran := make(map[string]bool, len(stats))
+	for _, st := range stats {
+		ran[st.Command] = true
+	}
+
+	var names []string
+	for _, cmd := range s.lister.All() {
+		if pkgcmd.IsHidden(cmd) {
+			continue
+		}
+		cmds := pkgcmd.Subcommands(cmd)
+		if len(cmds) == 0 {
+			cmds = []pkgcmd.Command{cmd}
+		}
+		for _, c := range cmds {
+			if !pkgcmd.IsHidden(c) && !ran[c.Name()] {
+				names = append(names, c.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatAvg rounds a duration for display: milliseconds below a second,
+// tenths of a second above.
+func formatAvg(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
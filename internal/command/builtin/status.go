@@ -3,14 +3,72 @@ package builtin
 import (
 	"context"
 	"fmt"
+	"image/color"
 	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/rashpile/pako-telegram/internal/history"
 	"github.com/rashpile/pako-telegram/internal/status"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
+// SelfReporter reports the bot's own resource usage and activity.
+type SelfReporter interface {
+	SelfStats() status.SelfStats
+}
+
+// TrendSource summarizes and lists recorded metrics.
+type TrendSource interface {
+	Trend(metric string, window time.Duration, buckets int) (history.Trend, error)
+	Series(metric string, since time.Time) ([]history.Point, error)
+}
+
+// defaultGraphWindow is the period /status graph shows without an argument.
+const defaultGraphWindow = 24 * time.Hour
+
+// graphLines are the metrics /status graph draws, with their colors and the
+// matching legend symbols.
+var graphLines = []struct {
+	metric string
+	label  string
+	color  color.RGBA
+}{
+	{status.MetricCPU, "🟥 CPU", color.RGBA{220, 50, 50, 255}},
+	{status.MetricMemory, "🟦 Memory", color.RGBA{50, 100, 220, 255}},
+	{status.MetricDisk, "🟩 Disk", color.RGBA{40, 160, 80, 255}},
+}
+
+// trendWindows are the periods /status summarizes, with their labels.
+var trendWindows = []struct {
+	label  string
+	window time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+}
+
+// trendLabels names the recorded metrics in the trends section.
+var trendLabels = map[string]string{
+	status.MetricCPU:    "CPU",
+	status.MetricMemory: "Memory",
+	status.MetricDisk:   "Disk",
+	status.MetricLoad1:  "Load",
+}
+
+// sparkBuckets is the number of characters in a trend sparkline.
+const sparkBuckets = 12
+
 // StatusCommand shows system resource usage.
 type StatusCommand struct {
 	collector status.Collector
+	self      SelfReporter
+	history   TrendSource
+
+	fileResponse *pkgcmd.FileResponse
 }
 
 // NewStatusCommand creates a status command.
@@ -18,6 +76,18 @@ func NewStatusCommand(collector status.Collector) *StatusCommand {
 	return &StatusCommand{collector: collector}
 }
 
+// SetSelfReporter sets the source of the bot's own metrics. Without one, the
+// bot section is omitted.
+func (s *StatusCommand) SetSelfReporter(r SelfReporter) {
+	s.self = r
+}
+
+// SetHistory sets the recorded metrics used for 1h and 24h trends. Without
+// it, the trends section is omitted.
+func (s *StatusCommand) SetHistory(h TrendSource) {
+	s.history = h
+}
+
 // Name returns "status".
 func (s *StatusCommand) Name() string {
 	return "status"
@@ -25,11 +95,20 @@ func (s *StatusCommand) Name() string {
 
 // Description returns the status description.
 func (s *StatusCommand) Description() string {
-	return "Show CPU, memory, and disk usage"
+	return "Show CPU, memory, disk, load and network usage with 1h/24h trends, and bot stats: /status [graph [window]]"
 }
 
-// Execute collects and writes system metrics.
+// Execute collects and writes system metrics. /status graph [window] renders
+// the recorded history as a chart instead.
 func (s *StatusCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	s.fileResponse = nil
+	if len(args) > 0 {
+		if args[0] != "graph" {
+			return fmt.Errorf("unknown argument %q (usage: /status [graph [window]])", args[0])
+		}
+		return s.graph(args[1:], output)
+	}
+
 	metrics, err := s.collector.Collect(ctx)
 	if err != nil {
 		return err
@@ -44,15 +123,165 @@ func (s *StatusCommand) Execute(ctx context.Context, args []string, output io.Wr
 		formatBytes(metrics.MemoryUsed),
 		formatBytes(metrics.MemoryTotal),
 	)
-	fmt.Fprintf(output, "Disk:   %5.1f%% (%s / %s)\n",
-		metrics.DiskPercent,
-		formatBytes(metrics.DiskUsed),
-		formatBytes(metrics.DiskTotal),
+	if len(metrics.Disks) == 1 {
+		fmt.Fprintf(output, "Disk:   %5.1f%% (%s / %s)\n",
+			metrics.DiskPercent,
+			formatBytes(metrics.DiskUsed),
+			formatBytes(metrics.DiskTotal),
+		)
+	} else {
+		width := 0
+		for _, d := range metrics.Disks {
+			width = max(width, utf8.RuneCountInString(d.Path))
+		}
+		fmt.Fprintf(output, "Disks:\n")
+		for _, d := range metrics.Disks {
+			fmt.Fprintf(output, "  %-*s %5.1f%% (%s / %s)\n",
+				width, d.Path,
+				d.Percent,
+				formatBytes(d.Used),
+				formatBytes(d.Total),
+			)
+		}
+	}
+	fmt.Fprintf(output, "Load:   %.2f %.2f %.2f\n", metrics.Load1, metrics.Load5, metrics.Load15)
+	fmt.Fprintf(output, "Net:    ↓ %s/s  ↑ %s/s\n",
+		formatBytes(uint64(metrics.NetRxRate)),
+		formatBytes(uint64(metrics.NetTxRate)),
 	)
+	if metrics.MaxFiles > 0 {
+		fmt.Fprintf(output, "Files:  %d / %d open\n", metrics.OpenFiles, metrics.MaxFiles)
+	}
+
+	if s.history != nil {
+		s.writeTrends(output)
+	}
+
+	if s.self != nil {
+		self := s.self.SelfStats()
+		fmt.Fprintf(output, "\nBot\n")
+		fmt.Fprintf(output, "───\n\n")
+		fmt.Fprintf(output, "Uptime:     %s\n", self.Uptime.Round(time.Second))
+		fmt.Fprintf(output, "Goroutines: %d\n", self.Goroutines)
+		fmt.Fprintf(output, "Memory:     %s heap / %s sys\n", formatBytes(self.HeapAlloc), formatBytes(self.Sys))
+		fmt.Fprintf(output, "Commands:   %d\n", self.Commands)
+		fmt.Fprintf(output, "Sessions:   %d\n", self.Sessions)
+		fmt.Fprintf(output, "Scheduled:  %d\n", self.ScheduledJobs)
+	}
 
 	return nil
 }
 
+// FileResponse returns the chart rendered by /status graph.
+func (s *StatusCommand) FileResponse() *pkgcmd.FileResponse {
+	return s.fileResponse
+}
+
+// graph renders CPU, memory and disk history over the window (default 24h)
+// as a PNG chart sent after the command finishes.
+func (s *StatusCommand) graph(args []string, output io.Writer) error {
+	if s.history == nil {
+		return fmt.Errorf("metrics history is not enabled")
+	}
+
+	window := defaultGraphWindow
+	if len(args) > 0 {
+		w, err := parseWindow(args[0])
+		if err != nil {
+			return err
+		}
+		window = w
+	}
+
+	end := time.Now()
+	start := end.Add(-window)
+	var lines []history.Line
+	var legend []string
+	samples := 0
+	for _, g := range graphLines {
+		points, err := s.history.Series(g.metric, start)
+		if err != nil {
+			return err
+		}
+		samples = max(samples, len(points))
+		lines = append(lines, history.Line{Points: points, Color: g.color})
+		legend = append(legend, g.label)
+	}
+	if samples == 0 {
+		return fmt.Errorf("no metrics recorded in the last %s", formatWindow(window))
+	}
+
+	file, err := os.CreateTemp("", "status-*.png")
+	if err != nil {
+		return fmt.Errorf("create chart: %w", err)
+	}
+	defer file.Close()
+	if err := history.RenderChart(file, lines, start, end); err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("render chart: %w", err)
+	}
+
+	fmt.Fprintf(output, "Chart of %d samples over the last %s\n", samples, formatWindow(window))
+	s.fileResponse = &pkgcmd.FileResponse{
+		Path:    file.Name(),
+		Caption: fmt.Sprintf("%s, last %s (0-100%%, grid every 25%%)", strings.Join(legend, " · "), formatWindow(window)),
+		Cleanup: true,
+	}
+	return nil
+}
+
+// writeTrends writes min/avg/max and a sparkline of each recorded metric
+// over the trend windows. Nothing is written before the first sample.
+func (s *StatusCommand) writeTrends(output io.Writer) {
+	header := false
+	for _, metric := range history.Metrics {
+		for i, w := range trendWindows {
+			trend, err := s.history.Trend(metric, w.window, sparkBuckets)
+			if err != nil || trend.Samples == 0 {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(output, "\nTrends (min / avg / max)\n")
+				fmt.Fprintf(output, "────────────────────────\n\n")
+				header = true
+			}
+
+			label := ""
+			if i == 0 {
+				label = trendLabels[metric]
+			}
+			// Percentages use a fixed scale so small wobbles stay flat
+			hi := 100.0
+			if metric == status.MetricLoad1 {
+				hi = trend.Max
+			}
+			fmt.Fprintf(output, "%-6s %3s %5.1f / %5.1f / %5.1f  %s\n",
+				label, w.label, trend.Min, trend.Avg, trend.Max, sparkline(trend.Buckets, hi))
+		}
+	}
+}
+
+// sparkBars are the sparkline levels, lowest first.
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values scaled from 0 to hi as block characters. NaN values
+// (no samples) are shown as spaces.
+func sparkline(values []float64, hi float64) string {
+	var sb strings.Builder
+	for _, v := range values {
+		if math.IsNaN(v) {
+			sb.WriteRune(' ')
+			continue
+		}
+		level := 0
+		if hi > 0 {
+			level = int(v / hi * float64(len(sparkBars)-1))
+		}
+		sb.WriteRune(sparkBars[min(max(level, 0), len(sparkBars)-1)])
+	}
+	return sb.String()
+}
+
 // formatBytes converts bytes to human-readable format.
 func formatBytes(b uint64) string {
 	const unit = 1024
@@ -0,0 +1,80 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/status"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+const (
+	// defaultTopCount is how many processes /top lists per table.
+	defaultTopCount = 10
+
+	// maxTopCount caps /top <n> to keep the output in one message.
+	maxTopCount = 30
+
+	// topSample is the window CPU usage is measured over.
+	topSample = time.Second
+)
+
+// TopCommand lists the processes using the most CPU and memory.
+type TopCommand struct{}
+
+// NewTopCommand creates a top command.
+func NewTopCommand() *TopCommand {
+	return &TopCommand{}
+}
+
+// Name returns "top".
+func (t *TopCommand) Name() string {
+	return "top"
+}
+
+// Description returns the top command description.
+func (t *TopCommand) Description() string {
+	return "Top processes by CPU and memory: /top [count]"
+}
+
+// Category returns the command's category for menu grouping.
+func (t *TopCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: "system",
+		Icon: "ℹ️",
+	}
+}
+
+// Execute samples processes and writes the top ones by CPU and by memory.
+func (t *TopCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	count := defaultTopCount
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid count %q", args[0])
+		}
+		count = min(n, maxTopCount)
+	}
+
+	procs, err := status.Processes(ctx, topSample)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(output, "Top by CPU\n")
+	writeProcesses(output, status.TopByCPU(procs, count))
+	fmt.Fprintf(output, "\nTop by memory\n")
+	writeProcesses(output, status.TopByMemory(procs, count))
+	return nil
+}
+
+// writeProcesses writes a process table.
+func writeProcesses(output io.Writer, procs []status.Process) {
+	fmt.Fprintf(output, "%7s %6s %6s %9s  %s\n", "PID", "CPU%", "MEM%", "RSS", "NAME")
+	for _, p := range procs {
+		fmt.Fprintf(output, "%7d %6.1f %6.1f %9s  %s\n", p.PID, p.CPUPercent, p.MemPercent, formatBytes(p.MemoryRSS), p.Name)
+	}
+}
@@ -0,0 +1,349 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// External plugins are separate executables in the plugins directory, written
+// in any language. The bot starts each one once and talks to it with JSON
+// messages, one per line, over the plugin's stdin and stdout:
+//
+//	plugin: {"protocol":1}                                   handshake, first line
+//	bot:    {"id":1,"method":"describe"}
+//	plugin: {"id":1,"commands":[{"name":"hello","description":"Say hello"}]}
+//	bot:    {"id":2,"method":"execute","command":"hello","args":["world"]}
+//	plugin: {"id":2,"output":"Hello, "}                      streamed, any number
+//	plugin: {"id":2,"output":"world\n"}
+//	plugin: {"id":2,"done":true}                             or {"id":2,"error":"..."}
+//	bot:    {"id":2,"method":"cancel"}                        on timeout or shutdown
+//
+// Requests may overlap; responses are matched by id. The plugin's stderr is
+// logged. PluginEnv is set in the plugin's environment so a binary can tell
+// it was started by the bot.
+const (
+	PluginProtocol = 1
+	PluginEnv      = "PAKO_PLUGIN_PROTOCOL"
+)
+
+// pluginHandshakeTimeout is how long a plugin has to answer the handshake
+// and describe requests.
+const pluginHandshakeTimeout = 10 * time.Second
+
+// pluginMessage is one line of the external plugin protocol, in either
+// direction.
+type pluginMessage struct {
+	ID       int64                 `json:"id,omitempty"`
+	Protocol int                   `json:"protocol,omitempty"`
+	Method   string                `json:"method,omitempty"`
+	Command  string                `json:"command,omitempty"`
+	Args     []string              `json:"args,omitempty"`
+	Commands []ExternalCommandInfo `json:"commands,omitempty"`
+	Output   string                `json:"output,omitempty"`
+	Done     bool                  `json:"done,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+// ExternalCommandInfo describes a command provided by an external plugin.
+type ExternalCommandInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Timeout     string `json:"timeout,omitempty"` // Go duration, e.g. "30s" (default: defaults.timeout)
+	Confirm     bool   `json:"confirm,omitempty"`
+	Role        string `json:"required_role,omitempty"`
+	Hidden      bool   `json:"hidden,omitempty"`
+}
+
+// pluginCall is a request waiting for the plugin's answer.
+type pluginCall struct {
+	output io.Writer // Receives streamed output of execute requests
+	reply  chan pluginMessage
+}
+
+// ExternalPlugin is a running external plugin process.
+type ExternalPlugin struct {
+	path    string
+	modTime time.Time // Of the executable when started
+	cmd     *exec.Cmd
+
+	writeMu sync.Mutex
+	enc     *json.Encoder
+
+	mu     sync.Mutex
+	nextID int64
+	calls  map[int64]*pluginCall
+
+	exited  chan struct{} // Closed when the process exits
+	exitErr error         // Set before exited is closed
+}
+
+// StartExternalPlugin starts the executable and waits for its handshake.
+func StartExternalPlugin(path string) (*ExternalPlugin, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%d", PluginEnv, PluginProtocol))
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &ExternalPlugin{
+		path:    path,
+		modTime: info.ModTime(),
+		cmd:     cmd,
+		enc:     json.NewEncoder(stdin),
+		calls:   make(map[int64]*pluginCall),
+		exited:  make(chan struct{}),
+	}
+
+	go p.logStderr(stderr)
+
+	handshake := make(chan error, 1)
+	go p.read(stdout, handshake)
+
+	select {
+	case err := <-handshake:
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+	case <-time.After(pluginHandshakeTimeout):
+		p.Close()
+		return nil, errors.New("no handshake from plugin")
+	}
+	return p, nil
+}
+
+// Path returns the plugin executable's path.
+func (p *ExternalPlugin) Path() string {
+	return p.path
+}
+
+// Running reports whether the process is alive and its executable unchanged
+// since it was started.
+func (p *ExternalPlugin) Running() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+	}
+	info, err := os.Stat(p.path)
+	return err == nil && info.ModTime().Equal(p.modTime)
+}
+
+// Commands asks the plugin for its commands.
+func (p *ExternalPlugin) Commands() ([]pkgcmd.Command, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginHandshakeTimeout)
+	defer cancel()
+
+	reply, err := p.call(ctx, pluginMessage{Method: "describe"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]pkgcmd.Command, 0, len(reply.Commands))
+	for _, info := range reply.Commands {
+		if info.Name == "" {
+			return nil, errors.New("plugin described a command without a name")
+		}
+		var timeout time.Duration
+		if info.Timeout != "" {
+			if timeout, err = time.ParseDuration(info.Timeout); err != nil {
+				return nil, fmt.Errorf("command %q: invalid timeout %q", info.Name, info.Timeout)
+			}
+		}
+		commands = append(commands, &ExternalCommand{plugin: p, info: info, timeout: timeout})
+	}
+	return commands, nil
+}
+
+// Close stops the plugin process. Running executions fail.
+func (p *ExternalPlugin) Close() error {
+	select {
+	case <-p.exited:
+		return nil
+	default:
+	}
+	p.cmd.Process.Kill()
+	<-p.exited
+	return nil
+}
+
+// call sends a request and waits for its final reply. Output messages are
+// written to output as they arrive. If the context ends first, the request
+// is cancelled in the plugin.
+func (p *ExternalPlugin) call(ctx context.Context, msg pluginMessage, output io.Writer) (pluginMessage, error) {
+	c := &pluginCall{output: output, reply: make(chan pluginMessage, 1)}
+
+	p.mu.Lock()
+	p.nextID++
+	msg.ID = p.nextID
+	p.calls[msg.ID] = c
+	p.mu.Unlock()
+
+	forget := func() {
+		p.mu.Lock()
+		delete(p.calls, msg.ID)
+		p.mu.Unlock()
+	}
+
+	if err := p.send(msg); err != nil {
+		forget()
+		return pluginMessage{}, err
+	}
+
+	select {
+	case reply := <-c.reply:
+		if reply.Error != "" {
+			return reply, errors.New(reply.Error)
+		}
+		return reply, nil
+	case <-p.exited:
+		forget()
+		return pluginMessage{}, fmt.Errorf("plugin exited: %v", p.exitErr)
+	case <-ctx.Done():
+		forget()
+		p.send(pluginMessage{ID: msg.ID, Method: "cancel"})
+		return pluginMessage{}, ctx.Err()
+	}
+}
+
+// send writes one message to the plugin.
+func (p *ExternalPlugin) send(msg pluginMessage) error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	if err := p.enc.Encode(msg); err != nil {
+		return fmt.Errorf("write to plugin: %w", err)
+	}
+	return nil
+}
+
+// read dispatches the plugin's messages until its stdout closes, then waits
+// for the process to exit. The first message must be the handshake.
+func (p *ExternalPlugin) read(stdout io.Reader, handshake chan<- error) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var handshakeErr error
+	if !scanner.Scan() {
+		handshakeErr = errors.New("plugin exited before handshake")
+	} else {
+		var msg pluginMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			handshakeErr = fmt.Errorf("invalid handshake %q", scanner.Text())
+		} else if msg.Protocol != PluginProtocol {
+			handshakeErr = fmt.Errorf("unsupported protocol %d (want %d)", msg.Protocol, PluginProtocol)
+		}
+	}
+	handshake <- handshakeErr
+
+	for handshakeErr == nil && scanner.Scan() {
+		var msg pluginMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			slog.Warn("invalid plugin message", "plugin", p.path, "line", scanner.Text())
+			continue
+		}
+		p.dispatch(msg)
+	}
+
+	// Drain, so the process isn't blocked writing when it is killed
+	io.Copy(io.Discard, stdout)
+	p.exitErr = p.cmd.Wait()
+	close(p.exited)
+}
+
+// dispatch routes a message to the call it answers.
+func (p *ExternalPlugin) dispatch(msg pluginMessage) {
+	p.mu.Lock()
+	c := p.calls[msg.ID]
+	final := msg.Done || msg.Error != "" || msg.Output == "" // Output alone is streamed
+	if final {
+		delete(p.calls, msg.ID)
+	}
+	p.mu.Unlock()
+
+	if c == nil {
+		return // Cancelled or unknown
+	}
+	if msg.Output != "" && c.output != nil {
+		io.WriteString(c.output, msg.Output)
+	}
+	if final {
+		c.reply <- msg
+	}
+}
+
+// logStderr logs the plugin's stderr line by line.
+func (p *ExternalPlugin) logStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		slog.Info("plugin stderr", "plugin", p.path, "line", scanner.Text())
+	}
+}
+
+// ExternalCommand is a command implemented by an external plugin.
+type ExternalCommand struct {
+	plugin  *ExternalPlugin
+	info    ExternalCommandInfo
+	timeout time.Duration
+}
+
+// Name returns the command name.
+func (c *ExternalCommand) Name() string {
+	return c.info.Name
+}
+
+// Description returns the command description.
+func (c *ExternalCommand) Description() string {
+	return c.info.Description
+}
+
+// Execute runs the command in the plugin, streaming its output.
+func (c *ExternalCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
+	_, err := c.plugin.call(ctx, pluginMessage{Method: "execute", Command: c.info.Name, Args: args}, output)
+	return err
+}
+
+// Metadata returns the execution settings the plugin described. A zero
+// timeout uses the configured default.
+func (c *ExternalCommand) Metadata() pkgcmd.Metadata {
+	return pkgcmd.Metadata{
+		Timeout:        c.timeout,
+		RequireConfirm: c.info.Confirm,
+		RequiredRole:   c.info.Role,
+		Hidden:         c.info.Hidden,
+	}
+}
+
+// Category returns the command's menu category.
+func (c *ExternalCommand) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{Name: c.info.Category, Icon: c.info.Icon}
+}
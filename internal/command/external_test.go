@@ -0,0 +1,100 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain runs the test binary as an external plugin when started by
+// StartExternalPlugin.
+func TestMain(m *testing.M) {
+	if os.Getenv(PluginEnv) != "" {
+		runTestPlugin()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin implements "echo" (streams its arguments one per message)
+// and "sleep" (never finishes, until cancelled).
+func runTestPlugin() {
+	enc := json.NewEncoder(os.Stdout)
+	enc.Encode(pluginMessage{Protocol: PluginProtocol})
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg pluginMessage
+		json.Unmarshal(scanner.Bytes(), &msg)
+		switch {
+		case msg.Method == "describe":
+			enc.Encode(pluginMessage{ID: msg.ID, Commands: []ExternalCommandInfo{
+				{Name: "echo", Description: "Echo arguments", Category: "test", Timeout: "5s"},
+				{Name: "sleep", Description: "Wait forever"},
+			}})
+		case msg.Method == "execute" && msg.Command == "echo":
+			for _, arg := range msg.Args {
+				enc.Encode(pluginMessage{ID: msg.ID, Output: arg + "\n"})
+			}
+			enc.Encode(pluginMessage{ID: msg.ID, Done: true})
+		case msg.Method == "execute" && msg.Command == "sleep":
+		case msg.Method == "cancel":
+			fmt.Fprintf(os.Stderr, "cancelled %d\n", msg.ID)
+		default:
+			enc.Encode(pluginMessage{ID: msg.ID, Error: "unknown command " + msg.Command})
+		}
+	}
+}
+
+func TestExternalPlugin(t *testing.T) {
+	p, err := StartExternalPlugin(os.Args[0])
+	if err != nil {
+		t.Fatalf("StartExternalPlugin() error = %v", err)
+	}
+	defer p.Close()
+
+	cmds, err := p.Commands()
+	if err != nil {
+		t.Fatalf("Commands() error = %v", err)
+	}
+	if len(cmds) != 2 || cmds[0].Name() != "echo" {
+		t.Fatalf("Commands() = %v, want echo and sleep", cmds)
+	}
+	echo := cmds[0].(*ExternalCommand)
+	if echo.Metadata().Timeout != 5*time.Second || echo.Category().Name != "test" {
+		t.Errorf("echo metadata = %+v, category %+v", echo.Metadata(), echo.Category())
+	}
+
+	var out strings.Builder
+	if err := echo.Execute(context.Background(), []string{"a", "b"}, &out); err != nil {
+		t.Fatalf("Execute(echo) error = %v", err)
+	}
+	if out.String() != "a\nb\n" {
+		t.Errorf("Execute(echo) output = %q, want a and b", out.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := cmds[1].Execute(ctx, nil, &out); err != context.DeadlineExceeded {
+		t.Errorf("Execute(sleep) error = %v, want deadline exceeded", err)
+	}
+
+	// The plugin keeps serving after a cancelled request
+	unknown := &ExternalCommand{plugin: p, info: ExternalCommandInfo{Name: "missing"}}
+	if err := unknown.Execute(context.Background(), nil, &out); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("Execute(missing) error = %v, want plugin error", err)
+	}
+
+	p.Close()
+	if p.Running() {
+		t.Error("Running() after Close() = true")
+	}
+	if err := echo.Execute(context.Background(), nil, &out); err == nil {
+		t.Error("Execute() after Close(): want error")
+	}
+}
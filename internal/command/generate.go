@@ -0,0 +1,111 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// OutputDirEnv is the environment variable holding a generator's output
+// directory.
+const OutputDirEnv = "OUTPUT_DIR"
+
+// DefaultGenerateProgress is shown while a generator runs unless it sets
+// its own progress line.
+const DefaultGenerateProgress = "Generating..."
+
+// GenerateDef makes a command a generator: it writes files into the
+// directory in $OUTPUT_DIR, which the bot creates for each run, sends the
+// files from, and removes afterwards.
+type GenerateDef struct {
+	Files    []string `yaml:"files"`    // Expected files relative to $OUTPUT_DIR; templates over arguments, globs allowed
+	Caption  string   `yaml:"caption"`  // Caption for the files; template over arguments
+	Progress string   `yaml:"progress"` // Shown while the command runs (default: DefaultGenerateProgress)
+	Voice    bool     `yaml:"voice"`    // Send audio files as voice messages
+}
+
+// Validate checks that files are declared and the templates parse.
+func (g GenerateDef) Validate() error {
+	if len(g.Files) == 0 {
+		return fmt.Errorf("generate needs at least one file")
+	}
+	for _, f := range g.Files {
+		if _, err := template.New("file").Parse(f); err != nil {
+			return fmt.Errorf("file %q: %w", f, err)
+		}
+	}
+	if _, err := template.New("caption").Parse(g.Caption); err != nil {
+		return fmt.Errorf("caption: %w", err)
+	}
+	return nil
+}
+
+// ProgressText returns the line shown while the generator runs.
+func (g GenerateDef) ProgressText() string {
+	if g.Progress == "" {
+		return DefaultGenerateProgress
+	}
+	return g.Progress
+}
+
+// OutputFiles returns the generated files in dir, in the order declared.
+// Each pattern is rendered with args and may match several files; a
+// pattern matching nothing, or pointing outside dir, is an error.
+func (g GenerateDef) OutputFiles(dir string, args map[string]string) ([]string, error) {
+	var files []string
+	for _, f := range g.Files {
+		pattern, err := renderTemplate("file", f, args)
+		if err != nil {
+			return nil, err
+		}
+		pattern = filepath.Clean(pattern)
+		if filepath.IsAbs(pattern) || pattern == ".." || strings.HasPrefix(pattern, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("output file %q is outside the output directory", pattern)
+		}
+
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("output file %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("output file %q was not created", pattern)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// RenderCaption returns the caption rendered with args.
+func (g GenerateDef) RenderCaption(args map[string]string) (string, error) {
+	return renderTemplate("caption", g.Caption, args)
+}
+
+// renderTemplate executes text as a template over args.
+func renderTemplate(name, text string, args map[string]string) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, args); err != nil {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+type outputDirKey struct{}
+
+// WithOutputDir returns a context running a generator with dir as its
+// output directory.
+func WithOutputDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, outputDirKey{}, dir)
+}
+
+// outputDir returns the directory set by WithOutputDir, or "".
+func outputDir(ctx context.Context) string {
+	dir, _ := ctx.Value(outputDirKey{}).(string)
+	return dir
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// envExecutor records the environment it was run with.
+type envExecutor struct {
+	env []string
+}
+
+func (e *envExecutor) Execute(ctx context.Context, cfg ExecuteConfig) error {
+	e.env = cfg.Env
+	return nil
+}
+
+func TestGenerateOutputFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"report.pdf", "page-1.png", "page-2.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := map[string]string{"name": "report"}
+
+	def := GenerateDef{Files: []string{"{{.name}}.pdf", "page-*.png"}, Caption: "{{.name}} ready"}
+	files, err := def.OutputFiles(dir, args)
+	if err != nil {
+		t.Fatalf("OutputFiles() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "report.pdf"), filepath.Join(dir, "page-1.png"), filepath.Join(dir, "page-2.png")}
+	if !slices.Equal(files, want) {
+		t.Errorf("OutputFiles() = %v, want %v", files, want)
+	}
+	if caption, err := def.RenderCaption(args); err != nil || caption != "report ready" {
+		t.Errorf("RenderCaption() = %q, %v", caption, err)
+	}
+
+	for _, pattern := range []string{"missing.pdf", "../report.pdf", "/etc/passwd"} {
+		def := GenerateDef{Files: []string{pattern}}
+		if _, err := def.OutputFiles(dir, args); err == nil {
+			t.Errorf("OutputFiles(%q) expected error", pattern)
+		}
+	}
+}
+
+func TestGenerateDefValidate(t *testing.T) {
+	tests := []struct {
+		def     GenerateDef
+		wantErr bool
+	}{
+		{GenerateDef{Files: []string{"out.png"}}, false},
+		{GenerateDef{}, true},
+		{GenerateDef{Files: []string{"{{.name"}}, true},
+		{GenerateDef{Files: []string{"out.png"}, Caption: "{{end}}"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.def.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.def, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGenerateOutputDirEnv(t *testing.T) {
+	exec := &envExecutor{}
+	cmd := &YAMLCommand{def: YAMLCommandDef{Generate: &GenerateDef{Files: []string{"out.png"}}}, env: []string{"A=1"}, executor: exec}
+
+	ctx := WithOutputDir(context.Background(), "/tmp/gen")
+	if err := cmd.Execute(ctx, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"A=1", "OUTPUT_DIR=/tmp/gen"}; !slices.Equal(exec.env, want) {
+		t.Errorf("env = %v, want %v", exec.env, want)
+	}
+	if len(cmd.env) != 1 {
+		t.Errorf("command env modified: %v", cmd.env)
+	}
+}
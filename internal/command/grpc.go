@@ -0,0 +1,235 @@
+package command
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/rashpile/pako-telegram/internal/config"
+)
+
+// GRPCDef configures a command that calls a gRPC method instead of running a
+// shell command. The request is JSON (protobuf JSON mapping) and is a
+// template over the command's arguments, like a shell command.
+type GRPCDef struct {
+	Address   string            `yaml:"address"`   // host:port
+	Method    string            `yaml:"method"`    // package.Service/Method
+	Request   string            `yaml:"request"`   // JSON request template (default: {})
+	Plaintext bool              `yaml:"plaintext"` // Connect without TLS
+	Protoset  string            `yaml:"protoset"`  // FileDescriptorSet file; server reflection is used when empty
+	Headers   map[string]string `yaml:"headers"`   // Request metadata; values support ${VAR} and secret references
+}
+
+// grpcExecutor calls a gRPC method. ExecuteConfig.Command holds the rendered
+// JSON request.
+type grpcExecutor struct {
+	def     GRPCDef
+	service string // Fully qualified service name
+	method  string // Method name within the service
+	headers metadata.MD
+
+	mu   sync.Mutex
+	desc protoreflect.MethodDescriptor // Resolved on first use unless from a protoset
+}
+
+// newGRPCExecutor validates def. A protoset (relative to baseDir) is read
+// immediately, so a missing method is reported when the command is loaded.
+func newGRPCExecutor(def GRPCDef, baseDir string) (*grpcExecutor, error) {
+	if def.Address == "" {
+		return nil, errors.New("grpc.address is required")
+	}
+	service, method, ok := strings.Cut(strings.TrimPrefix(def.Method, "/"), "/")
+	if !ok || service == "" || method == "" {
+		return nil, fmt.Errorf("grpc.method must be package.Service/Method, got %q", def.Method)
+	}
+	if def.Request == "" {
+		def.Request = "{}"
+	}
+
+	e := &grpcExecutor{def: def, service: service, method: method, headers: metadata.MD{}}
+	for k, v := range def.Headers {
+		val, err := config.Expand(v)
+		if err != nil {
+			return nil, fmt.Errorf("grpc.headers %s: %w", k, err)
+		}
+		e.headers.Append(k, val)
+	}
+
+	if def.Protoset != "" {
+		path := def.Protoset
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		files, err := readProtoset(path)
+		if err != nil {
+			return nil, fmt.Errorf("grpc.protoset: %w", err)
+		}
+		if e.desc, err = findMethod(files, service, method); err != nil {
+			return nil, fmt.Errorf("grpc.protoset: %w", err)
+		}
+	}
+	return e, nil
+}
+
+// Execute calls the method with the rendered JSON request and writes the
+// response as indented JSON. Only unary methods are supported.
+func (e *grpcExecutor) Execute(ctx context.Context, cfg ExecuteConfig) error {
+	if len(cfg.Args) > 0 {
+		return errors.New("gRPC commands take arguments only through argument definitions")
+	}
+
+	creds := credentials.NewTLS(&tls.Config{})
+	if e.def.Plaintext {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(e.def.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", e.def.Address, err)
+	}
+	defer conn.Close()
+
+	desc, err := e.methodDescriptor(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if desc.IsStreamingClient() || desc.IsStreamingServer() {
+		return fmt.Errorf("%s is a streaming method; only unary methods are supported", e.def.Method)
+	}
+
+	req := dynamicpb.NewMessage(desc.Input())
+	if err := protojson.Unmarshal([]byte(cfg.Command), req); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	resp := dynamicpb.NewMessage(desc.Output())
+
+	ctx = metadata.NewOutgoingContext(ctx, e.headers)
+	if err := conn.Invoke(ctx, "/"+e.service+"/"+e.method, req, resp); err != nil {
+		return fmt.Errorf("call %s: %w", e.def.Method, err)
+	}
+
+	out, err := protojson.MarshalOptions{Multiline: true, Indent: "  ", EmitUnpopulated: true}.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("format response: %w", err)
+	}
+	_, err = fmt.Fprintln(cfg.Output, string(out))
+	return err
+}
+
+// methodDescriptor returns the method's descriptor, asking the server via
+// reflection the first time if no protoset was given.
+func (e *grpcExecutor) methodDescriptor(ctx context.Context, conn *grpc.ClientConn) (protoreflect.MethodDescriptor, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.desc != nil {
+		return e.desc, nil
+	}
+
+	files, err := reflectFiles(ctx, conn, e.service)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection: %w", err)
+	}
+	desc, err := findMethod(files, e.service, e.method)
+	if err != nil {
+		return nil, err
+	}
+	e.desc = desc
+	return desc, nil
+}
+
+// readProtoset reads a FileDescriptorSet, as written by
+// protoc --descriptor_set_out --include_imports.
+func readProtoset(path string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return protodesc.NewFiles(&set)
+}
+
+// reflectFiles fetches the file defining service and its dependencies with
+// the gRPC server reflection API.
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, service string) (*protoregistry.Files, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	var set descriptorpb.FileDescriptorSet
+	seen := make(map[string]bool)
+	queue := []*reflectionpb.ServerReflectionRequest{{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	}}
+	for len(queue) > 0 {
+		if err := stream.Send(queue[0]); err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return nil, errors.New(errResp.GetErrorMessage())
+		}
+
+		for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			var file descriptorpb.FileDescriptorProto
+			if err := proto.Unmarshal(raw, &file); err != nil {
+				return nil, err
+			}
+			if seen[file.GetName()] {
+				continue
+			}
+			seen[file.GetName()] = true
+			set.File = append(set.File, &file)
+			for _, dep := range file.GetDependency() {
+				if !seen[dep] {
+					queue = append(queue, &reflectionpb.ServerReflectionRequest{
+						MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+					})
+				}
+			}
+		}
+	}
+	return protodesc.NewFiles(&set)
+}
+
+// findMethod looks up service/method in files.
+func findMethod(files *protoregistry.Files, service, method string) (protoreflect.MethodDescriptor, error) {
+	d, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found", service)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("method %s not found in %s", method, service)
+	}
+	return md, nil
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+func TestGRPCExecutor(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus("db", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	reflection.Register(srv)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	e, err := newGRPCExecutor(GRPCDef{
+		Address:   lis.Addr().String(),
+		Method:    "grpc.health.v1.Health/Check",
+		Plaintext: true,
+	}, "")
+	if err != nil {
+		t.Fatalf("newGRPCExecutor() error = %v", err)
+	}
+
+	var out strings.Builder
+	err = e.Execute(context.Background(), ExecuteConfig{Command: `{"service": "db"}`, Output: &out})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "NOT_SERVING") {
+		t.Errorf("Execute() output = %q, want NOT_SERVING status", out.String())
+	}
+
+	if err := e.Execute(context.Background(), ExecuteConfig{Command: `{"bogus": 1}`, Output: &out}); err == nil {
+		t.Error("Execute() with unknown field: want error")
+	}
+	if _, err := newGRPCExecutor(GRPCDef{Address: "x:1", Method: "Check"}, ""); err == nil {
+		t.Error("newGRPCExecutor() without service: want error")
+	}
+}
@@ -0,0 +1,295 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonFilter is a parsed jq-like filter: stages joined by "|", each taking
+// every result of the previous stage as input.
+//
+// Supported: "." (identity), ".name", `.["name"]`, ".[0]", ".[-1]" (from
+// the end), ".[]" (every element or value), chains of these such as
+// ".items[].metadata.name", and the functions "keys" and "length".
+type jsonFilter []filterStage
+
+// filterStage is a path of steps, or one of the functions.
+type filterStage struct {
+	fn    string // "keys" or "length"; empty for a path
+	steps []filterStep
+}
+
+// filterStep is one path element.
+type filterStep struct {
+	field   string
+	index   int
+	kind    stepKind
+	literal string // As written, for error messages
+}
+
+type stepKind int
+
+const (
+	stepField stepKind = iota
+	stepIndex
+	stepIterate
+)
+
+// parseJSONFilter parses a filter expression.
+func parseJSONFilter(expr string) (jsonFilter, error) {
+	var filter jsonFilter
+	for _, part := range splitPipes(expr) {
+		stage, err := parseFilterStage(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, stage)
+	}
+	return filter, nil
+}
+
+// splitPipes splits expr on "|" outside of quoted strings.
+func splitPipes(expr string) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case expr[i] == '\\' && quoted:
+			i++
+		case expr[i] == '"':
+			quoted = !quoted
+		case expr[i] == '|' && !quoted:
+			parts = append(parts, expr[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, expr[start:])
+}
+
+// parseFilterStage parses one stage between pipes.
+func parseFilterStage(s string) (filterStage, error) {
+	switch s {
+	case "keys", "length":
+		return filterStage{fn: s}, nil
+	case "":
+		return filterStage{}, errors.New("empty filter")
+	}
+	if s[0] != '.' {
+		return filterStage{}, fmt.Errorf("unsupported filter %q: must start with \".\"", s)
+	}
+
+	var stage filterStage
+	rest := s[1:]
+	if rest != "" && rest[0] != '[' {
+		rest = "." + rest
+	}
+	for rest != "" {
+		var step filterStep
+		var err error
+		switch rest[0] {
+		case '.':
+			step, rest, err = parseFieldStep(rest[1:])
+		case '[':
+			step, rest, err = parseBracketStep(rest[1:])
+		default:
+			err = fmt.Errorf("unexpected %q", rest)
+		}
+		if err != nil {
+			return filterStage{}, fmt.Errorf("invalid filter %q: %w", s, err)
+		}
+		stage.steps = append(stage.steps, step)
+	}
+	return stage, nil
+}
+
+// parseFieldStep parses the name after ".", which may be followed by a
+// bracket directly (".items[]").
+func parseFieldStep(s string) (filterStep, string, error) {
+	if s != "" && s[0] == '[' {
+		return parseBracketStep(s[1:])
+	}
+	end := 0
+	for end < len(s) && (s[end] == '_' || s[end] >= 'a' && s[end] <= 'z' || s[end] >= 'A' && s[end] <= 'Z' || end > 0 && s[end] >= '0' && s[end] <= '9') {
+		end++
+	}
+	if end == 0 {
+		return filterStep{}, "", errors.New("expected a field name after \".\"")
+	}
+	return filterStep{kind: stepField, field: s[:end], literal: "." + s[:end]}, s[end:], nil
+}
+
+// parseBracketStep parses what follows "[": "]", `"name"]` or "n]".
+func parseBracketStep(s string) (filterStep, string, error) {
+	end := strings.IndexByte(s, ']')
+	if s != "" && s[0] == '"' {
+		// The closing quote may be followed by "]" only
+		q := 1
+		for q < len(s) && s[q] != '"' {
+			if s[q] == '\\' {
+				q++
+			}
+			q++
+		}
+		end = q + 1
+		if end >= len(s) || s[end] != ']' {
+			return filterStep{}, "", errors.New("unterminated [\"...\"]")
+		}
+	}
+	if end < 0 {
+		return filterStep{}, "", errors.New("missing \"]\"")
+	}
+
+	inner, rest := s[:end], s[end+1:]
+	literal := "[" + inner + "]"
+	switch {
+	case inner == "":
+		return filterStep{kind: stepIterate, literal: literal}, rest, nil
+	case inner[0] == '"':
+		name, err := strconv.Unquote(inner)
+		if err != nil {
+			return filterStep{}, "", fmt.Errorf("invalid key %s", inner)
+		}
+		return filterStep{kind: stepField, field: name, literal: literal}, rest, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil {
+		return filterStep{}, "", fmt.Errorf("invalid index %q", inner)
+	}
+	return filterStep{kind: stepIndex, index: n, literal: literal}, rest, nil
+}
+
+// apply runs the filter on a decoded JSON value.
+func (f jsonFilter) apply(v any) ([]any, error) {
+	values := []any{v}
+	for _, stage := range f {
+		var next []any
+		for _, in := range values {
+			out, err := stage.apply(in)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// apply runs one stage on a value.
+func (s filterStage) apply(v any) ([]any, error) {
+	switch s.fn {
+	case "keys":
+		switch x := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(x))
+			for k := range x {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			out := make([]any, len(keys))
+			for i, k := range keys {
+				out[i] = k
+			}
+			return []any{out}, nil
+		case []any:
+			out := make([]any, len(x))
+			for i := range x {
+				out[i] = json.Number(strconv.Itoa(i))
+			}
+			return []any{out}, nil
+		}
+		return nil, fmt.Errorf("%s has no keys", jsonType(v))
+	case "length":
+		switch x := v.(type) {
+		case map[string]any:
+			return []any{json.Number(strconv.Itoa(len(x)))}, nil
+		case []any:
+			return []any{json.Number(strconv.Itoa(len(x)))}, nil
+		case string:
+			return []any{json.Number(strconv.Itoa(len([]rune(x))))}, nil
+		case nil:
+			return []any{json.Number("0")}, nil
+		}
+		return nil, fmt.Errorf("%s has no length", jsonType(v))
+	}
+
+	values := []any{v}
+	for _, step := range s.steps {
+		var next []any
+		for _, in := range values {
+			out, err := step.apply(in)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// apply runs one path step on a value. Like jq, indexing null gives null and
+// an index out of range gives null.
+func (s filterStep) apply(v any) ([]any, error) {
+	switch s.kind {
+	case stepField:
+		switch x := v.(type) {
+		case map[string]any:
+			return []any{x[s.field]}, nil
+		case nil:
+			return []any{nil}, nil
+		}
+	case stepIndex:
+		switch x := v.(type) {
+		case []any:
+			i := s.index
+			if i < 0 {
+				i += len(x)
+			}
+			if i < 0 || i >= len(x) {
+				return []any{nil}, nil
+			}
+			return []any{x[i]}, nil
+		case nil:
+			return []any{nil}, nil
+		}
+	case stepIterate:
+		switch x := v.(type) {
+		case []any:
+			return x, nil
+		case map[string]any:
+			keys := make([]string, 0, len(x))
+			for k := range x {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			out := make([]any, len(keys))
+			for i, k := range keys {
+				out[i] = x[k]
+			}
+			return out, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot apply %s to %s", s.literal, jsonType(v))
+}
+
+// jsonType names the JSON type of a decoded value for error messages.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
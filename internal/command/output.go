@@ -0,0 +1,172 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Output formats.
+const (
+	OutputJSON  = "json"
+	OutputTable = "table"
+)
+
+// OutputDef configures how a command's output is formatted before it is
+// sent. Formatted output is sent once the command finishes instead of being
+// streamed.
+type OutputDef struct {
+	Format   string   `yaml:"format"`   // "json": pretty-print JSON output; "table": align delimited output
+	Filter   string   `yaml:"filter"`   // jq-like expression applied to JSON output, e.g. ".items[].metadata.name"
+	Columns  []string `yaml:"columns"`  // Table columns to keep, by header name (default: all)
+	Width    int      `yaml:"width"`    // Table width in characters (default: DefaultTableWidth)
+	Template string   `yaml:"template"` // Go template over OutputData replacing the output
+}
+
+// OutputData is what an output template renders.
+type OutputData struct {
+	Output   string            // Captured output, after format and filter
+	ExitCode int               // 0 on success, -1 if the command failed without an exit status
+	Error    string            // Why the command failed; empty on success
+	Duration time.Duration     // How long the command ran
+	Args     map[string]string // Argument values by name, for commands with arguments
+	Argv     []string          // Arguments typed after the command, for commands without
+}
+
+// JSON decodes Output as JSON, for templates such as
+// {{with .JSON}}{{.status}}{{end}}. It returns nil if Output isn't JSON.
+func (d OutputData) JSON() any {
+	dec := json.NewDecoder(strings.NewReader(d.Output))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// outputFuncs are the functions available in output templates besides the
+// text/template built-ins.
+var outputFuncs = template.FuncMap{
+	"trim": strings.TrimSpace,
+	"lines": func(s string) []string {
+		var lines []string
+		for _, line := range strings.Split(s, "\n") {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	},
+}
+
+// Validate checks the format and its options and parses the filter.
+func (o OutputDef) Validate() error {
+	switch o.Format {
+	case "", OutputJSON, OutputTable:
+	default:
+		return fmt.Errorf("output.format must be %q or %q, got %q", OutputJSON, OutputTable, o.Format)
+	}
+	if o.Filter != "" {
+		if o.Format != OutputJSON {
+			return fmt.Errorf("output.filter requires output.format %q", OutputJSON)
+		}
+		if _, err := parseJSONFilter(o.Filter); err != nil {
+			return fmt.Errorf("output.filter: %w", err)
+		}
+	}
+	if (len(o.Columns) > 0 || o.Width != 0) && o.Format != OutputTable {
+		return fmt.Errorf("output.columns and output.width require output.format %q", OutputTable)
+	}
+	if o.Width < 0 || (o.Width > 0 && o.Width < 2*tableMinColumn) {
+		return fmt.Errorf("output.width must be at least %d", 2*tableMinColumn)
+	}
+	if o.Template != "" {
+		if _, err := o.parseTemplate(); err != nil {
+			return fmt.Errorf("output.template: %w", err)
+		}
+	}
+	return nil
+}
+
+// Render executes the output template with data.
+func (o OutputDef) Render(data OutputData) ([]byte, error) {
+	tmpl, err := o.parseTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("output.template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("output.template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (o OutputDef) parseTemplate() (*template.Template, error) {
+	return template.New("output").Funcs(outputFuncs).Option("missingkey=zero").Parse(o.Template)
+}
+
+// Apply formats output. Output that isn't JSON or a table, as the format
+// expects, is returned unchanged.
+func (o OutputDef) Apply(output []byte) ([]byte, error) {
+	switch o.Format {
+	case OutputJSON:
+		return formatJSON(output, o.Filter)
+	case OutputTable:
+		return formatTable(output, o.Columns, o.Width), nil
+	}
+	return output, nil
+}
+
+// formatJSON pretty-prints JSON output, applying filter if set. Each filter
+// result is printed on its own; strings are printed without quotes, like
+// jq -r. Output that isn't a JSON document is returned unchanged.
+func formatJSON(output []byte, filter string) ([]byte, error) {
+	trimmed := bytes.TrimSpace(output)
+	if len(trimmed) == 0 || !json.Valid(trimmed) {
+		return output, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return output, nil
+	}
+
+	results := []any{doc}
+	if filter != "" {
+		f, err := parseJSONFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		if results, err = f.apply(doc); err != nil {
+			return nil, fmt.Errorf("output.filter: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, v := range results {
+		if s, ok := v.(string); ok {
+			buf.WriteString(s)
+			buf.WriteByte('\n')
+			continue
+		}
+		if err := writeIndentedJSON(&buf, v); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeIndentedJSON writes v as indented JSON without HTML escaping.
+func writeIndentedJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeExecutor writes output and returns err.
+type fakeExecutor struct {
+	output string
+	err    error
+}
+
+func (f fakeExecutor) Execute(ctx context.Context, cfg ExecuteConfig) error {
+	io.WriteString(cfg.Output, f.output)
+	return f.err
+}
+
+func TestFormatJSON(t *testing.T) {
+	doc := `{"items": [{"metadata": {"name": "web", "labels": {"app": "<web>"}}, "replicas": 3}, {"metadata": {"name": "db"}, "replicas": 1}]}`
+
+	tests := []struct {
+		name    string
+		output  string
+		filter  string
+		want    string
+		wantErr bool
+	}{
+		{"pretty print", `{"a":1,"b":[true,null]}`, "", "{\n  \"a\": 1,\n  \"b\": [\n    true,\n    null\n  ]\n}\n", false},
+		{"not json", "plain text\n", "", "plain text\n", false},
+		{"field iterate", doc, ".items[].metadata.name", "web\ndb\n", false},
+		{"index and quoted key", doc, `.items[-1]["replicas"]`, "1\n", false},
+		{"no html escaping", doc, ".items[0].metadata.labels", "{\n  \"app\": \"<web>\"\n}\n", false},
+		{"missing field", doc, ".items[5].metadata", "null\n", false},
+		{"pipe and length", doc, ".items | length", "2\n", false},
+		{"keys", doc, ".items[0] | keys", "[\n  \"metadata\",\n  \"replicas\"\n]\n", false},
+		{"large numbers kept", `{"id": 12345678901234567890}`, ".id", "12345678901234567890\n", false},
+		{"type error", doc, ".items.name", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatJSON([]byte(tt.output), tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("formatJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputDefValidate(t *testing.T) {
+	tests := []struct {
+		def     OutputDef
+		wantErr bool
+	}{
+		{OutputDef{}, false},
+		{OutputDef{Format: "json", Filter: ".a[0].b"}, false},
+		{OutputDef{Format: "yaml"}, true},
+		{OutputDef{Filter: ".a"}, true},
+		{OutputDef{Format: "json", Filter: "a"}, true},
+		{OutputDef{Format: "json", Filter: ".a["}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.def.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.def, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOutputTemplate(t *testing.T) {
+	def := YAMLCommandDef{Output: OutputDef{
+		Format:   "json",
+		Template: `{{if .Error}}❌ {{.Args.env}}: exit {{.ExitCode}}{{else}}✅ {{.Args.env}} is {{.JSON.status}} ({{len (lines .Output)}} lines){{end}}`,
+	}}
+	args := map[string]string{"env": "prod"}
+
+	cmd := &YAMLCommand{def: def, executor: fakeExecutor{output: `{"status":"healthy"}`}}
+	var out strings.Builder
+	if err := cmd.ExecuteRendered(context.Background(), "check", args, &out); err != nil {
+		t.Fatalf("ExecuteRendered() error = %v", err)
+	}
+	if want := "✅ prod is healthy (3 lines)"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+
+	failure := errors.New("boom")
+	cmd = &YAMLCommand{def: def, executor: fakeExecutor{output: "not json", err: failure}}
+	out.Reset()
+	if err := cmd.ExecuteRendered(context.Background(), "check", args, &out); !errors.Is(err, failure) {
+		t.Fatalf("ExecuteRendered() error = %v, want %v", err, failure)
+	}
+	if want := "❌ prod: exit -1"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
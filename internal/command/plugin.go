@@ -0,0 +1,123 @@
+package command
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"plugin"
+	"slices"
+	"sort"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// PluginSymbol is the function a Go plugin exports to provide its commands:
+//
+//	func Commands() []command.Command
+const PluginSymbol = "Commands"
+
+// pluginFiles returns the Go plugins (.so files) and external plugins (other
+// executables) directly in dir, sorted by name. A missing directory has no
+// plugins.
+func pluginFiles(dir string) (goPlugins, external []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read plugins directory: %w", err)
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if filepath.Ext(e.Name()) == ".so" {
+			goPlugins = append(goPlugins, path)
+			continue
+		}
+		info, err := os.Stat(path) // Follows symlinks
+		if err == nil && info.Mode().IsRegular() && info.Mode()&0o111 != 0 {
+			external = append(external, path)
+		}
+	}
+	sort.Strings(goPlugins)
+	sort.Strings(external)
+	return goPlugins, external, nil
+}
+
+// loadPlugin opens a Go plugin and returns its commands. The Go runtime
+// never unloads a plugin: opening the same path again returns the already
+// loaded code, so a changed .so takes effect only after a restart.
+func loadPlugin(path string) ([]pkgcmd.Command, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := sym.(func() []pkgcmd.Command)
+	if !ok {
+		return nil, fmt.Errorf("%s has type %T, want func() []command.Command", PluginSymbol, sym)
+	}
+
+	commands := fn()
+	for i, cmd := range commands {
+		if cmd == nil || cmd.Name() == "" {
+			return nil, fmt.Errorf("command %d has no name", i)
+		}
+	}
+	return commands, nil
+}
+
+// externalCommands returns the commands of the external plugin at path,
+// starting it unless it is already running from the same executable.
+func (l *Loader) externalCommands(path string) ([]pkgcmd.Command, error) {
+	l.externalMu.Lock()
+	defer l.externalMu.Unlock()
+
+	p := l.external[path]
+	if p != nil && !p.Running() {
+		p.Close()
+		p = nil
+	}
+	if p == nil {
+		var err error
+		if p, err = StartExternalPlugin(path); err != nil {
+			return nil, err
+		}
+		if l.external == nil {
+			l.external = make(map[string]*ExternalPlugin)
+		}
+		l.external[path] = p
+		slog.Info("external plugin started", "plugin", path)
+	}
+
+	commands, err := p.Commands()
+	if err != nil {
+		p.Close()
+		delete(l.external, path)
+		return nil, err
+	}
+	return commands, nil
+}
+
+// stopExternal stops running external plugins not in keep.
+func (l *Loader) stopExternal(keep []string) {
+	l.externalMu.Lock()
+	defer l.externalMu.Unlock()
+
+	for path, p := range l.external {
+		if !slices.Contains(keep, path) {
+			p.Close()
+			delete(l.external, path)
+			slog.Info("external plugin stopped", "plugin", path)
+		}
+	}
+}
+
+// Close stops all external plugins.
+func (l *Loader) Close() {
+	l.stopExternal(nil)
+}
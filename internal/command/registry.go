@@ -3,36 +3,191 @@ package command
 
 import (
 	"sort"
+	"strings"
 	"sync"
 
+	"github.com/rashpile/pako-telegram/internal/config"
 	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
 // Registry manages available commands with thread-safe access.
 type Registry struct {
-	mu       sync.RWMutex
-	commands map[string]pkgcmd.Command
+	mu         sync.RWMutex
+	commands   map[string]pkgcmd.Command
+	builtins   map[string]bool // Names registered with RegisterBuiltin
+	categories map[string]config.CategoryConfig
+	hooks      []ChangeHook
 }
 
+// ChangeKind says what happened to a command in the registry.
+type ChangeKind int
+
+// Kinds of registry changes.
+const (
+	CommandAdded    ChangeKind = iota // Registered under a new name
+	CommandRemoved                    // Unregistered or dropped by Reload
+	CommandReplaced                   // Registered or reloaded under an existing name
+)
+
+// String returns "added", "removed" or "replaced".
+func (k ChangeKind) String() string {
+	switch k {
+	case CommandAdded:
+		return "added"
+	case CommandRemoved:
+		return "removed"
+	case CommandReplaced:
+		return "replaced"
+	}
+	return "unknown"
+}
+
+// Change describes a command added to, removed from or replaced in the
+// registry.
+type Change struct {
+	Kind    ChangeKind
+	Name    string
+	Command pkgcmd.Command // The new command, or the removed one for CommandRemoved
+}
+
+// ChangeHook is called with the changes made by one Register, Unregister
+// or Reload call.
+type ChangeHook func(changes []Change)
+
 // NewRegistry creates an empty command registry.
 func NewRegistry() *Registry {
 	return &Registry{
 		commands: make(map[string]pkgcmd.Command),
+		builtins: make(map[string]bool),
 	}
 }
 
-// Register adds a command. Overwrites if name exists.
-func (r *Registry) Register(cmd pkgcmd.Command) {
+// SetCategories sets menu metadata (display name, icon, order) by category name.
+func (r *Registry) SetCategories(categories map[string]config.CategoryConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.categories = categories
+}
+
+// CategoryConfig returns the configured metadata for a category, if any.
+func (r *Registry) CategoryConfig(name string) (config.CategoryConfig, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cfg, ok := r.categories[name]
+	return cfg, ok
+}
+
+// OnChange subscribes hook to registry changes, so components such as the
+// menus and the scheduler can follow reloads. Hooks run in subscription
+// order after each change, outside the registry's lock.
+func (r *Registry) OnChange(hook ChangeHook) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+// update applies fn under the write lock, then passes the changes it
+// reports to the hooks.
+func (r *Registry) update(fn func() []Change) {
+	r.mu.Lock()
+	changes := fn()
+	hooks := r.hooks
+	r.mu.Unlock()
+
+	if len(changes) == 0 {
+		return
+	}
+	for _, hook := range hooks {
+		hook(changes)
+	}
+}
+
+// put stores cmd and describes the change. Callers hold the write lock.
+func (r *Registry) put(cmd pkgcmd.Command) Change {
+	kind := CommandAdded
+	if _, ok := r.commands[cmd.Name()]; ok {
+		kind = CommandReplaced
+	}
 	r.commands[cmd.Name()] = cmd
+	return Change{Kind: kind, Name: cmd.Name(), Command: cmd}
+}
+
+// Register adds a command. Overwrites if name exists.
+func (r *Registry) Register(cmd pkgcmd.Command) {
+	r.update(func() []Change {
+		delete(r.builtins, cmd.Name())
+		return []Change{r.put(cmd)}
+	})
+}
+
+// RegisterBuiltin adds a built-in command, which Reload keeps. Overwrites
+// if name exists.
+func (r *Registry) RegisterBuiltin(cmd pkgcmd.Command) {
+	r.update(func() []Change {
+		r.builtins[cmd.Name()] = true
+		return []Change{r.put(cmd)}
+	})
+}
+
+// Unregister removes a command by name. No-op if it doesn't exist.
+func (r *Registry) Unregister(name string) {
+	r.update(func() []Change {
+		cmd, ok := r.commands[name]
+		if !ok {
+			return nil
+		}
+		delete(r.commands, name)
+		delete(r.builtins, name)
+		return []Change{{Kind: CommandRemoved, Name: name, Command: cmd}}
+	})
 }
 
-// Get retrieves a command by name. Returns nil if not found.
+// Get retrieves a command by name. Returns nil if not found. Subcommands
+// are found by their full name, e.g. "docker ps".
 func (r *Registry) Get(name string) pkgcmd.Command {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.commands[name]
+	if cmd, ok := r.commands[name]; ok {
+		return cmd
+	}
+	parent, _, ok := strings.Cut(name, " ")
+	if !ok {
+		return nil
+	}
+	for _, sub := range pkgcmd.Subcommands(r.commands[parent]) {
+		if sub.Name() == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// Resolve looks up the command invoked as /name followed by argText. For a
+// command with subcommands, a leading word naming one of them selects that
+// subcommand and is removed from the returned argument text; otherwise the
+// command itself is returned with argText unchanged. Returns nil if name
+// is not registered.
+func (r *Registry) Resolve(name, argText string) (pkgcmd.Command, string) {
+	cmd := r.Get(name)
+	subs := pkgcmd.Subcommands(cmd)
+	if len(subs) == 0 {
+		return cmd, argText
+	}
+
+	trimmed := strings.TrimLeft(argText, " \t\n")
+	word := trimmed
+	if i := strings.IndexAny(trimmed, " \t\n"); i >= 0 {
+		word = trimmed[:i]
+	}
+	if word == "" {
+		return cmd, argText
+	}
+	for _, sub := range subs {
+		if sub.Name() == name+" "+word {
+			return sub, strings.TrimLeft(trimmed[len(word):], " \t")
+		}
+	}
+	return cmd, argText
 }
 
 // All returns all registered commands (for /help).
@@ -48,37 +203,68 @@ func (r *Registry) All() []pkgcmd.Command {
 }
 
 // Reload atomically replaces all YAML-based commands.
-// Built-in commands are preserved.
+// Commands registered with RegisterBuiltin are preserved and win over
+// loaded commands of the same name.
+// Loaded commands are reported as added or replaced, dropped ones as
+// removed; preserved built-ins are unchanged.
 func (r *Registry) Reload(commands []pkgcmd.Command) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	r.update(func() []Change {
+		var changes []Change
 
-	// Create new map with provided commands
-	newCommands := make(map[string]pkgcmd.Command, len(commands))
-	for _, cmd := range commands {
-		newCommands[cmd.Name()] = cmd
-	}
+		// Create new map with provided commands
+		newCommands := make(map[string]pkgcmd.Command, len(commands))
+		for _, cmd := range commands {
+			if r.builtins[cmd.Name()] {
+				continue
+			}
+			kind := CommandAdded
+			if _, ok := r.commands[cmd.Name()]; ok {
+				kind = CommandReplaced
+			}
+			newCommands[cmd.Name()] = cmd
+			changes = append(changes, Change{Kind: kind, Name: cmd.Name(), Command: cmd})
+		}
 
-	// Preserve built-in commands (help, status, reload, version, scheduled)
-	builtins := []string{"help", "status", "reload", "version", "scheduled"}
-	for _, name := range builtins {
-		if cmd, ok := r.commands[name]; ok {
-			newCommands[name] = cmd
+		// Preserve built-in commands
+		for name := range r.builtins {
+			newCommands[name] = r.commands[name]
 		}
-	}
 
-	r.commands = newCommands
+		for name, cmd := range r.commands {
+			if _, ok := newCommands[name]; !ok {
+				changes = append(changes, Change{Kind: CommandRemoved, Name: name, Command: cmd})
+			}
+		}
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Name < changes[j].Name
+		})
+
+		r.commands = newCommands
+		return changes
+	})
 }
 
 // CategoryWithCommands holds a category and its commands.
 type CategoryWithCommands struct {
-	Name     string
-	Icon     string
-	Commands []pkgcmd.Command
+	Name        string
+	DisplayName string // Configured menu label, empty if not set
+	Icon        string
+	Order       int
+	Commands    []pkgcmd.Command
+}
+
+// Label returns the display name, falling back to the category name.
+func (c CategoryWithCommands) Label() string {
+	if c.DisplayName != "" {
+		return c.DisplayName
+	}
+	return c.Name
 }
 
-// Categories returns commands grouped by category, sorted alphabetically.
-// Commands without a category are grouped under "other".
+// Categories returns commands grouped by category, sorted by configured
+// order and then alphabetically. Commands without a category are grouped
+// under "other".
+// Hidden and disabled commands are excluded.
 func (r *Registry) Categories() []CategoryWithCommands {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -87,6 +273,10 @@ func (r *Registry) Categories() []CategoryWithCommands {
 	groups := make(map[string]*CategoryWithCommands)
 
 	for _, cmd := range r.commands {
+		if !listed(cmd) {
+			continue
+		}
+
 		catName := "other"
 		catIcon := ""
 
@@ -104,6 +294,13 @@ func (r *Registry) Categories() []CategoryWithCommands {
 				Name: catName,
 				Icon: catIcon,
 			}
+			if cfg, ok := r.categories[catName]; ok {
+				group.DisplayName = cfg.DisplayName
+				group.Order = cfg.Order
+				if cfg.Icon != "" {
+					group.Icon = cfg.Icon
+				}
+			}
 			groups[catName] = group
 		}
 		// Update icon if we have one and the group doesn't
@@ -123,13 +320,15 @@ func (r *Registry) Categories() []CategoryWithCommands {
 		result = append(result, *group)
 	}
 
-	// Sort categories (other always last)
+	// Sort categories by order, then name (unconfigured "other" always last)
 	sort.Slice(result, func(i, j int) bool {
-		if result[i].Name == "other" {
-			return false
+		iOther := result[i].Name == "other" && r.categories["other"] == (config.CategoryConfig{})
+		jOther := result[j].Name == "other" && r.categories["other"] == (config.CategoryConfig{})
+		if iOther != jOther {
+			return jOther
 		}
-		if result[j].Name == "other" {
-			return true
+		if result[i].Order != result[j].Order {
+			return result[i].Order < result[j].Order
 		}
 		return result[i].Name < result[j].Name
 	})
@@ -138,12 +337,17 @@ func (r *Registry) Categories() []CategoryWithCommands {
 }
 
 // ByCategory returns commands in a specific category.
+// Hidden and disabled commands are excluded.
 func (r *Registry) ByCategory(category string) []pkgcmd.Command {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var cmds []pkgcmd.Command
 	for _, cmd := range r.commands {
+		if !listed(cmd) {
+			continue
+		}
+
 		catName := "other"
 		if withCat, ok := cmd.(pkgcmd.WithCategory); ok {
 			info := withCat.Category()
@@ -164,3 +368,8 @@ func (r *Registry) ByCategory(category string) []pkgcmd.Command {
 
 	return cmds
 }
+
+// listed returns true if the command should appear in menus.
+func listed(cmd pkgcmd.Command) bool {
+	return !pkgcmd.IsHidden(cmd) && !pkgcmd.IsDisabled(cmd)
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+type namedCmd string
+
+func (c namedCmd) Name() string                                       { return string(c) }
+func (c namedCmd) Description() string                                { return "" }
+func (c namedCmd) Execute(context.Context, []string, io.Writer) error { return nil }
+
+func TestReloadKeepsBuiltins(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(namedCmd("deploy"))
+	registry.RegisterBuiltin(namedCmd("version"))
+	registry.RegisterBuiltin(namedCmd("podcast"))
+	registry.RegisterBuiltin(namedCmd("backup"))
+	registry.Unregister("podcast")
+
+	registry.Reload([]pkgcmd.Command{namedCmd("uptime"), namedCmd("backup")})
+
+	for _, name := range []string{"uptime", "version", "backup"} {
+		if registry.Get(name) == nil {
+			t.Errorf("%s missing after reload", name)
+		}
+	}
+	for _, name := range []string{"deploy", "podcast"} {
+		if registry.Get(name) != nil {
+			t.Errorf("%s kept after reload", name)
+		}
+	}
+
+	// A plain registration replaces the built-in, which then goes on reload
+	registry.Register(namedCmd("version"))
+	registry.Reload(nil)
+	if registry.Get("version") != nil {
+		t.Error("version kept after re-registering it as a plain command")
+	}
+	if registry.Get("backup") == nil {
+		t.Error("backup missing after second reload")
+	}
+}
+
+func TestChangeHooks(t *testing.T) {
+	registry := NewRegistry()
+	var got []string
+	registry.OnChange(func(changes []Change) {
+		for _, c := range changes {
+			got = append(got, c.Kind.String()+" "+c.Name)
+		}
+		got = append(got, "|")
+	})
+
+	registry.Register(namedCmd("deploy"))
+	registry.RegisterBuiltin(namedCmd("help"))
+	registry.Register(namedCmd("deploy"))
+	registry.Unregister("missing")
+	registry.Reload([]pkgcmd.Command{namedCmd("uptime"), namedCmd("help")})
+	registry.Unregister("uptime")
+
+	want := []string{
+		"added deploy", "|",
+		"added help", "|",
+		"replaced deploy", "|",
+		"removed deploy", "added uptime", "|",
+		"removed uptime", "|",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,199 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rashpile/pako-telegram/internal/auth"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+// YAMLGroup is a command namespace defined in YAML: a parent such as
+// /docker whose subcommands are run as /docker ps, /docker restart.
+type YAMLGroup struct {
+	def  YAMLCommandDef
+	subs []pkgcmd.Command
+}
+
+// Name returns the namespace name.
+func (g *YAMLGroup) Name() string {
+	return g.def.Name
+}
+
+// Description returns the group description.
+func (g *YAMLGroup) Description() string {
+	return g.def.Description
+}
+
+// Execute lists the subcommands. The bot normally shows them as a menu
+// instead of running the group.
+func (g *YAMLGroup) Execute(ctx context.Context, args []string, output io.Writer) error {
+	fmt.Fprintln(output, "Subcommands:")
+	for _, sub := range g.subs {
+		if pkgcmd.IsHidden(sub) {
+			continue
+		}
+		fmt.Fprintf(output, "/%s - %s\n", sub.Name(), sub.Description())
+	}
+	return nil
+}
+
+// Subcommands returns the group's subcommands in definition order.
+func (g *YAMLGroup) Subcommands() []pkgcmd.Command {
+	return g.subs
+}
+
+// Metadata returns the group's visibility and access settings.
+func (g *YAMLGroup) Metadata() pkgcmd.Metadata {
+	meta := pkgcmd.DefaultMetadata()
+	meta.Hidden = g.def.Hidden
+	meta.Disabled = g.def.Disabled
+	meta.RequiredRole = g.def.RequiredRole
+	return meta
+}
+
+// Permits returns true if the group may be used from the chat by the user,
+// per its allowed_chat_ids and allowed_users.
+func (g *YAMLGroup) Permits(chatID, userID int64, username string) bool {
+	return permits(g.def, chatID, userID, username)
+}
+
+// Category returns the group's category for menu grouping.
+func (g *YAMLGroup) Category() pkgcmd.CategoryInfo {
+	return pkgcmd.CategoryInfo{
+		Name: g.def.Category,
+		Icon: g.def.Icon,
+	}
+}
+
+// loadGroup builds a namespace and its subcommands. Subcommands inherit the
+// parent's category, icon, workdir, env, timeout, output limit, truncation,
+// read_only and access restrictions unless they set their own, and add their
+// redact patterns to the parent's.
+func (l *Loader) loadGroup(path string, def YAMLCommandDef, n defNode) (*YAMLGroup, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	for _, key := range []string{"command", "grpc", "arguments", "schedule", "interval", "input", "generate"} {
+		if n.keyLine(n.mapping(), key) > 0 {
+			return nil, n.errorf(key, "commands with subcommands cannot set %s", key)
+		}
+	}
+	if def.RequiredRole != "" {
+		if _, err := auth.ParseRole(def.RequiredRole); err != nil {
+			return nil, n.errorf("required_role", "required_role: %w", err)
+		}
+	}
+
+	group := &YAMLGroup{def: def}
+	seen := make(map[string]bool)
+	var names []string
+	for i, subDef := range def.Subcommands {
+		sn := n.subcommand(i)
+		if subDef.Name == "" || strings.ContainsAny(subDef.Name, " \t\n") {
+			return nil, sn.errorf("name", "subcommand %d: name must be a single word", i+1)
+		}
+		if seen[subDef.Name] {
+			return nil, sn.errorf("name", "duplicate subcommand %q", subDef.Name)
+		}
+		seen[subDef.Name] = true
+		if len(subDef.Subcommands) > 0 {
+			return nil, sn.errorf("subcommands", "subcommand %q: subcommands cannot be nested", subDef.Name)
+		}
+		if len(subDef.Schedule) > 0 || subDef.Interval > 0 {
+			key := "schedule"
+			if subDef.Interval > 0 {
+				key = "interval"
+			}
+			return nil, sn.errorf(key, "subcommand %q: subcommands cannot be scheduled", subDef.Name)
+		}
+
+		sub, err := l.build(path, inherit(def, subDef), sn)
+		if err != nil {
+			return nil, subcommandError(subDef.Name, sn, err)
+		}
+		group.subs = append(group.subs, sub)
+		names = append(names, subDef.Name)
+	}
+
+	if group.def.Description == "" {
+		group.def.Description = "Subcommands: " + strings.Join(names, ", ")
+	}
+	group.def.Subcommands = nil
+	return group, nil
+}
+
+// inherit returns sub with its full name and the parent's settings filled in
+// where sub leaves them unset.
+func inherit(parent, sub YAMLCommandDef) YAMLCommandDef {
+	sub.Name = parent.Name + " " + sub.Name
+	if sub.Category == "" {
+		sub.Category = parent.Category
+	}
+	if sub.Icon == "" {
+		sub.Icon = parent.Icon
+	}
+	if sub.Workdir == "" {
+		sub.Workdir = parent.Workdir
+	}
+	if sub.Timeout == 0 {
+		sub.Timeout = parent.Timeout
+	}
+	if sub.MaxOutput == 0 {
+		sub.MaxOutput = parent.MaxOutput
+	}
+	if sub.Truncate == "" {
+		sub.Truncate = parent.Truncate
+	}
+	if len(sub.AllowedChatIDs) == 0 {
+		sub.AllowedChatIDs = parent.AllowedChatIDs
+	}
+	if len(sub.AllowedUsers) == 0 {
+		sub.AllowedUsers = parent.AllowedUsers
+	}
+	if sub.RequiredRole == "" {
+		sub.RequiredRole = parent.RequiredRole
+	}
+	sub.Disabled = sub.Disabled || parent.Disabled
+	sub.ReadOnly = sub.ReadOnly || parent.ReadOnly
+	sub.Redact = append(slices.Clip(parent.Redact), sub.Redact...)
+	if len(parent.Env) > 0 {
+		env := maps.Clone(parent.Env)
+		maps.Copy(env, sub.Env)
+		sub.Env = env
+	}
+	return sub
+}
+
+// subcommand locates the i-th subcommand's fields for error reporting.
+func (n defNode) subcommand(i int) defNode {
+	seq := n.value(n.mapping(), "subcommands")
+	if seq == nil || seq.Kind != yaml.SequenceNode || i >= len(seq.Content) {
+		return defNode{warnings: n.warnings}
+	}
+	return defNode{root: &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{seq.Content[i]}}, warnings: n.warnings}
+}
+
+// subcommandError prefixes a subcommand's error with its name, keeping the
+// line of the offending field or else of the subcommand.
+func subcommandError(name string, n defNode, err error) error {
+	line := 0
+	if m := n.mapping(); m != nil {
+		line = m.Line
+	}
+	var le *lineError
+	if errors.As(err, &le) {
+		err = le.err
+		if le.line > 0 {
+			line = le.line
+		}
+	}
+	return &lineError{line: line, err: fmt.Errorf("subcommand %q: %w", name, err)}
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rashpile/pako-telegram/internal/config"
+	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
+)
+
+const dockerDef = `name: docker
+category: system
+workdir: /srv
+allowed_chat_ids: [1]
+env:
+  DOCKER_HOST: unix:///run/docker.sock
+subcommands:
+  - name: ps
+    description: List containers
+    command: docker ps
+  - name: restart
+    command: docker restart {{.name}}
+    confirm: true
+    arguments:
+      - name: name
+        required: true
+`
+
+func loadTestCommands(t *testing.T, def string) ([]pkgcmd.Command, error) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cmd.yaml"), []byte(def), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return NewLoader([]string{dir}, config.DefaultsConfig{}, nil).Load()
+}
+
+func TestLoadSubcommands(t *testing.T) {
+	cmds, err := loadTestCommands(t, dockerDef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmds) != 1 {
+		t.Fatalf("got %d commands, want 1", len(cmds))
+	}
+	subs := pkgcmd.Subcommands(cmds[0])
+	if len(subs) != 2 {
+		t.Fatalf("got %d subcommands, want 2", len(subs))
+	}
+
+	restart, ok := subs[1].(*YAMLCommand)
+	if !ok {
+		t.Fatalf("subcommand is %T, want *YAMLCommand", subs[1])
+	}
+	if restart.Name() != "docker restart" {
+		t.Errorf("name = %q, want %q", restart.Name(), "docker restart")
+	}
+	if restart.Workdir() != "/srv" || restart.Category().Name != "system" {
+		t.Errorf("workdir %q, category %q not inherited", restart.Workdir(), restart.Category().Name)
+	}
+	if restart.Permits(2, 0, "") {
+		t.Error("allowed_chat_ids not inherited")
+	}
+	if !restart.Metadata().RequireConfirm || !restart.HasArguments() {
+		t.Error("own settings lost")
+	}
+	if !strings.Contains(cmds[0].Description(), "ps, restart") {
+		t.Errorf("default description = %q", cmds[0].Description())
+	}
+}
+
+func TestLoadSubcommandErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		def  string
+		want string
+	}{
+		{"command on parent", "name: docker\ncommand: docker\nsubcommands:\n  - name: ps\n    command: docker ps\n", "cannot set command"},
+		{"missing command", "name: docker\nsubcommands:\n  - name: ps\n", `subcommand "ps": command is required`},
+		{"two words", "name: docker\nsubcommands:\n  - name: ps all\n    command: docker ps -a\n", "single word"},
+		{"duplicate", "name: docker\nsubcommands:\n  - name: ps\n    command: a\n  - name: ps\n    command: b\n", "duplicate subcommand"},
+		{"scheduled", "name: docker\nsubcommands:\n  - name: ps\n    command: docker ps\n    interval: 1m\n", "cannot be scheduled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestCommands(t, tt.def)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryResolve(t *testing.T) {
+	cmds, err := loadTestCommands(t, dockerDef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewRegistry()
+	registry.Register(cmds[0])
+
+	tests := []struct {
+		name, args   string
+		wantCmd      string
+		wantArgsText string
+	}{
+		{"docker", "ps", "docker ps", ""},
+		{"docker", "restart  name=web", "docker restart", "name=web"},
+		{"docker", "", "docker", ""},
+		{"docker", "logs web", "docker", "logs web"},
+		{"missing", "ps", "", "ps"},
+	}
+	for _, tt := range tests {
+		cmd, rest := registry.Resolve(tt.name, tt.args)
+		got := ""
+		if cmd != nil {
+			got = cmd.Name()
+		}
+		if got != tt.wantCmd || rest != tt.wantArgsText {
+			t.Errorf("Resolve(%q, %q) = %q, %q; want %q, %q", tt.name, tt.args, got, rest, tt.wantCmd, tt.wantArgsText)
+		}
+	}
+
+	if cmd := registry.Get("docker ps"); cmd == nil || cmd.Name() != "docker ps" {
+		t.Errorf("Get(docker ps) = %v", cmd)
+	}
+	if cmd := registry.Get("docker top"); cmd != nil {
+		t.Errorf("Get(docker top) = %v, want nil", cmd.Name())
+	}
+}
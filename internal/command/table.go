@@ -0,0 +1,247 @@
+package command
+
+import (
+	"encoding/csv"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultTableWidth is the table width in characters when output.width is
+// unset; wider tables wrap in Telegram's mobile apps.
+const DefaultTableWidth = 48
+
+// tableMinColumn is how narrow a column may be truncated to.
+const tableMinColumn = 6
+
+// formatTable renders delimited output (tab, "|" or comma separated, or
+// aligned columns like docker ps) as a table at most width characters wide.
+// The first row is the header; columns selects and orders columns by header
+// name (case-insensitive). Output without a recognisable table is returned
+// unchanged.
+func formatTable(output []byte, columns []string, width int) []byte {
+	rows := parseTable(string(output))
+	if rows == nil {
+		return output
+	}
+	if len(columns) > 0 {
+		rows = selectColumns(rows, columns)
+	}
+	if width <= 0 {
+		width = DefaultTableWidth
+	}
+	return []byte(renderTable(rows, width))
+}
+
+// parseTable splits output into rows of cells, trying each delimiter in
+// turn. It returns nil unless there is a header and at least one row, all
+// with the same number of columns (at least two).
+func parseTable(output string) [][]string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, "\r"))
+		}
+	}
+	if len(lines) < 2 {
+		return nil
+	}
+
+	for _, parse := range []func([]string) [][]string{
+		func(lines []string) [][]string { return splitDelimited(lines, "\t") },
+		splitPiped,
+		splitCSV,
+		splitAligned,
+	} {
+		if rows := parse(lines); rows != nil {
+			return rows
+		}
+	}
+	return nil
+}
+
+// splitDelimited splits every line on sep.
+func splitDelimited(lines []string, sep string) [][]string {
+	n := strings.Count(lines[0], sep) + 1
+	if n < 2 {
+		return nil
+	}
+	rows := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		cells := strings.Split(line, sep)
+		if len(cells) != n {
+			return nil
+		}
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+// splitPiped splits "a | b" rows as printed by psql and mysql, dropping
+// border and separator lines made of "-", "+", "=" and "|", and psql's
+// "(2 rows)" footer.
+func splitPiped(lines []string) [][]string {
+	var kept []string
+	for _, line := range lines {
+		if strings.Trim(line, "-+=| ") == "" || psqlFooter.MatchString(line) {
+			continue
+		}
+		// Strip outer borders ("| a | b |")
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "|") && strings.HasSuffix(line, "|") && len(line) > 1 {
+			line = line[1 : len(line)-1]
+		}
+		kept = append(kept, line)
+	}
+	if len(kept) < 2 {
+		return nil
+	}
+	return splitDelimited(kept, "|")
+}
+
+var psqlFooter = regexp.MustCompile(`^\(\d+ rows?\)$`)
+
+// splitCSV parses comma separated values with quoting.
+func splitCSV(lines []string) [][]string {
+	r := csv.NewReader(strings.NewReader(strings.Join(lines, "\n")))
+	r.TrimLeadingSpace = true
+	rows, err := r.ReadAll() // Fails on rows with a different number of fields
+	if err != nil || len(rows) < 2 || len(rows[0]) < 2 {
+		return nil
+	}
+	return rows
+}
+
+// splitAligned splits columns aligned with spaces, like docker ps or
+// kubectl get. Columns start where header words separated by two or more
+// spaces start; every line must have a space before each column start.
+func splitAligned(lines []string) [][]string {
+	header := []rune(lines[0])
+	var starts []int
+	for i, r := range header {
+		if r == ' ' || (i > 0 && header[i-1] != ' ') {
+			continue
+		}
+		if i == 0 || (i >= 2 && header[i-2] == ' ') {
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) < 2 {
+		return nil
+	}
+
+	rows := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		runes := []rune(line)
+		cells := make([]string, len(starts))
+		for i, start := range starts {
+			if start > 0 && start < len(runes) && !unicode.IsSpace(runes[start-1]) {
+				return nil // A value runs across the column boundary
+			}
+			end := len(runes)
+			if i+1 < len(starts) {
+				end = min(starts[i+1], len(runes))
+			}
+			if start < end {
+				cells[i] = strings.TrimSpace(string(runes[start:end]))
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+// selectColumns keeps the named columns, in the given order. Unknown names
+// are ignored; if none match, rows are returned unchanged.
+func selectColumns(rows [][]string, columns []string) [][]string {
+	var idx []int
+	for _, name := range columns {
+		for i, h := range rows[0] {
+			if strings.EqualFold(h, name) {
+				idx = append(idx, i)
+				break
+			}
+		}
+	}
+	if len(idx) == 0 {
+		return rows
+	}
+
+	selected := make([][]string, len(rows))
+	for r, row := range rows {
+		selected[r] = make([]string, len(idx))
+		for c, i := range idx {
+			selected[r][c] = row[i]
+		}
+	}
+	return selected
+}
+
+// renderTable aligns rows into columns separated by two spaces, with a line
+// under the header. While the table is wider than width, the widest column
+// is narrowed, down to tableMinColumn; cut cells end with "…".
+func renderTable(rows [][]string, width int) string {
+	const gap = 2
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	total := func() int {
+		sum := gap * (len(widths) - 1)
+		for _, w := range widths {
+			sum += w
+		}
+		return sum
+	}
+	for total() > width {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= tableMinColumn {
+			break
+		}
+		widths[widest]--
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		var line strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString(strings.Repeat(" ", gap))
+			}
+			cell = truncateCell(cell, widths[i])
+			line.WriteString(cell)
+			line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+
+	writeRow(rows[0])
+	b.WriteString(strings.Repeat("─", min(total(), width)))
+	b.WriteByte('\n')
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// truncateCell shortens s to width characters, ending with "…" if cut.
+func truncateCell(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
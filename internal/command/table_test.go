@@ -0,0 +1,68 @@
+package command
+
+import (
+	"testing"
+)
+
+func TestFormatTable(t *testing.T) {
+	dockerPS := "CONTAINER ID   IMAGE          STATUS         NAMES\n" +
+		"4f1c2a9b8e7d   nginx:1.27     Up 2 hours     web\n" +
+		"9a8b7c6d5e4f   postgres:16    Up 3 days      db\n"
+
+	tests := []struct {
+		name    string
+		output  string
+		columns []string
+		width   int
+		want    string
+	}{
+		{
+			name:   "tsv",
+			output: "name\tstate\nweb\tup\ndb\tdown\n",
+			want:   "name  state\n───────────\nweb   up\ndb    down\n",
+		},
+		{
+			name:   "csv with quotes",
+			output: "id,note\n1,\"a, b\"\n",
+			want:   "id  note\n────────\n1   a, b\n",
+		},
+		{
+			name:   "psql",
+			output: " id | name\n----+------\n  1 | web\n(1 row)\n",
+			want:   "id  name\n────────\n1   web\n",
+		},
+		{
+			name:   "mysql",
+			output: "+----+------+\n| id | name |\n+----+------+\n|  1 | web  |\n+----+------+\n",
+			want:   "id  name\n────────\n1   web\n",
+		},
+		{
+			name:    "aligned with columns",
+			output:  dockerPS,
+			columns: []string{"names", "Status"},
+			want:    "NAMES  STATUS\n─────────────────\nweb    Up 2 hours\ndb     Up 3 days\n",
+		},
+		{
+			name:   "aligned truncated",
+			output: dockerPS,
+			width:  30,
+			want: "CONTA…  IMAGE   STATUS   NAMES\n" +
+				"──────────────────────────────\n" +
+				"4f1c2…  nginx…  Up 2 h…  web\n" +
+				"9a8b7…  postg…  Up 3 d…  db\n",
+		},
+		{
+			name:   "plain text",
+			output: "all good\n",
+			want:   "all good\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatTable([]byte(tt.output), tt.columns, tt.width)
+			if string(got) != tt.want {
+				t.Errorf("formatTable() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
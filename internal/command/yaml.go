@@ -1,17 +1,30 @@
 package command
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/rashpile/pako-telegram/internal/auth"
 	"github.com/rashpile/pako-telegram/internal/config"
+	"github.com/rashpile/pako-telegram/internal/ratelimit"
+	"github.com/rashpile/pako-telegram/internal/redact"
 	pkgcmd "github.com/rashpile/pako-telegram/pkg/command"
 )
 
@@ -24,31 +37,88 @@ type ArgumentDef struct {
 	Choices     []string `yaml:"choices"`
 	Default     string   `yaml:"default"`
 	Sensitive   bool     `yaml:"sensitive"`
+	// ChoicesCommand produces the choice list at prompt time, one choice per output line.
+	ChoicesCommand string `yaml:"choices_command"`
+	// Min and Max bound numeric values (nil = unbounded).
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+	// MinLength and MaxLength bound string length in characters (0 = unbounded).
+	MinLength int `yaml:"min_length"`
+	MaxLength int `yaml:"max_length"`
+	// When is a template evaluated against earlier answers; the argument is
+	// only prompted if it renders to a truthy value (e.g. `{{eq .target "custom"}}`).
+	When string `yaml:"when"`
 }
 
 // YAMLCommandDef represents a shell command definition from YAML.
 type YAMLCommandDef struct {
-	Name            string        `yaml:"name"`
-	Description     string        `yaml:"description"`
-	Command         string        `yaml:"command"`
-	Workdir         string        `yaml:"workdir"`
-	Timeout         time.Duration `yaml:"timeout"`
-	MaxOutput       int           `yaml:"max_output"`
-	Confirm         bool          `yaml:"confirm"`
-	Category        string        `yaml:"category"`
-	Icon            string        `yaml:"icon"`
-	Arguments       []ArgumentDef `yaml:"arguments"`
-	ArgumentTimeout time.Duration `yaml:"argument_timeout"`
-	Schedule        []string      `yaml:"schedule"`       // List of "HH:MM" times for scheduled execution
-	Interval        time.Duration `yaml:"interval"`       // Interval for periodic execution (e.g., "5m")
-	InitialPaused   bool          `yaml:"initial_paused"` // Start with schedule paused
-	Quiet           bool          `yaml:"quiet"`          // Suppress "Running..." messages and file-only output
-}
-
-// YAMLCommand is a Command implementation backed by a shell command.
+	Name            string         `yaml:"name"`
+	Description     string         `yaml:"description"`
+	Command         string         `yaml:"command"`
+	Workdir         string         `yaml:"workdir"`
+	Timeout         time.Duration  `yaml:"timeout"`
+	MaxOutput       int            `yaml:"max_output"`
+	Truncate        string         `yaml:"truncate"` // Part of long output kept: head (default), tail or head_tail
+	PTY             bool           `yaml:"pty"`      // Run under a pseudo-terminal, for tools that only show progress or color on a TTY
+	PTYSize         string         `yaml:"pty_size"` // Terminal size as COLSxROWS (default: 80x24)
+	Confirm         bool           `yaml:"confirm"`
+	ConfirmRendered bool           `yaml:"confirm_rendered"` // Preview the rendered command before execution
+	ConfirmPhrase   string         `yaml:"confirm_phrase"`   // Confirm by typing the command name (true) or a random word (random)
+	ConfirmTTL      time.Duration  `yaml:"confirm_ttl"`      // How long the confirmation stays valid (default: defaults.confirm_ttl)
+	ConfirmMessage  string         `yaml:"confirm_message"`  // Go template for the confirmation dialog (default: defaults.confirm_message)
+	Danger          string         `yaml:"danger"`           // Warning shown in the confirmation dialog
+	DryRunCommand   string         `yaml:"dry_run_command"`  // Safe preview (e.g. terraform plan) offered as a Dry run button when confirming
+	Redact          []string       `yaml:"redact"`           // Regular expressions masked in output and audit log arguments, besides defaults.redact
+	Category        string         `yaml:"category"`
+	Icon            string         `yaml:"icon"`
+	Arguments       []ArgumentDef  `yaml:"arguments"`
+	ArgumentTimeout time.Duration  `yaml:"argument_timeout"`
+	Schedule        []string       `yaml:"schedule"`         // List of "HH:MM" times for scheduled execution
+	Interval        time.Duration  `yaml:"interval"`         // Interval for periodic execution (e.g., "5m")
+	InitialPaused   bool           `yaml:"initial_paused"`   // Start with schedule paused
+	SchedulePaused  bool           `yaml:"schedule_paused"`  // Alias for initial_paused
+	Quiet           bool           `yaml:"quiet"`            // Suppress "Running..." messages and file-only output
+	NotifyOnChange  bool           `yaml:"notify_on_change"` // Scheduled runs only report output that changed, as a diff
+	Hidden          bool           `yaml:"hidden"`           // Exclude from /help and menus (still runnable)
+	Disabled        bool           `yaml:"disabled"`         // Reject execution with a message
+	AllowedChatIDs  []int64        `yaml:"allowed_chat_ids"` // Restrict to these chats (empty = any allowlisted chat)
+	AllowedUsers    []string       `yaml:"allowed_users"`    // Restrict to these usernames or user IDs (empty = anyone)
+	RequiredRole    string         `yaml:"required_role"`    // Minimum role to run (admin, operator, viewer)
+	Approvals       int            `yaml:"approvals"`        // Distinct admin approvals needed before running
+	NoSelfApproval  bool           `yaml:"no_self_approval"` // The requester can't be one of the approvers
+	RequireOTP      bool           `yaml:"require_otp"`      // Require a TOTP code before running
+	Elevated        bool           `yaml:"elevated"`         // Require an active /sudo session
+	RateLimit       ratelimit.Rule `yaml:"rate_limit"`       // Per-user limit for this command
+	Cooldown        time.Duration  `yaml:"cooldown"`         // Minimum time between runs by anyone
+	ReadOnly        bool           `yaml:"read_only"`        // Changes nothing, so still runs in maintenance mode
+	AllowedHours    string         `yaml:"allowed_hours"`    // Time-of-day window "HH:MM-HH:MM" (may wrap midnight)
+	Input           string         `yaml:"input"`            // Accept an uploaded file ("document" or "photo"); its path is the argument
+	// Env sets extra environment variables; values support ${VAR} and
+	// secret references such as ${file:/run/secrets/db_password}.
+	Env map[string]string `yaml:"env"`
+	// GRPC calls a gRPC method instead of running Command.
+	GRPC *GRPCDef `yaml:"grpc"`
+	// Output formats the command's output, e.g. pretty-printing JSON.
+	Output OutputDef `yaml:"output"`
+	// Generate declares files the command writes to $OUTPUT_DIR for the
+	// bot to send.
+	Generate *GenerateDef `yaml:"generate"`
+	// Subcommands turns the command into a namespace, e.g. /docker ps.
+	Subcommands []YAMLCommandDef `yaml:"subcommands"`
+}
+
+// MinInterval is the shortest allowed interval for periodic execution.
+const MinInterval = 10 * time.Second
+
+// YAMLCommand is a Command implementation backed by a shell command or a
+// gRPC method.
 type YAMLCommand struct {
 	def      YAMLCommandDef
-	executor Executor
+	env      []string // Resolved Env as KEY=value pairs
+	redact   redact.Rules
+	tty      *TerminalSize // Set for pty commands
+	executor Executor      // Shell executor, also used for choices_command
+	grpc     *grpcExecutor
 }
 
 // ExecuteConfig holds parameters for command execution.
@@ -57,6 +127,24 @@ type ExecuteConfig struct {
 	Args    []string
 	Output  io.Writer
 	Workdir string
+	Env     []string      // Extra KEY=value pairs added to the process environment
+	Stderr  io.Writer     // Where stderr goes; nil sends it to Output
+	TTY     *TerminalSize // Run under a pseudo-terminal of this size; nil for pipes
+}
+
+// TerminalSize is the size of a pseudo-terminal in characters.
+type TerminalSize struct {
+	Cols uint16
+	Rows uint16
+}
+
+// DefaultTerminalSize is the pseudo-terminal size without pty_size.
+var DefaultTerminalSize = TerminalSize{Cols: 80, Rows: 24}
+
+// StderrSplitter is implemented by outputs that show a command's stderr
+// apart from its stdout.
+type StderrSplitter interface {
+	Stderr() io.Writer
 }
 
 // Executor runs shell commands. Injected to allow testing.
@@ -64,6 +152,19 @@ type Executor interface {
 	Execute(ctx context.Context, cfg ExecuteConfig) error
 }
 
+// ExitCode derives a process exit code from an execution error.
+// Returns 0 on success and -1 for failures without a process exit status.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
 // Name returns the command name.
 func (y *YAMLCommand) Name() string {
 	return y.def.Name
@@ -74,14 +175,81 @@ func (y *YAMLCommand) Description() string {
 	return y.def.Description
 }
 
+// runner returns the executor that runs the command itself.
+func (y *YAMLCommand) runner() Executor {
+	if y.grpc != nil {
+		return y.grpc
+	}
+	return y.executor
+}
+
 // Execute runs the shell command with arguments.
 func (y *YAMLCommand) Execute(ctx context.Context, args []string, output io.Writer) error {
-	return y.executor.Execute(ctx, ExecuteConfig{
+	return y.run(ctx, ExecuteConfig{
 		Command: y.def.Command,
 		Args:    args,
 		Output:  output,
-		Workdir: y.def.Workdir,
-	})
+	}, nil)
+}
+
+// run executes cfg in the command's workdir and environment. With an output
+// format or template, the output is collected and formatted once the command
+// finishes. A failed command's output is not formatted, but is still
+// rendered through the template; collected are the arguments it sees.
+func (y *YAMLCommand) run(ctx context.Context, cfg ExecuteConfig, collected map[string]string) error {
+	cfg.Workdir = y.def.Workdir
+	cfg.TTY = y.tty
+	if splitter, ok := cfg.Output.(StderrSplitter); ok {
+		cfg.Stderr = splitter.Stderr()
+	}
+	cfg.Env = y.env
+	if dir := outputDir(ctx); dir != "" {
+		cfg.Env = append(slices.Clip(y.env), OutputDirEnv+"="+dir)
+	}
+	if y.def.Output.Format == "" && y.def.Output.Template == "" {
+		return y.runner().Execute(ctx, cfg)
+	}
+
+	output := cfg.Output
+	var buf bytes.Buffer
+	cfg.Output = &buf
+	start := time.Now()
+	execErr := y.runner().Execute(ctx, cfg)
+	elapsed := time.Since(start)
+
+	result := buf.Bytes()
+	if execErr == nil {
+		formatted, err := y.def.Output.Apply(result)
+		if err != nil {
+			output.Write(result)
+			return err
+		}
+		result = formatted
+	}
+
+	if y.def.Output.Template != "" {
+		data := OutputData{
+			Output:   string(result),
+			ExitCode: ExitCode(execErr),
+			Duration: elapsed,
+			Args:     collected,
+			Argv:     cfg.Args,
+		}
+		if execErr != nil {
+			data.Error = execErr.Error()
+		}
+		rendered, err := y.def.Output.Render(data)
+		if err != nil {
+			output.Write(result)
+			return errors.Join(execErr, err)
+		}
+		result = rendered
+	}
+
+	if _, err := output.Write(result); err != nil && execErr == nil {
+		return err
+	}
+	return execErr
 }
 
 // Metadata returns command configuration.
@@ -90,9 +258,23 @@ func (y *YAMLCommand) Metadata() pkgcmd.Metadata {
 		Timeout:        y.def.Timeout,
 		MaxOutput:      y.def.MaxOutput,
 		RequireConfirm: y.def.Confirm,
+		Hidden:         y.def.Hidden,
+		Disabled:       y.def.Disabled,
+		RequiredRole:   y.def.RequiredRole,
+		Approvals:      y.def.Approvals,
+		NoSelfApproval: y.def.NoSelfApproval,
+		RequireOTP:     y.def.RequireOTP,
+		Elevated:       y.def.Elevated,
+		Truncate:       y.def.Truncate,
 	}
 }
 
+// ConfirmRendered returns true if the rendered command should be previewed
+// and confirmed after argument collection.
+func (y *YAMLCommand) ConfirmRendered() bool {
+	return y.def.ConfirmRendered
+}
+
 // Category returns the command's category for menu grouping.
 func (y *YAMLCommand) Category() pkgcmd.CategoryInfo {
 	return pkgcmd.CategoryInfo{
@@ -121,13 +303,55 @@ func (y *YAMLCommand) CommandTemplate() string {
 	return y.def.Command
 }
 
-// ExecuteRendered runs a pre-rendered command string.
-func (y *YAMLCommand) ExecuteRendered(ctx context.Context, rendered string, output io.Writer) error {
-	return y.executor.Execute(ctx, ExecuteConfig{
+// CommandLine returns what Execute runs for args: the shell command line, or
+// the JSON request of a gRPC command.
+func (y *YAMLCommand) CommandLine(args []string) string {
+	if len(args) == 0 {
+		return y.def.Command
+	}
+	return y.def.Command + " " + strings.Join(args, " ")
+}
+
+// Target describes where a gRPC command is sent, as "method at address".
+// It is empty for shell commands, which run on the bot's host.
+func (y *YAMLCommand) Target() string {
+	if y.grpc == nil {
+		return ""
+	}
+	return y.grpc.def.Method + " at " + y.grpc.def.Address
+}
+
+// ExecuteRendered runs a pre-rendered command string (for gRPC commands, the
+// rendered request). Collected are the argument values it was rendered with.
+func (y *YAMLCommand) ExecuteRendered(ctx context.Context, rendered string, collected map[string]string, output io.Writer) error {
+	return y.run(ctx, ExecuteConfig{
 		Command: rendered,
 		Output:  output,
+	}, collected)
+}
+
+// RunChoicesCommand executes a choices_command and returns one choice per
+// non-empty output line.
+func (y *YAMLCommand) RunChoicesCommand(ctx context.Context, choicesCmd string) ([]string, error) {
+	var buf bytes.Buffer
+	err := y.executor.Execute(ctx, ExecuteConfig{
+		Command: choicesCmd,
+		Output:  &buf,
 		Workdir: y.def.Workdir,
+		Env:     y.env,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	var choices []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !slices.Contains(choices, line) {
+			choices = append(choices, line)
+		}
+	}
+	return choices, nil
 }
 
 // Workdir returns the command's working directory.
@@ -141,6 +365,12 @@ func (y *YAMLCommand) FileResponse() *pkgcmd.FileResponse {
 	return nil
 }
 
+// Generate returns the files the command generates, or nil if it isn't a
+// generator.
+func (y *YAMLCommand) Generate() *GenerateDef {
+	return y.def.Generate
+}
+
 // Schedule returns the list of scheduled times for this command.
 func (y *YAMLCommand) Schedule() []string {
 	return y.def.Schedule
@@ -153,7 +383,28 @@ func (y *YAMLCommand) Interval() time.Duration {
 
 // InitialPaused returns true if the command should start with schedule paused.
 func (y *YAMLCommand) InitialPaused() bool {
-	return y.def.InitialPaused
+	return y.def.InitialPaused || y.def.SchedulePaused
+}
+
+// RateLimit returns the per-user rate limit for this command.
+func (y *YAMLCommand) RateLimit() ratelimit.Rule {
+	return y.def.RateLimit
+}
+
+// Cooldown returns the minimum time between user-started runs, or 0.
+func (y *YAMLCommand) Cooldown() time.Duration {
+	return y.def.Cooldown
+}
+
+// ReadOnly returns true if the command changes nothing and may run in
+// maintenance mode.
+func (y *YAMLCommand) ReadOnly() bool {
+	return y.def.ReadOnly
+}
+
+// Input returns the kind of uploaded file the command accepts, or "".
+func (y *YAMLCommand) Input() string {
+	return y.def.Input
 }
 
 // Quiet returns true if the command should suppress "Running..." messages
@@ -162,115 +413,721 @@ func (y *YAMLCommand) Quiet() bool {
 	return y.def.Quiet
 }
 
-// Loader loads YAML command definitions from a directory.
+// Confirmation phrase modes of confirm_phrase.
+const (
+	ConfirmPhraseName   = "name"   // Type the command name
+	ConfirmPhraseRandom = "random" // Type a random word shown in the prompt
+)
+
+// ConfirmPhrase returns how the command is confirmed by typing: "" (a
+// button), ConfirmPhraseName or ConfirmPhraseRandom.
+func (y *YAMLCommand) ConfirmPhrase() string {
+	return y.def.ConfirmPhrase
+}
+
+// ConfirmTTL returns how long the confirmation stays valid; zero uses the
+// configured default.
+func (y *YAMLCommand) ConfirmTTL() time.Duration {
+	return y.def.ConfirmTTL
+}
+
+// ConfirmMessage returns the template of the confirmation dialog; empty uses
+// the configured default.
+func (y *YAMLCommand) ConfirmMessage() string {
+	return y.def.ConfirmMessage
+}
+
+// Danger returns the warning shown when confirming the command.
+func (y *YAMLCommand) Danger() string {
+	return y.def.Danger
+}
+
+// PTY returns true if the command runs under a pseudo-terminal, so its
+// output may hold terminal escape sequences and carriage returns.
+func (y *YAMLCommand) PTY() bool {
+	return y.tty != nil
+}
+
+// Redactions returns the rules masking secrets in the command's output and
+// audit log arguments: its own redact patterns and defaults.redact.
+func (y *YAMLCommand) Redactions() redact.Rules {
+	return y.redact
+}
+
+// DryRunCommand returns the template of the safe preview offered when
+// confirming the command, or "" without one.
+func (y *YAMLCommand) DryRunCommand() string {
+	return y.def.DryRunCommand
+}
+
+// ExecuteDryRun runs the dry-run command line with args in the shell, in the
+// command's workdir and environment. Output formatting is not applied.
+func (y *YAMLCommand) ExecuteDryRun(ctx context.Context, commandLine string, args []string, output io.Writer) error {
+	cfg := ExecuteConfig{
+		Command: commandLine,
+		Args:    args,
+		Output:  output,
+		Workdir: y.def.Workdir,
+		Env:     y.env,
+		TTY:     y.tty,
+	}
+	if splitter, ok := output.(StderrSplitter); ok {
+		cfg.Stderr = splitter.Stderr()
+	}
+	return y.executor.Execute(ctx, cfg)
+}
+
+// NotifyOnChange returns true if scheduled runs should only report output
+// that differs from the previous run.
+func (y *YAMLCommand) NotifyOnChange() bool {
+	return y.def.NotifyOnChange
+}
+
+// Permits returns true if the command may be run from the given chat by the given user.
+// Users match by numeric ID or username (with or without a leading @).
+func (y *YAMLCommand) Permits(chatID, userID int64, username string) bool {
+	return permits(y.def, chatID, userID, username)
+}
+
+// permits checks def's allowed_chat_ids and allowed_users.
+func permits(def YAMLCommandDef, chatID, userID int64, username string) bool {
+	if len(def.AllowedChatIDs) > 0 && !slices.Contains(def.AllowedChatIDs, chatID) {
+		return false
+	}
+
+	if len(def.AllowedUsers) == 0 {
+		return true
+	}
+
+	id := strconv.FormatInt(userID, 10)
+	for _, u := range def.AllowedUsers {
+		u = strings.TrimPrefix(u, "@")
+		if u == id || (username != "" && strings.EqualFold(u, username)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedHours returns the configured time-of-day window, or "" if unrestricted.
+func (y *YAMLCommand) AllowedHours() string {
+	return y.def.AllowedHours
+}
+
+// AllowedAt returns true if t (local time) falls within allowed_hours.
+// Windows may wrap midnight, e.g. "22:00-06:00".
+func (y *YAMLCommand) AllowedAt(t time.Time) bool {
+	start, end, ok := parseTimeWindow(y.def.AllowedHours)
+	if !ok {
+		return true
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// parseTimeWindow parses "HH:MM-HH:MM" into minutes since midnight.
+func parseTimeWindow(s string) (start, end int, ok bool) {
+	from, to, found := strings.Cut(s, "-")
+	if !found || validateTimeFormat(from) != nil || validateTimeFormat(to) != nil {
+		return 0, 0, false
+	}
+	minutes := func(hm string) int {
+		h, _ := strconv.Atoi(hm[:2])
+		m, _ := strconv.Atoi(hm[3:])
+		return h*60 + m
+	}
+	return minutes(from), minutes(to), true
+}
+
+// Loader loads YAML command definitions from one or more directories, and
+// Go and external plugins from the plugins directory.
 type Loader struct {
-	dir      string
-	defaults config.DefaultsConfig
-	executor Executor
+	mu         sync.RWMutex
+	dirs       []string
+	pluginsDir string
+	defaults   config.DefaultsConfig
+	categories map[string]config.CategoryConfig
+	executor   Executor
+
+	externalMu sync.Mutex
+	external   map[string]*ExternalPlugin // Running external plugins by path
 }
 
-// NewLoader creates a YAML command loader.
-func NewLoader(dir string, defaults config.DefaultsConfig, executor Executor) *Loader {
+// NewLoader creates a YAML command loader. Commands from all directories are
+// merged; a command name defined twice is an error.
+func NewLoader(dirs []string, defaults config.DefaultsConfig, executor Executor) *Loader {
 	return &Loader{
-		dir:      dir,
+		dirs:     dirs,
 		defaults: defaults,
 		executor: executor,
 	}
 }
 
-// Load reads all .yaml files from the configured directory and subdirectories.
+// SetDefaults replaces the defaults applied to subsequently loaded commands.
+func (l *Loader) SetDefaults(defaults config.DefaultsConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.defaults = defaults
+}
+
+// SetCategories sets per-category defaults applied to subsequently loaded commands.
+func (l *Loader) SetCategories(categories map[string]config.CategoryConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.categories = categories
+}
+
+// SetPluginsDir sets the directory Go plugins (.so files) are loaded from.
+// Empty disables plugins.
+func (l *Loader) SetPluginsDir(dir string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pluginsDir = dir
+}
+
+// Load reads all .yaml files from the configured directories and subdirectories,
+// then the commands of every plugin in the plugins directory. Warnings are
+// logged; the commands they concern still load.
 func (l *Loader) Load() ([]pkgcmd.Command, error) {
-	if _, err := os.Stat(l.dir); os.IsNotExist(err) {
-		return nil, nil // No commands directory is OK
+	commands, problems := l.Validate()
+	for _, p := range problems {
+		if !p.Warning {
+			return nil, fmt.Errorf("load %w", p)
+		}
+	}
+	for _, p := range problems {
+		slog.Warn("command file problem", "problem", p)
 	}
+	return commands, nil
+}
 
-	var commands []pkgcmd.Command
-	err := filepath.WalkDir(l.dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
+// ValidationError describes an invalid command file.
+type ValidationError struct {
+	Path    string
+	Line    int // 0 if unknown
+	Err     error
+	Warning bool // The command still loads, with the problem corrected or ignored
+}
+
+func (e *ValidationError) Error() string {
+	msg := e.Err.Error()
+	if e.Warning {
+		msg = "warning: " + msg
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, msg)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validate loads every command file and plugin and reports all problems
+// instead of stopping at the first one. Command names defined in more than
+// one file are reported as conflicts. Problems the loader works around, such
+// as a too short interval, are reported as warnings.
+func (l *Loader) Validate() ([]pkgcmd.Command, []*ValidationError) {
+	var (
+		commands []pkgcmd.Command
+		problems []*ValidationError
+		seen     = make(map[string]string)
+	)
+	for _, dir := range l.dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue // Missing commands directory is OK
 		}
 
-		ext := filepath.Ext(d.Name())
-		if ext != ".yaml" && ext != ".yml" {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !isYAMLFile(d.Name()) {
+				return nil
+			}
+
+			cmd, warnings, err := l.loadFile(path)
+			if err != nil {
+				problems = append(problems, newValidationError(path, err))
+				return nil
+			}
+			for _, w := range warnings {
+				ve := newValidationError(path, w)
+				ve.Warning = true
+				problems = append(problems, ve)
+			}
+
+			if other, ok := seen[cmd.Name()]; ok {
+				problems = append(problems, &ValidationError{
+					Path: path,
+					Err:  fmt.Errorf("duplicate command name %q (also defined in %s)", cmd.Name(), other),
+				})
+				return nil
+			}
+			seen[cmd.Name()] = path
+
+			commands = append(commands, cmd)
 			return nil
+		})
+		if err != nil {
+			problems = append(problems, &ValidationError{Path: dir, Err: fmt.Errorf("walk commands directory: %w", err)})
 		}
+	}
+
+	l.mu.RLock()
+	pluginsDir := l.pluginsDir
+	l.mu.RUnlock()
+	if pluginsDir == "" {
+		return commands, problems
+	}
 
-		cmd, err := l.loadFile(path)
+	goPlugins, external, err := pluginFiles(pluginsDir)
+	if err != nil {
+		problems = append(problems, &ValidationError{Path: pluginsDir, Err: err})
+	}
+	l.stopExternal(external)
+	for _, path := range slices.Concat(goPlugins, external) {
+		load := loadPlugin
+		if !strings.HasSuffix(path, ".so") {
+			load = l.externalCommands
+		}
+		cmds, err := load(path)
 		if err != nil {
-			return fmt.Errorf("load %s: %w", path, err)
+			problems = append(problems, &ValidationError{Path: path, Err: fmt.Errorf("load plugin: %w", err)})
+			continue
+		}
+		for _, cmd := range cmds {
+			if other, ok := seen[cmd.Name()]; ok {
+				problems = append(problems, &ValidationError{
+					Path: path,
+					Err:  fmt.Errorf("duplicate command name %q (also defined in %s)", cmd.Name(), other),
+				})
+				continue
+			}
+			seen[cmd.Name()] = path
+			commands = append(commands, cmd)
 		}
+	}
 
-		commands = append(commands, cmd)
+	return commands, problems
+}
+
+// isYAMLFile reports whether name has a YAML extension.
+func isYAMLFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// lineError attaches a source line to a command definition error.
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string {
+	return e.err.Error()
+}
+
+func (e *lineError) Unwrap() error {
+	return e.err
+}
+
+// newValidationError wraps a loadFile error with the file path and line.
+func newValidationError(path string, err error) *ValidationError {
+	ve := &ValidationError{Path: path, Err: err}
+	var le *lineError
+	if errors.As(err, &le) {
+		ve.Line = le.line
+		ve.Err = le.err
+	}
+	return ve
+}
+
+// defNode locates fields of a parsed command file for error reporting.
+type defNode struct {
+	root     *yaml.Node
+	warnings *[]error // Collects warnf's warnings; nil discards them
+}
+
+// errorf returns an error positioned at the given top-level key.
+func (n defNode) errorf(key, format string, args ...any) error {
+	return &lineError{line: n.keyLine(n.mapping(), key), err: fmt.Errorf(format, args...)}
+}
+
+// warnf records a warning positioned at the given top-level key, for a
+// problem the loader corrects or ignores.
+func (n defNode) warnf(key, format string, args ...any) {
+	if n.warnings != nil {
+		*n.warnings = append(*n.warnings, n.errorf(key, format, args...))
+	}
+}
+
+// argErrorf returns an error positioned at a key of the i-th argument.
+func (n defNode) argErrorf(i int, key, format string, args ...any) error {
+	line := n.keyLine(n.mapping(), "arguments")
+	if seq := n.value(n.mapping(), "arguments"); seq != nil && seq.Kind == yaml.SequenceNode && i < len(seq.Content) {
+		line = seq.Content[i].Line
+		if l := n.keyLine(seq.Content[i], key); l > 0 {
+			line = l
+		}
+	}
+	return &lineError{line: line, err: fmt.Errorf(format, args...)}
+}
+
+func (n defNode) mapping() *yaml.Node {
+	if n.root == nil || len(n.root.Content) == 0 {
 		return nil
-	})
-	if err != nil {
-		return nil, fmt.Errorf("walk commands directory: %w", err)
 	}
+	return n.root.Content[0]
+}
 
-	return commands, nil
+func (n defNode) value(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func (n defNode) keyLine(m *yaml.Node, key string) int {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return 0
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i].Line
+		}
+	}
+	return 0
 }
 
-// loadFile parses a single YAML command file.
-func (l *Loader) loadFile(path string) (*YAMLCommand, error) {
+// loadFile parses a single YAML command file. It also returns warnings
+// about problems it corrected or ignored.
+func (l *Loader) loadFile(path string) (pkgcmd.Command, []error, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, fmt.Errorf("parse yaml: %w", err)
+	}
 	var def YAMLCommandDef
-	if err := yaml.Unmarshal(data, &def); err != nil {
-		return nil, fmt.Errorf("parse yaml: %w", err)
+	if err := root.Decode(&def); err != nil {
+		return nil, nil, fmt.Errorf("parse yaml: %w", err)
 	}
+	var warnings []error
+	n := defNode{root: &root, warnings: &warnings}
 
+	if len(def.Subcommands) > 0 {
+		group, err := l.loadGroup(path, def, n)
+		if err != nil {
+			return nil, nil, err
+		}
+		return group, warnings, nil
+	}
+	cmd, err := l.build(path, def, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cmd, warnings, nil
+}
+
+// build validates a command definition, applies defaults and creates the
+// command. n locates the definition's fields for error reporting.
+func (l *Loader) build(path string, def YAMLCommandDef, n defNode) (*YAMLCommand, error) {
 	if def.Name == "" {
 		return nil, fmt.Errorf("name is required")
 	}
+	var grpcExec *grpcExecutor
+	var err error
+	if def.GRPC != nil {
+		if def.Command != "" {
+			return nil, n.errorf("grpc", "use either command or grpc, not both")
+		}
+		grpcExec, err = newGRPCExecutor(*def.GRPC, filepath.Dir(path))
+		if err != nil {
+			return nil, n.errorf("grpc", "%w", err)
+		}
+		// The request is the command template
+		def.Command = grpcExec.def.Request
+		if def.Description == "" {
+			def.Description = "gRPC " + grpcExec.def.Method
+		}
+	}
 	if def.Command == "" {
 		return nil, fmt.Errorf("command is required")
 	}
 
+	if def.RequiredRole != "" {
+		if _, err := auth.ParseRole(def.RequiredRole); err != nil {
+			return nil, n.errorf("required_role", "required_role: %w", err)
+		}
+	}
+
+	if def.Approvals < 0 {
+		return nil, n.errorf("approvals", "approvals must not be negative")
+	}
+	if def.Approvals > 1 && (len(def.Schedule) > 0 || def.Interval > 0) {
+		return nil, n.errorf("approvals", "scheduled commands cannot require approvals")
+	}
+	if def.NoSelfApproval && def.Approvals < 2 {
+		return nil, n.errorf("no_self_approval", "no_self_approval requires approvals of 2 or more")
+	}
+
+	if def.RateLimit.Requests < 0 || def.RateLimit.Per < 0 || (def.RateLimit.Requests > 0) != (def.RateLimit.Per > 0) {
+		return nil, n.errorf("rate_limit", "rate_limit needs positive requests and per")
+	}
+
+	if def.Cooldown < 0 {
+		return nil, n.errorf("cooldown", "cooldown must be positive")
+	}
+
+	if def.Elevated && (len(def.Schedule) > 0 || def.Interval > 0) {
+		return nil, n.errorf("elevated", "scheduled commands cannot be elevated")
+	}
+
+	if def.RequireOTP && (len(def.Schedule) > 0 || def.Interval > 0) {
+		return nil, n.errorf("require_otp", "scheduled commands cannot require an OTP code")
+	}
+
+	if def.AllowedHours != "" {
+		if _, _, ok := parseTimeWindow(def.AllowedHours); !ok {
+			return nil, n.errorf("allowed_hours", "allowed_hours must be HH:MM-HH:MM")
+		}
+	}
+
+	var tty *TerminalSize
+	if def.PTY {
+		if grpcExec != nil {
+			return nil, n.errorf("pty", "gRPC commands cannot use pty")
+		}
+		size := DefaultTerminalSize
+		if def.PTYSize != "" {
+			if size, err = parseTerminalSize(def.PTYSize); err != nil {
+				return nil, n.errorf("pty_size", "%w", err)
+			}
+		}
+		tty = &size
+	} else if def.PTYSize != "" {
+		return nil, n.errorf("pty_size", "pty_size requires pty: true")
+	}
+
+	switch def.Truncate {
+	case "", pkgcmd.TruncateHead, pkgcmd.TruncateTail, pkgcmd.TruncateHeadTail:
+	default:
+		return nil, n.errorf("truncate", "truncate must be %q, %q or %q", pkgcmd.TruncateHead, pkgcmd.TruncateTail, pkgcmd.TruncateHeadTail)
+	}
+
+	switch def.Input {
+	case "", pkgcmd.InputDocument, pkgcmd.InputPhoto:
+	default:
+		return nil, n.errorf("input", "input must be %q or %q", pkgcmd.InputDocument, pkgcmd.InputPhoto)
+	}
+	if def.Input != "" && len(def.Arguments) > 0 {
+		return nil, n.errorf("input", "commands with arguments cannot take an input file")
+	}
+	if def.Input != "" && (len(def.Schedule) > 0 || def.Interval > 0) {
+		return nil, n.errorf("input", "scheduled commands cannot take an input file")
+	}
+	if def.ConfirmTTL < 0 {
+		return nil, n.errorf("confirm_ttl", "confirm_ttl must be positive")
+	}
+	if _, err := template.New("confirm_message").Parse(def.ConfirmMessage); err != nil {
+		return nil, n.errorf("confirm_message", "%w", err)
+	}
+	if _, err := redact.Compile(def.Redact); err != nil {
+		return nil, n.errorf("redact", "%w", err)
+	}
+	if _, err := template.New("dry_run_command").Parse(def.DryRunCommand); err != nil {
+		return nil, n.errorf("dry_run_command", "%w", err)
+	}
+	if err := def.Output.Validate(); err != nil {
+		return nil, n.errorf("output", "%w", err)
+	}
+	if def.Generate != nil {
+		if err := def.Generate.Validate(); err != nil {
+			return nil, n.errorf("generate", "%w", err)
+		}
+		if grpcExec != nil {
+			return nil, n.errorf("generate", "gRPC commands cannot generate files")
+		}
+		if def.NotifyOnChange {
+			return nil, n.errorf("generate", "generators cannot use notify_on_change")
+		}
+	}
+
+	// Validate arguments
+	for i, arg := range def.Arguments {
+		if arg.Name == "" {
+			return nil, n.argErrorf(i, "name", "argument %d: name is required", i+1)
+		}
+		if arg.ChoicesCommand != "" && arg.Type != "choice" {
+			return nil, n.argErrorf(i, "choices_command", "argument %q: choices_command requires type choice", arg.Name)
+		}
+		if arg.When != "" {
+			if _, err := template.New("when").Parse(arg.When); err != nil {
+				return nil, n.argErrorf(i, "when", "argument %q: invalid when: %w", arg.Name, err)
+			}
+		}
+		if arg.Min != nil && arg.Max != nil && *arg.Min > *arg.Max {
+			return nil, n.argErrorf(i, "min", "argument %q: min must not exceed max", arg.Name)
+		}
+		if arg.MaxLength > 0 && arg.MinLength > arg.MaxLength {
+			return nil, n.argErrorf(i, "min_length", "argument %q: min_length must not exceed max_length", arg.Name)
+		}
+	}
+	if len(def.Arguments) > 0 {
+		if _, err := template.New("cmd").Parse(def.Command); err != nil {
+			return nil, n.errorf("command", "invalid command template: %w", err)
+		}
+	}
+
 	// Validate schedule
 	if len(def.Schedule) > 0 {
 		if len(def.Arguments) > 0 {
-			return nil, fmt.Errorf("commands with arguments cannot be scheduled")
+			return nil, n.errorf("schedule", "commands with arguments cannot be scheduled")
 		}
 		for _, t := range def.Schedule {
 			if err := validateTimeFormat(t); err != nil {
-				return nil, fmt.Errorf("invalid schedule time %q: %w", t, err)
+				return nil, n.errorf("schedule", "invalid schedule time %q: %w", t, err)
 			}
 		}
 	}
 
 	// Validate interval
+	if def.Interval < 0 {
+		return nil, n.errorf("interval", "interval must be positive")
+	}
 	if def.Interval > 0 {
+		// Files with shorter intervals loaded before the minimum existed
+		if def.Interval < MinInterval {
+			n.warnf("interval", "interval %s is below the minimum of %s, using %s", def.Interval, MinInterval, MinInterval)
+			def.Interval = MinInterval
+		}
 		if len(def.Arguments) > 0 {
-			return nil, fmt.Errorf("commands with arguments cannot use interval scheduling")
+			return nil, n.errorf("interval", "commands with arguments cannot use interval scheduling")
 		}
 		if len(def.Schedule) > 0 {
-			return nil, fmt.Errorf("cannot use both schedule and interval on the same command")
+			return nil, n.errorf("interval", "cannot use both schedule and interval on the same command")
+		}
+	}
+
+	// Pausing nothing is harmless, and such files loaded before it was checked
+	if (def.InitialPaused || def.SchedulePaused) && len(def.Schedule) == 0 && def.Interval == 0 {
+		key := "schedule_paused"
+		if n.keyLine(n.mapping(), key) == 0 {
+			key = "initial_paused"
+		}
+		n.warnf(key, "%s without schedule or interval is ignored", key)
+		def.InitialPaused, def.SchedulePaused = false, false
+	}
+	if def.NotifyOnChange && len(def.Schedule) == 0 && def.Interval == 0 {
+		return nil, n.errorf("notify_on_change", "notify_on_change requires schedule or interval")
+	}
+
+	switch def.ConfirmPhrase {
+	case "", "false":
+		def.ConfirmPhrase = ""
+	case "true", ConfirmPhraseName:
+		def.ConfirmPhrase = ConfirmPhraseName
+	case ConfirmPhraseRandom:
+	default:
+		return nil, n.errorf("confirm_phrase", "confirm_phrase must be true, false or %q, got %q", ConfirmPhraseRandom, def.ConfirmPhrase)
+	}
+
+	env, err := resolveEnv(def.Env)
+	if err != nil {
+		return nil, n.errorf("env", "%w", err)
+	}
+
+	// Apply defaults: command settings, then category, then global
+	l.mu.RLock()
+	defaults := l.defaults
+	cat, hasCat := l.categories[def.Category]
+	l.mu.RUnlock()
+
+	if hasCat && def.Category != "" {
+		if def.Icon == "" {
+			def.Icon = cat.Icon
+		}
+		if cat.Confirm != nil && n.keyLine(n.mapping(), "confirm") == 0 {
+			def.Confirm = *cat.Confirm
+		}
+		if def.Timeout == 0 {
+			def.Timeout = cat.Timeout
+		}
+		if def.MaxOutput == 0 {
+			def.MaxOutput = cat.MaxOutput
 		}
 	}
 
-	// Apply defaults
 	if def.Timeout == 0 {
-		def.Timeout = l.defaults.Timeout
+		def.Timeout = defaults.Timeout
 	}
 	if def.MaxOutput == 0 {
-		def.MaxOutput = l.defaults.MaxOutput
+		def.MaxOutput = defaults.MaxOutput
 	}
 	if def.Description == "" {
 		def.Description = def.Command
 	}
+	if def.ConfirmPhrase != "" {
+		def.Confirm = true // A phrase is a stricter confirmation
+	}
+	rules, err := redact.Compile(append(slices.Clip(defaults.Redact), def.Redact...))
+	if err != nil {
+		return nil, n.errorf("redact", "%w", err)
+	}
 
 	return &YAMLCommand{
 		def:      def,
+		env:      env,
+		redact:   rules,
+		tty:      tty,
 		executor: l.executor,
+		grpc:     grpcExec,
 	}, nil
 }
 
+// resolveEnv expands variables and secret references in env values.
+func resolveEnv(vars map[string]string) ([]string, error) {
+	if len(vars) == 0 {
+		return nil, nil
+	}
+
+	keys := slices.Sorted(maps.Keys(vars))
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		val, err := config.Expand(vars[k])
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", k, err)
+		}
+		env = append(env, k+"="+val)
+	}
+	return env, nil
+}
+
+// parseTerminalSize parses a terminal size given as COLSxROWS, e.g. 120x40.
+func parseTerminalSize(s string) (TerminalSize, error) {
+	cols, rows, ok := strings.Cut(s, "x")
+	c, err1 := strconv.ParseUint(cols, 10, 16)
+	r, err2 := strconv.ParseUint(rows, 10, 16)
+	if !ok || err1 != nil || err2 != nil || c == 0 || r == 0 {
+		return TerminalSize{}, fmt.Errorf("pty_size must be COLSxROWS, e.g. 120x40, got %q", s)
+	}
+	return TerminalSize{Cols: uint16(c), Rows: uint16(r)}, nil
+}
+
 // validateTimeFormat validates a time string in "HH:MM" format.
 func validateTimeFormat(t string) error {
 	if len(t) != 5 || t[2] != ':' {
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/config"
+)
+
+func TestLoadClampsShortInterval(t *testing.T) {
+	cmds, err := loadTestCommands(t, "name: poll\ncommand: date\ninterval: 2s\n")
+	if err != nil {
+		t.Fatalf("Load() = %v, want the command loaded", err)
+	}
+	if got := cmds[0].(*YAMLCommand).Interval(); got != MinInterval {
+		t.Errorf("Interval() = %s, want %s", got, MinInterval)
+	}
+
+	cmds, err = loadTestCommands(t, "name: poll\ncommand: date\ninterval: 1m\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmds[0].(*YAMLCommand).Interval(); got != time.Minute {
+		t.Errorf("Interval() = %s, want 1m", got)
+	}
+}
+
+func TestLoadIgnoresPausedWithoutSchedule(t *testing.T) {
+	for _, key := range []string{"schedule_paused", "initial_paused"} {
+		cmds, err := loadTestCommands(t, "name: once\ncommand: date\n"+key+": true\n")
+		if err != nil {
+			t.Fatalf("%s: Load() = %v, want the command loaded", key, err)
+		}
+		if cmds[0].(*YAMLCommand).InitialPaused() {
+			t.Errorf("%s: InitialPaused() = true without a schedule", key)
+		}
+	}
+
+	cmds, err := loadTestCommands(t, "name: poll\ncommand: date\ninterval: 1m\nschedule_paused: true\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmds[0].(*YAMLCommand).InitialPaused() {
+		t.Error("InitialPaused() = false with an interval")
+	}
+}
+
+func TestValidateReportsWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		def  string
+		want string
+	}{
+		{"short interval", "name: poll\ncommand: date\ninterval: 2s\n", "cmd.yaml:3: warning: interval 2s is below the minimum"},
+		{"paused without schedule", "name: once\ncommand: date\nschedule_paused: true\n", "cmd.yaml:3: warning: schedule_paused without schedule"},
+		{"initial_paused without schedule", "name: once\ncommand: date\ninitial_paused: true\n", "cmd.yaml:3: warning: initial_paused without schedule"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "cmd.yaml"), []byte(tt.def), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cmds, problems := NewLoader([]string{dir}, config.DefaultsConfig{}, nil).Validate()
+			if len(cmds) != 1 {
+				t.Errorf("Validate() loaded %d commands, want 1", len(cmds))
+			}
+			if len(problems) != 1 || !problems[0].Warning || !strings.Contains(problems[0].Error(), tt.want) {
+				t.Fatalf("Validate() problems = %v, want a warning %q", problems, tt.want)
+			}
+		})
+	}
+}
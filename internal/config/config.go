@@ -4,29 +4,298 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
+	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/rashpile/pako-telegram/internal/i18n"
+	"github.com/rashpile/pako-telegram/internal/logbuf"
+	"github.com/rashpile/pako-telegram/internal/ratelimit"
+	"github.com/rashpile/pako-telegram/internal/redact"
+	"github.com/rashpile/pako-telegram/internal/status"
 )
 
 // Config holds all application configuration.
 type Config struct {
-	Telegram         TelegramConfig `yaml:"telegram"`
-	CommandsDir      string         `yaml:"commands_dir"`
-	PluginsDir       string         `yaml:"plugins_dir"`
-	Database         DatabaseConfig `yaml:"database"`
-	Defaults         DefaultsConfig `yaml:"defaults"`
-	Podcast          PodcastConfig  `yaml:"podcast"`
-	MessageStorePath string         `yaml:"message_store_path"` // Path to store sent message IDs for cleanup
+	Telegram          TelegramConfig            `yaml:"telegram"`
+	CommandsDir       StringList                `yaml:"commands_dir"` // One directory or a list; entries may be globs
+	PluginsDir        string                    `yaml:"plugins_dir"`  // Go plugins (.so) providing commands; disabled when empty
+	Database          DatabaseConfig            `yaml:"database"`
+	Defaults          DefaultsConfig            `yaml:"defaults"`
+	Podcast           PodcastConfig             `yaml:"podcast"`
+	MessageStorePath  string                    `yaml:"message_store_path"`  // Path to store sent message IDs for cleanup
+	MessageStoreTTL   time.Duration             `yaml:"message_store_ttl"`   // Forget tracked messages after this long (default: 60 days)
+	AutoCleanup       AutoCleanupConfig         `yaml:"auto_cleanup"`        // Scheduled deletion of old tracked messages
+	TrackUserCommands bool                      `yaml:"track_user_commands"` // Also track users' /command messages for cleanup
+	WatchCommands     bool                      `yaml:"watch_commands"`      // Reload commands automatically when files change
+	Categories        map[string]CategoryConfig `yaml:"categories"`          // Per-category menu metadata and command defaults
+	Roles             RolesConfig               `yaml:"roles"`               // Role assignments for required_role
+	OTP               OTPConfig                 `yaml:"otp"`                 // TOTP secrets for require_otp
+	RateLimit         RateLimitConfig           `yaml:"rate_limit"`          // Global rate limits
+	Sudo              SudoConfig                `yaml:"sudo"`                // PINs for elevated commands
+	URLFiles          URLFilesConfig            `yaml:"url_files"`           // Downloads for [url:...] references
+	Inbox             InboxConfig               `yaml:"inbox"`               // Storage for files sent to commands with input
+	Transcription     TranscriptionConfig       `yaml:"transcription"`       // Speech-to-text for voice messages
+	Menus             map[int64]MenuConfig      `yaml:"menus"`               // Per-chat menu layouts (chat ID -> layout)
+	Language          string                    `yaml:"language"`            // Bot message language (default: en)
+	ChatLanguages     map[int64]string          `yaml:"chat_languages"`      // Per-chat language (chat ID -> language); /settings overrides
+	Heartbeat         HeartbeatConfig           `yaml:"heartbeat"`           // Periodic liveness message
+	Status            StatusConfig              `yaml:"status"`              // What /status reports
+	Alerts            AlertsConfig              `yaml:"alerts"`              // Threshold alerts on /status metrics
+	Webhook           WebhookConfig             `yaml:"webhook"`             // HTTP endpoint triggering commands
+	Logs              LogsConfig                `yaml:"logs"`                // The bot's own logs
+	Restart           RestartConfig             `yaml:"restart"`             // Graceful restarts with /restart
+	Backup            BackupConfig              `yaml:"backup"`              // Archives of config, commands and state
+	Shell             ShellConfig               `yaml:"shell"`               // Interactive /shell sessions for admins
+	Get               GetConfig                 `yaml:"get"`                 // Files /get may send
+	Put               PutConfig                 `yaml:"put"`                 // Where /put saves uploaded files
+}
+
+// MenuConfig selects what a chat's menu shows. A command is shown if its
+// category or its name is listed (everything when both lists are empty) and
+// it is not hidden. Menus only; running commands by name is unaffected.
+type MenuConfig struct {
+	Categories []string `yaml:"categories"` // Categories to show
+	Commands   []string `yaml:"commands"`   // Individual commands to show
+	Hide       []string `yaml:"hide"`       // Commands to leave out
+}
+
+// Shows returns true if the layout includes the command. Listing a category
+// includes its sub-categories, e.g. "docker" includes "docker/prod".
+func (m MenuConfig) Shows(category, name string) bool {
+	if slices.Contains(m.Hide, name) {
+		return false
+	}
+	if len(m.Categories) == 0 && len(m.Commands) == 0 {
+		return true
+	}
+	for _, c := range m.Categories {
+		if category == c || strings.HasPrefix(category, c+"/") {
+			return true
+		}
+	}
+	return slices.Contains(m.Commands, name)
+}
+
+// TranscriptionConfig turns voice messages into the text argument of a
+// command. Set either Command (local program) or APIURL; disabled when
+// neither is set.
+type TranscriptionConfig struct {
+	Command  string        `yaml:"command"`  // Program run with the audio path appended; stdout is the transcript
+	APIURL   string        `yaml:"api_url"`  // OpenAI-compatible /v1/audio/transcriptions endpoint
+	APIKey   string        `yaml:"api_key"`  // Bearer token for APIURL
+	Model    string        `yaml:"model"`    // API model (default: whisper-1)
+	Language string        `yaml:"language"` // API language hint, e.g. "en" (default: auto-detect)
+	Target   string        `yaml:"target"`   // Command that receives the transcript; a /command caption overrides it
+	Timeout  time.Duration `yaml:"timeout"`  // Per-message transcription limit (default: 2m)
+}
+
+// Enabled returns true if a transcription backend is configured.
+func (t TranscriptionConfig) Enabled() bool {
+	return t.Command != "" || t.APIURL != ""
+}
+
+// InboxConfig controls where files uploaded to commands with `input` are
+// stored. Uploads are disabled when Dir is empty.
+type InboxConfig struct {
+	Dir       string        `yaml:"dir"`       // Relative to the config file
+	Retention time.Duration `yaml:"retention"` // Delete uploads after this long (default: 168h)
+}
+
+// URLFilesConfig controls downloading [url:...] references from command
+// output. Downloads are disabled when AllowedHosts is empty.
+type URLFilesConfig struct {
+	AllowedHosts []string      `yaml:"allowed_hosts"` // Exact host names or "*.example.com"
+	MaxSizeMB    int           `yaml:"max_size_mb"`   // Largest download (default and cap: 50)
+	Timeout      time.Duration `yaml:"timeout"`       // Per-download time limit (default: 30s)
+}
+
+// SudoConfig holds per-user PINs for /sudo and how long elevation lasts.
+type SudoConfig struct {
+	PINs     map[string]string `yaml:"pins"`     // Username or user ID -> PIN
+	Duration time.Duration     `yaml:"duration"` // Elevation window (default: 15m)
+}
+
+// AutoCleanupConfig schedules deletion of old tracked messages in every
+// allowed chat. Disabled when Schedule is empty.
+type AutoCleanupConfig struct {
+	Schedule  []string      `yaml:"schedule"`   // Times of day (HH:MM), e.g. ["03:00"]
+	OlderThan time.Duration `yaml:"older_than"` // Minimum message age (default: 168h)
+	Types     []string      `yaml:"types"`      // Message types to delete (default: [file])
+}
+
+// HeartbeatConfig posts a liveness message to an ops chat so a silent crash
+// is noticed. Disabled when ChatID is 0.
+type HeartbeatConfig struct {
+	ChatID   int64         `yaml:"chat_id"`  // Chat to post in
+	Interval time.Duration `yaml:"interval"` // How often to update (default: 5m)
+	Mode     string        `yaml:"mode"`     // "edit" one message (default) or "post" a new one each time
+}
+
+// BackupConfig controls /backup and scheduled backups.
+type BackupConfig struct {
+	Schedule []string `yaml:"schedule"` // Times of day (HH:MM) for automatic backups (default: none)
+	ChatID   int64    `yaml:"chat_id"`  // Chat receiving scheduled backups (default: admin chat)
+	S3       S3Config `yaml:"s3"`       // Upload archives instead of sending them to the chat
+}
+
+// S3Config is an S3-compatible bucket. Disabled when Bucket is empty.
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`   // Default: https://s3.<region>.amazonaws.com
+	Region    string `yaml:"region"`     // Default: us-east-1
+	Bucket    string `yaml:"bucket"`     // Bucket name
+	Prefix    string `yaml:"prefix"`     // Prepended to object names, e.g. "pako/"
+	AccessKey string `yaml:"access_key"` // Access key ID
+	SecretKey string `yaml:"secret_key"` // Secret access key
+}
+
+// Enabled returns true if a bucket is configured.
+func (s S3Config) Enabled() bool {
+	return s.Bucket != ""
+}
+
+// RestartConfig controls /restart.
+type RestartConfig struct {
+	DrainTimeout time.Duration `yaml:"drain_timeout"` // How long running commands may take to finish (default: 1m)
+}
+
+// ShellConfig enables /shell: a shell process bound to a chat, fed by an
+// admin's messages. Disabled unless Enabled is set.
+type ShellConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Command     string        `yaml:"command"`      // Shell and its arguments (default: /bin/sh)
+	Workdir     string        `yaml:"workdir"`      // Starting directory (default: the bot's)
+	IdleTimeout time.Duration `yaml:"idle_timeout"` // Close a session after this long without input (default: 10m)
+}
+
+// GetConfig restricts /get to files under AllowedPaths. /get is disabled
+// when AllowedPaths is empty.
+type GetConfig struct {
+	AllowedPaths []string `yaml:"allowed_paths"` // Absolute directories or files, e.g. /var/log/nginx
+}
+
+// PutConfig sets where /put saves uploaded documents. /put is disabled when
+// Dir is empty, and needs the inbox (Inbox.Dir) for receiving uploads.
+type PutConfig struct {
+	Dir       string `yaml:"dir"`         // Destination directory, relative to the config file
+	MaxSizeMB int    `yaml:"max_size_mb"` // Largest file saved (default and cap: 20, what bots can download)
+	Overwrite bool   `yaml:"overwrite"`   // Replace existing files (default: refuse)
+}
+
+// LogsConfig controls the bot's own logs.
+type LogsConfig struct {
+	Buffer int             `yaml:"buffer"` // Recent records kept in memory for /logs (default: 500)
+	Mirror LogMirrorConfig `yaml:"mirror"` // Forward warnings and errors to a chat
+}
+
+// LogMirrorConfig forwards the bot's warnings and errors to a chat, so
+// failures don't go unnoticed in the system log. Disabled when ChatID is 0.
+type LogMirrorConfig struct {
+	ChatID int64         `yaml:"chat_id"` // Chat to forward to
+	Level  string        `yaml:"level"`   // Lowest level forwarded: warn (default) or error
+	Window time.Duration `yaml:"window"`  // A repeated message is forwarded once per window (default: 10m)
+	Limit  int           `yaml:"limit"`   // Most records forwarded per window (default: 10)
+}
+
+// SlogLevel returns the lowest level forwarded.
+func (m LogMirrorConfig) SlogLevel() slog.Level {
+	if m.Level == "error" {
+		return slog.LevelError
+	}
+	return slog.LevelWarn
+}
+
+// StatusConfig selects what /status and related builtins report.
+type StatusConfig struct {
+	Mounts         StringList `yaml:"mounts"`           // Mount points, or "auto" for all real filesystems (default: "/")
+	ExcludeFSTypes []string   `yaml:"exclude_fs_types"` // Filesystem types skipped by "auto" (default: pseudo filesystems)
+	DockerSocket   string     `yaml:"docker_socket"`    // Docker API socket for /containers (default: $DOCKER_HOST or /var/run/docker.sock)
+	// Units are systemd units shown by /services; the admin chat is alerted
+	// when one fails or recovers. Disabled when empty.
+	Units             []string      `yaml:"units"`
+	UnitCheckInterval time.Duration `yaml:"unit_check_interval"` // How often units are checked (default: 1m)
+	// History samples metrics into the database for the trends in /status.
+	HistoryInterval  time.Duration `yaml:"history_interval"`  // How often metrics are recorded (default: 1m)
+	HistoryRetention time.Duration `yaml:"history_retention"` // How long samples are kept (default: 168h)
+}
+
+// MountPoints returns the configured mount points, or nil for "auto".
+func (s StatusConfig) MountPoints() []string {
+	if len(s.Mounts) == 1 && s.Mounts[0] == "auto" {
+		return nil
+	}
+	return s.Mounts
+}
+
+// AlertsConfig defines threshold alerts on the metrics /status reports,
+// e.g. CPU above 90% for 5 minutes. Disabled when there are no rules.
+type AlertsConfig struct {
+	ChatIDs  []int64            `yaml:"chat_ids"` // Chats alerted (default: the admin chat)
+	Interval time.Duration      `yaml:"interval"` // How often rules are checked (default: 1m)
+	Rules    []status.AlertRule `yaml:"rules"`
+}
+
+// WebhookConfig serves an HTTP endpoint where other systems (CI, cron on
+// another machine) trigger commands. Disabled when Listen is empty.
+type WebhookConfig struct {
+	Listen   string   `yaml:"listen"`   // Address, e.g. "127.0.0.1:8080"
+	Token    string   `yaml:"token"`    // Bearer token every request must carry
+	ChatID   int64    `yaml:"chat_id"`  // Chat receiving output when a request names none (default: the admin chat)
+	Commands []string `yaml:"commands"` // Commands that may be triggered, "*" for all but confirm ones (default: none)
+}
+
+// RateLimitConfig holds global token-bucket limits. Zero rules are disabled.
+type RateLimitConfig struct {
+	PerUser ratelimit.Rule `yaml:"per_user"` // Command requests per user across all chats
+	PerChat ratelimit.Rule `yaml:"per_chat"` // Command requests per chat across all users
+}
+
+// OTPConfig holds per-user TOTP secrets (base32, as used by authenticator apps).
+type OTPConfig struct {
+	Secrets map[string]string `yaml:"secrets"` // Username or user ID -> secret
+}
+
+// RolesConfig maps chats and users to roles (admin, operator, viewer, none).
+type RolesConfig struct {
+	Default string            `yaml:"default"` // Role for everyone else (default: viewer, or admin without chats and users)
+	Chats   map[int64]string  `yaml:"chats"`   // Chat ID -> role
+	Users   map[string]string `yaml:"users"`   // Username or user ID -> role
+}
+
+// Configured returns true if any roles are set. Without them everyone
+// allowed to use the bot is an admin.
+func (r RolesConfig) Configured() bool {
+	return r.Default != "" || len(r.Chats) > 0 || len(r.Users) > 0
+}
+
+// CategoryConfig holds menu metadata and command defaults for a category.
+// Command YAML settings take precedence over these values.
+type CategoryConfig struct {
+	DisplayName string        `yaml:"display_name"` // Menu label (default: capitalized category name)
+	Icon        string        `yaml:"icon"`         // Emoji icon for the category and its commands
+	Order       int           `yaml:"order"`        // Menu position; lower first, ties sorted by name
+	Confirm     *bool         `yaml:"confirm"`      // Default confirm for commands in this category
+	Timeout     time.Duration `yaml:"timeout"`      // Default timeout for commands in this category
+	MaxOutput   int           `yaml:"max_output"`   // Default max_output for commands in this category
 }
 
 // TelegramConfig holds Telegram bot settings.
 type TelegramConfig struct {
 	Token          string  `yaml:"token"`
 	AllowedChatIDs []int64 `yaml:"allowed_chat_ids"`
+	AdminChatID    int64   `yaml:"admin_chat_id"` // Chat for operational notices (default: first allowed chat)
+	// AllowedUserIDs and AllowedUsernames restrict who may run commands
+	// within allowed chats (empty = any member).
+	AllowedUserIDs   []int64  `yaml:"allowed_user_ids"`
+	AllowedUsernames []string `yaml:"allowed_usernames"`
+	ApprovalsChatID  int64    `yaml:"approvals_chat_id"` // Chat for multi-person approval requests (default: requesting chat)
 }
 
 // DatabaseConfig holds database connection settings.
@@ -39,27 +308,44 @@ type DefaultsConfig struct {
 	Timeout          time.Duration `yaml:"timeout"`
 	MaxOutput        int           `yaml:"max_output"`
 	MaxFilesPerGroup int           `yaml:"max_files_per_group"`
+	ConfirmTTL       time.Duration `yaml:"confirm_ttl"`     // How long confirmation buttons stay valid (default: 5m)
+	ConfirmMessage   string        `yaml:"confirm_message"` // Go template for confirmation dialogs (default: built-in text)
+	Redact           []string      `yaml:"redact"`          // Regular expressions masked in all command output and audit log arguments
 }
 
 // PodcastConfig holds configuration for podcast generation.
 type PodcastConfig struct {
-	PodcastgenPath string `yaml:"podcastgen_path"` // Path to podcastgen directory
-	ConfigPath     string `yaml:"config_path"`     // Path to TTS config.yml
-	TempDir        string `yaml:"temp_dir"`        // Temp directory for files
+	PodcastgenPath string        `yaml:"podcastgen_path"` // Path to podcastgen directory
+	ConfigPath     string        `yaml:"config_path"`     // Path to TTS config.yml
+	TempDir        string        `yaml:"temp_dir"`        // Temp directory for files
+	Voices         []string      `yaml:"voices"`          // Voices offered by /podcast; the first is the default
+	Speeds         []string      `yaml:"speeds"`          // Speech speeds offered by /podcast, e.g. "1.0"; the first is the default
+	ChunkSize      int           `yaml:"chunk_size"`      // Split text longer than this many characters into separate runs (0 = never)
+	Parallel       int           `yaml:"parallel"`        // Chunks generated at the same time (default: 1)
+	Timeout        time.Duration `yaml:"timeout"`         // Limit for a whole /podcast run (default: 10m)
+	VoiceNote      bool          `yaml:"voice_note"`      // Send the result as an OGG/OPUS voice message instead of an MP3
+	MaxJobs        int           `yaml:"max_jobs"`        // Podcasts generated at the same time; others are queued (default: 1)
 }
 
 // Load reads configuration from the specified YAML file path.
-// Supports ${ENV_VAR} expansion in string values.
+// Supports ${ENV_VAR} expansion and ${scheme:ref} secret references in
+// values.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config file: %w", err)
 	}
 
-	expanded := expandEnvVars(string(data))
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if err := expandNode(&root); err != nil {
+		return nil, err
+	}
 
 	var cfg Config
-	if err := yaml.Unmarshal([]byte(expanded), &cfg); err != nil {
+	if err := root.Decode(&cfg); err != nil {
 		return nil, fmt.Errorf("parse config: %w", err)
 	}
 
@@ -80,8 +366,12 @@ func (c *Config) setDefaults() error {
 		return fmt.Errorf("telegram.allowed_chat_ids must have at least one entry")
 	}
 
-	if c.CommandsDir == "" {
-		c.CommandsDir = "./commands"
+	if c.Telegram.AdminChatID == 0 {
+		c.Telegram.AdminChatID = c.Telegram.AllowedChatIDs[0]
+	}
+
+	if len(c.CommandsDir) == 0 {
+		c.CommandsDir = StringList{"./commands"}
 	}
 
 	if c.Database.Path == "" {
@@ -96,10 +386,169 @@ func (c *Config) setDefaults() error {
 		c.Defaults.MaxOutput = 5000
 	}
 
+	if c.MessageStoreTTL == 0 {
+		c.MessageStoreTTL = 60 * 24 * time.Hour
+	}
+
+	if c.AutoCleanup.OlderThan == 0 {
+		c.AutoCleanup.OlderThan = 7 * 24 * time.Hour
+	}
+
+	if len(c.AutoCleanup.Types) == 0 {
+		c.AutoCleanup.Types = []string{"file"}
+	}
+
+	if c.Transcription.Command != "" && c.Transcription.APIURL != "" {
+		return fmt.Errorf("transcription: set either command or api_url, not both")
+	}
+
+	if c.Transcription.Timeout == 0 {
+		c.Transcription.Timeout = 2 * time.Minute
+	}
+
 	if c.Defaults.MaxFilesPerGroup == 0 {
 		c.Defaults.MaxFilesPerGroup = 10
 	}
 
+	if c.Defaults.ConfirmTTL == 0 {
+		c.Defaults.ConfirmTTL = 5 * time.Minute
+	}
+	if c.Defaults.ConfirmTTL < 0 {
+		return fmt.Errorf("defaults.confirm_ttl must be positive")
+	}
+	if _, err := template.New("confirm_message").Parse(c.Defaults.ConfirmMessage); err != nil {
+		return fmt.Errorf("defaults.confirm_message: %w", err)
+	}
+	if _, err := redact.Compile(c.Defaults.Redact); err != nil {
+		return fmt.Errorf("defaults.redact: %w", err)
+	}
+
+	if len(c.Status.Mounts) == 0 {
+		c.Status.Mounts = StringList{"/"}
+	}
+
+	if c.Status.UnitCheckInterval == 0 {
+		c.Status.UnitCheckInterval = time.Minute
+	}
+
+	if c.Status.HistoryInterval == 0 {
+		c.Status.HistoryInterval = time.Minute
+	}
+	if c.Status.HistoryRetention == 0 {
+		c.Status.HistoryRetention = 7 * 24 * time.Hour
+	}
+	if c.Status.HistoryRetention < 24*time.Hour {
+		return fmt.Errorf("status.history_retention must be at least 24h")
+	}
+
+	if c.Alerts.Interval == 0 {
+		c.Alerts.Interval = time.Minute
+	}
+	for i := range c.Alerts.Rules {
+		if err := c.Alerts.Rules[i].Validate(); err != nil {
+			return fmt.Errorf("alerts.rules[%d]: %w", i, err)
+		}
+	}
+
+	if c.Backup.ChatID == 0 {
+		c.Backup.ChatID = c.Telegram.AdminChatID
+	}
+	if c.Backup.S3.Enabled() {
+		if c.Backup.S3.Region == "" {
+			c.Backup.S3.Region = "us-east-1"
+		}
+		if c.Backup.S3.Endpoint == "" {
+			c.Backup.S3.Endpoint = "https://s3." + c.Backup.S3.Region + ".amazonaws.com"
+		}
+		if c.Backup.S3.AccessKey == "" || c.Backup.S3.SecretKey == "" {
+			return fmt.Errorf("backup.s3 needs access_key and secret_key")
+		}
+	}
+
+	if c.Shell.Command == "" {
+		c.Shell.Command = "/bin/sh"
+	}
+	if strings.TrimSpace(c.Shell.Command) == "" {
+		return fmt.Errorf("shell.command is blank")
+	}
+	if c.Shell.Enabled && !c.Roles.Configured() {
+		return fmt.Errorf("shell.enabled needs roles: /shell is for admins, and without roles everyone is one")
+	}
+	if c.Shell.IdleTimeout == 0 {
+		c.Shell.IdleTimeout = 10 * time.Minute
+	}
+	if c.Shell.IdleTimeout < 0 {
+		return fmt.Errorf("shell.idle_timeout must be positive")
+	}
+
+	for _, p := range c.Get.AllowedPaths {
+		if !filepath.IsAbs(p) {
+			return fmt.Errorf("get.allowed_paths: %q is not an absolute path", p)
+		}
+	}
+
+	if c.Put.Dir != "" && c.Inbox.Dir == "" {
+		return fmt.Errorf("put.dir needs inbox.dir to receive uploads")
+	}
+	if c.Put.MaxSizeMB < 0 {
+		return fmt.Errorf("put.max_size_mb must not be negative")
+	}
+	if c.Put.MaxSizeMB == 0 || c.Put.MaxSizeMB > 20 {
+		c.Put.MaxSizeMB = 20
+	}
+
+	if c.Restart.DrainTimeout == 0 {
+		c.Restart.DrainTimeout = time.Minute
+	}
+
+	if c.Logs.Buffer == 0 {
+		c.Logs.Buffer = logbuf.DefaultSize
+	}
+	if c.Logs.Buffer < 0 {
+		return fmt.Errorf("logs.buffer must not be negative")
+	}
+	if c.Logs.Mirror.Level == "" {
+		c.Logs.Mirror.Level = "warn"
+	}
+	if c.Logs.Mirror.Level != "warn" && c.Logs.Mirror.Level != "error" {
+		return fmt.Errorf("logs.mirror.level must be warn or error, got %q", c.Logs.Mirror.Level)
+	}
+	if c.Logs.Mirror.Window == 0 {
+		c.Logs.Mirror.Window = 10 * time.Minute
+	}
+	if c.Logs.Mirror.Limit == 0 {
+		c.Logs.Mirror.Limit = 10
+	}
+
+	if c.Webhook.Listen != "" && c.Webhook.Token == "" {
+		return fmt.Errorf("webhook.token is required when webhook.listen is set")
+	}
+
+	if c.Heartbeat.Interval == 0 {
+		c.Heartbeat.Interval = 5 * time.Minute
+	}
+	if c.Heartbeat.Interval < time.Minute {
+		return fmt.Errorf("heartbeat.interval must be at least 1m")
+	}
+	if c.Heartbeat.Mode == "" {
+		c.Heartbeat.Mode = "edit"
+	}
+	if c.Heartbeat.Mode != "edit" && c.Heartbeat.Mode != "post" {
+		return fmt.Errorf("heartbeat.mode: want edit or post, got %q", c.Heartbeat.Mode)
+	}
+
+	if c.Language == "" {
+		c.Language = i18n.Default
+	}
+	if !i18n.Supported(c.Language) {
+		return fmt.Errorf("language: unsupported %q (available: %s)", c.Language, strings.Join(i18n.Languages(), ", "))
+	}
+	for chatID, lang := range c.ChatLanguages {
+		if !i18n.Supported(lang) {
+			return fmt.Errorf("chat_languages: chat %d: unsupported %q (available: %s)", chatID, lang, strings.Join(i18n.Languages(), ", "))
+		}
+	}
+
 	return nil
 }
 
@@ -111,15 +560,110 @@ func (c *Config) ExpandPath(base, path string) string {
 	return filepath.Join(filepath.Dir(base), path)
 }
 
+// CommandDirs returns the command directories resolved relative to the
+// config file, with glob patterns expanded. Duplicates are removed.
+func (c *Config) CommandDirs(base string) []string {
+	var dirs []string
+	for _, entry := range c.CommandsDir {
+		path := c.ExpandPath(base, entry)
+		matches := []string{path}
+		if strings.ContainsAny(entry, "*?[") {
+			matches, _ = filepath.Glob(path) // Pattern errors yield no matches
+		}
+		for _, m := range matches {
+			if !slices.Contains(dirs, m) {
+				dirs = append(dirs, m)
+			}
+		}
+	}
+	return dirs
+}
+
+// PluginsPath resolves plugins_dir relative to the config file, or returns ""
+// when plugins are disabled.
+func (c *Config) PluginsPath(base string) string {
+	if c.PluginsDir == "" {
+		return ""
+	}
+	return c.ExpandPath(base, c.PluginsDir)
+}
+
+// StringList is a YAML value given as either a single string or a list.
+type StringList []string
+
+// UnmarshalYAML accepts a scalar or a sequence of strings.
+func (s *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value == "" {
+			*s = nil
+			return nil
+		}
+		*s = StringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// expandNode expands the string scalars under n in place, so comments are
+// left alone and expanded values can't change the YAML structure. Unquoted
+// values are typed again after expansion, so ${CHAT_ID} can fill a number.
+func expandNode(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.ShortTag() != "!!str" || !strings.Contains(n.Value, "$") {
+			return nil
+		}
+		val, err := Expand(n.Value)
+		if err != nil || val == n.Value {
+			return err
+		}
+		n.Value = val
+		if n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			n.Tag = ""
+			if n.ShortTag() == "!!null" {
+				n.Tag = "!!str" // An empty or "null" value is still a string
+			}
+		}
+	case yaml.AliasNode:
+		// Expanded where the anchor is
+	default:
+		for _, c := range n.Content {
+			if err := expandNode(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // envVarPattern matches ${VAR} or $VAR patterns.
 var envVarPattern = regexp.MustCompile(`\$\{([^}]+)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
 
-// expandEnvVars replaces ${VAR} and $VAR with environment variable values.
-func expandEnvVars(s string) string {
-	return envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+// Expand replaces ${VAR} and $VAR with environment variable values and
+// ${scheme:ref} with secrets, e.g. ${file:/run/secrets/token} or
+// ${vault:kv/telegram#token}. Unset variables are left as is; unresolvable
+// secrets are an error.
+func Expand(s string) (string, error) {
+	var firstErr error
+	expanded := envVarPattern.ReplaceAllStringFunc(s, func(match string) string {
 		var name string
 		if match[1] == '{' {
 			name = match[2 : len(match)-1]
+			if r, ref, ok := secretResolver(name); ok {
+				val, err := r.Resolve(ref)
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("resolve secret %q: %w", name, err)
+					}
+					return match
+				}
+				return val
+			}
 		} else {
 			name = match[1:]
 		}
@@ -128,4 +672,5 @@ func expandEnvVars(s string) string {
 		}
 		return match
 	})
+	return expanded, firstErr
 }
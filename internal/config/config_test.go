@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadShellConfig(t *testing.T) {
+	base := "telegram:\n  token: t\n  allowed_chat_ids: [1]\n"
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"blank command", "shell:\n  command: '  '\n", "shell.command is blank"},
+		{"enabled without roles", "shell:\n  enabled: true\n", "shell.enabled needs roles"},
+		{"enabled with roles", "shell:\n  enabled: true\nroles:\n  users:\n    \"@alice\": admin\n", ""},
+		{"default command", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(base+tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Shell.Command == "" {
+				t.Error("shell.command left empty")
+			}
+		})
+	}
+}
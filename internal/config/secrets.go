@@ -0,0 +1,120 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretResolver resolves the reference part of a ${scheme:ref} expression.
+type SecretResolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to SecretResolver.
+type SecretResolverFunc func(ref string) (string, error)
+
+// Resolve calls f(ref).
+func (f SecretResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+var (
+	secretMu        sync.RWMutex
+	secretResolvers = map[string]SecretResolver{
+		"file":  SecretResolverFunc(resolveFileSecret),
+		"vault": SecretResolverFunc(resolveVaultSecret),
+	}
+)
+
+// RegisterSecretResolver makes ${scheme:ref} references resolve through r.
+func RegisterSecretResolver(scheme string, r SecretResolver) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	secretResolvers[scheme] = r
+}
+
+// secretResolver returns the resolver for a ${...} expression body, if it
+// is a secret reference. Shell-style modifiers like ${file:-x} are not.
+func secretResolver(expr string) (SecretResolver, string, bool) {
+	scheme, ref, ok := strings.Cut(expr, ":")
+	if !ok || ref == "" || strings.ContainsAny(ref[:1], "-+=?") {
+		return nil, "", false
+	}
+
+	secretMu.RLock()
+	defer secretMu.RUnlock()
+	r, ok := secretResolvers[scheme]
+	return r, ref, ok
+}
+
+// resolveFileSecret reads a secret from a file, e.g. ${file:/run/secrets/token}.
+// A single trailing newline is removed.
+func resolveFileSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+// vaultClient is used for Vault lookups.
+var vaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// resolveVaultSecret reads a field from a Vault KV v2 secret, e.g.
+// ${vault:kv/telegram#token}. Uses VAULT_ADDR and VAULT_TOKEN.
+func resolveVaultSecret(ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || field == "" {
+		return "", fmt.Errorf("vault reference must be mount/path#field")
+	}
+	mount, secretPath, ok := strings.Cut(path, "/")
+	if !ok || secretPath == "" {
+		return "", fmt.Errorf("vault reference must be mount/path#field")
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+
+	url := strings.TrimSuffix(addr, "/") + "/v1/" + mount + "/data/" + secretPath
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+
+	val, ok := body.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found", field)
+	}
+	return fmt.Sprint(val), nil
+}
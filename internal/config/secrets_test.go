@@ -0,0 +1,118 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	secretPath := filepath.Join(dir, "token")
+	if err := os.WriteFile(secretPath, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PAKO_TEST_VAR", "value")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"env braces", "${PAKO_TEST_VAR}", "value", false},
+		{"env bare", "$PAKO_TEST_VAR", "value", false},
+		{"unset env kept", "${PAKO_TEST_UNSET}", "${PAKO_TEST_UNSET}", false},
+		{"file secret", "token: ${file:" + secretPath + "}", "token: s3cret", false},
+		{"missing file", "${file:" + filepath.Join(dir, "nope") + "}", "", true},
+		{"shell default kept", "${file:-x}", "${file:-x}", false},
+		{"unknown scheme kept", "${other:ref}", "${other:ref}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expand(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Expand(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveVaultSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/data/telegram" || r.Header.Get("X-Vault-Token") != "tok" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"token":"abc"}}}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "tok")
+
+	got, err := Expand("${vault:kv/telegram#token}")
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if got != "abc" {
+		t.Errorf("Expand() = %q, want %q", got, "abc")
+	}
+
+	if _, err := Expand("${vault:kv/telegram#missing}"); err == nil {
+		t.Error("expected error for missing field")
+	}
+}
+
+func TestLoadExpandsValues(t *testing.T) {
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, "token")
+	// Characters that would break the YAML if spliced into the file
+	token := "123:abc # 'not\" a comment\nsecond: line"
+	if err := os.WriteFile(tokenPath, []byte(token+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PAKO_TEST_CHAT", "-100123")
+
+	configPath := filepath.Join(dir, "config.yaml")
+	data := `# token: ${file:/nonexistent/old-token}
+telegram:
+  token: ${file:` + tokenPath + `}
+  allowed_chat_ids:
+    - ${PAKO_TEST_CHAT}
+shell:
+  command: "/bin/sh -c '$$'" # ${vault:kv/commented#out}
+`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Telegram.Token != token {
+		t.Errorf("token = %q, want %q", cfg.Telegram.Token, token)
+	}
+	if len(cfg.Telegram.AllowedChatIDs) != 1 || cfg.Telegram.AllowedChatIDs[0] != -100123 {
+		t.Errorf("allowed_chat_ids = %v, want [-100123]", cfg.Telegram.AllowedChatIDs)
+	}
+	if cfg.Shell.Command != "/bin/sh -c '$$'" {
+		t.Errorf("shell.command = %q", cfg.Shell.Command)
+	}
+
+	missing := strings.Replace(data, "${file:"+tokenPath+"}", "${file:"+filepath.Join(dir, "nope")+"}", 1)
+	if err := os.WriteFile(configPath, []byte(missing), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(configPath); err == nil {
+		t.Error("expected error for an unresolvable secret in a value")
+	}
+}
@@ -0,0 +1,201 @@
+// Package diff produces line-based unified diffs of command output.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxEdits bounds the work spent finding a minimal diff. Outputs that differ
+// in more lines are shown as entirely replaced.
+const maxEdits = 1000
+
+// Kind is the type of a diff line.
+type Kind byte
+
+// Line kinds, as the markers printed before each line.
+const (
+	Equal  Kind = ' '
+	Delete Kind = '-'
+	Insert Kind = '+'
+)
+
+// Line is one line of a diff.
+type Line struct {
+	Kind Kind
+	Text string
+}
+
+// Lines returns the edit script turning a into b, line by line.
+func Lines(a, b string) []Line {
+	return compute(splitLines(a), splitLines(b))
+}
+
+// Unified formats the difference between a and b as unified diff hunks with
+// context lines of context around each change. It returns "" if a and b
+// have the same lines.
+func Unified(a, b string, context int) string {
+	lines := Lines(a, b)
+
+	var out strings.Builder
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == Equal {
+			i++
+			continue
+		}
+
+		// Extend the hunk while changes are close enough to share context
+		start := max(i-context, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].Kind != Equal {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].Kind == Equal {
+				next++
+			}
+			if next == len(lines) || next-end > 2*context {
+				end = min(end+context, len(lines))
+				break
+			}
+			end = next
+		}
+
+		writeHunk(&out, lines, start, end)
+		i = end
+	}
+	return out.String()
+}
+
+// writeHunk writes lines[start:end] with an "@@ -a,n +b,m @@" header.
+func writeHunk(out *strings.Builder, lines []Line, start, end int) {
+	oldLine, newLine := 1, 1
+	for _, l := range lines[:start] {
+		if l.Kind != Insert {
+			oldLine++
+		}
+		if l.Kind != Delete {
+			newLine++
+		}
+	}
+	var oldCount, newCount int
+	for _, l := range lines[start:end] {
+		if l.Kind != Insert {
+			oldCount++
+		}
+		if l.Kind != Delete {
+			newCount++
+		}
+	}
+	// Empty ranges start at the line before, as in diff -u
+	if oldCount == 0 {
+		oldLine--
+	}
+	if newCount == 0 {
+		newLine--
+	}
+
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+	for _, l := range lines[start:end] {
+		out.WriteByte(byte(l.Kind))
+		out.WriteString(l.Text)
+		out.WriteByte('\n')
+	}
+}
+
+// splitLines splits s into lines, ignoring a trailing newline.
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// compute finds a shortest edit script with Myers' algorithm. Each step d
+// keeps the furthest x reached on every diagonal k = x - y; the history is
+// replayed backwards to recover the edits.
+func compute(a, b []string) []Line {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+
+	// v[k+offset] is the furthest x on diagonal k
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int // trace[d] holds v[offset-d : offset+d+1] before step d
+
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Down: insert
+			} else {
+				x = v[offset+k-1] + 1 // Right: delete
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, d)
+			}
+		}
+	}
+
+	// Too many differences: replace everything
+	lines := make([]Line, 0, n+m)
+	for _, s := range a {
+		lines = append(lines, Line{Delete, s})
+	}
+	for _, s := range b {
+		lines = append(lines, Line{Insert, s})
+	}
+	return lines
+}
+
+// backtrack walks the trace from the end of both inputs back to the start.
+func backtrack(a, b []string, trace [][]int, depth int) []Line {
+	x, y := len(a), len(b)
+	var rev []Line
+	for d := depth; d > 0; d-- {
+		v := trace[d] // Indexed by k + d
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, Line{Equal, a[x]})
+		}
+		if x == prevX {
+			y--
+			rev = append(rev, Line{Insert, b[y]})
+		} else {
+			x--
+			rev = append(rev, Line{Delete, a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		rev = append(rev, Line{Equal, a[x]})
+	}
+
+	lines := make([]Line, len(rev))
+	for i, l := range rev {
+		lines[len(rev)-1-i] = l
+	}
+	return lines
+}
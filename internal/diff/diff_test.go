@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{
+			name: "unchanged",
+			a:    "a\nb\n",
+			b:    "a\nb",
+			want: "",
+		},
+		{
+			name:    "changed line",
+			a:       "nginx up\npostgres up\nredis up\n",
+			b:       "nginx up\npostgres down\nredis up\n",
+			context: 1,
+			want:    "@@ -1,3 +1,3 @@\n nginx up\n-postgres up\n+postgres down\n redis up\n",
+		},
+		{
+			name:    "separate hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:       "1\nX\n3\n4\n5\n6\n7\nY\n",
+			context: 1,
+			want:    "@@ -1,3 +1,3 @@\n 1\n-2\n+X\n 3\n@@ -7,2 +7,2 @@\n 7\n-8\n+Y\n",
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "new\n",
+			want: "@@ -0,0 +1,1 @@\n+new\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified(tt.a, tt.b, tt.context); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinesRoundTrip(t *testing.T) {
+	a := "the\nquick\nbrown\nfox\njumps\nover\nthe\nlazy\ndog"
+	b := "a\nquick\nred\nfox\njumps\nthe\nlazy\nsleeping\ndog"
+
+	var oldLines, newLines []string
+	edits := 0
+	for _, l := range Lines(a, b) {
+		if l.Kind != Insert {
+			oldLines = append(oldLines, l.Text)
+		}
+		if l.Kind != Delete {
+			newLines = append(newLines, l.Text)
+		}
+		if l.Kind != Equal {
+			edits++
+		}
+	}
+	if strings.Join(oldLines, "\n") != a || strings.Join(newLines, "\n") != b {
+		t.Errorf("Lines() does not reproduce its inputs")
+	}
+	if edits != 6 {
+		t.Errorf("Lines() made %d edits, want the minimal 6", edits)
+	}
+}
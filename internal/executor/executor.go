@@ -4,9 +4,13 @@ package executor
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
 
+	"github.com/creack/pty"
+
 	"github.com/rashpile/pako-telegram/internal/command"
 )
 
@@ -27,20 +31,53 @@ func (e *ShellExecutor) Execute(ctx context.Context, cfg command.ExecuteConfig)
 	}
 
 	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", fullCmd)
-	cmd.Stdout = cfg.Output
-	cmd.Stderr = cfg.Output
+
+	if len(cfg.Env) > 0 {
+		cmd.Env = append(os.Environ(), cfg.Env...)
+	}
 
 	// Set working directory if specified
 	if cfg.Workdir != "" {
 		cmd.Dir = cfg.Workdir
 	}
 
-	if err := cmd.Run(); err != nil {
-		if ctx.Err() != nil {
-			return fmt.Errorf("command timed out or cancelled")
-		}
-		return fmt.Errorf("command failed: %w", err)
+	if cfg.TTY != nil {
+		return runTTY(ctx, cmd, cfg)
 	}
 
-	return nil
+	cmd.Stdout = cfg.Output
+	cmd.Stderr = cfg.Output
+	if cfg.Stderr != nil {
+		cmd.Stderr = cfg.Stderr
+	}
+	return runError(ctx, cmd.Run())
+}
+
+// runTTY runs cmd under a pseudo-terminal of cfg.TTY's size and copies what
+// it writes to the terminal, stdout and stderr alike, to cfg.Output.
+func runTTY(ctx context.Context, cmd *exec.Cmd, cfg command.ExecuteConfig) error {
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: cfg.TTY.Cols, Rows: cfg.TTY.Rows})
+	if err != nil {
+		return fmt.Errorf("start pty: %w", err)
+	}
+	defer ptmx.Close()
+
+	// Stop reading on timeout even if a background child keeps the terminal open
+	stop := context.AfterFunc(ctx, func() { ptmx.Close() })
+	defer stop()
+
+	// Reading fails (EIO on Linux) once the terminal is closed; that is the end
+	_, _ = io.Copy(cfg.Output, ptmx)
+	return runError(ctx, cmd.Wait())
+}
+
+// runError describes how a finished command failed, or returns nil.
+func runError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("command timed out or cancelled")
+	}
+	return fmt.Errorf("command failed: %w", err)
 }
@@ -1,9 +1,11 @@
 // Package fileref handles parsing and processing of file references in command output.
 // Commands can include [file:/path/to/file] patterns in their output, which will be
-// extracted and sent as Telegram media groups.
+// extracted and sent as Telegram media groups. [url:https://...] references are
+// downloaded from allowed hosts first; [voice:/path] sends audio as a voice message.
 package fileref
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -18,23 +20,35 @@ const (
 	FileTypePhoto
 	FileTypeVideo
 	FileTypeAudio
+	FileTypeVoice // Voice message; sent on its own, not in a media group
 )
 
 // FileRef represents a parsed file reference from command output.
 type FileRef struct {
 	Path string
 	Type FileType
+	Temp bool // Created for upload (e.g. compressed copy); remove with RemoveTemp
+
+	// Cleanup deletes the file from disk after a successful upload
+	// ([file:path|cleanup]); see RemoveCleanup.
+	Cleanup bool
+	Origin  string // Original path when Path is a compressed copy
+
+	// Video preview details, filled when ffmpeg/ffprobe are available
+	Thumb                   string // JPEG thumbnail (temp file; removed by RemoveTemp)
+	Width, Height, Duration int    // Duration in seconds
 }
 
 // ParseResult contains the parsed command output.
 type ParseResult struct {
 	Text   string    // Cleaned text without file references
 	Files  []FileRef // Extracted file references (validated to exist)
-	Errors []string  // Error messages for missing files
+	Errors []string  // Error messages for missing, oversized or failed files
 }
 
-// fileRefPattern matches [file:/path/to/file] patterns.
-var fileRefPattern = regexp.MustCompile(`\[file:([^\]]+)\]`)
+// fileRefPattern matches [file:/path/to/file], [voice:/path/to/audio] and
+// [url:https://...] patterns.
+var fileRefPattern = regexp.MustCompile(`\[(file|voice|url):([^\]]+)\]`)
 
 // photoExtensions maps extensions to photo type.
 var photoExtensions = map[string]bool{
@@ -54,7 +68,14 @@ var audioExtensions = map[string]bool{
 // ParseOutput extracts file references from command output.
 // Returns cleaned text, valid files, and error messages for missing files.
 // If workdir is provided, relative paths are resolved against it.
+// URL references are reported as errors; use ParseOutputWithURLs to download them.
 func ParseOutput(output string, workdir string) ParseResult {
+	return ParseOutputWithURLs(output, workdir, nil)
+}
+
+// ParseOutputWithURLs is like ParseOutput but downloads [url:...] references
+// with d. Downloaded files are temp files; remove them with RemoveTemp.
+func ParseOutputWithURLs(output string, workdir string, d *Downloader) ParseResult {
 	var result ParseResult
 	var files []FileRef
 	var errors []string
@@ -72,19 +93,45 @@ func ParseOutput(output string, workdir string) ParseResult {
 
 	for _, match := range matches {
 		// match[0]:match[1] is the full match [file:path]
-		// match[2]:match[3] is the captured group (path)
+		// match[2]:match[3] is the kind (file, voice or url)
+		// match[4]:match[5] is the path or URL
 		fullStart, fullEnd := match[0], match[1]
-		pathStart, pathEnd := match[2], match[3]
+		kind := output[match[2]:match[3]]
+		pathStart, pathEnd := match[4], match[5]
 
 		// Append text before this match
 		cleaned.WriteString(output[lastEnd:fullStart])
 		lastEnd = fullEnd
 
 		// Extract and validate path
-		path := strings.TrimSpace(output[pathStart:pathEnd])
+		path, modifiers := splitModifiers(output[pathStart:pathEnd])
 		if path == "" {
 			continue
 		}
+		cleanup := false
+		for _, m := range modifiers {
+			switch m {
+			case "cleanup":
+				cleanup = true
+			default:
+				errors = append(errors, fmt.Sprintf("Unknown file modifier %q: %s", m, path))
+			}
+		}
+
+		// Download URL references
+		if kind == "url" {
+			if d == nil {
+				errors = append(errors, "URL downloads are not enabled: "+path)
+				continue
+			}
+			ref, err := d.Download(path)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("Download failed: %s (%v)", path, err))
+				continue
+			}
+			files = append(files, ref) // Downloads are always temp files
+			continue
+		}
 
 		// Resolve relative paths against workdir
 		fullPath := path
@@ -93,15 +140,39 @@ func ParseOutput(output string, workdir string) ParseResult {
 		}
 
 		// Check if file exists
-		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		info, err := os.Stat(fullPath)
+		if os.IsNotExist(err) {
 			errors = append(errors, "File not found: "+fullPath)
 			continue
 		}
 
-		files = append(files, FileRef{
-			Path: fullPath,
-			Type: DetectType(fullPath),
-		})
+		ref := FileRef{
+			Path:    fullPath,
+			Type:    DetectType(fullPath),
+			Cleanup: cleanup,
+		}
+		if kind == "voice" {
+			ref.Type = FileTypeVoice
+		}
+
+		// Check Telegram upload limits (compressing or converting if needed)
+		if err == nil {
+			if kind == "voice" {
+				ref, err = prepareVoice(ref, info.Size())
+			} else {
+				ref, err = fitUploadLimit(ref, info.Size())
+			}
+			if err != nil {
+				errors = append(errors, err.Error())
+				continue
+			}
+		}
+
+		if ref.Type == FileTypeVideo {
+			ref = prepareVideo(ref)
+		}
+
+		files = append(files, ref)
 	}
 
 	// Append remaining text
@@ -115,6 +186,19 @@ func ParseOutput(output string, workdir string) ParseResult {
 	return result
 }
 
+// splitModifiers separates "path|mod1|mod2" into the trimmed path and
+// lowercase modifiers.
+func splitModifiers(s string) (string, []string) {
+	parts := strings.Split(s, "|")
+	var modifiers []string
+	for _, m := range parts[1:] {
+		if m = strings.ToLower(strings.TrimSpace(m)); m != "" {
+			modifiers = append(modifiers, m)
+		}
+	}
+	return strings.TrimSpace(parts[0]), modifiers
+}
+
 // DetectType determines Telegram media type from file extension.
 func DetectType(path string) FileType {
 	ext := strings.ToLower(filepath.Ext(path))
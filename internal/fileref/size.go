@@ -0,0 +1,135 @@
+package fileref
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Telegram Bot API upload limits. Variables so tests can lower them.
+var (
+	maxUploadSize   int64 = 50 << 20 // Documents, audio and video
+	maxPhotoSize    int64 = 10 << 20 // Photos
+	maxCompressSize int64 = 1 << 30  // Larger files are not worth compressing
+)
+
+// errTooLarge aborts compression once the output exceeds the upload limit.
+var errTooLarge = errors.New("compressed file exceeds upload limit")
+
+// fitUploadLimit adjusts ref to Telegram's upload limits. Oversized photos
+// are sent as documents; oversized files are gzipped into a temp file.
+// Returns an error message if the file cannot be made to fit.
+func fitUploadLimit(ref FileRef, size int64) (FileRef, error) {
+	if ref.Type == FileTypePhoto && size > maxPhotoSize {
+		ref.Type = FileTypeDocument
+	}
+	if size <= maxUploadSize {
+		return ref, nil
+	}
+
+	tooLarge := fmt.Errorf("File too large: %s (%s, limit %s)", ref.Path, formatSize(size), formatSize(maxUploadSize))
+	if size > maxCompressSize {
+		return ref, tooLarge
+	}
+
+	compressed, err := gzipToTemp(ref.Path)
+	if errors.Is(err, errTooLarge) {
+		return ref, fmt.Errorf("%w, even compressed", tooLarge)
+	}
+	if err != nil {
+		return ref, fmt.Errorf("File too large: %s (%s) and compression failed: %v", ref.Path, formatSize(size), err)
+	}
+	return FileRef{Path: compressed, Type: FileTypeDocument, Temp: true, Cleanup: ref.Cleanup, Origin: ref.Path}, nil
+}
+
+// gzipToTemp compresses path into a new temp directory, keeping the base
+// name with a .gz suffix. Returns errTooLarge if the result would not fit.
+func gzipToTemp(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dir, err := os.MkdirTemp("", "pako-upload-*")
+	if err != nil {
+		return "", err
+	}
+	dst := filepath.Join(dir, filepath.Base(path)+".gz")
+
+	f, err := os.Create(dst)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	zw := gzip.NewWriter(&limitedWriter{w: f, remaining: maxUploadSize})
+	zw.Name = filepath.Base(path)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dst, nil
+}
+
+// limitedWriter fails with errTooLarge once more than remaining bytes are written.
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		return 0, errTooLarge
+	}
+	n, err := l.w.Write(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// RemoveTemp deletes temp files created for upload (e.g. compressed copies
+// and video thumbnails).
+func RemoveTemp(files []FileRef) {
+	for _, f := range files {
+		if f.Temp {
+			os.RemoveAll(filepath.Dir(f.Path))
+		}
+		if f.Thumb != "" {
+			os.RemoveAll(filepath.Dir(f.Thumb))
+		}
+	}
+}
+
+// RemoveCleanup deletes files marked with the cleanup modifier (the original
+// file for compressed copies). Call it only after a successful upload.
+func RemoveCleanup(files []FileRef) error {
+	var errs []error
+	for _, f := range files {
+		if !f.Cleanup {
+			continue
+		}
+		path := f.Path
+		if f.Origin != "" {
+			path = f.Origin
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// formatSize renders a byte count in MB with one decimal.
+func formatSize(n int64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
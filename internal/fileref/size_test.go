@@ -0,0 +1,150 @@
+package fileref
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withLimits lowers the upload limits for the duration of a test.
+func withLimits(t *testing.T, upload, photo int64) {
+	t.Helper()
+	oldUpload, oldPhoto := maxUploadSize, maxPhotoSize
+	maxUploadSize, maxPhotoSize = upload, photo
+	t.Cleanup(func() { maxUploadSize, maxPhotoSize = oldUpload, oldPhoto })
+}
+
+func TestParseOutputSizeLimits(t *testing.T) {
+	withLimits(t, 1024, 512)
+	tmpDir := t.TempDir()
+
+	small := filepath.Join(tmpDir, "small.txt")
+	bigPhoto := filepath.Join(tmpDir, "big.jpg")
+	compressible := filepath.Join(tmpDir, "app.log")
+	random := filepath.Join(tmpDir, "random.bin")
+
+	noise := make([]byte, 4096)
+	if _, err := rand.Read(noise); err != nil {
+		t.Fatal(err)
+	}
+	for path, data := range map[string][]byte{
+		small:        []byte("ok"),
+		bigPhoto:     bytes.Repeat([]byte("p"), 800),
+		compressible: bytes.Repeat([]byte("line of log output\n"), 1000),
+		random:       noise,
+	} {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("small file unchanged", func(t *testing.T) {
+		result := ParseOutput("[file:"+small+"]", "")
+		if len(result.Files) != 1 || result.Files[0].Path != small || result.Files[0].Temp {
+			t.Fatalf("Files = %+v", result.Files)
+		}
+	})
+
+	t.Run("large photo sent as document", func(t *testing.T) {
+		result := ParseOutput("[file:"+bigPhoto+"]", "")
+		if len(result.Files) != 1 || result.Files[0].Type != FileTypeDocument {
+			t.Fatalf("Files = %+v", result.Files)
+		}
+	})
+
+	t.Run("oversized file compressed", func(t *testing.T) {
+		result := ParseOutput("[file:"+compressible+"]", "")
+		if len(result.Errors) != 0 || len(result.Files) != 1 {
+			t.Fatalf("Files = %+v, Errors = %v", result.Files, result.Errors)
+		}
+		ref := result.Files[0]
+		if !ref.Temp || ref.Type != FileTypeDocument || filepath.Base(ref.Path) != "app.log.gz" {
+			t.Fatalf("ref = %+v", ref)
+		}
+
+		f, err := os.Open(ref.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != 19000 {
+			t.Errorf("decompressed %d bytes, want 19000", len(data))
+		}
+
+		RemoveTemp(result.Files)
+		if _, err := os.Stat(ref.Path); !os.IsNotExist(err) {
+			t.Errorf("temp file not removed: %v", err)
+		}
+	})
+
+	t.Run("incompressible file reported", func(t *testing.T) {
+		result := ParseOutput("[file:"+random+"]", "")
+		if len(result.Files) != 0 || len(result.Errors) != 1 {
+			t.Fatalf("Files = %+v, Errors = %v", result.Files, result.Errors)
+		}
+		if !strings.Contains(result.Errors[0], "File too large") {
+			t.Errorf("error = %q", result.Errors[0])
+		}
+	})
+}
+
+func TestParseOutputCleanupModifier(t *testing.T) {
+	withLimits(t, 1024, 512)
+	tmpDir := t.TempDir()
+
+	plain := filepath.Join(tmpDir, "keep.txt")
+	marked := filepath.Join(tmpDir, "out.png")
+	large := filepath.Join(tmpDir, "big.log")
+	for path, data := range map[string][]byte{
+		plain:  []byte("keep"),
+		marked: []byte("png"),
+		large:  bytes.Repeat([]byte("log line\n"), 500),
+	} {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := "[file:" + plain + "] [file:" + marked + " | cleanup] [file:" + large + "|cleanup]"
+	result := ParseOutput(output, "")
+	if len(result.Errors) != 0 || len(result.Files) != 3 {
+		t.Fatalf("Files = %+v, Errors = %v", result.Files, result.Errors)
+	}
+	if result.Files[0].Cleanup || !result.Files[1].Cleanup || !result.Files[2].Cleanup {
+		t.Fatalf("Cleanup flags = %+v", result.Files)
+	}
+	if result.Files[1].Path != marked || result.Files[1].Type != FileTypePhoto {
+		t.Errorf("modifier not stripped: %+v", result.Files[1])
+	}
+
+	if err := RemoveCleanup(result.Files); err != nil {
+		t.Fatal(err)
+	}
+	RemoveTemp(result.Files)
+	if _, err := os.Stat(plain); err != nil {
+		t.Errorf("unmarked file removed: %v", err)
+	}
+	for _, path := range []string{marked, large, result.Files[2].Path} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", path, err)
+		}
+	}
+
+	result = ParseOutput("[file:"+plain+"|bogus]", "")
+	if len(result.Errors) != 1 || len(result.Files) != 1 {
+		t.Errorf("unknown modifier: Files = %+v, Errors = %v", result.Files, result.Errors)
+	}
+}
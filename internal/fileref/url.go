@@ -0,0 +1,175 @@
+package fileref
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for URL downloads when not configured.
+const (
+	DefaultDownloadTimeout = 30 * time.Second
+	maxRedirects           = 5
+)
+
+// Downloader fetches [url:...] references from allowed hosts into temp files.
+// Safe for concurrent use.
+type Downloader struct {
+	mu      sync.RWMutex
+	hosts   []string
+	maxSize int64
+	timeout time.Duration
+	client  *http.Client
+}
+
+// NewDownloader creates a downloader. Hosts are exact names or "*.domain"
+// for subdomains; an empty list disables downloads. A zero maxSize uses the
+// upload limit and a zero timeout uses DefaultDownloadTimeout.
+func NewDownloader(hosts []string, maxSize int64, timeout time.Duration) *Downloader {
+	d := &Downloader{}
+	d.client = &http.Client{CheckRedirect: d.checkRedirect}
+	d.Reload(hosts, maxSize, timeout)
+	return d
+}
+
+// Reload replaces the allowed hosts and limits.
+func (d *Downloader) Reload(hosts []string, maxSize int64, timeout time.Duration) {
+	if maxSize <= 0 || maxSize > maxUploadSize {
+		maxSize = maxUploadSize
+	}
+	if timeout <= 0 {
+		timeout = DefaultDownloadTimeout
+	}
+	normalized := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			normalized = append(normalized, h)
+		}
+	}
+
+	d.mu.Lock()
+	d.hosts = normalized
+	d.maxSize = maxSize
+	d.timeout = timeout
+	d.mu.Unlock()
+}
+
+// Allowed returns true if host may be downloaded from.
+func (d *Downloader) Allowed(host string) bool {
+	host = strings.ToLower(host)
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, h := range d.hosts {
+		if h == host {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(h, "*"); ok && strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Download fetches rawURL into a new temp directory and returns it as a
+// temp file reference. The download is bounded by the size and time limits.
+func (d *Downloader) Download(rawURL string) (FileRef, error) {
+	u, err := d.checkURL(rawURL)
+	if err != nil {
+		return FileRef{}, err
+	}
+
+	d.mu.RLock()
+	maxSize, timeout := d.maxSize, d.timeout
+	d.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return FileRef{}, err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return FileRef{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return FileRef{}, fmt.Errorf("server returned %s", resp.Status)
+	}
+	if resp.ContentLength > maxSize {
+		return FileRef{}, fmt.Errorf("too large (%s, limit %s)", formatSize(resp.ContentLength), formatSize(maxSize))
+	}
+
+	dir, err := os.MkdirTemp("", "pako-download-*")
+	if err != nil {
+		return FileRef{}, err
+	}
+	dst := filepath.Join(dir, downloadName(u))
+
+	f, err := os.Create(dst)
+	if err != nil {
+		os.RemoveAll(dir)
+		return FileRef{}, err
+	}
+	n, err := io.Copy(f, io.LimitReader(resp.Body, maxSize+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > maxSize {
+		err = fmt.Errorf("too large (limit %s)", formatSize(maxSize))
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return FileRef{}, err
+	}
+
+	ref := FileRef{Path: dst, Type: DetectType(dst), Temp: true}
+	if ref.Type == FileTypePhoto && n > maxPhotoSize {
+		ref.Type = FileTypeDocument
+	}
+	return ref, nil
+}
+
+// checkURL parses rawURL and verifies its scheme and host.
+func (d *Downloader) checkURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if !d.Allowed(u.Hostname()) {
+		return nil, fmt.Errorf("host %q is not allowed", u.Hostname())
+	}
+	return u, nil
+}
+
+// checkRedirect only follows redirects to allowed hosts.
+func (d *Downloader) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("too many redirects")
+	}
+	_, err := d.checkURL(req.URL.String())
+	return err
+}
+
+// downloadName picks a file name from the URL path.
+func downloadName(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "." || name == "/" || name == "" {
+		return "download"
+	}
+	return name
+}
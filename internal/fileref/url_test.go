@@ -0,0 +1,93 @@
+package fileref
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloaderAllowed(t *testing.T) {
+	d := NewDownloader([]string{"Reports.example.com", "*.internal.net"}, 0, 0)
+
+	tests := map[string]bool{
+		"reports.example.com": true,
+		"example.com":         false,
+		"ci.internal.net":     true,
+		"a.b.internal.net":    true,
+		"internal.net":        false,
+		"evil.com":            false,
+	}
+	for host, want := range tests {
+		if got := d.Allowed(host); got != want {
+			t.Errorf("Allowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestParseOutputWithURLs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/report.pdf":
+			w.Write([]byte("%PDF report"))
+		case "/big.bin":
+			w.Write([]byte(strings.Repeat("x", 2048)))
+		case "/redirect":
+			http.Redirect(w, r, "http://evil.invalid/x", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	d := NewDownloader([]string{u.Hostname()}, 1024, 5*time.Second)
+
+	t.Run("download", func(t *testing.T) {
+		result := ParseOutputWithURLs("Report:\n[url:"+srv.URL+"/report.pdf]", "", d)
+		if len(result.Errors) != 0 || len(result.Files) != 1 {
+			t.Fatalf("Files = %+v, Errors = %v", result.Files, result.Errors)
+		}
+		ref := result.Files[0]
+		data, err := os.ReadFile(ref.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "%PDF report" || !ref.Temp || !strings.HasSuffix(ref.Path, "report.pdf") {
+			t.Errorf("ref = %+v, data = %q", ref, data)
+		}
+		if result.Text != "Report:" {
+			t.Errorf("Text = %q", result.Text)
+		}
+		RemoveTemp(result.Files)
+		if _, err := os.Stat(ref.Path); !os.IsNotExist(err) {
+			t.Errorf("download not removed: %v", err)
+		}
+	})
+
+	failures := map[string]string{
+		"too large":      srv.URL + "/big.bin",
+		"not found":      srv.URL + "/missing",
+		"host denied":    "https://example.com/report.pdf",
+		"bad scheme":     "ftp://" + u.Host + "/report.pdf",
+		"redirect check": srv.URL + "/redirect",
+	}
+	for name, rawURL := range failures {
+		t.Run(name, func(t *testing.T) {
+			result := ParseOutputWithURLs("[url:"+rawURL+"]", "", d)
+			if len(result.Files) != 0 || len(result.Errors) != 1 {
+				t.Fatalf("Files = %+v, Errors = %v", result.Files, result.Errors)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		result := ParseOutput("[url:"+srv.URL+"/report.pdf]", "")
+		if len(result.Files) != 0 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "not enabled") {
+			t.Fatalf("Files = %+v, Errors = %v", result.Files, result.Errors)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package fileref
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// videoToolTimeout bounds ffprobe and thumbnail extraction per video.
+const videoToolTimeout = 30 * time.Second
+
+// lookFFprobe finds ffprobe. Variable so tests can stub it.
+var lookFFprobe = func() (string, error) {
+	return exec.LookPath("ffprobe")
+}
+
+// prepareVideo adds dimensions, duration and a thumbnail to a video ref so
+// Telegram clients can preview it. Best effort: without ffmpeg/ffprobe, or
+// on failure, ref is returned unchanged.
+func prepareVideo(ref FileRef) FileRef {
+	if ffprobe, err := lookFFprobe(); err == nil {
+		ref.Width, ref.Height, ref.Duration = probeVideo(ffprobe, ref.Path)
+	}
+	if ffmpeg, err := lookFFmpeg(); err == nil {
+		ref.Thumb = extractThumbnail(ffmpeg, ref.Path)
+	}
+	return ref
+}
+
+// probeVideo returns the first video stream's size and the duration in
+// seconds. For audio files only the duration is set.
+func probeVideo(ffprobe, path string) (width, height, duration int) {
+	ctx, cancel := context.WithTimeout(context.Background(), videoToolTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, ffprobe, "-v", "error", "-select_streams", "v:0",
+		"-show_entries", "stream=width,height:format=duration", "-of", "json", path).Output()
+	if err != nil {
+		return 0, 0, 0
+	}
+
+	var probe struct {
+		Streams []struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return 0, 0, 0
+	}
+	if len(probe.Streams) > 0 {
+		width, height = probe.Streams[0].Width, probe.Streams[0].Height
+	}
+	if d, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		duration = int(math.Round(d))
+	}
+	return width, height, duration
+}
+
+// extractThumbnail writes a representative frame as a JPEG of at most
+// 320px (Telegram's thumbnail limit) into a new temp directory.
+// Returns "" on failure.
+func extractThumbnail(ffmpeg, path string) string {
+	dir, err := os.MkdirTemp("", "pako-thumb-*")
+	if err != nil {
+		return ""
+	}
+	dst := filepath.Join(dir, "thumb.jpg")
+
+	ctx, cancel := context.WithTimeout(context.Background(), videoToolTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, ffmpeg, "-nostdin", "-loglevel", "error", "-y",
+		"-i", path, "-vf", "thumbnail,scale=320:320:force_original_aspect_ratio=decrease",
+		"-frames:v", "1", "-q:v", "5", dst)
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(dir)
+		return ""
+	}
+	if _, err := os.Stat(dst); err != nil {
+		os.RemoveAll(dir)
+		return ""
+	}
+	return dst
+}
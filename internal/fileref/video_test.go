@@ -0,0 +1,40 @@
+package fileref
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseOutputVideoDetails(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Fake ffprobe printing fixed JSON
+	ffprobe := filepath.Join(tmpDir, "ffprobe")
+	script := "#!/bin/sh\necho '{\"streams\":[{\"width\":1280,\"height\":720}],\"format\":{\"duration\":\"12.6\"}}'\n"
+	if err := os.WriteFile(ffprobe, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldProbe, oldFFmpeg := lookFFprobe, lookFFmpeg
+	lookFFprobe = func() (string, error) { return ffprobe, nil }
+	lookFFmpeg = func() (string, error) { return "", errors.New("not found") }
+	t.Cleanup(func() { lookFFprobe, lookFFmpeg = oldProbe, oldFFmpeg })
+
+	video := filepath.Join(tmpDir, "clip.mp4")
+	if err := os.WriteFile(video, []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := ParseOutput("[file:"+video+"]", "")
+	if len(result.Files) != 1 {
+		t.Fatalf("Files = %+v, Errors = %v", result.Files, result.Errors)
+	}
+	ref := result.Files[0]
+	if ref.Width != 1280 || ref.Height != 720 || ref.Duration != 13 {
+		t.Errorf("details = %dx%d %ds, want 1280x720 13s", ref.Width, ref.Height, ref.Duration)
+	}
+	if ref.Thumb != "" {
+		t.Errorf("Thumb = %q without ffmpeg", ref.Thumb)
+	}
+}
@@ -0,0 +1,110 @@
+package fileref
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// voiceConvertTimeout bounds a single ffmpeg conversion.
+const voiceConvertTimeout = 2 * time.Minute
+
+// voiceExtensions are formats Telegram accepts as voice messages. Only
+// OGG/OPUS gets the waveform bubble, so other formats are converted when
+// ffmpeg is available.
+var voiceExtensions = map[string]bool{
+	".ogg": true, ".oga": true, ".opus": true, ".mp3": true, ".m4a": true,
+}
+
+// lookFFmpeg finds ffmpeg. Variable so tests can stub it.
+var lookFFmpeg = func() (string, error) {
+	return exec.LookPath("ffmpeg")
+}
+
+// PrepareVoice prepares the file at path to be sent as a voice message:
+// converted to OGG/OPUS if needed, with its duration when ffprobe is
+// available. A converted copy is marked Temp; remove it with RemoveTemp.
+func PrepareVoice(path string) (FileRef, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileRef{}, err
+	}
+	return prepareVoice(FileRef{Path: path}, info.Size())
+}
+
+// prepareVoice converts ref to OGG/OPUS if needed and ffmpeg is available,
+// and adds the duration if ffprobe is. Returns an error message if the file
+// cannot be sent as a voice message.
+func prepareVoice(ref FileRef, size int64) (FileRef, error) {
+	ref, err := convertVoice(ref, size)
+	if err == nil {
+		if ffprobe, probeErr := lookFFprobe(); probeErr == nil {
+			_, _, ref.Duration = probeVideo(ffprobe, ref.Path)
+		}
+	}
+	return ref, err
+}
+
+// convertVoice converts ref to OGG/OPUS if needed and ffmpeg is available.
+func convertVoice(ref FileRef, size int64) (FileRef, error) {
+	ref.Type = FileTypeVoice
+	if size > maxUploadSize {
+		return ref, fmt.Errorf("File too large: %s (%s, limit %s)", ref.Path, formatSize(size), formatSize(maxUploadSize))
+	}
+
+	ext := strings.ToLower(filepath.Ext(ref.Path))
+	if ext == ".ogg" || ext == ".oga" || ext == ".opus" {
+		return ref, nil
+	}
+
+	ffmpeg, err := lookFFmpeg()
+	if err != nil {
+		if voiceExtensions[ext] {
+			return ref, nil // Sent as is, without a waveform
+		}
+		return ref, fmt.Errorf("Cannot send %s as voice: convert it to OGG/OPUS or install ffmpeg", ref.Path)
+	}
+
+	converted, err := convertToOpus(ffmpeg, ref.Path)
+	if err != nil {
+		return ref, fmt.Errorf("Voice conversion failed: %s (%v)", ref.Path, err)
+	}
+	return FileRef{Path: converted, Type: FileTypeVoice, Temp: true, Cleanup: ref.Cleanup, Origin: ref.Path}, nil
+}
+
+// convertToOpus encodes path as OGG/OPUS into a new temp directory.
+func convertToOpus(ffmpeg, path string) (string, error) {
+	dir, err := os.MkdirTemp("", "pako-voice-*")
+	if err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	dst := filepath.Join(dir, base+".ogg")
+
+	ctx, cancel := context.WithTimeout(context.Background(), voiceConvertTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, ffmpeg, "-nostdin", "-loglevel", "error", "-y",
+		"-i", path, "-vn", "-c:a", "libopus", "-b:a", "48k", dst)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+
+	info, err := os.Stat(dst)
+	if err == nil && info.Size() > maxUploadSize {
+		err = fmt.Errorf("converted file exceeds %s", formatSize(maxUploadSize))
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dst, nil
+}
@@ -0,0 +1,75 @@
+package fileref
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOutputVoiceWithoutFFmpeg(t *testing.T) {
+	old := lookFFmpeg
+	lookFFmpeg = func() (string, error) { return "", errors.New("not found") }
+	t.Cleanup(func() { lookFFmpeg = old })
+
+	tmpDir := t.TempDir()
+	for _, name := range []string{"note.ogg", "note.mp3", "note.wav"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("audio"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result := ParseOutput("Summary\n[voice:note.ogg]\n[voice:note.mp3]\n[voice:note.wav]\n[file:note.ogg]", tmpDir)
+	if result.Text != "Summary" {
+		t.Errorf("Text = %q", result.Text)
+	}
+	if len(result.Files) != 3 {
+		t.Fatalf("Files = %+v", result.Files)
+	}
+	for i, want := range []FileType{FileTypeVoice, FileTypeVoice, FileTypeAudio} {
+		if result.Files[i].Type != want {
+			t.Errorf("Files[%d].Type = %v, want %v", i, result.Files[i].Type, want)
+		}
+		if result.Files[i].Temp {
+			t.Errorf("Files[%d] unexpectedly converted", i)
+		}
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "note.wav") {
+		t.Errorf("Errors = %v", result.Errors)
+	}
+}
+
+func TestPrepareVoiceDuration(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Fake ffprobe printing an audio-only result
+	ffprobe := filepath.Join(tmpDir, "ffprobe")
+	script := "#!/bin/sh\necho '{\"streams\":[],\"format\":{\"duration\":\"61.4\"}}'\n"
+	if err := os.WriteFile(ffprobe, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	oldProbe := lookFFprobe
+	lookFFprobe = func() (string, error) { return ffprobe, nil }
+	t.Cleanup(func() { lookFFprobe = oldProbe })
+
+	note := filepath.Join(tmpDir, "note.ogg")
+	if err := os.WriteFile(note, []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := PrepareVoice(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Type != FileTypeVoice || ref.Path != note || ref.Temp {
+		t.Errorf("ref = %+v", ref)
+	}
+	if ref.Duration != 61 {
+		t.Errorf("Duration = %d, want 61", ref.Duration)
+	}
+
+	if _, err := PrepareVoice(filepath.Join(tmpDir, "missing.ogg")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
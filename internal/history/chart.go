@@ -0,0 +1,120 @@
+package history
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"time"
+)
+
+// Chart dimensions in pixels.
+const (
+	chartWidth  = 800
+	chartHeight = 400
+	chartMargin = 16
+)
+
+var (
+	chartBackground = color.RGBA{255, 255, 255, 255}
+	chartGrid       = color.RGBA{225, 225, 225, 255}
+	chartAxis       = color.RGBA{150, 150, 150, 255}
+)
+
+// Line is a metric's samples drawn in a chart.
+type Line struct {
+	Points []Point
+	Color  color.RGBA
+}
+
+// RenderChart draws percentage lines on a 0-100 scale over the period from
+// start to end as a PNG. Horizontal grid lines mark every 25%, vertical ones
+// split the period into quarters. Gaps in the samples (the bot was down) are
+// left open rather than bridged.
+func RenderChart(w io.Writer, lines []Line, start, end time.Time) error {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{chartBackground}, image.Point{}, draw.Src)
+
+	plot := image.Rect(chartMargin, chartMargin, chartWidth-chartMargin, chartHeight-chartMargin)
+	for i := 0; i <= 4; i++ {
+		c := chartGrid
+		if i == 0 {
+			c = chartAxis
+		}
+		y := plot.Max.Y - i*plot.Dy()/4
+		drawLine(img, plot.Min.X, y, plot.Max.X, y, c)
+		x := plot.Min.X + i*plot.Dx()/4
+		drawLine(img, x, plot.Min.Y, x, plot.Max.Y, chartGrid)
+	}
+
+	period := end.Sub(start)
+	if period <= 0 {
+		return png.Encode(w, img)
+	}
+	maxGap := period / 48
+	toPixel := func(p Point) (int, int) {
+		x := plot.Min.X + int(float64(plot.Dx())*float64(p.At.Sub(start))/float64(period))
+		v := min(max(p.Value, 0), 100)
+		y := plot.Max.Y - int(float64(plot.Dy())*v/100)
+		return x, y
+	}
+
+	for _, line := range lines {
+		for i, p := range line.Points {
+			x, y := toPixel(p)
+			if i == 0 || p.At.Sub(line.Points[i-1].At) > maxGap {
+				thickPoint(img, x, y, line.Color)
+				continue
+			}
+			px, py := toPixel(line.Points[i-1])
+			drawLine(img, px, py, x, y, line.Color)
+			drawLine(img, px, py-1, x, y-1, line.Color)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// thickPoint draws a 2x2 dot, so isolated samples stay visible.
+func thickPoint(img *image.RGBA, x, y int, c color.RGBA) {
+	img.SetRGBA(x, y, c)
+	img.SetRGBA(x+1, y, c)
+	img.SetRGBA(x, y-1, c)
+	img.SetRGBA(x+1, y-1, c)
+}
+
+// drawLine draws a one pixel line using Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
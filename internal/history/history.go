@@ -0,0 +1,199 @@
+// Package history stores periodic samples of system metrics in SQLite so
+// /status can show how they changed over the last hours.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/rashpile/pako-telegram/internal/status"
+)
+
+// columns maps the recorded metrics to their table columns.
+var columns = map[string]string{
+	status.MetricCPU:    "cpu",
+	status.MetricMemory: "memory",
+	status.MetricDisk:   "disk",
+	status.MetricLoad1:  "load1",
+}
+
+// Metrics lists the recorded metrics in display order. Disk is the first
+// monitored mount point.
+var Metrics = []string{status.MetricCPU, status.MetricMemory, status.MetricDisk, status.MetricLoad1}
+
+// Trend summarizes a metric over a time window.
+type Trend struct {
+	Samples       int
+	Min, Avg, Max float64
+	Buckets       []float64 // Averages over equal parts of the window, oldest first; NaN where empty
+}
+
+// Point is one recorded value of a metric.
+type Point struct {
+	At    time.Time
+	Value float64
+}
+
+// Store records metric samples.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (or creates) the metrics table in the database at dbPath.
+func Open(dbPath string) (*Store, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	schema := `
+		CREATE TABLE IF NOT EXISTS metrics_history (
+			at INTEGER NOT NULL,
+			cpu REAL NOT NULL,
+			memory REAL NOT NULL,
+			disk REAL NOT NULL,
+			load1 REAL NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_metrics_history_at ON metrics_history(at);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Record stores a sample taken at the given time.
+func (s *Store) Record(at time.Time, m *status.Metrics) error {
+	_, err := s.db.Exec(
+		"INSERT INTO metrics_history (at, cpu, memory, disk, load1) VALUES (?, ?, ?, ?, ?)",
+		at.Unix(), m.CPUPercent, m.MemoryPercent, m.DiskPercent, m.Load1,
+	)
+	if err != nil {
+		return fmt.Errorf("record metrics: %w", err)
+	}
+	return nil
+}
+
+// Purge removes samples taken before the cutoff.
+func (s *Store) Purge(before time.Time) (int, error) {
+	res, err := s.db.Exec("DELETE FROM metrics_history WHERE at < ?", before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("purge metrics: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// Series returns a metric's samples recorded since the given time, oldest
+// first.
+func (s *Store) Series(metric string, since time.Time) ([]Point, error) {
+	column, ok := columns[metric]
+	if !ok {
+		return nil, fmt.Errorf("no history for metric %q", metric)
+	}
+
+	rows, err := s.db.Query(
+		"SELECT at, "+column+" FROM metrics_history WHERE at >= ? ORDER BY at",
+		since.Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var points []Point
+	for rows.Next() {
+		var at int64
+		var value float64
+		if err := rows.Scan(&at, &value); err != nil {
+			return nil, fmt.Errorf("query metrics: %w", err)
+		}
+		points = append(points, Point{At: time.Unix(at, 0), Value: value})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query metrics: %w", err)
+	}
+	return points, nil
+}
+
+// Trend summarizes a metric over the window ending now, split into the given
+// number of buckets. Samples is zero if nothing was recorded in the window.
+func (s *Store) Trend(metric string, window time.Duration, buckets int) (Trend, error) {
+	start := time.Now().Add(-window)
+	points, err := s.Series(metric, start)
+	if err != nil {
+		return Trend{}, err
+	}
+
+	t := Trend{Samples: len(points), Min: math.Inf(1), Max: math.Inf(-1), Buckets: make([]float64, buckets)}
+	sums := make([]float64, buckets)
+	counts := make([]int, buckets)
+	var total float64
+	for _, p := range points {
+		total += p.Value
+		t.Min = min(t.Min, p.Value)
+		t.Max = max(t.Max, p.Value)
+
+		i := int(p.At.Sub(start) * time.Duration(buckets) / window)
+		i = min(max(i, 0), buckets-1)
+		sums[i] += p.Value
+		counts[i]++
+	}
+
+	if t.Samples == 0 {
+		return Trend{}, nil
+	}
+	t.Avg = total / float64(t.Samples)
+	for i := range t.Buckets {
+		t.Buckets[i] = math.NaN()
+		if counts[i] > 0 {
+			t.Buckets[i] = sums[i] / float64(counts[i])
+		}
+	}
+	return t, nil
+}
+
+// Run records a sample every interval until the context is cancelled,
+// deleting samples older than retention.
+func (s *Store) Run(ctx context.Context, collector status.Collector, interval, retention time.Duration) {
+	sample := func() {
+		metrics, err := collector.Collect(ctx)
+		if err != nil {
+			slog.Warn("metrics sample failed", "error", err)
+			return
+		}
+		now := time.Now()
+		if err := s.Record(now, metrics); err != nil {
+			slog.Warn("metrics sample failed", "error", err)
+			return
+		}
+		if _, err := s.Purge(now.Add(-retention)); err != nil {
+			slog.Warn("metrics purge failed", "error", err)
+		}
+	}
+
+	sample()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sample()
+		}
+	}
+}
+
+// Close releases database resources.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
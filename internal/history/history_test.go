@@ -0,0 +1,81 @@
+package history
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rashpile/pako-telegram/internal/status"
+)
+
+func TestTrend(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer s.Close()
+
+	now := time.Now()
+	samples := []struct {
+		ago time.Duration
+		cpu float64
+	}{
+		{2 * time.Hour, 99}, // Outside the window
+		{55 * time.Minute, 10},
+		{50 * time.Minute, 20},
+		{5 * time.Minute, 90},
+	}
+	for _, sm := range samples {
+		if err := s.Record(now.Add(-sm.ago), &status.Metrics{CPUPercent: sm.cpu}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	trend, err := s.Trend(status.MetricCPU, time.Hour, 4)
+	if err != nil {
+		t.Fatalf("Trend() error = %v", err)
+	}
+	if trend.Samples != 3 || trend.Min != 10 || trend.Max != 90 || trend.Avg != 40 {
+		t.Errorf("Trend() = %+v, want 3 samples, 10/40/90", trend)
+	}
+	if trend.Buckets[0] != 15 || !math.IsNaN(trend.Buckets[1]) || trend.Buckets[3] != 90 {
+		t.Errorf("Trend() buckets = %v, want [15 NaN NaN 90]", trend.Buckets)
+	}
+
+	if n, err := s.Purge(now.Add(-time.Hour)); err != nil || n != 1 {
+		t.Errorf("Purge() = %d, %v; want 1 removed", n, err)
+	}
+	if _, err := s.Trend("swap", time.Hour, 4); err == nil {
+		t.Error("Trend() for unrecorded metric: want error")
+	}
+}
+
+func TestRenderChart(t *testing.T) {
+	end := time.Now()
+	start := end.Add(-time.Hour)
+	red := color.RGBA{255, 0, 0, 255}
+	lines := []Line{{
+		Points: []Point{{start, 0}, {start.Add(30 * time.Minute), 100}, {end, 50}},
+		Color:  red,
+	}}
+
+	var buf bytes.Buffer
+	if err := RenderChart(&buf, lines, start, end); err != nil {
+		t.Fatalf("RenderChart() error = %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode chart: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != chartWidth || b.Dy() != chartHeight {
+		t.Errorf("chart size = %v, want %dx%d", b, chartWidth, chartHeight)
+	}
+	// The peak at 30 minutes is drawn at the top of the plot
+	if got := color.RGBAModel.Convert(img.At(chartWidth/2, chartMargin)); got != red {
+		t.Errorf("pixel at peak = %v, want line color", got)
+	}
+}
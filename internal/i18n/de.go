@@ -0,0 +1,226 @@
+package i18n
+
+var german = map[Key]string{
+	BotRestarted:      "Bot neu gestartet",
+	UnknownCommand:    "Unbekannter Befehl: /%s\nMit /help siehst du alle verfügbaren Befehle.",
+	CommandDisabled:   "Der Befehl /%s ist derzeit deaktiviert.",
+	CommandNotFound:   "Befehl nicht gefunden.",
+	Running:           "Führe /%s aus...",
+	Cancel:            "Abbrechen",
+	Back:              "<< Zurück",
+	BackToMenu:        "<< Zurück zum Menü",
+	BackTo:            "<< Zurück zu %s",
+	ProcessFailed:     "Befehl konnte nicht verarbeitet werden: %v",
+	SendAudioFailed:   "Audio konnte nicht gesendet werden: %v",
+	SendVoiceFailed:   "Sprachnachricht konnte nicht gesendet werden: %v",
+	SendPhotoFailed:   "Bild konnte nicht gesendet werden: %v",
+	SendFileFailed:    "Datei konnte nicht gesendet werden: %v",
+	InvalidArgs:       "Ungültige Argumente: %v\nVerwendung: /%s name=wert ...",
+	TooManyRequests:   "⏳ Zu viele Anfragen. Versuche /%s in %s erneut.",
+	MaintenanceDryRun: "🚧 Wartungsmodus: /%s wurde nicht ausgeführt.\nWürde ausführen: %s",
+	CoolingDown:       "⏳ /%s wurde von %s vor %s ausgeführt und hat eine Abklingzeit. Versuche es in %s erneut.",
+
+	ChatUnauthorized:     "Nicht berechtigt. Deine Chat-ID (%d) ist nicht freigegeben.",
+	UserUnauthorized:     "Nicht berechtigt. Deine Benutzer-ID (%d) darf keine Befehle ausführen.",
+	RequestAccess:        "🔑 Zugang anfragen",
+	AccessGranted:        "Zugang gewährt.",
+	AccessDenied:         "Zugangsanfrage abgelehnt.",
+	AccessAlreadyAllowed: "Dieser Chat hat bereits Zugang.",
+	AccessPending:        "Deine Zugangsanfrage wartet bereits auf einen Admin.",
+	AccessRequestFailed:  "Die Zugangsanfrage konnte nicht gesendet werden. Versuche es später erneut.",
+	AccessRequested:      "Zugang angefragt. Du wirst benachrichtigt, sobald ein Admin entscheidet.",
+	AccessRequestFrom:    "🔑 Zugangsanfrage von %s",
+	AccessRequestGone:    "Zugangsanfrage abgelaufen oder bereits bearbeitet.",
+	AccessApprovedBy:     "✅ %s hat den Zugang für %s genehmigt. Füge %d zu allowed_chat_ids hinzu, damit er einen Neustart übersteht.",
+	AccessDeniedBy:       "❌ %s hat den Zugang für %s abgelehnt.",
+	Approve:              "Genehmigen",
+	Deny:                 "Ablehnen",
+
+	SelectCategory:   "Kategorie wählen:",
+	CategoryCommands: "Befehle in %s:\n\nTippe auf einen Befehl, um ihn auszuführen.",
+	CleanupButton:    "🗑️ Aufräumen",
+	PrevPage:         "‹ Zurück",
+	NextPage:         "Weiter ›",
+	MenuOutdated:     "Dieses Menü ist veraltet; die Befehle haben sich geändert.",
+	Refresh:          "🔄 Aktualisieren",
+
+	Confirm:               "Bestätigen",
+	ConfirmExecution:      "Ausführung von `/%s` bestätigen?",
+	ConfirmExpired:        "Bestätigung abgelaufen oder ungültig.",
+	ConfirmDanger:         "⚠️ Achtung: %s",
+	ConfirmPhrasePrompt:   "Gib `%s` ein, um zu bestätigen, oder drücke Abbrechen.",
+	DryRun:                "🔍 Probelauf",
+	DryRunRunning:         "🔍 Probelauf von /%s (der Befehl selbst wartet weiter auf Bestätigung):",
+	ConfirmPhraseMismatch: "Text stimmt nicht überein. /%s abgebrochen.",
+	CommandCancelled:      "Befehl abgebrochen.",
+	NothingToCancel:       "Kein aktiver Befehl zum Abbrechen.",
+	Executing:             "Führe /%s aus...",
+	ApprovedExecuting:     "Genehmigt von %s. Führe /%s aus...",
+	DeniedBy:              "/%s abgelehnt von %s.",
+	AdminsOnly:            "Nur Admins können /%s genehmigen oder ablehnen.",
+	AlreadyApproved:       "%s hat /%s bereits genehmigt; ein weiterer Admin muss zustimmen.",
+	SelfApprovalForbidden: "%s hat /%s angefragt und kann es nicht genehmigen; ein anderer Admin muss zustimmen.",
+	ApprovalRequired:      "Genehmigung erforderlich für /%s",
+	ApprovalRequestedBy:   "Angefragt von %s in Chat %d",
+	ApprovalCount:         "Genehmigungen: %d/%d",
+	ApprovalSent:          "/%s braucht %d Admin-Genehmigungen; Anfrage an den Genehmigungs-Chat gesendet.",
+	ApprovalRequestFailed: "Genehmigung konnte nicht angefragt werden: %v",
+	PreviewWorkdir:        "Arbeitsverzeichnis: %s",
+	PreviewTarget:         "Ausgeführt auf: %s",
+	PreviewLocalHost:      "Shell auf %s",
+
+	OTPNotConfigured:  "/%s erfordert einen Einmalcode, aber für dich ist kein OTP-Geheimnis konfiguriert.",
+	OTPPrompt:         "🔐 /%s erfordert einen Einmalcode. Antworte mit dem 6-stelligen Code aus deiner Authenticator-App oder /cancel.",
+	OTPExpired:        "Code-Anfrage abgelaufen. Bitte führe den Befehl erneut aus.",
+	OTPInvalid:        "Ungültiger Code. Versuche es erneut oder /cancel.",
+	OTPTooMany:        "Zu viele ungültige Codes. /%s abgebrochen.",
+	OTPLockedOut:      "Zu viele ungültige Codes. /%s abgebrochen, Einmalcodes sind vorübergehend gesperrt.",
+	SudoNotConfigured: "Rechteerhöhung ist nicht konfiguriert.",
+	SudoEnded:         "Rechteerhöhung beendet.",
+	SudoNotElevated:   "Du hast keine erhöhten Rechte.",
+	SudoRemaining:     "Erhöhte Rechte für weitere %s.",
+	SudoUsage:         "Verwendung: /sudo, /sudo off oder /sudo status. Sende die PIN nur, wenn du danach gefragt wirst.",
+	SudoNoPIN:         "Für dich ist keine sudo-PIN konfiguriert.",
+	SudoPrompt:        "🔐 Antworte mit deiner sudo-PIN oder /cancel.",
+	SudoExpired:       "PIN-Anfrage abgelaufen. Führe /sudo erneut aus.",
+	SudoElevated:      "🔓 Erhöhte Rechte bis %s. Mit /sudo off vorzeitig beenden.",
+	SudoLockedOut:     "Zu viele falsche PINs. Rechteerhöhung ist vorübergehend gesperrt.",
+	SudoWrongPIN:      "Falsche PIN. Versuche es erneut oder /cancel.",
+	SudoTooMany:       "Zu viele falsche PINs. /sudo abgebrochen.",
+
+	ArgsTimedOut:     "Zeit für die Eingabe abgelaufen. Bitte versuche es erneut.",
+	InvalidInput:     "Ungültige Eingabe: %s\n\n%s",
+	SessionExpired:   "Sitzung abgelaufen. Bitte beginne von vorn.",
+	InvalidSelection: "Ungültige Auswahl: %s",
+	Selected:         "%s gewählt: %s",
+	CannotSkip:       "Überspringen nicht möglich: %s",
+	Skipped:          "%s übersprungen",
+	ChoicesFailed:    "Auswahl für %s konnte nicht geladen werden: %v",
+	DefaultValue:     "Standard: %s (Enter zum Übernehmen)",
+	DefaultChoice:    "✓ %s (Standard)",
+	HintValue:        "Wert: %s",
+	HintBetween:      "zwischen %s und %s",
+	HintAtLeast:      "mindestens %s",
+	HintAtMost:       "höchstens %s",
+	HintDuration:     "Format: 30s, 5m, 1h30m",
+	HintDate:         "Format: JJJJ-MM-TT",
+	HintLength:       "Länge: %s Zeichen",
+	Today:            "Heute (%s)",
+	Tomorrow:         "Morgen (%s)",
+	PrevArg:          "« Zurück",
+	Skip:             "Überspringen »",
+	CancelArgs:       "✖ Abbrechen",
+
+	ScheduledRunning: "Geplant: Führe /%s aus...",
+	OutputChanged:    "🔄 Ausgabe von /%s hat sich geändert:",
+	WebhookRunning:   "Webhook: Führe /%s aus...",
+	ScheduleTimes:    "Zeitplan: %s",
+	ScheduleInterval: "Intervall: %s",
+	SchedulePaused:   "Status: Pausiert",
+	ScheduleActive:   "Status: Aktiv",
+	ScheduleAction:   "/%s\n\n%s\n\nAktion wählen:",
+	RunNow:           "▶ Jetzt ausführen",
+	ResumeSchedule:   "▶ Zeitplan fortsetzen",
+	PauseSchedule:    "⏸ Zeitplan pausieren",
+
+	CleanupDisabled:     "Aufräumen ist nicht aktiviert.",
+	CleanupDisabledHint: "Aufräumen ist nicht aktiviert. Setze message_store_path in der Konfiguration.",
+	CleanupMenu:         "Gesendete Dateien aufräumen\n\nErfasste Nachrichten: %d\n\nWas soll gelöscht werden?",
+	CleanupFailed:       "Aufräumen fehlgeschlagen: %v",
+	CleanupNothing:      "Keine Nachrichten zu löschen.",
+	CleanupDone:         "Aufräumen abgeschlossen.\n\nGelöscht: %d Nachrichten",
+	CleanupSomeFailed:   "Fehlgeschlagen: %d (Nachrichten evtl. schon gelöscht oder zu alt)",
+	CleanupPreview:      "Aufräumen: %s\n\n%s",
+	CleanupDelete:       "🗑️ Löschen",
+	CleanupWillDelete:   "%s werden gelöscht",
+	CleanupLastHour:     "%d aus der letzten Stunde",
+	CleanupLastDay:      "%d von vor 1-24 Std.",
+	CleanupOlder:        "%d älter als 24 Std.",
+	CleanupFiles:        "%d Datei|%d Dateien",
+	CleanupTexts:        "%d Textnachricht|%d Textnachrichten",
+	CleanupPrompts:      "%d Menü/Eingabe|%d Menüs/Eingaben",
+	CleanupConfirms:     "%d Bestätigung|%d Bestätigungen",
+	CleanupCommands:     "%d Benutzerbefehl|%d Benutzerbefehle",
+	CleanupAllMsgsHour:  "Alle Nachrichten (1 Std.)",
+	CleanupAllMsgsDay:   "Alle Nachrichten (24 Std.)",
+	CleanupFilesHour:    "Dateien (1 Std.)",
+	CleanupFilesDay:     "Dateien (24 Std.)",
+	CleanupFilesOld1d:   "Dateien älter 1 Tag",
+	CleanupFilesOld1w:   "Dateien älter 1 Woche",
+	CleanupFilesOld1mo:  "Dateien älter 1 Monat",
+	CleanupAllFiles:     "Alle Dateien",
+	CleanupUserCmds:     "Benutzerbefehle",
+
+	UploadsDisabled:     "Datei-Uploads sind nicht aktiviert.",
+	NoDocumentInput:     "/%s akzeptiert keine Dokumente.",
+	NoPhotoInput:        "/%s akzeptiert keine Fotos.",
+	ReceiveFailed:       "Datei konnte nicht empfangen werden: %v",
+	NoVoiceTarget:       "Kein Befehl verarbeitet Sprachnachrichten. Füge eine /befehl-Beschriftung hinzu.",
+	TranscriptionFailed: "Transkription fehlgeschlagen: %v",
+	NoWords:             "In der Sprachnachricht waren keine Worte zu erkennen.",
+	InvalidArgument:     "Ungültiger Wert für %s: %v",
+
+	SettingsMenu:    "Einstellungen für diesen Chat\n\nSprache: %s\nStart-Benachrichtigungen: %s\nAusgabeformat: %s\nRuhezeiten: %s\nBefehle bestätigen: %s",
+	SettingsUsage:   "Verwendung:\n/settings language <Code> (oder default)\n/settings startup on/off\n/settings output code/plain/markdown/html\n/settings quiet 22:00-07:00 (oder off)\n/settings confirm always/default",
+	SettingsFailed:  "Einstellungen konnten nicht gespeichert werden: %v",
+	LanguageDefault: "Standard (%s)",
+	UnknownLanguage: "Unbekannte Sprache %q. Verfügbar: %s",
+	On:              "an",
+	Off:             "aus",
+	OutputCode:      "Codeblock",
+	OutputPlain:     "Klartext",
+	OutputMarkdown:  "Markdown",
+	OutputHTML:      "HTML",
+	ConfirmDefault:  "wie konfiguriert",
+	ConfirmAlways:   "immer",
+	StartupButton:   "🔔 Start: %s",
+	OutputButton:    "📝 Ausgabe: %s",
+	QuietButton:     "🌙 Ruhezeiten: %s",
+	ConfirmButton:   "✅ Bestätigen: %s",
+
+	WhoamiIdentity:     "👤 Chat-ID: %d\nBenutzer-ID: %d\nBenutzername: %s\nChat erlaubt: %s\nBenutzer erlaubt: %s",
+	WhoamiAccess:       "Rolle: %s\nSudo: %s\nOTP-Codes: %s\nJetzt ausführbare Befehle: %d von %d",
+	WhoamiSudoActive:   "aktiv, noch %s",
+	WhoamiSudoInactive: "nicht aktiv",
+	WhoamiSetUp:        "eingerichtet",
+	WhoamiNotSetUp:     "nicht eingerichtet",
+	Yes:                "ja",
+	No:                 "nein",
+
+	HistoryTitle:       "🕘 Letzte %d Befehle in diesem Chat:",
+	HistoryExit:        "Exit %d",
+	HistoryEmpty:       "In diesem Chat wurden noch keine Befehle ausgeführt.",
+	HistoryUsage:       "Verwendung: /history [Anzahl], bis zu %d",
+	HistoryFailed:      "Verlauf konnte nicht gelesen werden: %v",
+	HistoryGone:        "Dieser Befehl ist nicht mehr im Verlauf.",
+	HistoryUnavailable: "Der Verlauf ist ohne Audit-Log nicht verfügbar.",
+	HistoryRedacted:    "Die Argumente von /%s wurden im Audit-Log maskiert, daher kann es nicht aus dem Verlauf erneut ausgeführt werden. Führe es mit seinen Argumenten erneut aus.",
+
+	Pong:       "🏓 Pong",
+	PingResult: "🏓 Pong\nTelegram-API-Umlaufzeit: %s\nUpdate-Verzögerung: %s (1 s Auflösung)",
+
+	LogsTitle:       "📜 Bot-Logs, ab %s (aktualisiert %s)",
+	LogsEmpty:       "Keine Log-Einträge auf dieser Stufe.",
+	LogsUsage:       "Verwendung: /logs [Stufe] [Anzahl]; Stufe ist debug, info, warn oder error, Anzahl bis zu %d",
+	LogsAdminOnly:   "Nur Admins können die Logs des Bots lesen.",
+	LogsUnavailable: "Logs werden nicht aufbewahrt.",
+
+	Restarting:        "🔄 Bot wird neu gestartet, gleich wieder da.",
+	RestartInProgress: "🔄 Der Bot wird neu gestartet; versuche es gleich noch einmal.",
+
+	Heartbeat:      "💓 Bot läuft\nLaufzeit: %s\nLetzter Befehl: %s\nAktualisiert: %s",
+	HeartbeatNever: "keiner seit dem Start",
+	HeartbeatAgo:   "vor %s",
+
+	ShellDisabled:     "/shell ist nicht aktiviert (shell.enabled in der Konfiguration).",
+	ShellAdminOnly:    "Nur Admins können eine Shell öffnen.",
+	ShellMaintenance:  "🚧 Wartungsmodus: Die Shell ist nicht verfügbar.",
+	ShellOpened:       "🐚 Shell geöffnet (%s). Deine Nachrichten in diesem Chat laufen jetzt darin; /exit schließt sie, ebenso %s ohne Eingabe.",
+	ShellAlreadyOpen:  "In diesem Chat ist bereits eine Shell offen; /exit schließt sie.",
+	ShellStartFailed:  "Shell konnte nicht gestartet werden: %v",
+	ShellClosed:       "🐚 Shell geschlossen.",
+	ShellIdleClosed:   "🐚 Shell nach %s ohne Eingabe geschlossen.",
+	ShellExited:       "🐚 Shell beendet (%s).",
+	ShellInputDropped: "⚠️ Die Shell liest Eingaben nicht schnell genug; diese Nachricht wurde verworfen.",
+	ShellRevoked:      "🐚 Shell geschlossen: Du darfst sie nicht mehr verwenden.",
+}
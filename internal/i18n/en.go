@@ -0,0 +1,470 @@
+package i18n
+
+// Message keys, grouped by where they appear.
+const (
+	// General
+	BotRestarted      Key = "bot_restarted"
+	UnknownCommand    Key = "unknown_command"
+	CommandDisabled   Key = "command_disabled"
+	CommandNotFound   Key = "command_not_found"
+	Running           Key = "running"
+	Cancel            Key = "cancel"
+	Back              Key = "back"
+	BackToMenu        Key = "back_to_menu"
+	BackTo            Key = "back_to"
+	ProcessFailed     Key = "process_failed"
+	SendAudioFailed   Key = "send_audio_failed"
+	SendVoiceFailed   Key = "send_voice_failed"
+	SendPhotoFailed   Key = "send_photo_failed"
+	SendFileFailed    Key = "send_file_failed"
+	InvalidArgs       Key = "invalid_args"
+	TooManyRequests   Key = "too_many_requests"
+	CoolingDown       Key = "cooling_down"
+	MaintenanceDryRun Key = "maintenance_dry_run"
+
+	// Access
+	ChatUnauthorized     Key = "chat_unauthorized"
+	UserUnauthorized     Key = "user_unauthorized"
+	RequestAccess        Key = "request_access"
+	AccessGranted        Key = "access_granted"
+	AccessDenied         Key = "access_denied"
+	AccessAlreadyAllowed Key = "access_already_allowed"
+	AccessPending        Key = "access_pending"
+	AccessRequestFailed  Key = "access_request_failed"
+	AccessRequested      Key = "access_requested"
+	AccessRequestFrom    Key = "access_request_from"
+	AccessRequestGone    Key = "access_request_gone"
+	AccessApprovedBy     Key = "access_approved_by"
+	AccessDeniedBy       Key = "access_denied_by"
+	Approve              Key = "approve"
+	Deny                 Key = "deny"
+
+	// Menus
+	SelectCategory   Key = "select_category"
+	CategoryCommands Key = "category_commands"
+	CleanupButton    Key = "cleanup_button"
+	PrevPage         Key = "prev_page"
+	NextPage         Key = "next_page"
+	MenuOutdated     Key = "menu_outdated"
+	Refresh          Key = "refresh"
+
+	// Confirmations and approvals
+	Confirm               Key = "confirm"
+	ConfirmExecution      Key = "confirm_execution"
+	ConfirmExpired        Key = "confirm_expired"
+	ConfirmPhrasePrompt   Key = "confirm_phrase_prompt"
+	ConfirmDanger         Key = "confirm_danger"
+	ConfirmPhraseMismatch Key = "confirm_phrase_mismatch"
+	DryRun                Key = "dry_run"
+	DryRunRunning         Key = "dry_run_running"
+	CommandCancelled      Key = "command_cancelled"
+	NothingToCancel       Key = "nothing_to_cancel"
+	Executing             Key = "executing"
+	ApprovedExecuting     Key = "approved_executing"
+	DeniedBy              Key = "denied_by"
+	AdminsOnly            Key = "admins_only"
+	AlreadyApproved       Key = "already_approved"
+	SelfApprovalForbidden Key = "self_approval_forbidden"
+	ApprovalRequired      Key = "approval_required"
+	ApprovalRequestedBy   Key = "approval_requested_by"
+	ApprovalCount         Key = "approval_count"
+	ApprovalSent          Key = "approval_sent"
+	ApprovalRequestFailed Key = "approval_request_failed"
+	PreviewWorkdir        Key = "preview_workdir"
+	PreviewTarget         Key = "preview_target"
+	PreviewLocalHost      Key = "preview_local_host"
+
+	// One-time codes and elevation
+	OTPNotConfigured  Key = "otp_not_configured"
+	OTPPrompt         Key = "otp_prompt"
+	OTPExpired        Key = "otp_expired"
+	OTPInvalid        Key = "otp_invalid"
+	OTPTooMany        Key = "otp_too_many"
+	OTPLockedOut      Key = "otp_locked_out"
+	SudoNotConfigured Key = "sudo_not_configured"
+	SudoEnded         Key = "sudo_ended"
+	SudoNotElevated   Key = "sudo_not_elevated"
+	SudoRemaining     Key = "sudo_remaining"
+	SudoUsage         Key = "sudo_usage"
+	SudoNoPIN         Key = "sudo_no_pin"
+	SudoPrompt        Key = "sudo_prompt"
+	SudoExpired       Key = "sudo_expired"
+	SudoElevated      Key = "sudo_elevated"
+	SudoLockedOut     Key = "sudo_locked_out"
+	SudoWrongPIN      Key = "sudo_wrong_pin"
+	SudoTooMany       Key = "sudo_too_many"
+
+	// Argument prompts
+	ArgsTimedOut     Key = "args_timed_out"
+	InvalidInput     Key = "invalid_input"
+	SessionExpired   Key = "session_expired"
+	InvalidSelection Key = "invalid_selection"
+	Selected         Key = "selected"
+	CannotSkip       Key = "cannot_skip"
+	Skipped          Key = "skipped"
+	ChoicesFailed    Key = "choices_failed"
+	DefaultValue     Key = "default_value"
+	DefaultChoice    Key = "default_choice"
+	HintValue        Key = "hint_value"
+	HintBetween      Key = "hint_between"
+	HintAtLeast      Key = "hint_at_least"
+	HintAtMost       Key = "hint_at_most"
+	HintDuration     Key = "hint_duration"
+	HintDate         Key = "hint_date"
+	HintLength       Key = "hint_length"
+	Today            Key = "today"
+	Tomorrow         Key = "tomorrow"
+	PrevArg          Key = "prev_arg"
+	Skip             Key = "skip"
+	CancelArgs       Key = "cancel_args"
+
+	// Schedules
+	ScheduledRunning Key = "scheduled_running"
+	WebhookRunning   Key = "webhook_running"
+	OutputChanged    Key = "output_changed"
+	ScheduleTimes    Key = "schedule_times"
+	ScheduleInterval Key = "schedule_interval"
+	SchedulePaused   Key = "schedule_paused"
+	ScheduleActive   Key = "schedule_active"
+	ScheduleAction   Key = "schedule_action"
+	RunNow           Key = "run_now"
+	ResumeSchedule   Key = "resume_schedule"
+	PauseSchedule    Key = "pause_schedule"
+
+	// Cleanup
+	CleanupDisabled     Key = "cleanup_disabled"
+	CleanupDisabledHint Key = "cleanup_disabled_hint"
+	CleanupMenu         Key = "cleanup_menu"
+	CleanupFailed       Key = "cleanup_failed"
+	CleanupNothing      Key = "cleanup_nothing"
+	CleanupDone         Key = "cleanup_done"
+	CleanupSomeFailed   Key = "cleanup_some_failed"
+	CleanupPreview      Key = "cleanup_preview"
+	CleanupDelete       Key = "cleanup_delete"
+	CleanupWillDelete   Key = "cleanup_will_delete"
+	CleanupLastHour     Key = "cleanup_last_hour"
+	CleanupLastDay      Key = "cleanup_last_day"
+	CleanupOlder        Key = "cleanup_older"
+	CleanupFiles        Key = "cleanup_files"
+	CleanupTexts        Key = "cleanup_texts"
+	CleanupPrompts      Key = "cleanup_prompts"
+	CleanupConfirms     Key = "cleanup_confirms"
+	CleanupCommands     Key = "cleanup_commands"
+	CleanupAllMsgsHour  Key = "cleanup_all_msgs_hour"
+	CleanupAllMsgsDay   Key = "cleanup_all_msgs_day"
+	CleanupFilesHour    Key = "cleanup_files_hour"
+	CleanupFilesDay     Key = "cleanup_files_day"
+	CleanupFilesOld1d   Key = "cleanup_files_old_1d"
+	CleanupFilesOld1w   Key = "cleanup_files_old_1w"
+	CleanupFilesOld1mo  Key = "cleanup_files_old_1mo"
+	CleanupAllFiles     Key = "cleanup_all_files"
+	CleanupUserCmds     Key = "cleanup_user_cmds"
+
+	// Uploads and voice messages
+	UploadsDisabled     Key = "uploads_disabled"
+	NoDocumentInput     Key = "no_document_input"
+	NoPhotoInput        Key = "no_photo_input"
+	ReceiveFailed       Key = "receive_failed"
+	NoVoiceTarget       Key = "no_voice_target"
+	TranscriptionFailed Key = "transcription_failed"
+	NoWords             Key = "no_words"
+	InvalidArgument     Key = "invalid_argument"
+
+	// Settings
+	SettingsMenu    Key = "settings_menu"
+	SettingsUsage   Key = "settings_usage"
+	SettingsFailed  Key = "settings_failed"
+	LanguageDefault Key = "language_default"
+	UnknownLanguage Key = "unknown_language"
+	On              Key = "on"
+	Off             Key = "off"
+	OutputCode      Key = "output_code"
+	OutputPlain     Key = "output_plain"
+	OutputMarkdown  Key = "output_markdown"
+	OutputHTML      Key = "output_html"
+	ConfirmDefault  Key = "confirm_default"
+	ConfirmAlways   Key = "confirm_always"
+	StartupButton   Key = "startup_button"
+	OutputButton    Key = "output_button"
+	QuietButton     Key = "quiet_button"
+	ConfirmButton   Key = "confirm_button"
+
+	// Whoami
+	WhoamiIdentity     Key = "whoami_identity"
+	WhoamiAccess       Key = "whoami_access"
+	WhoamiSudoActive   Key = "whoami_sudo_active"
+	WhoamiSudoInactive Key = "whoami_sudo_inactive"
+	WhoamiSetUp        Key = "whoami_set_up"
+	WhoamiNotSetUp     Key = "whoami_not_set_up"
+	Yes                Key = "yes"
+	No                 Key = "no"
+
+	// Ping
+	Pong       Key = "pong"
+	PingResult Key = "ping_result"
+
+	// Logs
+	LogsTitle       Key = "logs_title"
+	LogsEmpty       Key = "logs_empty"
+	LogsUsage       Key = "logs_usage"
+	LogsAdminOnly   Key = "logs_admin_only"
+	LogsUnavailable Key = "logs_unavailable"
+
+	// Restart
+	Restarting        Key = "restarting"
+	RestartInProgress Key = "restart_in_progress"
+
+	// History
+	HistoryTitle       Key = "history_title"
+	HistoryExit        Key = "history_exit"
+	HistoryEmpty       Key = "history_empty"
+	HistoryUsage       Key = "history_usage"
+	HistoryFailed      Key = "history_failed"
+	HistoryGone        Key = "history_gone"
+	HistoryUnavailable Key = "history_unavailable"
+	HistoryRedacted    Key = "history_redacted"
+
+	// Heartbeat
+	Heartbeat      Key = "heartbeat"
+	HeartbeatNever Key = "heartbeat_never"
+	HeartbeatAgo   Key = "heartbeat_ago"
+
+	// Shell sessions
+	ShellDisabled     Key = "shell_disabled"
+	ShellAdminOnly    Key = "shell_admin_only"
+	ShellMaintenance  Key = "shell_maintenance"
+	ShellOpened       Key = "shell_opened"
+	ShellAlreadyOpen  Key = "shell_already_open"
+	ShellStartFailed  Key = "shell_start_failed"
+	ShellClosed       Key = "shell_closed"
+	ShellIdleClosed   Key = "shell_idle_closed"
+	ShellExited       Key = "shell_exited"
+	ShellInputDropped Key = "shell_input_dropped"
+	ShellRevoked      Key = "shell_revoked"
+)
+
+// english is the reference catalog; every other catalog translates its keys.
+var english = map[Key]string{
+	BotRestarted:      "Bot restarted",
+	UnknownCommand:    "Unknown command: /%s\nUse /help to see available commands.",
+	CommandDisabled:   "Command /%s is currently disabled.",
+	CommandNotFound:   "Command not found.",
+	Running:           "Running /%s...",
+	Cancel:            "Cancel",
+	Back:              "<< Back",
+	BackToMenu:        "<< Back to Menu",
+	BackTo:            "<< Back to %s",
+	ProcessFailed:     "Failed to process command: %v",
+	SendAudioFailed:   "Failed to send audio: %v",
+	SendVoiceFailed:   "Failed to send voice message: %v",
+	SendPhotoFailed:   "Failed to send image: %v",
+	SendFileFailed:    "Failed to send file: %v",
+	InvalidArgs:       "Invalid arguments: %v\nUsage: /%s name=value ...",
+	TooManyRequests:   "⏳ Too many requests. Try /%s again in %s.",
+	CoolingDown:       "⏳ /%s was run by %s %s ago and is cooling down. Try again in %s.",
+	MaintenanceDryRun: "🚧 Maintenance mode: /%s was not run.\nWould run: %s",
+
+	ChatUnauthorized:     "Unauthorized. Your chat ID (%d) is not in the allowlist.",
+	UserUnauthorized:     "Unauthorized. Your user ID (%d) is not allowed to run commands.",
+	RequestAccess:        "🔑 Request access",
+	AccessGranted:        "Access granted.",
+	AccessDenied:         "Access request denied.",
+	AccessAlreadyAllowed: "This chat already has access.",
+	AccessPending:        "Your access request is already waiting for an admin.",
+	AccessRequestFailed:  "Could not send the access request. Try again later.",
+	AccessRequested:      "Access requested. You'll be notified when an admin decides.",
+	AccessRequestFrom:    "🔑 Access request from %s",
+	AccessRequestGone:    "Access request expired or already handled.",
+	AccessApprovedBy:     "✅ %s approved access for %s. Add %d to allowed_chat_ids to keep it after a restart.",
+	AccessDeniedBy:       "❌ %s denied access for %s.",
+	Approve:              "Approve",
+	Deny:                 "Deny",
+
+	SelectCategory:   "Select a category:",
+	CategoryCommands: "%s commands:\n\nTap a command to run it.",
+	CleanupButton:    "🗑️ Cleanup",
+	PrevPage:         "‹ Prev",
+	NextPage:         "Next ›",
+	MenuOutdated:     "This menu is outdated; commands have changed.",
+	Refresh:          "🔄 Refresh",
+
+	Confirm:               "Confirm",
+	ConfirmExecution:      "Confirm execution of `/%s`?",
+	ConfirmExpired:        "Confirmation expired or invalid.",
+	ConfirmDanger:         "⚠️ Danger: %s",
+	ConfirmPhrasePrompt:   "Type `%s` to confirm, or press Cancel.",
+	ConfirmPhraseMismatch: "Text did not match. /%s cancelled.",
+	DryRun:                "🔍 Dry run",
+	DryRunRunning:         "🔍 Dry run of /%s (the command itself still awaits confirmation):",
+	CommandCancelled:      "Command cancelled.",
+	NothingToCancel:       "No active command to cancel.",
+	Executing:             "Executing /%s...",
+	ApprovedExecuting:     "Approved by %s. Executing /%s...",
+	DeniedBy:              "/%s denied by %s.",
+	AdminsOnly:            "Only admins can approve or deny /%s.",
+	AlreadyApproved:       "%s already approved /%s; another admin must approve.",
+	SelfApprovalForbidden: "%s requested /%s and can't approve it; another admin must approve.",
+	ApprovalRequired:      "Approval required for /%s",
+	ApprovalRequestedBy:   "Requested by %s in chat %d",
+	ApprovalCount:         "Approvals: %d/%d",
+	ApprovalSent:          "/%s needs %d admin approvals; request sent to the approvals chat.",
+	ApprovalRequestFailed: "Failed to request approval: %v",
+	PreviewWorkdir:        "Workdir: %s",
+	PreviewTarget:         "Runs on: %s",
+	PreviewLocalHost:      "shell on %s",
+
+	OTPNotConfigured:  "/%s requires a one-time code, but no OTP secret is configured for you.",
+	OTPPrompt:         "🔐 /%s requires a one-time code. Reply with the 6-digit code from your authenticator app, or /cancel.",
+	OTPExpired:        "Code request expired. Please run the command again.",
+	OTPInvalid:        "Invalid code. Try again, or /cancel.",
+	OTPTooMany:        "Too many invalid codes. /%s cancelled.",
+	OTPLockedOut:      "Too many invalid codes. /%s cancelled, and one-time codes are locked for a while.",
+	SudoNotConfigured: "Elevation is not configured.",
+	SudoEnded:         "Elevation ended.",
+	SudoNotElevated:   "You are not elevated.",
+	SudoRemaining:     "Elevated for another %s.",
+	SudoUsage:         "Usage: /sudo, /sudo off or /sudo status. Send the PIN only when asked.",
+	SudoNoPIN:         "No sudo PIN is configured for you.",
+	SudoPrompt:        "🔐 Reply with your sudo PIN, or /cancel.",
+	SudoExpired:       "PIN request expired. Run /sudo again.",
+	SudoElevated:      "🔓 Elevated until %s. Use /sudo off to end early.",
+	SudoLockedOut:     "Too many wrong PINs. Elevation is locked for a while.",
+	SudoWrongPIN:      "Wrong PIN. Try again, or /cancel.",
+	SudoTooMany:       "Too many wrong PINs. /sudo cancelled.",
+
+	ArgsTimedOut:     "Argument collection timed out. Please try again.",
+	InvalidInput:     "Invalid input: %s\n\n%s",
+	SessionExpired:   "Session expired. Please start over.",
+	InvalidSelection: "Invalid selection: %s",
+	Selected:         "Selected %s: %s",
+	CannotSkip:       "Cannot skip: %s",
+	Skipped:          "Skipped %s",
+	ChoicesFailed:    "Failed to load choices for %s: %v",
+	DefaultValue:     "Default: %s (press Enter to use)",
+	DefaultChoice:    "✓ %s (default)",
+	HintValue:        "Value: %s",
+	HintBetween:      "between %s and %s",
+	HintAtLeast:      "at least %s",
+	HintAtMost:       "at most %s",
+	HintDuration:     "Format: 30s, 5m, 1h30m",
+	HintDate:         "Format: YYYY-MM-DD",
+	HintLength:       "Length: %s characters",
+	Today:            "Today (%s)",
+	Tomorrow:         "Tomorrow (%s)",
+	PrevArg:          "« Back",
+	Skip:             "Skip »",
+	CancelArgs:       "✖ Cancel",
+
+	ScheduledRunning: "Scheduled: Running /%s...",
+	OutputChanged:    "🔄 /%s output changed:",
+	WebhookRunning:   "Webhook: Running /%s...",
+	ScheduleTimes:    "Schedule: %s",
+	ScheduleInterval: "Interval: %s",
+	SchedulePaused:   "Status: Paused",
+	ScheduleActive:   "Status: Running",
+	ScheduleAction:   "/%s\n\n%s\n\nSelect action:",
+	RunNow:           "▶ Run now",
+	ResumeSchedule:   "▶ Resume schedule",
+	PauseSchedule:    "⏸ Pause schedule",
+
+	CleanupDisabled:     "Cleanup is not enabled.",
+	CleanupDisabledHint: "Cleanup is not enabled. Set message_store_path in config.",
+	CleanupMenu:         "Cleanup tracked files\n\nTracked messages: %d\n\nSelect what to delete:",
+	CleanupFailed:       "Cleanup failed: %v",
+	CleanupNothing:      "No messages to delete.",
+	CleanupDone:         "Cleanup complete.\n\nDeleted: %d messages",
+	CleanupSomeFailed:   "Failed: %d (messages may already be deleted or too old)",
+	CleanupPreview:      "Cleanup: %s\n\n%s",
+	CleanupDelete:       "🗑️ Delete",
+	CleanupWillDelete:   "%s will be deleted",
+	CleanupLastHour:     "%d from the last hour",
+	CleanupLastDay:      "%d from 1-24h ago",
+	CleanupOlder:        "%d older than 24h",
+	CleanupFiles:        "%d file|%d files",
+	CleanupTexts:        "%d text message|%d text messages",
+	CleanupPrompts:      "%d menu/prompt|%d menus/prompts",
+	CleanupConfirms:     "%d confirmation|%d confirmations",
+	CleanupCommands:     "%d user command|%d user commands",
+	CleanupAllMsgsHour:  "All messages (1h)",
+	CleanupAllMsgsDay:   "All messages (24h)",
+	CleanupFilesHour:    "Files (1h)",
+	CleanupFilesDay:     "Files (24h)",
+	CleanupFilesOld1d:   "Files older 1d",
+	CleanupFilesOld1w:   "Files older 1w",
+	CleanupFilesOld1mo:  "Files older 1mo",
+	CleanupAllFiles:     "All files",
+	CleanupUserCmds:     "User commands",
+
+	UploadsDisabled:     "File uploads are not enabled.",
+	NoDocumentInput:     "/%s does not accept documents.",
+	NoPhotoInput:        "/%s does not accept photos.",
+	ReceiveFailed:       "Failed to receive file: %v",
+	NoVoiceTarget:       "No command handles voice messages. Add a /command caption.",
+	TranscriptionFailed: "Transcription failed: %v",
+	NoWords:             "Couldn't make out any words in the voice message.",
+	InvalidArgument:     "Invalid %s: %v",
+
+	SettingsMenu:    "Settings for this chat\n\nLanguage: %s\nStartup notifications: %s\nOutput format: %s\nQuiet hours: %s\nConfirm commands: %s",
+	SettingsUsage:   "Usage:\n/settings language <code> (or default)\n/settings startup on/off\n/settings output code/plain/markdown/html\n/settings quiet 22:00-07:00 (or off)\n/settings confirm always/default",
+	SettingsFailed:  "Failed to save settings: %v",
+	LanguageDefault: "Default (%s)",
+	UnknownLanguage: "Unknown language %q. Available: %s",
+	On:              "on",
+	Off:             "off",
+	OutputCode:      "code block",
+	OutputPlain:     "plain text",
+	OutputMarkdown:  "Markdown",
+	OutputHTML:      "HTML",
+	ConfirmDefault:  "as configured",
+	ConfirmAlways:   "always",
+	StartupButton:   "🔔 Startup: %s",
+	OutputButton:    "📝 Output: %s",
+	QuietButton:     "🌙 Quiet hours: %s",
+	ConfirmButton:   "✅ Confirm: %s",
+
+	WhoamiIdentity:     "👤 Chat ID: %d\nUser ID: %d\nUsername: %s\nChat allowed: %s\nUser allowed: %s",
+	WhoamiAccess:       "Role: %s\nSudo: %s\nOTP codes: %s\nCommands you can run now: %d of %d",
+	WhoamiSudoActive:   "active, %s left",
+	WhoamiSudoInactive: "not active",
+	WhoamiSetUp:        "set up",
+	WhoamiNotSetUp:     "not set up",
+	Yes:                "yes",
+	No:                 "no",
+
+	Pong:       "🏓 Pong",
+	PingResult: "🏓 Pong\nTelegram API round trip: %s\nUpdate lag: %s (1s resolution)",
+
+	LogsTitle:       "📜 Bot logs, %s and above (updated %s)",
+	LogsEmpty:       "No log records at this level.",
+	LogsUsage:       "Usage: /logs [level] [count]; level is debug, info, warn or error, count up to %d",
+	LogsAdminOnly:   "Only admins can read the bot's logs.",
+	LogsUnavailable: "Logs are not being kept.",
+
+	Restarting:        "🔄 Bot restarting, back shortly.",
+	RestartInProgress: "🔄 The bot is restarting; try again in a moment.",
+
+	HistoryTitle:       "🕘 Last %d commands in this chat:",
+	HistoryExit:        "exit %d",
+	HistoryEmpty:       "No commands have run in this chat yet.",
+	HistoryUsage:       "Usage: /history [count], up to %d",
+	HistoryFailed:      "Failed to read history: %v",
+	HistoryGone:        "This command is no longer in the history.",
+	HistoryUnavailable: "History is not available without the audit log.",
+	HistoryRedacted:    "The arguments of /%s were masked in the audit log, so it can't be re-run from history. Run it again with its arguments.",
+
+	Heartbeat:      "💓 Bot alive\nUptime: %s\nLast command: %s\nUpdated: %s",
+	HeartbeatNever: "none since start",
+	HeartbeatAgo:   "%s ago",
+
+	ShellDisabled:     "/shell is not enabled (shell.enabled in the config).",
+	ShellAdminOnly:    "Only admins can open a shell.",
+	ShellMaintenance:  "🚧 Maintenance mode: the shell is unavailable.",
+	ShellOpened:       "🐚 Shell open (%s). Your messages in this chat now run in it; /exit closes it, as do %s without input.",
+	ShellAlreadyOpen:  "A shell is already open in this chat; /exit closes it.",
+	ShellStartFailed:  "Failed to start the shell: %v",
+	ShellClosed:       "🐚 Shell closed.",
+	ShellIdleClosed:   "🐚 Shell closed after %s without input.",
+	ShellExited:       "🐚 Shell exited (%s).",
+	ShellInputDropped: "⚠️ The shell is not reading input fast enough; this message was dropped.",
+	ShellRevoked:      "🐚 Shell closed: you may no longer use it.",
+}
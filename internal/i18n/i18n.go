@@ -0,0 +1,117 @@
+// Package i18n holds the catalog of user-facing bot messages and their
+// translations. Messages are looked up by key; a language missing a message
+// falls back to English.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Default is the language used when none is configured.
+const Default = "en"
+
+// Key identifies a message in the catalog.
+type Key string
+
+// catalogs maps a language code to its messages. Messages with plural forms
+// separate them with "|" (see N).
+var catalogs = map[string]map[Key]string{
+	"en": english,
+	"de": german,
+	"ru": russian,
+}
+
+// names are the languages' own names, shown in the language picker.
+var names = map[string]string{
+	"en": "English",
+	"de": "Deutsch",
+	"ru": "Русский",
+}
+
+// Supported returns true if lang has a catalog.
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Languages returns the supported language codes, sorted.
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+	return langs
+}
+
+// Name returns the language's own name, e.g. "Deutsch", or the code if unknown.
+func Name(lang string) string {
+	if name, ok := names[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// T returns the message for key in lang, formatted with args like fmt.Sprintf.
+func T(lang string, key Key, args ...any) string {
+	text := lookup(lang, key)
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// N returns the plural form of the message for count n, formatted with args.
+// Forms are separated by "|": one|other in English and German,
+// one|few|many in Russian.
+func N(lang string, key Key, n int, args ...any) string {
+	// Plural rules must match the catalog the message comes from
+	if lookupIn(lang, key) == "" {
+		lang = Default
+	}
+	forms := strings.Split(lookup(lang, key), "|")
+	text := forms[min(pluralForm(lang, n), len(forms)-1)]
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// lookup returns the raw message for key, falling back to English and then
+// to the key itself.
+func lookup(lang string, key Key) string {
+	if text := lookupIn(lang, key); text != "" {
+		return text
+	}
+	if text := lookupIn(Default, key); text != "" {
+		return text
+	}
+	return string(key)
+}
+
+func lookupIn(lang string, key Key) string {
+	return catalogs[lang][key]
+}
+
+// pluralForm returns the index of the plural form for n in lang.
+func pluralForm(lang string, n int) int {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case "ru":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		}
+		return 2
+	}
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
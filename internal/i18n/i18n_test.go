@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// verbs matches fmt verbs, ignoring escaped percent signs.
+var verbs = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z]`)
+
+func TestCatalogsComplete(t *testing.T) {
+	pluralForms := map[string]int{"en": 2, "de": 2, "ru": 3}
+
+	for _, lang := range Languages() {
+		if _, ok := names[lang]; !ok {
+			t.Errorf("%s: missing language name", lang)
+		}
+		for key, want := range english {
+			got, ok := catalogs[lang][key]
+			if !ok {
+				t.Errorf("%s: missing %s", lang, key)
+				continue
+			}
+
+			// Plural messages: every form has the same verbs as English's
+			wantForms := strings.Split(want, "|")
+			gotForms := strings.Split(got, "|")
+			if len(wantForms) > 1 && len(gotForms) != pluralForms[lang] {
+				t.Errorf("%s: %s has %d plural forms, want %d", lang, key, len(gotForms), pluralForms[lang])
+			}
+			for _, form := range gotForms {
+				if g, w := verbs.FindAllString(form, -1), verbs.FindAllString(wantForms[0], -1); !slices.Equal(g, w) {
+					t.Errorf("%s: %s verbs = %v, want %v", lang, key, g, w)
+				}
+			}
+		}
+		for key := range catalogs[lang] {
+			if _, ok := english[key]; !ok {
+				t.Errorf("%s: %s is not in the English catalog", lang, key)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("de", Running, "deploy"); got != "Führe /deploy aus..." {
+		t.Errorf("T(de) = %q", got)
+	}
+	// Unknown languages fall back to English
+	if got := T("xx", Running, "deploy"); got != "Running /deploy..." {
+		t.Errorf("T(xx) = %q", got)
+	}
+	if got := T("en", Key("missing")); got != "missing" {
+		t.Errorf("T(missing) = %q", got)
+	}
+}
+
+func TestN(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 1, "1 file"},
+		{"en", 0, "0 files"},
+		{"de", 3, "3 Dateien"},
+		{"ru", 1, "1 файл"},
+		{"ru", 3, "3 файла"},
+		{"ru", 11, "11 файлов"},
+		{"ru", 21, "21 файл"},
+		{"ru", 25, "25 файлов"},
+		{"xx", 2, "2 files"},
+	}
+	for _, tt := range tests {
+		if got := N(tt.lang, CleanupFiles, tt.n, tt.n); got != tt.want {
+			t.Errorf("N(%s, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package i18n
+
+var russian = map[Key]string{
+	BotRestarted:      "Бот перезапущен",
+	UnknownCommand:    "Неизвестная команда: /%s\nСписок команд: /help",
+	CommandDisabled:   "Команда /%s сейчас отключена.",
+	CommandNotFound:   "Команда не найдена.",
+	Running:           "Выполняю /%s...",
+	Cancel:            "Отмена",
+	Back:              "<< Назад",
+	BackToMenu:        "<< В меню",
+	BackTo:            "<< Назад: %s",
+	ProcessFailed:     "Не удалось обработать команду: %v",
+	SendAudioFailed:   "Не удалось отправить аудио: %v",
+	SendVoiceFailed:   "Не удалось отправить голосовое сообщение: %v",
+	SendPhotoFailed:   "Не удалось отправить изображение: %v",
+	SendFileFailed:    "Не удалось отправить файл: %v",
+	InvalidArgs:       "Неверные аргументы: %v\nИспользование: /%s имя=значение ...",
+	TooManyRequests:   "⏳ Слишком много запросов. Повторите /%s через %s.",
+	MaintenanceDryRun: "🚧 Режим обслуживания: /%s не запущена.\nБыло бы запущено: %s",
+	CoolingDown:       "⏳ /%s запускал %s %s назад, действует пауза. Повторите через %s.",
+
+	ChatUnauthorized:     "Нет доступа. ID чата (%d) не в списке разрешённых.",
+	UserUnauthorized:     "Нет доступа. Пользователю с ID %d запрещено выполнять команды.",
+	RequestAccess:        "🔑 Запросить доступ",
+	AccessGranted:        "Доступ предоставлен.",
+	AccessDenied:         "В доступе отказано.",
+	AccessAlreadyAllowed: "У этого чата уже есть доступ.",
+	AccessPending:        "Ваш запрос доступа уже ожидает решения администратора.",
+	AccessRequestFailed:  "Не удалось отправить запрос доступа. Попробуйте позже.",
+	AccessRequested:      "Доступ запрошен. Вы получите уведомление, когда администратор примет решение.",
+	AccessRequestFrom:    "🔑 Запрос доступа от %s",
+	AccessRequestGone:    "Запрос доступа истёк или уже обработан.",
+	AccessApprovedBy:     "✅ %s разрешил(а) доступ для %s. Добавьте %d в allowed_chat_ids, чтобы доступ сохранился после перезапуска.",
+	AccessDeniedBy:       "❌ %s отклонил(а) доступ для %s.",
+	Approve:              "Одобрить",
+	Deny:                 "Отклонить",
+
+	SelectCategory:   "Выберите категорию:",
+	CategoryCommands: "Команды %s:\n\nНажмите на команду, чтобы выполнить её.",
+	CleanupButton:    "🗑️ Очистка",
+	PrevPage:         "‹ Назад",
+	NextPage:         "Далее ›",
+	MenuOutdated:     "Это меню устарело: команды изменились.",
+	Refresh:          "🔄 Обновить",
+
+	Confirm:               "Подтвердить",
+	ConfirmExecution:      "Подтвердить выполнение `/%s`?",
+	ConfirmExpired:        "Подтверждение истекло или недействительно.",
+	ConfirmDanger:         "⚠️ Внимание: %s",
+	ConfirmPhrasePrompt:   "Введите `%s` для подтверждения или нажмите «Отмена».",
+	DryRun:                "🔍 Пробный запуск",
+	DryRunRunning:         "🔍 Пробный запуск /%s (сама команда всё ещё ждёт подтверждения):",
+	ConfirmPhraseMismatch: "Текст не совпал. /%s отменена.",
+	CommandCancelled:      "Команда отменена.",
+	NothingToCancel:       "Нет активной команды для отмены.",
+	Executing:             "Выполняю /%s...",
+	ApprovedExecuting:     "Одобрено: %s. Выполняю /%s...",
+	DeniedBy:              "/%s отклонена: %s.",
+	AdminsOnly:            "Одобрить или отклонить /%s могут только администраторы.",
+	AlreadyApproved:       "%s уже одобрил(а) /%s; нужно одобрение другого администратора.",
+	SelfApprovalForbidden: "%s запросил(а) /%s и не может это одобрить; нужно одобрение другого админа.",
+	ApprovalRequired:      "Требуется одобрение для /%s",
+	ApprovalRequestedBy:   "Запросил(а) %s в чате %d",
+	ApprovalCount:         "Одобрения: %d/%d",
+	ApprovalSent:          "/%s требует одобрения администраторов (%d); запрос отправлен в чат одобрений.",
+	ApprovalRequestFailed: "Не удалось запросить одобрение: %v",
+	PreviewWorkdir:        "Рабочий каталог: %s",
+	PreviewTarget:         "Выполняется на: %s",
+	PreviewLocalHost:      "оболочка на %s",
+
+	OTPNotConfigured:  "/%s требует одноразовый код, но для вас не настроен секрет OTP.",
+	OTPPrompt:         "🔐 /%s требует одноразовый код. Ответьте 6-значным кодом из приложения-аутентификатора или /cancel.",
+	OTPExpired:        "Запрос кода истёк. Запустите команду ещё раз.",
+	OTPInvalid:        "Неверный код. Попробуйте ещё раз или /cancel.",
+	OTPTooMany:        "Слишком много неверных кодов. /%s отменена.",
+	OTPLockedOut:      "Слишком много неверных кодов. /%s отменена, одноразовые коды временно заблокированы.",
+	SudoNotConfigured: "Повышение прав не настроено.",
+	SudoEnded:         "Повышение прав завершено.",
+	SudoNotElevated:   "У вас нет повышенных прав.",
+	SudoRemaining:     "Повышенные права ещё %s.",
+	SudoUsage:         "Использование: /sudo, /sudo off или /sudo status. Отправляйте PIN только по запросу.",
+	SudoNoPIN:         "Для вас не настроен PIN для sudo.",
+	SudoPrompt:        "🔐 Ответьте PIN-кодом для sudo или /cancel.",
+	SudoExpired:       "Запрос PIN истёк. Запустите /sudo ещё раз.",
+	SudoElevated:      "🔓 Повышенные права до %s. /sudo off — завершить раньше.",
+	SudoLockedOut:     "Слишком много неверных PIN. Повышение прав временно заблокировано.",
+	SudoWrongPIN:      "Неверный PIN. Попробуйте ещё раз или /cancel.",
+	SudoTooMany:       "Слишком много неверных PIN. /sudo отменена.",
+
+	ArgsTimedOut:     "Время ввода истекло. Попробуйте ещё раз.",
+	InvalidInput:     "Неверный ввод: %s\n\n%s",
+	SessionExpired:   "Сессия истекла. Начните заново.",
+	InvalidSelection: "Неверный выбор: %s",
+	Selected:         "Выбрано %s: %s",
+	CannotSkip:       "Нельзя пропустить: %s",
+	Skipped:          "Пропущено: %s",
+	ChoicesFailed:    "Не удалось загрузить варианты для %s: %v",
+	DefaultValue:     "По умолчанию: %s (нажмите Enter)",
+	DefaultChoice:    "✓ %s (по умолчанию)",
+	HintValue:        "Значение: %s",
+	HintBetween:      "от %s до %s",
+	HintAtLeast:      "не меньше %s",
+	HintAtMost:       "не больше %s",
+	HintDuration:     "Формат: 30s, 5m, 1h30m",
+	HintDate:         "Формат: ГГГГ-ММ-ДД",
+	HintLength:       "Длина: %s символов",
+	Today:            "Сегодня (%s)",
+	Tomorrow:         "Завтра (%s)",
+	PrevArg:          "« Назад",
+	Skip:             "Пропустить »",
+	CancelArgs:       "✖ Отмена",
+
+	ScheduledRunning: "По расписанию: выполняю /%s...",
+	OutputChanged:    "🔄 Вывод /%s изменился:",
+	WebhookRunning:   "Вебхук: выполняю /%s...",
+	ScheduleTimes:    "Расписание: %s",
+	ScheduleInterval: "Интервал: %s",
+	SchedulePaused:   "Статус: на паузе",
+	ScheduleActive:   "Статус: активно",
+	ScheduleAction:   "/%s\n\n%s\n\nВыберите действие:",
+	RunNow:           "▶ Выполнить сейчас",
+	ResumeSchedule:   "▶ Возобновить расписание",
+	PauseSchedule:    "⏸ Приостановить расписание",
+
+	CleanupDisabled:     "Очистка не включена.",
+	CleanupDisabledHint: "Очистка не включена. Задайте message_store_path в конфигурации.",
+	CleanupMenu:         "Очистка отправленных файлов\n\nОтслеживается сообщений: %d\n\nЧто удалить?",
+	CleanupFailed:       "Ошибка очистки: %v",
+	CleanupNothing:      "Нет сообщений для удаления.",
+	CleanupDone:         "Очистка завершена.\n\nУдалено сообщений: %d",
+	CleanupSomeFailed:   "Не удалось: %d (сообщения могли быть уже удалены или слишком старые)",
+	CleanupPreview:      "Очистка: %s\n\n%s",
+	CleanupDelete:       "🗑️ Удалить",
+	CleanupWillDelete:   "Будет удалено: %s",
+	CleanupLastHour:     "%d за последний час",
+	CleanupLastDay:      "%d за 1-24 ч",
+	CleanupOlder:        "%d старше 24 ч",
+	CleanupFiles:        "%d файл|%d файла|%d файлов",
+	CleanupTexts:        "%d текстовое сообщение|%d текстовых сообщения|%d текстовых сообщений",
+	CleanupPrompts:      "%d меню/запрос|%d меню/запроса|%d меню/запросов",
+	CleanupConfirms:     "%d подтверждение|%d подтверждения|%d подтверждений",
+	CleanupCommands:     "%d команда пользователя|%d команды пользователей|%d команд пользователей",
+	CleanupAllMsgsHour:  "Все сообщения (1 ч)",
+	CleanupAllMsgsDay:   "Все сообщения (24 ч)",
+	CleanupFilesHour:    "Файлы (1 ч)",
+	CleanupFilesDay:     "Файлы (24 ч)",
+	CleanupFilesOld1d:   "Файлы старше 1 дня",
+	CleanupFilesOld1w:   "Файлы старше 1 недели",
+	CleanupFilesOld1mo:  "Файлы старше 1 месяца",
+	CleanupAllFiles:     "Все файлы",
+	CleanupUserCmds:     "Команды пользователей",
+
+	UploadsDisabled:     "Загрузка файлов не включена.",
+	NoDocumentInput:     "/%s не принимает документы.",
+	NoPhotoInput:        "/%s не принимает фото.",
+	ReceiveFailed:       "Не удалось получить файл: %v",
+	NoVoiceTarget:       "Ни одна команда не обрабатывает голосовые сообщения. Добавьте подпись /команда.",
+	TranscriptionFailed: "Ошибка распознавания: %v",
+	NoWords:             "В голосовом сообщении не удалось распознать слова.",
+	InvalidArgument:     "Неверное значение %s: %v",
+
+	SettingsMenu:    "Настройки этого чата\n\nЯзык: %s\nУведомления о запуске: %s\nФормат вывода: %s\nТихие часы: %s\nПодтверждать команды: %s",
+	SettingsUsage:   "Использование:\n/settings language <код> (или default)\n/settings startup on/off\n/settings output code/plain/markdown/html\n/settings quiet 22:00-07:00 (или off)\n/settings confirm always/default",
+	SettingsFailed:  "Не удалось сохранить настройки: %v",
+	LanguageDefault: "По умолчанию (%s)",
+	UnknownLanguage: "Неизвестный язык %q. Доступны: %s",
+	On:              "вкл.",
+	Off:             "выкл.",
+	OutputCode:      "блок кода",
+	OutputPlain:     "обычный текст",
+	OutputMarkdown:  "Markdown",
+	OutputHTML:      "HTML",
+	ConfirmDefault:  "как настроено",
+	ConfirmAlways:   "всегда",
+	StartupButton:   "🔔 Запуск: %s",
+	OutputButton:    "📝 Вывод: %s",
+	QuietButton:     "🌙 Тихие часы: %s",
+	ConfirmButton:   "✅ Подтверждение: %s",
+
+	WhoamiIdentity:     "👤 ID чата: %d\nID пользователя: %d\nИмя пользователя: %s\nЧат разрешён: %s\nПользователь разрешён: %s",
+	WhoamiAccess:       "Роль: %s\nSudo: %s\nOTP-коды: %s\nДоступно команд сейчас: %d из %d",
+	WhoamiSudoActive:   "активен, осталось %s",
+	WhoamiSudoInactive: "не активен",
+	WhoamiSetUp:        "настроены",
+	WhoamiNotSetUp:     "не настроены",
+	Yes:                "да",
+	No:                 "нет",
+
+	HistoryTitle:       "🕘 Последние команды в этом чате (%d):",
+	HistoryExit:        "код %d",
+	HistoryEmpty:       "В этом чате ещё не запускались команды.",
+	HistoryUsage:       "Использование: /history [количество], не больше %d",
+	HistoryFailed:      "Не удалось прочитать историю: %v",
+	HistoryGone:        "Этой команды больше нет в истории.",
+	HistoryUnavailable: "История недоступна без журнала аудита.",
+	HistoryRedacted:    "Аргументы /%s скрыты в журнале аудита, поэтому её нельзя повторить из истории. Запустите её снова с аргументами.",
+
+	Pong:       "🏓 Понг",
+	PingResult: "🏓 Понг\nЗадержка Telegram API (туда и обратно): %s\nЗадержка обновления: %s (точность 1 с)",
+
+	LogsTitle:       "📜 Логи бота, уровень %s и выше (обновлено %s)",
+	LogsEmpty:       "Нет записей на этом уровне.",
+	LogsUsage:       "Использование: /logs [уровень] [количество]; уровень: debug, info, warn или error, количество до %d",
+	LogsAdminOnly:   "Только администраторы могут читать логи бота.",
+	LogsUnavailable: "Логи не сохраняются.",
+
+	Restarting:        "🔄 Бот перезапускается, скоро вернётся.",
+	RestartInProgress: "🔄 Бот перезапускается; попробуйте чуть позже.",
+
+	Heartbeat:      "💓 Бот работает\nАптайм: %s\nПоследняя команда: %s\nОбновлено: %s",
+	HeartbeatNever: "не было с запуска",
+	HeartbeatAgo:   "%s назад",
+
+	ShellDisabled:     "/shell не включён (shell.enabled в конфигурации).",
+	ShellAdminOnly:    "Открыть оболочку могут только администраторы.",
+	ShellMaintenance:  "🚧 Режим обслуживания: оболочка недоступна.",
+	ShellOpened:       "🐚 Оболочка открыта (%s). Ваши сообщения в этом чате теперь выполняются в ней; /exit закрывает её, как и %s без ввода.",
+	ShellAlreadyOpen:  "В этом чате уже открыта оболочка; /exit закрывает её.",
+	ShellStartFailed:  "Не удалось запустить оболочку: %v",
+	ShellClosed:       "🐚 Оболочка закрыта.",
+	ShellIdleClosed:   "🐚 Оболочка закрыта после %s без ввода.",
+	ShellExited:       "🐚 Оболочка завершилась (%s).",
+	ShellInputDropped: "⚠️ Оболочка не успевает читать ввод; это сообщение отброшено.",
+	ShellRevoked:      "🐚 Оболочка закрыта: у вас больше нет к ней доступа.",
+}
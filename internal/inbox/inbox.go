@@ -0,0 +1,146 @@
+// Package inbox stores files uploaded by users so commands can process them.
+// Files live under one directory per chat and are removed after a retention
+// period.
+package inbox
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetention is how long uploaded files are kept when not configured.
+const DefaultRetention = 7 * 24 * time.Hour
+
+// unsafeChars matches characters replaced in stored file names, so paths are
+// safe to pass to shell commands unquoted.
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Inbox is a managed directory of uploaded files.
+type Inbox struct {
+	dir       string
+	retention time.Duration
+	now       func() time.Time
+}
+
+// New creates the inbox directory if needed. A zero retention uses
+// DefaultRetention.
+func New(dir string, retention time.Duration) (*Inbox, error) {
+	if retention < 0 {
+		return nil, fmt.Errorf("inbox retention must not be negative")
+	}
+	if retention == 0 {
+		retention = DefaultRetention
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create inbox: %w", err)
+	}
+	return &Inbox{dir: dir, retention: retention, now: time.Now}, nil
+}
+
+// Dir returns the absolute inbox directory.
+func (i *Inbox) Dir() string {
+	return i.dir
+}
+
+// Save writes r to a new file for the chat and returns its absolute path.
+// The name is sanitized and prefixed with a timestamp to keep it unique.
+// At most maxSize bytes are accepted (0 = unlimited).
+func (i *Inbox) Save(chatID int64, name string, r io.Reader, maxSize int64) (string, error) {
+	chatDir := filepath.Join(i.dir, strconv.FormatInt(chatID, 10))
+	if err := os.MkdirAll(chatDir, 0700); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(chatDir, i.now().Format("20060102-150405")+"-*-"+sanitize(name))
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+
+	if maxSize > 0 {
+		r = io.LimitReader(r, maxSize+1)
+	}
+	n, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && maxSize > 0 && n > maxSize {
+		err = fmt.Errorf("file exceeds %d bytes", maxSize)
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// Contains returns true if path is a file saved by Save for the chat.
+func (i *Inbox) Contains(chatID int64, path string) bool {
+	chatDir := filepath.Join(i.dir, strconv.FormatInt(chatID, 10))
+	return filepath.Dir(filepath.Clean(path)) == chatDir
+}
+
+// Name returns the sanitized name a file saved by Save was uploaded as,
+// without the prefix that keeps it unique.
+func Name(path string) string {
+	parts := strings.SplitN(filepath.Base(path), "-", 4) // Date, time, random, name
+	if len(parts) < 4 {
+		return filepath.Base(path)
+	}
+	return parts[3]
+}
+
+// Prune deletes files older than the retention period and returns how many
+// were removed. Empty chat directories are removed too.
+func (i *Inbox) Prune() (int, error) {
+	cutoff := i.now().Add(-i.retention)
+	removed := 0
+	err := filepath.WalkDir(i.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Removed concurrently
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(path); err == nil {
+				removed++
+			}
+		}
+		return nil
+	})
+
+	// Drop chat directories left empty (Remove fails on non-empty ones)
+	if entries, readErr := os.ReadDir(i.dir); readErr == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				os.Remove(filepath.Join(i.dir, e.Name()))
+			}
+		}
+	}
+	return removed, err
+}
+
+// sanitize reduces a user-supplied file name to safe characters.
+func sanitize(name string) string {
+	name = unsafeChars.ReplaceAllString(filepath.Base(name), "_")
+	name = strings.Trim(name, "._")
+	if name == "" {
+		return "upload"
+	}
+	if len(name) > 100 {
+		name = name[len(name)-100:]
+	}
+	return name
+}
@@ -0,0 +1,100 @@
+package inbox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSave(t *testing.T) {
+	in, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := in.Save(-100123, "../my backup (1).tar.gz", strings.NewReader("data"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != filepath.Join(in.Dir(), "-100123") {
+		t.Errorf("path = %q, want inside chat directory", path)
+	}
+	if !strings.HasSuffix(path, "-my_backup_1_.tar.gz") {
+		t.Errorf("path = %q, want sanitized name", path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "data" {
+		t.Errorf("content = %q, %v", data, err)
+	}
+
+	if _, err := in.Save(1, "big.bin", strings.NewReader("too much"), 4); err == nil {
+		t.Error("expected size error")
+	}
+	if entries, _ := os.ReadDir(filepath.Join(in.Dir(), "1")); len(entries) != 0 {
+		t.Errorf("oversized file left behind: %v", entries)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	in, err := New(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, _ := in.Save(1, "old.txt", strings.NewReader("old"), 0)
+	fresh, _ := in.Save(2, "fresh.txt", strings.NewReader("fresh"), 0)
+	past := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := in.Prune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if _, err := os.Stat(filepath.Dir(old)); !os.IsNotExist(err) {
+		t.Errorf("empty chat directory kept: %v", err)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("fresh file removed: %v", err)
+	}
+}
+
+func TestContainsAndName(t *testing.T) {
+	in, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := in.Save(-100123, "nginx-site.conf", strings.NewReader("data"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !in.Contains(-100123, path) {
+		t.Errorf("Contains(%q) = false", path)
+	}
+	if in.Contains(42, path) {
+		t.Errorf("Contains(%q) = true for another chat", path)
+	}
+	for _, p := range []string{
+		"/etc/passwd",
+		in.Dir(),
+		filepath.Join(in.Dir(), "stray.txt"),
+		filepath.Join(in.Dir(), "1", "2", "deep.txt"),
+		filepath.Join(in.Dir(), "..", "outside.txt"),
+		filepath.Join(in.Dir(), "-100123", "..", "-100124", "other.txt"),
+		filepath.Join(in.Dir(), "-100123", "sub", "deep.txt"),
+	} {
+		if in.Contains(-100123, p) {
+			t.Errorf("Contains(%q) = true", p)
+		}
+	}
+
+	if name := Name(path); name != "nginx-site.conf" {
+		t.Errorf("Name() = %q, want nginx-site.conf", name)
+	}
+}
@@ -0,0 +1,191 @@
+// Package logbuf keeps the bot's most recent log records in memory, so
+// they can be read back from Telegram, and can mirror warnings and errors
+// to a chat.
+package logbuf
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSize is how many records a buffer keeps unless configured.
+const DefaultSize = 500
+
+// Record is a captured log record.
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   string // key=value pairs, as in the text log
+}
+
+// String formats the record as a log line with the time of day.
+func (r Record) String() string {
+	s := r.Time.Format("15:04:05") + " " + r.Level.String() + " " + r.Message
+	if r.Attrs != "" {
+		s += " " + r.Attrs
+	}
+	return s
+}
+
+// Buffer is a ring buffer of the last records. Safe for concurrent use.
+type Buffer struct {
+	mu      sync.Mutex
+	records []Record
+	next    int // Where the next record goes once the buffer is full
+	size    int
+}
+
+// New creates a buffer keeping up to size records (DefaultSize if not
+// positive).
+func New(size int) *Buffer {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Buffer{size: size}
+}
+
+// Resize changes how many records are kept, dropping the oldest if the
+// buffer shrinks.
+func (b *Buffer) Resize(size int) {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	records := b.ordered()
+	if len(records) > size {
+		records = records[len(records)-size:]
+	}
+	b.records = records
+	b.next = 0
+	b.size = size
+}
+
+// Add stores a record, replacing the oldest if the buffer is full.
+func (b *Buffer) Add(r Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.records) < b.size {
+		b.records = append(b.records, r)
+		return
+	}
+	b.records[b.next] = r
+	b.next = (b.next + 1) % b.size
+}
+
+// Records returns up to limit of the newest records at or above level,
+// oldest first. A limit of 0 returns all of them.
+func (b *Buffer) Records(level slog.Level, limit int) []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []Record
+	for _, r := range b.ordered() {
+		if r.Level >= level {
+			out = append(out, r)
+		}
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+// ordered returns a copy of the records, oldest first. Called with mu held.
+func (b *Buffer) ordered() []Record {
+	out := make([]Record, 0, len(b.records))
+	out = append(out, b.records[b.next:]...)
+	return append(out, b.records[:b.next]...)
+}
+
+// Handler is a slog.Handler that copies records into a Buffer and passes
+// them on to another handler.
+type Handler struct {
+	next   slog.Handler
+	buf    *Buffer
+	mirror *Mirror // Optional, forwards warnings and errors
+	attrs  string  // Attributes added with WithAttrs, already formatted
+	prefix string  // Group prefix for attribute keys, e.g. "req."
+}
+
+// NewHandler returns a handler recording into buf and forwarding to next.
+// Records below next's level are neither kept nor forwarded.
+func NewHandler(next slog.Handler, buf *Buffer) *Handler {
+	return &Handler{next: next, buf: buf}
+}
+
+// SetMirror also passes warnings and errors to m. Call it before deriving
+// handlers with WithAttrs or WithGroup.
+func (h *Handler) SetMirror(m *Mirror) {
+	h.mirror = m
+}
+
+// Enabled reports whether the wrapped handler handles level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle stores the record and forwards it.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	var sb strings.Builder
+	sb.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&sb, h.prefix, a)
+		return true
+	})
+	record := Record{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: sb.String()}
+	h.buf.Add(record)
+	if h.mirror != nil {
+		h.mirror.add(record)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a handler that adds attrs to every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var sb strings.Builder
+	sb.WriteString(h.attrs)
+	for _, a := range attrs {
+		appendAttr(&sb, h.prefix, a)
+	}
+	return &Handler{next: h.next.WithAttrs(attrs), buf: h.buf, mirror: h.mirror, attrs: sb.String(), prefix: h.prefix}
+}
+
+// WithGroup returns a handler that nests later attributes under name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{next: h.next.WithGroup(name), buf: h.buf, mirror: h.mirror, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// appendAttr writes a as key=value, space separated, flattening groups
+// into dotted keys as slog's text handler does.
+func appendAttr(sb *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, g := range a.Value.Group() {
+			appendAttr(sb, prefix, g)
+		}
+		return
+	}
+	if sb.Len() > 0 {
+		sb.WriteByte(' ')
+	}
+	sb.WriteString(prefix + a.Key + "=")
+	v := a.Value.String()
+	if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+		v = strconv.Quote(v)
+	}
+	sb.WriteString(v)
+}
@@ -0,0 +1,74 @@
+package logbuf
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBufferWraps(t *testing.T) {
+	b := New(3)
+	for i, msg := range []string{"a", "b", "c", "d", "e"} {
+		level := slog.LevelInfo
+		if i%2 == 1 {
+			level = slog.LevelWarn
+		}
+		b.Add(Record{Level: level, Message: msg})
+	}
+
+	if got := messages(b.Records(slog.LevelDebug, 0)); got != "c d e" {
+		t.Errorf("Records() = %q, want %q", got, "c d e")
+	}
+	if got := messages(b.Records(slog.LevelDebug, 2)); got != "d e" {
+		t.Errorf("Records(limit 2) = %q, want %q", got, "d e")
+	}
+	if got := messages(b.Records(slog.LevelWarn, 0)); got != "d" {
+		t.Errorf("Records(warn) = %q, want %q", got, "d")
+	}
+
+	b.Resize(2)
+	if got := messages(b.Records(slog.LevelDebug, 0)); got != "d e" {
+		t.Errorf("after shrinking: %q, want %q", got, "d e")
+	}
+	b.Resize(4)
+	b.Add(Record{Message: "f"})
+	if got := messages(b.Records(slog.LevelDebug, 0)); got != "d e f" {
+		t.Errorf("after growing: %q, want %q", got, "d e f")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var out bytes.Buffer
+	buf := New(10)
+	logger := slog.New(NewHandler(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelInfo}), buf))
+
+	logger.Debug("hidden")
+	logger.With("chat_id", 42).WithGroup("cmd").Info("ran", "name", "uptime", "err", "exit status 1")
+
+	records := buf.Records(slog.LevelDebug, 0)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	want := `chat_id=42 cmd.name=uptime cmd.err="exit status 1"`
+	if records[0].Attrs != want {
+		t.Errorf("Attrs = %q, want %q", records[0].Attrs, want)
+	}
+	if !strings.Contains(out.String(), "msg=ran") {
+		t.Errorf("record not forwarded: %q", out.String())
+	}
+
+	r := Record{Time: time.Date(2025, 1, 1, 9, 5, 0, 0, time.UTC), Level: slog.LevelWarn, Message: "slow", Attrs: "ms=900"}
+	if got := r.String(); got != "09:05:00 WARN slow ms=900" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func messages(records []Record) string {
+	var msgs []string
+	for _, r := range records {
+		msgs = append(msgs, r.Message)
+	}
+	return strings.Join(msgs, " ")
+}
@@ -0,0 +1,120 @@
+package logbuf
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// maxPending bounds how many records a mirror holds before it is
+// configured, e.g. warnings logged while loading commands at startup.
+const maxPending = 20
+
+// Mirror forwards warnings and errors to a chat. A message repeated within
+// the window is forwarded once, and at most limit records are forwarded
+// per window; the number held back is reported with the next one sent.
+// Safe for concurrent use.
+type Mirror struct {
+	mu         sync.Mutex
+	configured bool
+	send       func(text string) // nil when disabled
+	level      slog.Level
+	window     time.Duration
+	limit      int
+	pending    []Record             // Records logged before Configure
+	seen       map[string]time.Time // Message -> when it was last forwarded
+	sent       []time.Time          // Forwards within the current window
+	suppressed int
+	now        func() time.Time
+}
+
+// NewMirror creates a mirror that holds records until it is configured.
+func NewMirror() *Mirror {
+	return &Mirror{
+		seen: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Configure forwards records at or above level through send, each message
+// once per window and at most limit per window. A nil send disables the
+// mirror. Records held since startup are forwarded or dropped accordingly.
+func (m *Mirror) Configure(send func(text string), level slog.Level, window time.Duration, limit int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.send = send
+	m.level = level
+	m.window = window
+	m.limit = limit
+
+	pending := m.pending
+	m.pending = nil
+	m.configured = true
+	for _, r := range pending {
+		m.forward(r)
+	}
+}
+
+// add forwards r if it qualifies, or holds it until the mirror is
+// configured.
+func (m *Mirror) add(r Record) {
+	if r.Level < slog.LevelWarn {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.configured {
+		if len(m.pending) < maxPending {
+			m.pending = append(m.pending, r)
+		}
+		return
+	}
+	m.forward(r)
+}
+
+// forward sends r unless it is below the level, a repeat, or over the
+// limit. Called with mu held; the send itself runs in its own goroutine
+// so that logging from the sender can't deadlock.
+func (m *Mirror) forward(r Record) {
+	if m.send == nil || r.Level < m.level {
+		return
+	}
+	now := m.now()
+
+	key := r.Level.String() + " " + r.Message + " " + r.Attrs
+	if last, ok := m.seen[key]; ok && now.Sub(last) < m.window {
+		m.suppressed++
+		return
+	}
+	for len(m.sent) > 0 && now.Sub(m.sent[0]) >= m.window {
+		m.sent = m.sent[1:]
+	}
+	if len(m.sent) >= m.limit {
+		m.suppressed++
+		return
+	}
+	for k, t := range m.seen {
+		if now.Sub(t) >= m.window {
+			delete(m.seen, k)
+		}
+	}
+	m.seen[key] = now
+	m.sent = append(m.sent, now)
+
+	text := mirrorText(r)
+	if m.suppressed > 0 {
+		text += fmt.Sprintf("\n(%d more held back)", m.suppressed)
+		m.suppressed = 0
+	}
+	go m.send(text)
+}
+
+// mirrorText formats a forwarded record.
+func mirrorText(r Record) string {
+	icon := "⚠️"
+	if r.Level >= slog.LevelError {
+		icon = "🔴"
+	}
+	return icon + " " + r.String()
+}
@@ -0,0 +1,73 @@
+package logbuf
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sink collects forwarded texts.
+type sink struct {
+	mu    sync.Mutex
+	texts []string
+	wg    sync.WaitGroup
+}
+
+func (s *sink) send(text string) {
+	defer s.wg.Done()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.texts = append(s.texts, text)
+}
+
+func TestMirror(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMirror()
+	m.now = func() time.Time { return now }
+
+	// Held until configured
+	m.add(Record{Time: now, Level: slog.LevelWarn, Message: "failed to load commands"})
+	m.add(Record{Time: now, Level: slog.LevelInfo, Message: "starting bot"})
+
+	s := &sink{}
+	s.wg.Add(1)
+	m.Configure(s.send, slog.LevelWarn, time.Minute, 2)
+
+	s.wg.Add(1)
+	m.add(Record{Time: now, Level: slog.LevelError, Message: "send failed"})
+	m.add(Record{Time: now, Level: slog.LevelError, Message: "send failed"}) // Repeat
+	m.add(Record{Time: now, Level: slog.LevelError, Message: "other"})       // Over the limit
+
+	now = now.Add(time.Minute)
+	s.wg.Add(1)
+	m.add(Record{Time: now, Level: slog.LevelError, Message: "send failed"})
+	s.wg.Wait()
+
+	if len(s.texts) != 3 {
+		t.Fatalf("forwarded %d records, want 3: %q", len(s.texts), s.texts)
+	}
+	var sawHeld bool
+	for _, text := range s.texts {
+		if strings.Contains(text, "starting bot") {
+			t.Errorf("info record forwarded: %q", text)
+		}
+		if strings.Contains(text, "(2 more held back)") {
+			sawHeld = true
+		}
+	}
+	if !sawHeld {
+		t.Errorf("held back count not reported: %q", s.texts)
+	}
+}
+
+func TestMirrorDisabled(t *testing.T) {
+	m := NewMirror()
+	m.add(Record{Level: slog.LevelError, Message: "early"})
+	m.Configure(nil, slog.LevelWarn, time.Minute, 10)
+	m.add(Record{Level: slog.LevelError, Message: "late"})
+	if len(m.pending) != 0 || len(m.sent) != 0 {
+		t.Errorf("disabled mirror kept %d pending, sent %d", len(m.pending), len(m.sent))
+	}
+}
@@ -3,7 +3,9 @@
 package msgstore
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"sync"
 	"time"
@@ -17,8 +19,23 @@ const (
 	TypeText MessageType = "text"
 	// TypeFile is a file/media message.
 	TypeFile MessageType = "file"
+	// TypePrompt is an argument prompt or menu sent while collecting input.
+	TypePrompt MessageType = "prompt"
+	// TypeConfirmation is a confirmation or approval request.
+	TypeConfirmation MessageType = "confirmation"
+	// TypeCommand is a user's own /command message that triggered the bot.
+	TypeCommand MessageType = "command"
 )
 
+// ParseMessageType validates a message type name from configuration.
+func ParseMessageType(s string) (MessageType, error) {
+	switch t := MessageType(s); t {
+	case TypeText, TypeFile, TypePrompt, TypeConfirmation, TypeCommand:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown message type %q (want text, file, prompt, confirmation or command)", s)
+}
+
 // Entry represents a stored message.
 type Entry struct {
 	ChatID    int64       `json:"chat_id"`
@@ -163,6 +180,34 @@ func (s *Store) GetAfterByType(chatID int64, after time.Time, msgType MessageTyp
 	return result
 }
 
+// GetAllByType returns all entries of a specific type for a chat.
+func (s *Store) GetAllByType(chatID int64, msgType MessageType) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Entry
+	for _, e := range s.entries {
+		if e.ChatID == chatID && e.entryType() == msgType {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// GetBeforeByType returns entries of specific type sent before the specified time.
+func (s *Store) GetBeforeByType(chatID int64, before time.Time, msgType MessageType) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Entry
+	for _, e := range s.entries {
+		if e.ChatID == chatID && e.SentAt.Before(before) && e.entryType() == msgType {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
 // Remove deletes entries by message IDs.
 func (s *Store) Remove(chatID int64, messageIDs []int) error {
 	if len(messageIDs) == 0 {
@@ -218,6 +263,11 @@ func (s *Store) CountByType(chatID int64, msgType MessageType) int {
 	return count
 }
 
+// Kind returns the message type, treating untyped entries as files.
+func (e Entry) Kind() MessageType {
+	return e.entryType()
+}
+
 // entryType returns the message type, defaulting to TypeFile for backwards compatibility.
 func (e *Entry) entryType() MessageType {
 	if e.Type == "" {
@@ -226,6 +276,59 @@ func (e *Entry) entryType() MessageType {
 	return e.Type
 }
 
+// Purge removes entries sent before the cutoff and duplicate entries for the
+// same message, then rewrites the backing file. Returns how many were removed.
+func (s *Store) Purge(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type key struct {
+		chatID    int64
+		messageID int
+	}
+	seen := make(map[key]bool, len(s.entries))
+	remaining := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		k := key{e.ChatID, e.MessageID}
+		if e.SentAt.Before(before) || seen[k] {
+			continue
+		}
+		seen[k] = true
+		remaining = append(remaining, e)
+	}
+
+	removed := len(s.entries) - len(remaining)
+	if removed == 0 {
+		return 0, nil
+	}
+	s.entries = remaining
+	return removed, s.save()
+}
+
+// RunExpiry purges entries older than ttl every interval until the context
+// is cancelled. onPurge is called after each purge that removed entries.
+func (s *Store) RunExpiry(ctx context.Context, ttl, interval time.Duration, onPurge func(removed int, err error)) {
+	purge := func() {
+		removed, err := s.Purge(time.Now().Add(-ttl))
+		if removed > 0 || err != nil {
+			onPurge(removed, err)
+		}
+	}
+
+	purge()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purge()
+		}
+	}
+}
+
 // Enabled returns true if the store has persistence enabled.
 func (s *Store) Enabled() bool {
 	return s.path != ""
@@ -251,10 +354,15 @@ func (s *Store) save() error {
 		return nil
 	}
 
-	data, err := json.MarshalIndent(s.entries, "", "  ")
+	data, err := json.Marshal(s.entries)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(s.path, data, 0644)
+	// Write a temp file and rename so a crash never leaves a truncated store
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
 }
\ No newline at end of file
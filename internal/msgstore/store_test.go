@@ -0,0 +1,72 @@
+package msgstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTypeFilters(t *testing.T) {
+	s, err := New(filepath.Join(t.TempDir(), "messages.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Add(1, 10) // Untyped entries count as files
+	s.AddWithType(1, 11, TypeText)
+	s.AddWithType(1, 12, TypePrompt)
+	s.AddBatchWithType(1, []int{13, 14}, TypeFile)
+	s.AddWithType(2, 20, TypeFile)
+
+	if got := len(s.GetAllByType(1, TypeFile)); got != 3 {
+		t.Errorf("GetAllByType(file) = %d entries, want 3", got)
+	}
+	if got := len(s.GetAfterByType(1, time.Now().Add(-time.Minute), TypeText)); got != 1 {
+		t.Errorf("GetAfterByType(text) = %d entries, want 1", got)
+	}
+	if got := len(s.GetBeforeByType(1, time.Now().Add(time.Minute), TypePrompt)); got != 1 {
+		t.Errorf("GetBeforeByType(prompt) = %d entries, want 1", got)
+	}
+	if got := s.CountByType(1, TypeConfirmation); got != 0 {
+		t.Errorf("CountByType(confirmation) = %d, want 0", got)
+	}
+
+	reloaded, err := New(s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(reloaded.GetAllByType(1, TypeFile)); got != 3 {
+		t.Errorf("after reload GetAllByType(file) = %d entries, want 3", got)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	s, err := New(filepath.Join(t.TempDir(), "messages.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	s.entries = []Entry{
+		{ChatID: 1, MessageID: 1, SentAt: old, Type: TypeFile},
+		{ChatID: 1, MessageID: 2, SentAt: time.Now(), Type: TypeText},
+		{ChatID: 1, MessageID: 2, SentAt: time.Now(), Type: TypeText},
+		{ChatID: 2, MessageID: 2, SentAt: time.Now(), Type: TypeText},
+	}
+
+	removed, err := s.Purge(time.Now().Add(-60 * 24 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("Purge() removed %d entries, want 2", removed)
+	}
+
+	reloaded, err := New(s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Count(1) != 1 || reloaded.Count(2) != 1 {
+		t.Errorf("after purge counts = %d, %d; want 1, 1", reloaded.Count(1), reloaded.Count(2))
+	}
+}
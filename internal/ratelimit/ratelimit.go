@@ -0,0 +1,87 @@
+// Package ratelimit provides keyed token-bucket rate limiting.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Rule allows Requests per Per, with bursts up to Requests.
+type Rule struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+}
+
+// Enabled returns true if the rule limits anything.
+func (r Rule) Enabled() bool {
+	return r.Requests > 0 && r.Per > 0
+}
+
+// bucket is a token bucket for one key.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter tracks token buckets by key (e.g. "user:123").
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// New creates a limiter.
+func New() *Limiter {
+	return &Limiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from key's bucket under rule. If none is available it
+// returns false and how long until the next token. Disabled rules always allow.
+func (l *Limiter) Allow(key string, rule Rule) (bool, time.Duration) {
+	if !rule.Enabled() {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	capacity := float64(rule.Requests)
+	rate := capacity / rule.Per.Seconds() // tokens per second
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+// Prune removes buckets idle for longer than maxIdle to bound memory.
+func (l *Limiter) Prune(maxIdle time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := l.now().Add(-maxIdle)
+	for key, b := range l.buckets {
+		if b.last.Before(cutoff) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := New()
+	l.now = func() time.Time { return now }
+
+	rule := Rule{Requests: 2, Per: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("user:1", rule); !ok {
+			t.Fatalf("request %d: expected allowed within burst", i+1)
+		}
+	}
+
+	ok, wait := l.Allow("user:1", rule)
+	if ok {
+		t.Fatal("expected third request to be throttled")
+	}
+	if wait != 30*time.Second {
+		t.Errorf("wait = %v, want 30s", wait)
+	}
+
+	if ok, _ := l.Allow("user:2", rule); !ok {
+		t.Error("expected other key to have its own bucket")
+	}
+
+	now = now.Add(30 * time.Second)
+	if ok, _ := l.Allow("user:1", rule); !ok {
+		t.Error("expected a token to be refilled after 30s")
+	}
+
+	if ok, _ := l.Allow("user:1", Rule{}); !ok {
+		t.Error("expected disabled rule to allow")
+	}
+}
+
+func TestLimiterPrune(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := New()
+	l.now = func() time.Time { return now }
+
+	l.Allow("a", Rule{Requests: 1, Per: time.Second})
+	now = now.Add(time.Hour)
+	l.Prune(time.Minute)
+
+	if len(l.buckets) != 0 {
+		t.Errorf("buckets = %d, want 0 after prune", len(l.buckets))
+	}
+}
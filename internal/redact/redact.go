@@ -0,0 +1,65 @@
+// Package redact masks secrets in command output matched by regular
+// expressions.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces redacted text.
+const Mask = "****"
+
+// Rules are compiled redaction patterns. A pattern with capture groups
+// masks only what the groups match, e.g. `password=(\S+)` keeps "password=";
+// one without masks the whole match.
+type Rules []*regexp.Regexp
+
+// Compile compiles patterns into rules.
+func Compile(patterns []string) (Rules, error) {
+	rules := make(Rules, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("redact pattern %q: %w", p, err)
+		}
+		rules = append(rules, re)
+	}
+	return rules, nil
+}
+
+// Apply returns s with everything the rules match masked.
+func (r Rules) Apply(s string) string {
+	for _, re := range r {
+		s = apply(re, s)
+	}
+	return s
+}
+
+// apply masks the matches of re in s, or only their groups if re has any.
+func apply(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllLiteralString(s, Mask)
+	}
+
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		for g := 2; g < len(m); g += 2 {
+			start, end := m[g], m[g+1]
+			if start < last || start == end {
+				continue // Unmatched, empty or nested in an earlier group
+			}
+			sb.WriteString(s[last:start])
+			sb.WriteString(Mask)
+			last = end
+		}
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
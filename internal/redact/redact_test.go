@@ -0,0 +1,35 @@
+package redact
+
+import "testing"
+
+func TestApply(t *testing.T) {
+	rules, err := Compile([]string{
+		`ghp_[A-Za-z0-9]{8,}`,
+		`(?i)password[=:]\s*(\S+)`,
+		`postgres://[^:]+:([^@]+)@`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in, want string
+	}{
+		{"token ghp_abcdEFGH1234 set", "token **** set"},
+		{"PASSWORD: hunter2\nuser=bob", "PASSWORD: ****\nuser=bob"},
+		{"dsn=postgres://app:s3cr3t@db/prod", "dsn=postgres://app:****@db/prod"},
+		{"password=a password=b", "password=**** password=****"},
+		{"nothing secret", "nothing secret"},
+	}
+	for _, tt := range tests {
+		if got := rules.Apply(tt.in); got != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	if _, err := Compile([]string{"ok", "("}); err == nil {
+		t.Error("Compile() accepted an invalid pattern")
+	}
+}
@@ -24,7 +24,11 @@ type ScheduledCommand struct {
 	Interval      time.Duration // Interval scheduling (e.g., 5m)
 	InitialPaused bool          // Start with schedule paused
 	Command       pkgcmd.Command
-	lastRun       time.Time // For interval scheduling
+	// Job, if set, runs per chat instead of Command (e.g. maintenance tasks).
+	// Jobs are added with AddJob and survive UpdateCommands.
+	Job     func(ctx context.Context, chatID int64) error
+	Once    bool      // Run Job once per trigger, with chat ID 0, instead of per chat
+	lastRun time.Time // For interval scheduling
 }
 
 // CommandExecutor executes commands and sends output to chats.
@@ -58,6 +62,13 @@ func New(cfg Config) *Scheduler {
 	}
 }
 
+// SetChatIDs replaces the chats that receive scheduled command output.
+func (s *Scheduler) SetChatIDs(chatIDs []int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatIDs = chatIDs
+}
+
 // IsPaused returns true if the command is paused.
 func (s *Scheduler) IsPaused(name string) bool {
 	s.mu.RLock()
@@ -95,6 +106,13 @@ func (s *Scheduler) UpdateCommands(commands []ScheduledCommand) {
 		existing[s.commands[i].Name] = &s.commands[i]
 	}
 
+	// Keep jobs, which are not part of the command set
+	for i := range s.commands {
+		if s.commands[i].Job != nil {
+			commands = append(commands, s.commands[i])
+		}
+	}
+
 	// Process new commands
 	for i := range commands {
 		cmd := &commands[i]
@@ -121,6 +139,31 @@ func (s *Scheduler) UpdateCommands(commands []ScheduledCommand) {
 	slog.Info("scheduler commands updated", "count", len(commands))
 }
 
+// AddJob schedules a job that runs for every chat. Replaces any job or
+// command with the same name.
+func (s *Scheduler) AddJob(job ScheduledCommand) {
+	s.mu.Lock()
+	commands := make([]ScheduledCommand, 0, len(s.commands)+1)
+	for _, cmd := range s.commands {
+		if cmd.Name != job.Name {
+			commands = append(commands, cmd)
+		}
+	}
+	if job.InitialPaused {
+		s.paused[job.Name] = true
+	}
+	s.commands = append(commands, job)
+	s.mu.Unlock()
+
+	// Signal to recalculate next execution
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+
+	slog.Info("scheduler job added", "job", job.Name)
+}
+
 // Run starts the scheduler. Blocks until context is cancelled.
 func (s *Scheduler) Run(ctx context.Context) error {
 	slog.Info("scheduler started")
@@ -309,8 +352,25 @@ func (s *Scheduler) executeForAllChats(ctx context.Context, cmd *ScheduledComman
 		s.mu.Unlock()
 	}
 
-	for _, chatID := range s.chatIDs {
-		if err := s.executor.ExecuteScheduled(ctx, chatID, cmd.Command); err != nil {
+	if cmd.Job != nil && cmd.Once {
+		if err := cmd.Job(ctx, 0); err != nil {
+			slog.Error("scheduled job failed", "job", cmd.Name, "error", err)
+		}
+		return
+	}
+
+	s.mu.RLock()
+	chatIDs := s.chatIDs
+	s.mu.RUnlock()
+
+	for _, chatID := range chatIDs {
+		var err error
+		if cmd.Job != nil {
+			err = cmd.Job(ctx, chatID)
+		} else {
+			err = s.executor.ExecuteScheduled(ctx, chatID, cmd.Command)
+		}
+		if err != nil {
 			slog.Error("scheduled command failed",
 				"command", cmd.Name,
 				"chat_id", chatID,
@@ -343,3 +343,70 @@ func TestSchedulerIntervalVsTimeOfDay(t *testing.T) {
 		t.Errorf("nextExecution() selected %q, want %q (interval runs first)", nextCmd.Name, "interval-cmd")
 	}
 }
+
+func TestSchedulerJobs(t *testing.T) {
+	exec := &fakeExecutor{}
+	s := New(Config{
+		ChatIDs:  []int64{1, 2},
+		Executor: exec,
+	})
+
+	var ran []int64
+	s.AddJob(ScheduledCommand{
+		Name:  "auto-cleanup",
+		Times: []TimeOfDay{{3, 0}},
+		Job: func(ctx context.Context, chatID int64) error {
+			ran = append(ran, chatID)
+			return nil
+		},
+	})
+
+	// Reloading commands must not drop the job
+	s.UpdateCommands([]ScheduledCommand{
+		{Name: "test", Times: []TimeOfDay{{9, 0}}, Command: &fakeCommand{name: "test"}},
+	})
+	if got := len(s.ListActive()); got != 2 {
+		t.Fatalf("ListActive() returned %d entries, want 2", got)
+	}
+
+	s.mu.RLock()
+	var job *ScheduledCommand
+	for i := range s.commands {
+		if s.commands[i].Name == "auto-cleanup" {
+			job = &s.commands[i]
+		}
+	}
+	s.mu.RUnlock()
+	if job == nil {
+		t.Fatal("job missing after UpdateCommands")
+	}
+
+	s.executeForAllChats(context.Background(), job)
+	if len(ran) != 2 || len(exec.executed) != 0 {
+		t.Errorf("job ran for %v and executor ran %d commands; want job for both chats only", ran, len(exec.executed))
+	}
+
+	s.UpdateCommands(nil)
+	if got := len(s.ListActive()); got != 1 {
+		t.Errorf("after clearing commands ListActive() returned %d entries, want 1", got)
+	}
+}
+
+func TestSchedulerJobOnce(t *testing.T) {
+	s := New(Config{ChatIDs: []int64{1, 2}, Executor: &fakeExecutor{}})
+
+	var ran []int64
+	job := ScheduledCommand{
+		Name:  "backup",
+		Times: []TimeOfDay{{3, 0}},
+		Once:  true,
+		Job: func(ctx context.Context, chatID int64) error {
+			ran = append(ran, chatID)
+			return nil
+		},
+	}
+	s.executeForAllChats(context.Background(), &job)
+	if len(ran) != 1 || ran[0] != 0 {
+		t.Errorf("job ran for %v, want once with chat ID 0", ran)
+	}
+}
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// State is the scheduler state worth keeping across restarts: which
+// commands are paused and when interval commands last ran.
+type State struct {
+	Paused  map[string]bool      `json:"paused"`
+	LastRun map[string]time.Time `json:"last_run"`
+}
+
+// State returns the current state of all scheduled commands and jobs.
+func (s *Scheduler) State() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := State{Paused: make(map[string]bool), LastRun: make(map[string]time.Time)}
+	for _, cmd := range s.commands {
+		st.Paused[cmd.Name] = s.paused[cmd.Name]
+		if !cmd.lastRun.IsZero() {
+			st.LastRun[cmd.Name] = cmd.lastRun
+		}
+	}
+	return st
+}
+
+// Restore applies a saved state, overriding initial_paused. Commands not in
+// the state keep their current settings.
+func (s *Scheduler) Restore(st State) {
+	s.mu.Lock()
+	for name, paused := range st.Paused {
+		if paused {
+			s.paused[name] = true
+		} else {
+			delete(s.paused, name)
+		}
+	}
+	for i := range s.commands {
+		cmd := &s.commands[i]
+		if last, ok := st.LastRun[cmd.Name]; ok && cmd.Interval > 0 {
+			cmd.lastRun = last
+		}
+	}
+	s.mu.Unlock()
+
+	// Signal to recalculate next execution
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// SaveState writes the current state to path as JSON.
+func (s *Scheduler) SaveState(path string) error {
+	data, err := json.MarshalIndent(s.State(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode scheduler state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write scheduler state: %w", err)
+	}
+	return nil
+}
+
+// LoadState restores the state saved at path. A missing file is not an
+// error.
+func (s *Scheduler) LoadState(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read scheduler state: %w", err)
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("decode scheduler state: %w", err)
+	}
+	s.Restore(st)
+	return nil
+}
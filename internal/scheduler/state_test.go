@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	last := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := New(Config{})
+	s.UpdateCommands([]ScheduledCommand{
+		{Name: "backup", Times: []TimeOfDay{{3, 0}}, InitialPaused: true},
+		{Name: "ping", Interval: 5 * time.Minute},
+	})
+	s.SetPaused("backup", false)
+	s.SetPaused("ping", true)
+	s.commands[1].lastRun = last
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := s.SaveState(path); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := New(Config{})
+	restored.UpdateCommands([]ScheduledCommand{
+		{Name: "backup", Times: []TimeOfDay{{3, 0}}, InitialPaused: true},
+		{Name: "ping", Interval: 5 * time.Minute},
+		{Name: "new", Interval: time.Hour, InitialPaused: true},
+	})
+	if err := restored.LoadState(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.IsPaused("backup") {
+		t.Error("backup is paused, want the saved unpaused state")
+	}
+	if !restored.IsPaused("ping") {
+		t.Error("ping is not paused, want the saved paused state")
+	}
+	if !restored.IsPaused("new") {
+		t.Error("new lost its initial pause")
+	}
+	if !restored.commands[1].lastRun.Equal(last) {
+		t.Errorf("ping last run = %s, want %s", restored.commands[1].lastRun, last)
+	}
+}
+
+func TestLoadStateMissing(t *testing.T) {
+	s := New(Config{})
+	if err := s.LoadState(filepath.Join(t.TempDir(), "none.json")); err != nil {
+		t.Errorf("LoadState() = %v, want nil for a missing file", err)
+	}
+}
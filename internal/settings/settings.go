@@ -0,0 +1,181 @@
+// Package settings stores per-chat preferences chosen with /settings in
+// SQLite. Values are cached in memory, so reads never touch the database.
+package settings
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Setting keys.
+const (
+	Language   = "language"    // Message language, e.g. "de"
+	Startup    = "startup"     // "off" skips the restart notification
+	Output     = "output"      // Command output format: code, plain, markdown, html
+	QuietHours = "quiet_hours" // Silent delivery window, e.g. "22:00-07:00"
+	Confirm    = "confirm"     // "always" confirms every command
+)
+
+// Output formats.
+const (
+	OutputCode     = "code" // Monospace code block (default)
+	OutputPlain    = "plain"
+	OutputMarkdown = "markdown"
+	OutputHTML     = "html"
+)
+
+// OutputFormats lists the supported output formats in menu order.
+var OutputFormats = []string{OutputCode, OutputPlain, OutputMarkdown, OutputHTML}
+
+// ConfirmAlways makes every command in the chat ask for confirmation.
+const ConfirmAlways = "always"
+
+// Hours is a daily time window in minutes since midnight. End may be before
+// Start, in which case the window spans midnight.
+type Hours struct {
+	Start, End int
+}
+
+// ParseHours parses a window such as "22:00-07:00".
+func ParseHours(s string) (Hours, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return Hours{}, fmt.Errorf("quiet hours %q: want HH:MM-HH:MM", s)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return Hours{}, fmt.Errorf("quiet hours %q: want HH:MM-HH:MM", s)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return Hours{}, fmt.Errorf("quiet hours %q: want HH:MM-HH:MM", s)
+	}
+	h := Hours{Start: start.Hour()*60 + start.Minute(), End: end.Hour()*60 + end.Minute()}
+	if h.Start == h.End {
+		return Hours{}, fmt.Errorf("quiet hours %q: start and end are equal", s)
+	}
+	return h, nil
+}
+
+// Contains reports whether t's local time of day falls within the window.
+func (h Hours) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if h.Start < h.End {
+		return m >= h.Start && m < h.End
+	}
+	return m >= h.Start || m < h.End
+}
+
+// String formats the window as "HH:MM-HH:MM".
+func (h Hours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", h.Start/60, h.Start%60, h.End/60, h.End%60)
+}
+
+// Store holds per-chat settings.
+type Store struct {
+	db *sql.DB
+
+	mu    sync.RWMutex
+	chats map[int64]map[string]string
+}
+
+// Open opens (or creates) the settings table in the database at dbPath and
+// loads all stored settings.
+func Open(dbPath string) (*Store, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	schema := `
+		CREATE TABLE IF NOT EXISTS chat_settings (
+			chat_id INTEGER NOT NULL,
+			key TEXT NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY (chat_id, key)
+		);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+
+	s := &Store{db: db, chats: make(map[int64]map[string]string)}
+	if err := s.load(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads all settings into the cache.
+func (s *Store) load() error {
+	rows, err := s.db.Query("SELECT chat_id, key, value FROM chat_settings")
+	if err != nil {
+		return fmt.Errorf("load settings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var chatID int64
+		var key, value string
+		if err := rows.Scan(&chatID, &key, &value); err != nil {
+			return fmt.Errorf("load settings: %w", err)
+		}
+		s.cache(chatID, key, value)
+	}
+	return rows.Err()
+}
+
+// Get returns a chat's setting, or "" if unset.
+func (s *Store) Get(chatID int64, key string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chats[chatID][key]
+}
+
+// Set stores a chat's setting. An empty value removes it.
+func (s *Store) Set(chatID int64, key, value string) error {
+	var err error
+	if value == "" {
+		_, err = s.db.Exec("DELETE FROM chat_settings WHERE chat_id = ? AND key = ?", chatID, key)
+	} else {
+		_, err = s.db.Exec(`
+			INSERT INTO chat_settings (chat_id, key, value) VALUES (?, ?, ?)
+			ON CONFLICT (chat_id, key) DO UPDATE SET value = excluded.value
+		`, chatID, key, value)
+	}
+	if err != nil {
+		return fmt.Errorf("save setting: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache(chatID, key, value)
+	return nil
+}
+
+// cache updates the in-memory copy. The caller must hold mu or be loading.
+func (s *Store) cache(chatID int64, key, value string) {
+	if value == "" {
+		delete(s.chats[chatID], key)
+		if len(s.chats[chatID]) == 0 {
+			delete(s.chats, chatID)
+		}
+		return
+	}
+	if s.chats[chatID] == nil {
+		s.chats[chatID] = make(map[string]string)
+	}
+	s.chats[chatID][key] = value
+}
+
+// Close releases database resources.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
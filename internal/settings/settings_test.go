@@ -0,0 +1,76 @@
+package settings
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := s.Set(-100, Language, "de"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := s.Set(-100, Language, "ru"); err != nil {
+		t.Fatalf("Set() overwrite error = %v", err)
+	}
+	if err := s.Set(42, Language, "de"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := s.Set(42, Language, ""); err != nil {
+		t.Fatalf("Set() clear error = %v", err)
+	}
+	s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer s.Close()
+
+	if got := s.Get(-100, Language); got != "ru" {
+		t.Errorf("Get(-100) = %q, want ru", got)
+	}
+	if got := s.Get(42, Language); got != "" {
+		t.Errorf("Get(42) = %q, want cleared", got)
+	}
+}
+
+func TestParseHours(t *testing.T) {
+	at := func(clock string) time.Time {
+		tm, _ := time.Parse("15:04", clock)
+		return tm
+	}
+
+	night, err := ParseHours("22:00-07:00")
+	if err != nil {
+		t.Fatalf("ParseHours() error = %v", err)
+	}
+	if night.String() != "22:00-07:00" {
+		t.Errorf("String() = %q", night.String())
+	}
+	for clock, want := range map[string]bool{"21:59": false, "22:00": true, "03:00": true, "06:59": true, "07:00": false, "12:00": false} {
+		if got := night.Contains(at(clock)); got != want {
+			t.Errorf("night.Contains(%s) = %v, want %v", clock, got, want)
+		}
+	}
+
+	lunch, err := ParseHours("12:00 - 13:30")
+	if err != nil {
+		t.Fatalf("ParseHours() error = %v", err)
+	}
+	if !lunch.Contains(at("13:29")) || lunch.Contains(at("13:30")) || lunch.Contains(at("11:59")) {
+		t.Errorf("lunch window = %v", lunch)
+	}
+
+	for _, bad := range []string{"", "22:00", "25:00-07:00", "22:00-22:00", "late-early"} {
+		if _, err := ParseHours(bad); err == nil {
+			t.Errorf("ParseHours(%q) should fail", bad)
+		}
+	}
+}
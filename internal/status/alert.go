@@ -0,0 +1,220 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Alert metrics. Percentages are 0-100; load averages are absolute.
+const (
+	MetricCPU    = "cpu"
+	MetricMemory = "memory"
+	MetricDisk   = "disk" // Checked for every monitored mount point
+	MetricLoad1  = "load1"
+	MetricLoad5  = "load5"
+	MetricLoad15 = "load15"
+	MetricFiles  = "files" // Open file descriptors as a percentage of the limit
+)
+
+// AlertMetrics lists the metrics alert rules can watch.
+var AlertMetrics = []string{MetricCPU, MetricMemory, MetricDisk, MetricLoad1, MetricLoad5, MetricLoad15, MetricFiles}
+
+// AlertRule raises an alert when a metric stays above a threshold.
+type AlertRule struct {
+	Name    string        `yaml:"name"`    // Shown in messages (default: the metric)
+	Metric  string        `yaml:"metric"`  // One of AlertMetrics
+	Above   float64       `yaml:"above"`   // Alert when the value exceeds this
+	For     time.Duration `yaml:"for"`     // How long it must stay above first (default: alert at once)
+	Recover float64       `yaml:"recover"` // Recover when the value drops below this (default: Above)
+}
+
+// Validate checks the rule and fills in defaults.
+func (r *AlertRule) Validate() error {
+	if !slices.Contains(AlertMetrics, r.Metric) {
+		return fmt.Errorf("unknown metric %q (available: %v)", r.Metric, AlertMetrics)
+	}
+	if r.Name == "" {
+		r.Name = r.Metric
+	}
+	if r.For < 0 {
+		return fmt.Errorf("%s: for must not be negative", r.Name)
+	}
+	if r.Recover == 0 {
+		r.Recover = r.Above
+	}
+	if r.Recover > r.Above {
+		return fmt.Errorf("%s: recover (%g) must not exceed above (%g)", r.Name, r.Recover, r.Above)
+	}
+	return nil
+}
+
+// Alert is a rule that started or stopped firing.
+type Alert struct {
+	Rule   AlertRule
+	Target string  // Mount point for disk rules, otherwise empty
+	Value  float64 // Value at the time of the change
+	Firing bool    // True when raised, false when recovered
+}
+
+// Subject names what the alert is about, e.g. "disk /data".
+func (a Alert) Subject() string {
+	if a.Target == "" {
+		return a.Rule.Metric
+	}
+	return a.Rule.Metric + " " + a.Target
+}
+
+// alertState tracks one rule and target between checks.
+type alertState struct {
+	since  time.Time // When the value first exceeded the threshold; zero if it doesn't
+	firing bool
+}
+
+// AlertMonitor evaluates alert rules against collected metrics. An alert is
+// reported once when it starts firing and once when it recovers; the recover
+// threshold adds hysteresis so a value hovering around the limit doesn't flap.
+type AlertMonitor struct {
+	collector Collector
+
+	mu     sync.Mutex
+	rules  []AlertRule
+	states map[string]*alertState // Keyed by rule name and target
+}
+
+// NewAlertMonitor creates a monitor for validated rules.
+func NewAlertMonitor(collector Collector, rules []AlertRule) *AlertMonitor {
+	return &AlertMonitor{
+		collector: collector,
+		rules:     rules,
+		states:    make(map[string]*alertState),
+	}
+}
+
+// SetRules replaces the rules. State of rules that still exist is kept.
+func (m *AlertMonitor) SetRules(rules []AlertRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = rules
+}
+
+// Run checks the rules every interval until the context is cancelled and
+// calls notify when an alert fires or recovers.
+func (m *AlertMonitor) Run(ctx context.Context, interval time.Duration, notify func(Alert)) {
+	check := func() {
+		m.mu.Lock()
+		enabled := len(m.rules) > 0
+		m.mu.Unlock()
+		if !enabled {
+			return
+		}
+
+		metrics, err := m.collector.Collect(ctx)
+		if err != nil {
+			slog.Warn("alert check failed", "error", err)
+			return
+		}
+		for _, a := range m.evaluate(metrics, time.Now()) {
+			notify(a)
+		}
+	}
+
+	check()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// evaluate updates the rules' states with metrics sampled at now and returns
+// the alerts that changed.
+func (m *AlertMonitor) evaluate(metrics *Metrics, now time.Time) []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var changed []Alert
+	seen := make(map[string]bool)
+	for _, rule := range m.rules {
+		for _, v := range metricValues(metrics, rule.Metric) {
+			target, value := v.target, v.value
+			key := rule.Name + "\x00" + target
+			seen[key] = true
+			state := m.states[key]
+			if state == nil {
+				state = &alertState{}
+				m.states[key] = state
+			}
+
+			if state.firing {
+				if value < rule.Recover {
+					state.firing = false
+					state.since = time.Time{}
+					changed = append(changed, Alert{Rule: rule, Target: target, Value: value})
+				}
+				continue
+			}
+			if value <= rule.Above {
+				state.since = time.Time{}
+				continue
+			}
+			if state.since.IsZero() {
+				state.since = now
+			}
+			if now.Sub(state.since) >= rule.For {
+				state.firing = true
+				changed = append(changed, Alert{Rule: rule, Target: target, Value: value, Firing: true})
+			}
+		}
+	}
+	// Forget removed rules and unmounted disks
+	for key := range m.states {
+		if !seen[key] {
+			delete(m.states, key)
+		}
+	}
+	return changed
+}
+
+// metricValue is a metric's value for one target.
+type metricValue struct {
+	target string
+	value  float64
+}
+
+// metricValues returns the metric's current values, one per target.
+func metricValues(metrics *Metrics, metric string) []metricValue {
+	switch metric {
+	case MetricCPU:
+		return []metricValue{{"", metrics.CPUPercent}}
+	case MetricMemory:
+		return []metricValue{{"", metrics.MemoryPercent}}
+	case MetricDisk:
+		values := make([]metricValue, 0, len(metrics.Disks))
+		for _, d := range metrics.Disks {
+			values = append(values, metricValue{d.Path, d.Percent})
+		}
+		return values
+	case MetricLoad1:
+		return []metricValue{{"", metrics.Load1}}
+	case MetricLoad5:
+		return []metricValue{{"", metrics.Load5}}
+	case MetricLoad15:
+		return []metricValue{{"", metrics.Load15}}
+	case MetricFiles:
+		if metrics.MaxFiles == 0 {
+			return nil
+		}
+		return []metricValue{{"", float64(metrics.OpenFiles) / float64(metrics.MaxFiles) * 100}}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package status
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertRuleValidate(t *testing.T) {
+	r := AlertRule{Metric: "cpu", Above: 90}
+	if err := r.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if r.Name != "cpu" || r.Recover != 90 {
+		t.Errorf("defaults = %+v, want name cpu, recover 90", r)
+	}
+
+	invalid := []AlertRule{
+		{Metric: "swap", Above: 50},
+		{Metric: "disk", Above: 85, Recover: 90},
+		{Metric: "memory", Above: 95, For: -time.Minute},
+	}
+	for _, r := range invalid {
+		if err := r.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", r)
+		}
+	}
+}
+
+func TestAlertMonitorEvaluate(t *testing.T) {
+	rules := []AlertRule{
+		{Name: "cpu-high", Metric: MetricCPU, Above: 90, For: 5 * time.Minute, Recover: 80},
+		{Name: "disk-full", Metric: MetricDisk, Above: 85, Recover: 85},
+	}
+	m := NewAlertMonitor(nil, rules)
+	start := time.Now()
+	check := func(after time.Duration, cpu, data float64) []Alert {
+		metrics := &Metrics{
+			CPUPercent: cpu,
+			Disks:      []DiskUsage{{Path: "/", Percent: 50}, {Path: "/data", Percent: data}},
+		}
+		return m.evaluate(metrics, start.Add(after))
+	}
+
+	// Disk alerts at once; CPU waits for 5m above the threshold
+	if got := check(0, 95, 90); len(got) != 1 || got[0].Subject() != "disk /data" || !got[0].Firing {
+		t.Fatalf("first check = %+v, want disk /data firing", got)
+	}
+	if got := check(3*time.Minute, 95, 90); len(got) != 0 {
+		t.Errorf("still firing = %+v, want no repeats", got)
+	}
+	if got := check(5*time.Minute, 96, 90); len(got) != 1 || got[0].Rule.Name != "cpu-high" || !got[0].Firing {
+		t.Errorf("after 5m = %+v, want cpu-high firing", got)
+	}
+
+	// Between recover and above nothing changes (hysteresis)
+	if got := check(6*time.Minute, 85, 90); len(got) != 0 {
+		t.Errorf("cpu 85%% = %+v, want no change", got)
+	}
+	got := check(7*time.Minute, 50, 60)
+	if len(got) != 2 || got[0].Firing || got[1].Firing {
+		t.Fatalf("recovery = %+v, want two recoveries", got)
+	}
+
+	// A dip below the threshold restarts the 5m wait
+	check(8*time.Minute, 95, 60)
+	check(10*time.Minute, 70, 60)
+	if got := check(14*time.Minute, 95, 60); len(got) != 0 {
+		t.Errorf("after dip = %+v, want the wait restarted", got)
+	}
+}
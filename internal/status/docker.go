@@ -0,0 +1,152 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultDockerSocket is used when DOCKER_HOST doesn't name a unix socket.
+const DefaultDockerSocket = "/var/run/docker.sock"
+
+// Container describes a Docker container's state.
+type Container struct {
+	ID           string
+	Name         string
+	Image        string
+	State        string // created, running, paused, restarting, exited, dead
+	Status       string // Human-readable, e.g. "Up 3 hours (healthy)"
+	Health       string // healthy, unhealthy, starting, or "" without a health check
+	RestartCount int
+	ExitCode     int
+}
+
+// OK reports whether the container is in a good state: not restarting,
+// dead or unhealthy, and not exited with an error.
+func (c Container) OK() bool {
+	switch {
+	case c.State == "restarting" || c.State == "dead":
+		return false
+	case c.Health == "unhealthy":
+		return false
+	case c.State == "exited" && c.ExitCode != 0:
+		return false
+	}
+	return true
+}
+
+// DockerClient queries the Docker Engine API over its unix socket.
+type DockerClient struct {
+	http *http.Client
+}
+
+// DockerSocket returns path, or when empty, DOCKER_HOST if it is a unix://
+// address, else DefaultDockerSocket.
+func DockerSocket(path string) string {
+	if path != "" {
+		return path
+	}
+	if host, ok := strings.CutPrefix(os.Getenv("DOCKER_HOST"), "unix://"); ok {
+		return host
+	}
+	return DefaultDockerSocket
+}
+
+// NewDockerClient creates a client for the socket at path (see DockerSocket).
+func NewDockerClient(path string) *DockerClient {
+	path = DockerSocket(path)
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return &DockerClient{
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+// Containers lists all containers, including stopped ones, sorted by name.
+func (c *DockerClient) Containers(ctx context.Context) ([]Container, error) {
+	var list []struct {
+		ID     string   `json:"Id"`
+		Names  []string `json:"Names"`
+		Image  string   `json:"Image"`
+		State  string   `json:"State"`
+		Status string   `json:"Status"`
+	}
+	if err := c.get(ctx, "/containers/json?all=1", &list); err != nil {
+		return nil, err
+	}
+
+	containers := make([]Container, 0, len(list))
+	for _, item := range list {
+		ctr := Container{
+			ID:     item.ID,
+			Image:  item.Image,
+			State:  item.State,
+			Status: item.Status,
+		}
+		if len(item.Names) > 0 {
+			ctr.Name = strings.TrimPrefix(item.Names[0], "/")
+		}
+
+		// Restarts, exit code and health are only in the full inspection
+		var inspect struct {
+			RestartCount int `json:"RestartCount"`
+			State        struct {
+				ExitCode int `json:"ExitCode"`
+				Health   *struct {
+					Status string `json:"Status"`
+				} `json:"Health"`
+			} `json:"State"`
+		}
+		if err := c.get(ctx, "/containers/"+item.ID+"/json", &inspect); err != nil {
+			return nil, err
+		}
+		ctr.RestartCount = inspect.RestartCount
+		ctr.ExitCode = inspect.State.ExitCode
+		if inspect.State.Health != nil {
+			ctr.Health = inspect.State.Health.Status
+		}
+		containers = append(containers, ctr)
+	}
+
+	slices.SortFunc(containers, func(a, b Container) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return containers, nil
+}
+
+// get fetches an API path and decodes the JSON response into v.
+func (c *DockerClient) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("docker: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("docker: %s: %s", resp.Status, apiErr.Message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("docker: decode %s: %w", path, err)
+	}
+	return nil
+}
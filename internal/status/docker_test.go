@@ -0,0 +1,69 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestDockerContainers(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("all") != "1" {
+			t.Errorf("list query = %q, want all=1", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `[
+			{"Id": "b2", "Names": ["/worker"], "Image": "app:2", "State": "restarting", "Status": "Restarting (1) 5 seconds ago"},
+			{"Id": "a1", "Names": ["/web"], "Image": "nginx", "State": "running", "Status": "Up 3 hours (healthy)"}
+		]`)
+	})
+	mux.HandleFunc("/containers/a1/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"RestartCount": 0, "State": {"ExitCode": 0, "Health": {"Status": "healthy"}}}`)
+	})
+	mux.HandleFunc("/containers/b2/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"RestartCount": 12, "State": {"ExitCode": 1}}`)
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	containers, err := NewDockerClient(socket).Containers(context.Background())
+	if err != nil {
+		t.Fatalf("Containers() error = %v", err)
+	}
+	if len(containers) != 2 || containers[0].Name != "web" || containers[1].Name != "worker" {
+		t.Fatalf("Containers() = %+v, want web and worker", containers)
+	}
+	if web := containers[0]; web.Health != "healthy" || !web.OK() {
+		t.Errorf("web = %+v, want healthy", web)
+	}
+	if worker := containers[1]; worker.RestartCount != 12 || worker.OK() {
+		t.Errorf("worker = %+v, want 12 restarts and not OK", worker)
+	}
+}
+
+func TestContainerOK(t *testing.T) {
+	tests := []struct {
+		ctr  Container
+		want bool
+	}{
+		{Container{State: "running"}, true},
+		{Container{State: "running", Health: "unhealthy"}, false},
+		{Container{State: "exited", ExitCode: 0}, true},
+		{Container{State: "exited", ExitCode: 137}, false},
+		{Container{State: "dead"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.ctr.OK(); got != tt.want {
+			t.Errorf("%+v OK() = %v, want %v", tt.ctr, got, tt.want)
+		}
+	}
+}
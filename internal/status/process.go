@@ -0,0 +1,91 @@
+package status
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// Process holds resource usage of one process.
+type Process struct {
+	PID        int32
+	Name       string
+	CPUPercent float64 // Share of one CPU over the sample window; can exceed 100
+	MemoryRSS  uint64
+	MemPercent float64
+}
+
+// Processes samples CPU time of all processes twice, sample apart, and
+// returns their usage. Processes that exit or can't be read are skipped.
+func Processes(ctx context.Context, sample time.Duration) ([]Process, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+
+	before := make(map[int32]float64, len(procs))
+	for _, p := range procs {
+		if t, err := p.TimesWithContext(ctx); err == nil {
+			before[p.Pid] = t.User + t.System
+		}
+	}
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(sample):
+	}
+	elapsed := time.Since(start).Seconds()
+
+	result := make([]Process, 0, len(procs))
+	for _, p := range procs {
+		cpuBefore, ok := before[p.Pid]
+		if !ok {
+			continue
+		}
+		t, err := p.TimesWithContext(ctx)
+		if err != nil {
+			continue // Exited
+		}
+		proc := Process{
+			PID:        p.Pid,
+			CPUPercent: (t.User + t.System - cpuBefore) / elapsed * 100,
+		}
+		proc.Name, _ = p.NameWithContext(ctx)
+		if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+			proc.MemoryRSS = mem.RSS
+		}
+		if pct, err := p.MemoryPercentWithContext(ctx); err == nil {
+			proc.MemPercent = float64(pct)
+		}
+		result = append(result, proc)
+	}
+	return result, nil
+}
+
+// TopByCPU returns up to n processes using the most CPU.
+func TopByCPU(procs []Process, n int) []Process {
+	return top(procs, n, func(p Process) float64 { return p.CPUPercent })
+}
+
+// TopByMemory returns up to n processes using the most memory.
+func TopByMemory(procs []Process, n int) []Process {
+	return top(procs, n, func(p Process) float64 { return float64(p.MemoryRSS) })
+}
+
+// top sorts a copy of procs by key, highest first, ties by PID.
+func top(procs []Process, n int, key func(Process) float64) []Process {
+	sorted := slices.Clone(procs)
+	slices.SortFunc(sorted, func(a, b Process) int {
+		if c := cmp.Compare(key(b), key(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.PID, b.PID)
+	})
+	return sorted[:min(n, len(sorted))]
+}
@@ -0,0 +1,29 @@
+package status
+
+import (
+	"runtime"
+	"time"
+)
+
+// SelfStats describes the bot process itself.
+type SelfStats struct {
+	Uptime        time.Duration
+	Goroutines    int
+	HeapAlloc     uint64 // Bytes of allocated heap objects
+	Sys           uint64 // Bytes obtained from the OS
+	Commands      int    // Registered commands
+	Sessions      int    // Argument prompts, confirmations and code prompts in progress
+	ScheduledJobs int    // Active (unpaused) scheduled commands and jobs
+}
+
+// RuntimeStats fills in the Go runtime fields of SelfStats.
+func RuntimeStats(started time.Time) SelfStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return SelfStats{
+		Uptime:     time.Since(started),
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  mem.HeapAlloc,
+		Sys:        mem.Sys,
+	}
+}
@@ -4,10 +4,17 @@ package status
 import (
 	"context"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/shirou/gopsutil/v4/cpu"
 	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/load"
 	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/shirou/gopsutil/v4/net"
 )
 
 // Metrics holds system resource usage.
@@ -16,9 +23,36 @@ type Metrics struct {
 	MemoryUsed    uint64
 	MemoryTotal   uint64
 	MemoryPercent float64
-	DiskUsed      uint64
+	DiskUsed      uint64 // First mount point (usually "/")
 	DiskTotal     uint64
 	DiskPercent   float64
+	Disks         []DiskUsage // Every monitored mount point
+
+	Load1, Load5, Load15 float64 // Load averages (zero where unsupported)
+
+	NetRxRate float64 // Bytes received per second, excluding loopback
+	NetTxRate float64 // Bytes sent per second, excluding loopback
+
+	OpenFiles uint64 // Open file descriptors system-wide (zero where unsupported)
+	MaxFiles  uint64 // System-wide file descriptor limit
+}
+
+// DiskUsage holds usage of one mount point.
+type DiskUsage struct {
+	Path    string
+	FSType  string
+	Used    uint64
+	Total   uint64
+	Percent float64
+}
+
+// DefaultExcludedFSTypes are pseudo and container filesystems left out when
+// mount points are discovered automatically.
+var DefaultExcludedFSTypes = []string{
+	"autofs", "binfmt_misc", "bpf", "cgroup", "cgroup2", "configfs", "debugfs",
+	"devpts", "devtmpfs", "efivarfs", "fusectl", "hugetlbfs", "mqueue", "nsfs",
+	"overlay", "proc", "pstore", "ramfs", "securityfs", "squashfs", "sysfs",
+	"tmpfs", "tracefs",
 }
 
 // Collector gathers system metrics.
@@ -26,16 +60,42 @@ type Collector interface {
 	Collect(ctx context.Context) (*Metrics, error)
 }
 
+// netSampleDelay is how long the first collection waits to measure network
+// rates when there is no earlier sample.
+const netSampleDelay = time.Second
+
 // GopsutilCollector uses gopsutil for metrics.
 type GopsutilCollector struct {
-	diskPath string
+	mu       sync.Mutex
+	mounts   []string  // Mount points to report; empty discovers them
+	excluded []string  // Filesystem types skipped by discovery
+	lastNet  netSample // Previous counters for rate calculation
+}
+
+// netSample is a snapshot of cumulative network counters.
+type netSample struct {
+	rx, tx uint64
+	at     time.Time
 }
 
-// NewGopsutilCollector creates a collector.
+// NewGopsutilCollector creates a collector that reports the root disk.
 func NewGopsutilCollector() *GopsutilCollector {
 	return &GopsutilCollector{
-		diskPath: "/",
+		mounts: []string{"/"},
+	}
+}
+
+// SetMounts sets the mount points to report. With no mounts, all mounted
+// filesystems are reported except those of the excluded types
+// (DefaultExcludedFSTypes when nil).
+func (c *GopsutilCollector) SetMounts(mounts, excludedFSTypes []string) {
+	if excludedFSTypes == nil {
+		excludedFSTypes = DefaultExcludedFSTypes
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mounts = mounts
+	c.excluded = excludedFSTypes
 }
 
 // Collect gathers current system metrics.
@@ -60,14 +120,152 @@ func (c *GopsutilCollector) Collect(ctx context.Context) (*Metrics, error) {
 	m.MemoryTotal = memInfo.Total
 	m.MemoryPercent = memInfo.UsedPercent
 
-	// Disk
-	diskInfo, err := disk.UsageWithContext(ctx, c.diskPath)
+	// Disks
+	m.Disks, err = c.disks(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("get disk: %w", err)
 	}
-	m.DiskUsed = diskInfo.Used
-	m.DiskTotal = diskInfo.Total
-	m.DiskPercent = diskInfo.UsedPercent
+	if len(m.Disks) > 0 {
+		m.DiskUsed = m.Disks[0].Used
+		m.DiskTotal = m.Disks[0].Total
+		m.DiskPercent = m.Disks[0].Percent
+	}
+
+	// Load averages (not available on every platform)
+	if avg, err := load.AvgWithContext(ctx); err == nil {
+		m.Load1, m.Load5, m.Load15 = avg.Load1, avg.Load5, avg.Load15
+	}
+
+	// Network
+	m.NetRxRate, m.NetTxRate, err = c.netRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get network: %w", err)
+	}
+
+	// File descriptors
+	m.OpenFiles, m.MaxFiles = openFiles()
 
 	return &m, nil
 }
+
+// disks returns usage of the configured or discovered mount points.
+func (c *GopsutilCollector) disks(ctx context.Context) ([]DiskUsage, error) {
+	c.mu.Lock()
+	mounts, excluded := c.mounts, c.excluded
+	c.mu.Unlock()
+
+	if len(mounts) == 0 {
+		var err error
+		if mounts, err = discoverMounts(ctx, excluded); err != nil {
+			return nil, err
+		}
+	}
+
+	disks := make([]DiskUsage, 0, len(mounts))
+	for _, path := range mounts {
+		usage, err := disk.UsageWithContext(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		disks = append(disks, DiskUsage{
+			Path:    path,
+			FSType:  usage.Fstype,
+			Used:    usage.Used,
+			Total:   usage.Total,
+			Percent: usage.UsedPercent,
+		})
+	}
+	return disks, nil
+}
+
+// discoverMounts lists mounted filesystems, skipping excluded types and
+// repeated mounts of the same device. "/" comes first.
+func discoverMounts(ctx context.Context, excluded []string) ([]string, error) {
+	partitions, err := disk.PartitionsWithContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var mounts []string
+	devices := make(map[string]bool)
+	for _, p := range partitions {
+		if slices.Contains(excluded, p.Fstype) || devices[p.Device] {
+			continue
+		}
+		devices[p.Device] = true
+		mounts = append(mounts, p.Mountpoint)
+	}
+	slices.SortStableFunc(mounts, func(a, b string) int {
+		switch {
+		case a == "/":
+			return -1
+		case b == "/":
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	return mounts, nil
+}
+
+// netRates returns receive and transmit rates in bytes per second since the
+// previous call. The first call measures over netSampleDelay.
+func (c *GopsutilCollector) netRates(ctx context.Context) (rx, tx float64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lastNet.at.IsZero() {
+		if c.lastNet, err = sampleNet(ctx); err != nil {
+			return 0, 0, err
+		}
+		select {
+		case <-ctx.Done():
+			return 0, 0, ctx.Err()
+		case <-time.After(netSampleDelay):
+		}
+	}
+
+	cur, err := sampleNet(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	prev := c.lastNet
+	c.lastNet = cur
+
+	secs := cur.at.Sub(prev.at).Seconds()
+	if secs <= 0 || cur.rx < prev.rx || cur.tx < prev.tx {
+		return 0, 0, nil // Counters reset (e.g. interface restarted)
+	}
+	return float64(cur.rx-prev.rx) / secs, float64(cur.tx-prev.tx) / secs, nil
+}
+
+// sampleNet sums the counters of all non-loopback interfaces.
+func sampleNet(ctx context.Context) (netSample, error) {
+	counters, err := net.IOCountersWithContext(ctx, true)
+	if err != nil {
+		return netSample{}, err
+	}
+	s := netSample{at: time.Now()}
+	for _, nic := range counters {
+		if strings.HasPrefix(nic.Name, "lo") {
+			continue
+		}
+		s.rx += nic.BytesRecv
+		s.tx += nic.BytesSent
+	}
+	return s, nil
+}
+
+// openFiles reads the system-wide open and maximum file descriptor counts
+// from /proc/sys/fs/file-nr. Returns zeros where that file doesn't exist.
+func openFiles() (open, limit uint64) {
+	data, err := os.ReadFile("/proc/sys/fs/file-nr")
+	if err != nil {
+		return 0, 0
+	}
+	// Format: allocated, unused (always 0 since Linux 2.6), maximum
+	var unused uint64
+	if _, err := fmt.Sscan(string(data), &open, &unused, &limit); err != nil {
+		return 0, 0
+	}
+	return open - unused, limit
+}
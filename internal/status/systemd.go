@@ -0,0 +1,176 @@
+package status
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Unit describes a systemd unit's state.
+type Unit struct {
+	Name        string
+	Description string
+	LoadState   string // loaded, not-found, ...
+	ActiveState string // active, inactive, failed, activating, ...
+	SubState    string // running, exited, dead, ...
+	Result      string // success, exit-code, timeout, ...
+}
+
+// Failed reports whether the unit is in the failed state.
+func (u Unit) Failed() bool {
+	return u.ActiveState == "failed"
+}
+
+// unitProperties are the properties read with systemctl show.
+const unitProperties = "Id,Description,LoadState,ActiveState,SubState,Result"
+
+// UnitMonitor reports the state of configured systemd units and alerts on
+// changes into and out of the failed state.
+type UnitMonitor struct {
+	mu     sync.Mutex
+	units  []string
+	failed map[string]bool // Units failed at the last check
+
+	// show runs systemctl show; replaced in tests
+	show func(ctx context.Context, units []string) ([]byte, error)
+}
+
+// NewUnitMonitor creates a monitor for the given units, e.g. "nginx" or
+// "backup.timer".
+func NewUnitMonitor(units []string) *UnitMonitor {
+	return &UnitMonitor{
+		units:  units,
+		failed: make(map[string]bool),
+		show:   systemctlShow,
+	}
+}
+
+// SetUnits replaces the monitored units.
+func (m *UnitMonitor) SetUnits(units []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.units = units
+}
+
+// Units returns the current state of the monitored units, in config order.
+func (m *UnitMonitor) Units(ctx context.Context) ([]Unit, error) {
+	m.mu.Lock()
+	units := m.units
+	m.mu.Unlock()
+
+	if len(units) == 0 {
+		return nil, nil
+	}
+	out, err := m.show(ctx, units)
+	if err != nil {
+		return nil, err
+	}
+	return parseUnits(out), nil
+}
+
+// Run checks the units every interval until the context is cancelled and
+// calls notify when a unit fails or recovers. Units already failed at the
+// first check are reported too.
+func (m *UnitMonitor) Run(ctx context.Context, interval time.Duration, notify func(u Unit, failed bool)) {
+	check := func() {
+		units, err := m.Units(ctx)
+		if err != nil {
+			slog.Warn("systemd unit check failed", "error", err)
+			return
+		}
+		for _, u := range m.changes(units) {
+			notify(u, u.Failed())
+		}
+	}
+
+	check()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// changes records the units' failed states and returns those that changed.
+func (m *UnitMonitor) changes(units []Unit) []Unit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var changed []Unit
+	seen := make(map[string]bool, len(units))
+	for _, u := range units {
+		seen[u.Name] = true
+		if u.Failed() != m.failed[u.Name] {
+			changed = append(changed, u)
+		}
+		m.failed[u.Name] = u.Failed()
+	}
+	// Forget units no longer monitored
+	for name := range m.failed {
+		if !seen[name] {
+			delete(m.failed, name)
+		}
+	}
+	return changed
+}
+
+// systemctlShow runs systemctl show for the units.
+func systemctlShow(ctx context.Context, units []string) ([]byte, error) {
+	args := append([]string{"show", "--no-pager", "--property=" + unitProperties, "--"}, units...)
+	out, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl show: %w", err)
+	}
+	return out, nil
+}
+
+// parseUnits parses systemctl show output: one block of Key=Value lines per
+// unit, separated by blank lines.
+func parseUnits(out []byte) []Unit {
+	var units []Unit
+	var cur Unit
+	flush := func() {
+		if cur.Name != "" {
+			units = append(units, cur)
+		}
+		cur = Unit{}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "Id":
+			cur.Name = value
+		case "Description":
+			cur.Description = value
+		case "LoadState":
+			cur.LoadState = value
+		case "ActiveState":
+			cur.ActiveState = value
+		case "SubState":
+			cur.SubState = value
+		case "Result":
+			cur.Result = value
+		}
+	}
+	flush()
+	return units
+}
@@ -0,0 +1,69 @@
+package status
+
+import (
+	"context"
+	"testing"
+)
+
+const showOutput = `Id=nginx.service
+Description=A high performance web server
+LoadState=loaded
+ActiveState=active
+SubState=running
+Result=success
+
+Id=backup.service
+Description=Nightly backup
+LoadState=loaded
+ActiveState=failed
+SubState=failed
+Result=exit-code
+
+Id=missing.service
+Description=missing.service
+LoadState=not-found
+ActiveState=inactive
+SubState=dead
+Result=success
+`
+
+func TestParseUnits(t *testing.T) {
+	units := parseUnits([]byte(showOutput))
+	if len(units) != 3 {
+		t.Fatalf("parseUnits() = %d units, want 3", len(units))
+	}
+	if u := units[1]; u.Name != "backup.service" || !u.Failed() || u.Result != "exit-code" {
+		t.Errorf("units[1] = %+v, want failed backup.service", u)
+	}
+	if u := units[2]; u.LoadState != "not-found" || u.Failed() {
+		t.Errorf("units[2] = %+v, want not-found", u)
+	}
+}
+
+func TestUnitMonitorChanges(t *testing.T) {
+	out := showOutput
+	m := NewUnitMonitor([]string{"nginx", "backup", "missing"})
+	m.show = func(context.Context, []string) ([]byte, error) { return []byte(out), nil }
+
+	check := func() []Unit {
+		units, err := m.Units(context.Background())
+		if err != nil {
+			t.Fatalf("Units() error = %v", err)
+		}
+		return m.changes(units)
+	}
+
+	// Failed at the first check is reported
+	if changed := check(); len(changed) != 1 || changed[0].Name != "backup.service" {
+		t.Fatalf("first check changes = %+v, want backup.service", changed)
+	}
+	// No repeat while still failed
+	if changed := check(); len(changed) != 0 {
+		t.Fatalf("second check changes = %+v, want none", changed)
+	}
+	// Recovery is reported
+	out = "Id=backup.service\nActiveState=active\nSubState=exited\n"
+	if changed := check(); len(changed) != 1 || changed[0].Failed() {
+		t.Fatalf("recovery changes = %+v, want recovered backup.service", changed)
+	}
+}
@@ -0,0 +1,145 @@
+// Package transcribe converts voice recordings to text, either with a local
+// speech-to-text program (e.g. whisper.cpp) or an OpenAI-compatible API.
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultModel is the API model used when none is configured.
+const DefaultModel = "whisper-1"
+
+// Transcriber converts the audio file at path to text.
+type Transcriber interface {
+	Transcribe(ctx context.Context, path string) (string, error)
+}
+
+// CommandTranscriber runs a shell command with the audio path appended and
+// reads the transcript from stdout.
+type CommandTranscriber struct {
+	command string
+}
+
+// NewCommand creates a transcriber backed by a local program.
+func NewCommand(command string) *CommandTranscriber {
+	return &CommandTranscriber{command: command}
+}
+
+// Transcribe runs the command and returns its trimmed stdout.
+func (c *CommandTranscriber) Transcribe(ctx context.Context, path string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", c.command+` "$0"`, path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, lastLine(msg))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// APITranscriber posts audio to an OpenAI-compatible
+// /v1/audio/transcriptions endpoint.
+type APITranscriber struct {
+	url      string
+	key      string
+	model    string
+	language string
+	client   *http.Client
+}
+
+// NewAPI creates an API transcriber. An empty model uses DefaultModel;
+// an empty language lets the service detect it.
+func NewAPI(url, key, model, language string) *APITranscriber {
+	if model == "" {
+		model = DefaultModel
+	}
+	return &APITranscriber{url: url, key: key, model: model, language: language, client: &http.Client{}}
+}
+
+// Transcribe uploads the file and returns the "text" field of the response.
+func (a *APITranscriber) Transcribe(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return "", err
+	}
+	mw.WriteField("model", a.model)
+	if a.language != "" {
+		mw.WriteField("language", a.language)
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if a.key != "" {
+		req.Header.Set("Authorization", "Bearer "+a.key)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Text  string `json:"text"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("transcription API returned %s", resp.Status)
+		}
+		return "", fmt.Errorf("parse transcription response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if result.Error != nil && result.Error.Message != "" {
+			return "", errors.New(result.Error.Message)
+		}
+		return "", fmt.Errorf("transcription API returned %s", resp.Status)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+// lastLine returns the last line of s, where tools usually put the error.
+func lastLine(s string) string {
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
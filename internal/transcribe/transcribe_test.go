@@ -0,0 +1,69 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommandTranscriber(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "voice note.ogg")
+	if err := os.WriteFile(path, []byte("  restart the web server \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := NewCommand("cat").Transcribe(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "restart the web server" {
+		t.Errorf("text = %q", text)
+	}
+
+	if _, err := NewCommand("echo boom >&2; false").Transcribe(context.Background(), path); err == nil || err.Error() != "exit status 1: boom" {
+		t.Errorf("err = %v, want exit status with stderr", err)
+	}
+}
+
+func TestAPITranscriber(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"message": "bad key"}})
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		file.Close()
+		if r.FormValue("model") != DefaultModel || r.FormValue("language") != "en" {
+			http.Error(w, "bad form", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"text": " check disk space "})
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "voice.ogg")
+	if err := os.WriteFile(path, []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := NewAPI(srv.URL, "secret", "", "en").Transcribe(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "check disk space" {
+		t.Errorf("text = %q", text)
+	}
+
+	if _, err := NewAPI(srv.URL, "wrong", "", "en").Transcribe(context.Background(), path); err == nil || err.Error() != "bad key" {
+		t.Errorf("err = %v, want API error message", err)
+	}
+}
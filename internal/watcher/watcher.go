@@ -0,0 +1,124 @@
+// Package watcher reloads commands when files in the commands directories change.
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long to wait for further changes before reloading.
+const DefaultDebounce = 500 * time.Millisecond
+
+// Watcher watches directory trees for YAML changes and invokes a callback.
+type Watcher struct {
+	dirs     []string
+	debounce time.Duration
+	onChange func()
+}
+
+// New creates a watcher for dirs. onChange is called once per burst of changes.
+func New(dirs []string, debounce time.Duration, onChange func()) *Watcher {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	return &Watcher{
+		dirs:     dirs,
+		debounce: debounce,
+		onChange: onChange,
+	}
+}
+
+// Run watches until the context is cancelled.
+func (w *Watcher) Run(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+	defer fsw.Close()
+
+	for _, dir := range w.dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			slog.Warn("commands directory does not exist, not watching", "dir", dir)
+			continue
+		}
+		if err := w.addTree(fsw, dir); err != nil {
+			return err
+		}
+		slog.Info("watching commands directory", "dir", dir)
+	}
+
+	// Timer starts stopped; each relevant event resets it
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+
+			// Watch newly created subdirectories too
+			if event.Has(fsnotify.Create) {
+				if err := w.addTree(fsw, event.Name); err != nil {
+					slog.Warn("failed to watch new directory", "path", event.Name, "error", err)
+				}
+			}
+
+			if !relevant(event) {
+				continue
+			}
+			timer.Reset(w.debounce)
+
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Warn("watcher error", "error", err)
+
+		case <-timer.C:
+			w.onChange()
+		}
+	}
+}
+
+// addTree adds root and all its subdirectories to the watcher.
+// Non-directories are ignored.
+func (w *Watcher) addTree(fsw *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := fsw.Add(path); err != nil {
+			return fmt.Errorf("watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// relevant returns true for changes to YAML files or removed/renamed entries
+// (which may be directories containing YAML files).
+func relevant(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return false
+	}
+	ext := filepath.Ext(event.Name)
+	if ext == ".yaml" || ext == ".yml" {
+		return true
+	}
+	return event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+}
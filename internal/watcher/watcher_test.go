@@ -0,0 +1,53 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherDebouncesChanges(t *testing.T) {
+	dir := t.TempDir()
+	changes := make(chan struct{}, 10)
+
+	w := New([]string{dir}, 50*time.Millisecond, func() { changes <- struct{}{} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	// Give the watcher time to register
+	time.Sleep(100 * time.Millisecond)
+
+	// A burst of writes triggers a single reload
+	for i := range 3 {
+		path := filepath.Join(dir, "cmd.yaml")
+		if err := os.WriteFile(path, []byte("name: test"+string(rune('a'+i))), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected change callback")
+	}
+
+	select {
+	case <-changes:
+		t.Error("expected burst to be debounced into one callback")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Non-YAML files are ignored
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+		t.Error("non-YAML change should not trigger callback")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
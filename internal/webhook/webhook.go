@@ -0,0 +1,180 @@
+// Package webhook serves an HTTP endpoint that lets other systems, such as CI
+// or cron on another machine, trigger the bot's commands.
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Errors a Runner returns to select the HTTP status of the response.
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrForbidden      = errors.New("forbidden")
+	ErrInvalidArgs    = errors.New("invalid arguments")
+	ErrThrottled      = errors.New("rate limited")
+)
+
+// Request asks for a command to run in a chat.
+type Request struct {
+	Command string
+	ChatID  int64  // 0 for the default chat
+	Args    string // As typed after the command in Telegram, e.g. "env=prod"
+	Listed  bool   // Named in the server's commands, not only allowed by AllCommands
+}
+
+// AllCommands in the commands list allows every command. Commands that ask
+// for confirmation must still be named.
+const AllCommands = "*"
+
+// Runner starts a triggered command. It checks the request and returns
+// before the command finishes; the command runs with ctx, which outlives the
+// HTTP request.
+type Runner interface {
+	Trigger(ctx context.Context, req Request) error
+}
+
+// maxBodySize limits request bodies.
+const maxBodySize = 64 * 1024
+
+// Server handles POST /hooks/<command> with a bearer token and an optional
+// JSON body {"chat_id": 123, "args": "env=prod"}.
+type Server struct {
+	token    string
+	chatID   int64    // Used when a request names no chat
+	commands []string // Commands that may be triggered, or AllCommands; empty allows none
+	runner   Runner
+
+	ctx context.Context // Commands run with this; set by Run
+}
+
+// New creates a server. Requests must carry token.
+func New(token string, chatID int64, commands []string, runner Runner) *Server {
+	return &Server{
+		token:    token,
+		chatID:   chatID,
+		commands: commands,
+		runner:   runner,
+		ctx:      context.Background(),
+	}
+}
+
+// Run listens on addr until ctx is cancelled, then shuts down gracefully.
+func (s *Server) Run(ctx context.Context, addr string) error {
+	s.ctx = ctx
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	slog.Info("webhook server listening", "addr", addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// ServeHTTP handles one webhook request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutPrefix(r.URL.Path, "/hooks/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		reply(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !s.authorized(r) {
+		slog.Warn("webhook request with invalid token", "command", name, "remote", r.RemoteAddr)
+		reply(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+	listed := slices.Contains(s.commands, name)
+	if !listed && !slices.Contains(s.commands, AllCommands) {
+		reply(w, http.StatusForbidden, "command not enabled for webhooks")
+		return
+	}
+
+	var body struct {
+		ChatID int64  `json:"chat_id"`
+		Args   string `json:"args"`
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		reply(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			reply(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+	}
+
+	req := Request{Command: name, ChatID: body.ChatID, Args: body.Args, Listed: listed}
+	if req.ChatID == 0 {
+		req.ChatID = s.chatID
+	}
+
+	logger := slog.With("command", name, "chat_id", req.ChatID, "remote", r.RemoteAddr)
+	if err := s.runner.Trigger(s.ctx, req); err != nil {
+		logger.Warn("webhook request rejected", "error", err)
+		reply(w, statusFor(err), err.Error())
+		return
+	}
+	logger.Info("command triggered by webhook")
+	reply(w, http.StatusAccepted, "")
+}
+
+// authorized checks the bearer token in constant time.
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// statusFor maps a Runner error to an HTTP status.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrUnknownCommand):
+		return http.StatusNotFound
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrInvalidArgs):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrThrottled):
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+// reply writes a JSON response: {"status": "accepted"} on success, otherwise
+// {"error": "..."}.
+func reply(w http.ResponseWriter, status int, errMsg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	resp := map[string]string{"status": "accepted"}
+	if errMsg != "" {
+		resp = map[string]string{"error": errMsg}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
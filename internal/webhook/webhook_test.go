@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeRunner records triggered requests; the "missing" command is unknown.
+type fakeRunner struct {
+	got []Request
+}
+
+func (f *fakeRunner) Trigger(ctx context.Context, req Request) error {
+	if req.Command == "missing" {
+		return fmt.Errorf("%w: /missing", ErrUnknownCommand)
+	}
+	f.got = append(f.got, req)
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	runner := &fakeRunner{}
+	s := New("secret", 42, []string{"deploy", "missing"}, runner)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		want   int
+	}{
+		{"triggered", http.MethodPost, "/hooks/deploy", "secret", `{"chat_id": 7, "args": "env=prod"}`, http.StatusAccepted},
+		{"empty body", http.MethodPost, "/hooks/deploy", "secret", "", http.StatusAccepted},
+		{"wrong token", http.MethodPost, "/hooks/deploy", "guess", "", http.StatusUnauthorized},
+		{"not enabled", http.MethodPost, "/hooks/reboot", "secret", "", http.StatusForbidden},
+		{"unknown command", http.MethodPost, "/hooks/missing", "secret", "", http.StatusNotFound},
+		{"invalid body", http.MethodPost, "/hooks/deploy", "secret", "{", http.StatusBadRequest},
+		{"get", http.MethodGet, "/hooks/deploy", "secret", "", http.StatusMethodNotAllowed},
+		{"other path", http.MethodPost, "/deploy", "secret", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	want := []Request{
+		{Command: "deploy", ChatID: 7, Args: "env=prod", Listed: true},
+		{Command: "deploy", ChatID: 42, Listed: true},
+	}
+	if fmt.Sprint(runner.got) != fmt.Sprint(want) {
+		t.Errorf("triggered = %v, want %v", runner.got, want)
+	}
+}
+
+func TestServerCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands []string
+		want     int
+		listed   bool
+	}{
+		{"empty allows none", nil, http.StatusForbidden, false},
+		{"listed", []string{"deploy"}, http.StatusAccepted, true},
+		{"wildcard", []string{AllCommands}, http.StatusAccepted, false},
+		{"wildcard and listed", []string{AllCommands, "deploy"}, http.StatusAccepted, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{}
+			req := httptest.NewRequest(http.MethodPost, "/hooks/deploy", nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			New("secret", 42, tt.commands, runner).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want == http.StatusAccepted && (len(runner.got) != 1 || runner.got[0].Listed != tt.listed) {
+				t.Errorf("triggered = %v, want Listed %v", runner.got, tt.listed)
+			}
+		})
+	}
+}
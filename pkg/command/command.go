@@ -5,6 +5,7 @@ package command
 import (
 	"context"
 	"io"
+	"sync"
 	"time"
 )
 
@@ -27,8 +28,23 @@ type Metadata struct {
 	Timeout        time.Duration
 	MaxOutput      int
 	RequireConfirm bool
+	Hidden         bool   // Exclude from /help and menus (still runnable by name)
+	Disabled       bool   // Reject execution with a message
+	RequiredRole   string // Minimum role to run the command (admin, operator, viewer); empty = any
+	Approvals      int    // Distinct admin approvals needed before running (<= 1 = none)
+	NoSelfApproval bool   // The requester's own approval doesn't count towards Approvals
+	RequireOTP     bool   // Require a TOTP code from the requester before running
+	Elevated       bool   // Require an active /sudo session
+	Truncate       string // Part of output over the limit that is kept: TruncateHead ("" too), TruncateTail or TruncateHeadTail
 }
 
+// Truncation modes: which part of output longer than the limit is kept.
+const (
+	TruncateHead     = "head"      // The beginning (default)
+	TruncateTail     = "tail"      // The end, e.g. where a failing build stopped
+	TruncateHeadTail = "head_tail" // The beginning and the end, with a marker for the gap
+)
+
 // DefaultMetadata returns sensible defaults for command execution.
 func DefaultMetadata() Metadata {
 	return Metadata{
@@ -44,6 +60,89 @@ type WithMetadata interface {
 	Metadata() Metadata
 }
 
+// IsHidden returns true if the command should be excluded from /help and menus.
+func IsHidden(cmd Command) bool {
+	if withMeta, ok := cmd.(WithMetadata); ok {
+		return withMeta.Metadata().Hidden
+	}
+	return false
+}
+
+// IsDisabled returns true if the command is turned off and must not execute.
+func IsDisabled(cmd Command) bool {
+	if withMeta, ok := cmd.(WithMetadata); ok {
+		return withMeta.Metadata().Disabled
+	}
+	return false
+}
+
+// Truncation returns which part of the command's long output is kept:
+// TruncateHead unless the command sets another mode.
+func Truncation(cmd Command) string {
+	if withMeta, ok := cmd.(WithMetadata); ok && withMeta.Metadata().Truncate != "" {
+		return withMeta.Metadata().Truncate
+	}
+	return TruncateHead
+}
+
+// RequiredRole returns the minimum role needed to run the command, or "".
+func RequiredRole(cmd Command) string {
+	if withMeta, ok := cmd.(WithMetadata); ok {
+		return withMeta.Metadata().RequiredRole
+	}
+	return ""
+}
+
+// WithAccess extends Command with per-command chat and user restrictions.
+type WithAccess interface {
+	Command
+	// Permits returns true if the command may be run from the chat by the user.
+	Permits(chatID, userID int64, username string) bool
+}
+
+// WithTimeWindow extends Command with time-of-day restrictions.
+type WithTimeWindow interface {
+	Command
+	// AllowedAt returns true if the command may run at t.
+	AllowedAt(t time.Time) bool
+	// AllowedHours describes the permitted window, e.g. "09:00-18:00".
+	AllowedHours() string
+}
+
+// RequiredApprovals returns the number of distinct admin approvals the
+// command needs before running; values <= 1 mean no multi-person approval.
+func RequiredApprovals(cmd Command) int {
+	if withMeta, ok := cmd.(WithMetadata); ok {
+		return withMeta.Metadata().Approvals
+	}
+	return 0
+}
+
+// ExcludesRequester returns true if the requester of a command needing
+// approvals may not approve it.
+func ExcludesRequester(cmd Command) bool {
+	if withMeta, ok := cmd.(WithMetadata); ok {
+		return withMeta.Metadata().NoSelfApproval
+	}
+	return false
+}
+
+// RequiresOTP returns true if the command needs a TOTP code before running.
+func RequiresOTP(cmd Command) bool {
+	if withMeta, ok := cmd.(WithMetadata); ok {
+		return withMeta.Metadata().RequireOTP
+	}
+	return false
+}
+
+// RequiresElevation returns true if the command needs an active /sudo session.
+func RequiresElevation(cmd Command) bool {
+	if withMeta, ok := cmd.(WithMetadata); ok {
+		return withMeta.Metadata().Elevated
+	}
+	return false
+}
+
 // CategoryInfo holds category metadata for menu organization.
 type CategoryInfo struct {
 	Name string // Category name (e.g., "system", "deploy")
@@ -56,16 +155,106 @@ type WithCategory interface {
 	Category() CategoryInfo
 }
 
+// WithSubcommands extends Command for a namespace of subcommands, e.g.
+// /docker ps and /docker restart. A subcommand's Name is the parent's name
+// and its own, separated by a space ("docker ps").
+type WithSubcommands interface {
+	Command
+	Subcommands() []Command
+}
+
+// Subcommands returns the command's subcommands, or nil if it has none.
+func Subcommands(cmd Command) []Command {
+	if group, ok := cmd.(WithSubcommands); ok {
+		return group.Subcommands()
+	}
+	return nil
+}
+
 // FileResponse indicates a command wants to send a file after execution.
 type FileResponse struct {
 	Path    string // Path to the file to send
 	Caption string // Optional caption for the file
 	Cleanup bool   // If true, delete file after sending
+	Voice   bool   // If true, send as a voice message (converted to OGG/OPUS)
 }
 
 // WithFileResponse extends Command for commands that return files.
 // After Execute() completes, the bot checks FileResponse() and sends the file.
+// Commands that may run several times at once return the file with
+// SetFileResponse instead.
 type WithFileResponse interface {
 	Command
 	FileResponse() *FileResponse
 }
+
+// Input kinds a command can accept from an uploaded file.
+const (
+	InputDocument = "document" // A document sent with the command as caption
+	InputPhoto    = "photo"    // A photo sent with the command as caption (largest size)
+)
+
+// WithInput extends Command for commands that take an uploaded file. The
+// bot saves the file and passes its local path as the only argument.
+type WithInput interface {
+	Command
+	// Input returns the accepted kind (e.g. InputDocument), or "" for none.
+	Input() string
+}
+
+// AcceptsInput returns true if the command takes uploaded files of the kind.
+func AcceptsInput(cmd Command, kind string) bool {
+	if withInput, ok := cmd.(WithInput); ok {
+		return withInput.Input() == kind
+	}
+	return false
+}
+
+type chatIDKey struct{}
+
+// WithChatID returns a context carrying the chat a command runs for. The
+// bot sets it for every execution.
+func WithChatID(ctx context.Context, chatID int64) context.Context {
+	return context.WithValue(ctx, chatIDKey{}, chatID)
+}
+
+// ChatID returns the chat set by WithChatID.
+func ChatID(ctx context.Context) (int64, bool) {
+	chatID, ok := ctx.Value(chatIDKey{}).(int64)
+	return chatID, ok
+}
+
+type fileResponseKey struct{}
+
+// fileResponseSlot holds the file returned by one execution.
+type fileResponseSlot struct {
+	mu   sync.Mutex
+	resp *FileResponse
+}
+
+// WithFileResponseSlot returns a context in which Execute can return a file
+// with SetFileResponse, and a function reading it once Execute returns. The
+// file belongs to this execution only, so concurrent runs of a command
+// can't pick up each other's files. The bot sets it for every execution.
+func WithFileResponseSlot(ctx context.Context) (context.Context, func() *FileResponse) {
+	slot := &fileResponseSlot{}
+	read := func() *FileResponse {
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+		return slot.resp
+	}
+	return context.WithValue(ctx, fileResponseKey{}, slot), read
+}
+
+// SetFileResponse returns resp as the file of the execution ctx belongs to.
+// Returns false if ctx has no slot set by WithFileResponseSlot.
+func SetFileResponse(ctx context.Context, resp *FileResponse) bool {
+	slot, ok := ctx.Value(fileResponseKey{}).(*fileResponseSlot)
+	if !ok {
+		return false
+	}
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	slot.resp = resp
+	return true
+}